@@ -0,0 +1,164 @@
+// Package age provides a config.ValuePreProcessor decrypting values encrypted with age, https://age-encryption.org.
+// This keeps secrets in config files or the environment without a cloud secret store.
+//
+// It lives in its own module, so that applications which do not use age do not depend on it.
+package age
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"strings"
+
+	"filippo.io/age"
+	"filippo.io/age/armor"
+	"github.com/imduffy15/config"
+)
+
+const (
+	// Scheme prefixes values holding age ciphertext, either armored, as age -a writes it,
+	// or base64 encoded, e.g. age://YWdlLWVuY3J5cHRpb24ub3JnL3Yx...
+	Scheme = "age"
+	// FileScheme prefixes values naming a file holding age ciphertext, armored or binary, e.g. age-file://secrets/db.age.
+	FileScheme = "age-file"
+)
+
+// AgeValuePreProcessor is a ValuePreProcessor for values encrypted with age.
+type AgeValuePreProcessor struct {
+	identities []age.Identity
+	ctx        context.Context
+}
+
+// NewAgeValuePreProcessor creates a new AgeValuePreProcessor with the given context,
+// decrypting values with any of identities, e.g. those returned by age.ParseIdentities.
+func NewAgeValuePreProcessor(ctx context.Context, identities ...age.Identity) *AgeValuePreProcessor {
+	return &AgeValuePreProcessor{identities: identities, ctx: ctx}
+}
+
+// NewAgeValuePreProcessorFromFile creates a new AgeValuePreProcessor with the given context,
+// decrypting values with the identities in the file at path, as written by age-keygen.
+func NewAgeValuePreProcessorFromFile(ctx context.Context, path string) (*AgeValuePreProcessor, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("config/age: error opening identity file: %w", err)
+	}
+	defer f.Close()
+	identities, err := age.ParseIdentities(f)
+	if err != nil {
+		return nil, fmt.Errorf("config/age: error parsing identity file %s: %w", path, err)
+	}
+	return NewAgeValuePreProcessor(ctx, identities...), nil
+}
+
+// PreProcessValue pre-processes a config key/value pair.
+// It panics with a *config.Error with config.CodeSecretFetch if a value cannot be decrypted.
+func (p *AgeValuePreProcessor) PreProcessValue(key, value string) string {
+	return p.PreProcessValueContext(p.ctx, key, value)
+}
+
+// PreProcessValueContext pre-processes a config key/value pair, using ctx rather than the context given at construction.
+func (p *AgeValuePreProcessor) PreProcessValueContext(ctx context.Context, key, value string) string {
+	return p.Register(config.NewResolvers(ctx)).PreProcessValueContext(ctx, key, value)
+}
+
+// Register registers the age resolvers with r, returning r.
+// This allows encrypted values to be resolved alongside references to secret stores.
+func (p *AgeValuePreProcessor) Register(r *config.Resolvers) *config.Resolvers {
+	return r.Register(Scheme, p.Resolver()).Register(FileScheme, p.FileResolver())
+}
+
+// Resolver returns a config.Resolver for age:// values, decrypting the ciphertext which follows the scheme.
+// A single trailing newline of the plaintext is dropped, as echo adds one.
+func (p *AgeValuePreProcessor) Resolver() config.Resolver {
+	return config.ResolverFunc(func(ctx context.Context, ref string) (string, error) {
+		ref = strings.TrimSpace(ref)
+		if strings.HasPrefix(ref, armor.Header) {
+			return p.decrypt(armor.NewReader(strings.NewReader(ref)))
+		}
+		ciphertext, err := base64.StdEncoding.DecodeString(ref)
+		if err != nil {
+			if ciphertext, err = base64.RawStdEncoding.DecodeString(ref); err != nil {
+				return "", fmt.Errorf("config/age: ciphertext is neither armored nor base64: %w", err)
+			}
+		}
+		return p.decrypt(bytes.NewReader(ciphertext))
+	})
+}
+
+// FileResolver returns a config.Resolver for age-file:// values, decrypting the file at the path which follows
+// the scheme. A single trailing newline of the plaintext is dropped, as echo adds one.
+func (p *AgeValuePreProcessor) FileResolver() config.Resolver {
+	return config.ResolverFunc(func(ctx context.Context, ref string) (string, error) {
+		b, err := ioutil.ReadFile(ref)
+		if err != nil {
+			return "", fmt.Errorf("config/age: error reading %s: %w", ref, err)
+		}
+		if bytes.HasPrefix(bytes.TrimSpace(b), []byte(armor.Header)) {
+			return p.decrypt(armor.NewReader(bytes.NewReader(bytes.TrimSpace(b))))
+		}
+		return p.decrypt(bytes.NewReader(b))
+	})
+}
+
+// ageMagic starts every binary age file.
+var ageMagic = []byte("age-encryption.org/")
+
+// Payload returns a config.PayloadTransformer decrypting config files encrypted with age, armored or binary,
+// so that config.URLSource and the S3 source of github.com/imduffy15/config/aws can fetch encrypted config:
+//
+//	config.FromURL(ctx, "https://config.internal/billing.yaml.age", config.URLTransform(p.Payload())).To(&c)
+//
+// A .age extension is removed from the file's name, so the format of the config beneath is detected.
+// Files which are not encrypted with age are refused, rather than trusted as they are.
+func (p *AgeValuePreProcessor) Payload() config.PayloadTransformer {
+	return config.PayloadTransformerFunc(func(ctx context.Context, payload config.Payload) (config.Payload, error) {
+		var ciphertext io.Reader = bytes.NewReader(payload.Data)
+		switch data := bytes.TrimSpace(payload.Data); {
+		case bytes.HasPrefix(data, []byte(armor.Header)):
+			ciphertext = armor.NewReader(bytes.NewReader(data))
+		case !bytes.HasPrefix(payload.Data, ageMagic):
+			return config.Payload{}, fmt.Errorf("config/age: %s is not encrypted with age", payload.Name)
+		}
+		plaintext, err := p.decryptBytes(ciphertext)
+		if err != nil {
+			return config.Payload{}, err
+		}
+		if ext := path.Ext(payload.Name); strings.EqualFold(ext, ".age") {
+			payload.Name = strings.TrimSuffix(payload.Name, ext)
+		}
+		payload.ContentType = ""
+		payload.Data = plaintext
+		return payload, nil
+	})
+}
+
+func (p *AgeValuePreProcessor) decrypt(ciphertext io.Reader) (string, error) {
+	plaintext, err := p.decryptBytes(ciphertext)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(string(plaintext), "\n"), nil
+}
+
+func (p *AgeValuePreProcessor) decryptBytes(ciphertext io.Reader) ([]byte, error) {
+	if len(p.identities) == 0 {
+		return nil, fmt.Errorf("config/age: no identities to decrypt with")
+	}
+	r, err := age.Decrypt(ciphertext, p.identities...)
+	if err != nil {
+		return nil, fmt.Errorf("config/age: error decrypting: %w", err)
+	}
+	plaintext, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("config/age: error decrypting: %w", err)
+	}
+	return plaintext, nil
+}
+
+// compile time assertion
+var _ config.ContextValuePreProcessor = (*AgeValuePreProcessor)(nil)
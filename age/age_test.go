@@ -0,0 +1,129 @@
+package age
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"filippo.io/age"
+	"filippo.io/age/armor"
+	"github.com/imduffy15/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func encrypt(t *testing.T, recipient age.Recipient, plaintext string, armored bool) []byte {
+	var buf bytes.Buffer
+	var dst io.WriteCloser = nopCloser{&buf}
+	if armored {
+		dst = armor.NewWriter(&buf)
+	}
+	w, err := age.Encrypt(dst, recipient)
+	require.NoError(t, err)
+	_, err = io.WriteString(w, plaintext)
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+	require.NoError(t, dst.Close())
+	return buf.Bytes()
+}
+
+type nopCloser struct{ io.Writer }
+
+func (nopCloser) Close() error { return nil }
+
+func TestAgeValuePreProcessor_PreProcessValue(t *testing.T) {
+	identity, err := age.GenerateX25519Identity()
+	require.NoError(t, err)
+	other, err := age.GenerateX25519Identity()
+	require.NoError(t, err)
+	p := NewAgeValuePreProcessor(context.Background(), identity)
+
+	dir, err := ioutil.TempDir("", "age")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	binaryFile, armoredFile := filepath.Join(dir, "db.age"), filepath.Join(dir, "db.age.asc")
+	require.NoError(t, ioutil.WriteFile(binaryFile, encrypt(t, identity.Recipient(), "s3cr3t\n", false), 0600))
+	require.NoError(t, ioutil.WriteFile(armoredFile, encrypt(t, identity.Recipient(), "armored", true), 0600))
+
+	binary := encrypt(t, identity.Recipient(), "s3cr3t", false)
+	tests := map[string]string{
+		"age://" + string(encrypt(t, identity.Recipient(), "armored\n", true)): "armored",
+		"age://" + base64.StdEncoding.EncodeToString(binary):                   "s3cr3t",
+		"age://" + base64.RawStdEncoding.EncodeToString(binary):                "s3cr3t",
+		"age-file://" + binaryFile:                                             "s3cr3t",
+		"age-file://" + armoredFile:                                            "armored",
+		"plain":                                                                "plain",
+	}
+	for value, want := range tests {
+		assert.Equal(t, want, p.PreProcessValue("KEY", value), value)
+	}
+
+	errs := map[string]string{
+		"age://not base64!": "neither armored nor base64",
+		"age://" + base64.StdEncoding.EncodeToString(encrypt(t, other.Recipient(), "x", false)): "error decrypting",
+		"age-file://" + filepath.Join(dir, "missing"):                                           "error reading",
+	}
+	for value, want := range errs {
+		err := recoverError(func() { p.PreProcessValue("KEY", value) })
+		assert.Equal(t, config.CodeSecretFetch, config.CodeOf(err), value)
+		assert.Contains(t, fmt.Sprint(err), want, value)
+	}
+
+	err = recoverError(func() { NewAgeValuePreProcessor(context.Background()).PreProcessValue("KEY", "age-file://"+binaryFile) })
+	assert.Contains(t, fmt.Sprint(err), "no identities")
+}
+
+func TestNewAgeValuePreProcessorFromFile(t *testing.T) {
+	identity, err := age.GenerateX25519Identity()
+	require.NoError(t, err)
+	file, err := ioutil.TempFile("", "identity")
+	require.NoError(t, err)
+	defer os.Remove(file.Name())
+	_, err = fmt.Fprintf(file, "# created: 2022-01-01T00:00:00Z\n%s\n", identity)
+	require.NoError(t, err)
+	require.NoError(t, file.Close())
+
+	p, err := NewAgeValuePreProcessorFromFile(context.Background(), file.Name())
+	require.NoError(t, err)
+	var got struct{ Password string }
+	config.WithValuePreProcessor(p.Register(config.NewResolvers(context.Background()))).
+		FromSource(config.MapSource(map[string]string{"PASSWORD": "age://" + string(encrypt(t, identity.Recipient(), "s3cr3t", true))})).
+		To(&got)
+	assert.Equal(t, "s3cr3t", got.Password)
+
+	_, err = NewAgeValuePreProcessorFromFile(context.Background(), file.Name()+".missing")
+	assert.Error(t, err)
+}
+
+func TestAgeValuePreProcessor_Payload(t *testing.T) {
+	identity, err := age.GenerateX25519Identity()
+	require.NoError(t, err)
+	p := NewAgeValuePreProcessor(context.Background(), identity)
+	ctx := context.Background()
+
+	for _, armored := range []bool{false, true} {
+		payload := config.Payload{Name: "/prod/app.yaml.age", ContentType: "application/octet-stream", Data: encrypt(t, identity.Recipient(), "port: 8080\n", armored)}
+		values, err := config.LoadPayload(ctx, payload, p.Payload())
+		require.NoError(t, err)
+		assert.Equal(t, map[string]string{"port": "8080"}, values, "armored: %v", armored)
+	}
+
+	_, err = config.LoadPayload(ctx, config.Payload{Name: "/prod/app.env.age", Data: []byte("PORT=8080")}, p.Payload())
+	assert.Contains(t, fmt.Sprint(err), "/prod/app.env.age is not encrypted with age")
+}
+
+func recoverError(f func()) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = r.(error)
+		}
+	}()
+	f()
+	return nil
+}
@@ -0,0 +1,534 @@
+// Package aws provides a config.ValuePreProcessor resolving values from AWS Secrets Manager and Parameter Store,
+// and a config.SchemaPublisher writing schemas to S3.
+//
+// It lives in its own module, so that applications which only bind environment variables and files
+// do not depend on the AWS SDK.
+package aws
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/arn"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/credentials/stscreds"
+	"github.com/aws/aws-sdk-go-v2/service/kms"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+	smtypes "github.com/aws/aws-sdk-go-v2/service/secretsmanager/types"
+	"github.com/aws/aws-sdk-go-v2/service/ssm"
+	ssmtypes "github.com/aws/aws-sdk-go-v2/service/ssm/types"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/imduffy15/config"
+	"github.com/pkg/errors"
+	"golang.org/x/time/rate"
+)
+
+const (
+	// SecretsManagerScheme prefixes values resolved from Secrets Manager, e.g. sm://my_secret.
+	SecretsManagerScheme = "sm"
+	// ParameterStoreScheme prefixes values resolved from Parameter Store, e.g. ssm://my_parameter.
+	ParameterStoreScheme = "ssm"
+	// SecretsManagerMultiScheme prefixes values expanded into every Secrets Manager secret under a prefix,
+	// e.g. sm-multi://partners/*.
+	SecretsManagerMultiScheme = "sm-multi"
+	// SecretsManagerJSONScheme prefixes values expanded into a key per field of a Secrets Manager secret
+	// holding a JSON object, e.g. sm-json://myapp/prod.
+	SecretsManagerJSONScheme = "sm-json"
+)
+
+var base64EncodingStringRe = regexp.MustCompile("^base64://")
+
+func checkPrefixAndStrip(re *regexp.Regexp, s string) (string, bool) {
+	if re.MatchString(s) {
+		return re.ReplaceAllString(s, ""), true
+	}
+	return s, false
+}
+
+// splitSubKey splits a secret reference at its last unescaped '#' into the secret name and the subkey.
+// Within both, `\#` stands for a literal '#' and `\\` for a literal backslash; any other backslash is kept as is.
+// So db#password selects the password key of secret db, app#1#key selects key of secret app#1,
+// and db#pass\#word selects the pass#word key of secret db.
+func splitSubKey(s string) (name, subKey string) {
+	split := -1
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '#':
+			split = i
+		}
+	}
+	if split < 0 {
+		return unescapeReference(s), ""
+	}
+	return unescapeReference(s[:split]), unescapeReference(s[split+1:])
+}
+
+func unescapeReference(s string) string {
+	if !strings.Contains(s, "\\") {
+		return s
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+1 < len(s) && (s[i+1] == '#' || s[i+1] == '\\') {
+			i++
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
+
+// Option configures how NewAWSSecretManagerValuePreProcessor loads the aws config.
+type Option func(*awsconfig.LoadOptions) error
+
+// WithProfile loads the aws config from the named profile of the shared config files, e.g. ~/.aws/config,
+// rather than AWS_PROFILE or the default profile. This lets developers signed in with aws sso login
+// resolve references with their SSO profile, without exporting temporary credentials.
+func WithProfile(profile string) Option {
+	return Option(awsconfig.WithSharedConfigProfile(profile))
+}
+
+// NewAWSSecretManagerValuePreProcessor creates a new AWSSecretManagerValuePreProcessor with the given context and whether to decrypt parameter store values or not.
+// This will load the aws config from external.LoadDefaultAWSConfig(), as configured by opts.
+func NewAWSSecretManagerValuePreProcessor(ctx context.Context, decryptParameterStoreValues bool, opts ...Option) (*AWSSecretManagerValuePreProcessor, error) {
+	loadOptions := []func(*awsconfig.LoadOptions) error{awsconfig.WithEC2IMDSRegion()}
+	for _, opt := range opts {
+		loadOptions = append(loadOptions, opt)
+	}
+	awsConfig, err := awsconfig.LoadDefaultConfig(ctx, loadOptions...)
+	if err != nil {
+		return nil, errors.Wrap(err, "config/aws: error loading default aws config")
+	}
+
+	return &AWSSecretManagerValuePreProcessor{
+		decryptParameterStoreValues: decryptParameterStoreValues,
+
+		secretsManager: secretsmanager.NewFromConfig(awsConfig),
+		parameterStore: ssm.NewFromConfig(awsConfig),
+		objects:        s3.NewFromConfig(awsConfig),
+		kms:            kms.NewFromConfig(awsConfig),
+		identity:       sts.NewFromConfig(awsConfig),
+		region:         awsConfig.Region,
+		ctx:            ctx,
+		newRoleClient:  roleClientFactory(awsConfig),
+	}, nil
+}
+
+type SecretsManager interface {
+	GetSecretValue(ctx context.Context, params *secretsmanager.GetSecretValueInput, optFns ...func(*secretsmanager.Options)) (*secretsmanager.GetSecretValueOutput, error)
+}
+
+// SecretsManagerLister is implemented by SecretsManager clients which can list secrets, as needed by sm-multi://.
+type SecretsManagerLister interface {
+	ListSecrets(ctx context.Context, params *secretsmanager.ListSecretsInput, optFns ...func(*secretsmanager.Options)) (*secretsmanager.ListSecretsOutput, error)
+}
+
+type ParameterStoreManager interface {
+	GetParameter(ctx context.Context, params *ssm.GetParameterInput, optFns ...func(*ssm.Options)) (*ssm.GetParameterOutput, error)
+}
+
+// AWSSecretManagerValuePreProcessor is a ValuePreProcessor for AWS.
+// Supports Secrets Manager and Parameter Store.
+type AWSSecretManagerValuePreProcessor struct {
+	decryptParameterStoreValues bool
+	splitStringLists            bool
+	// limiter, when set, limits the rate of requests to Secrets Manager and Parameter Store.
+	limiter *rate.Limiter
+
+	secretsManager SecretsManager
+	parameterStore ParameterStoreManager
+	// objects downloads config files from S3. See FromS3.
+	objects S3GetObjectAPI
+	// kms decrypts config files. See KMSPayload.
+	kms KMSDecryptAPI
+	ctx context.Context
+
+	// identity and region name the caller and resources of denied requests. See AccessDeniedError.
+	identity CallerIdentityAPI
+	region   string
+	callerMu sync.Mutex
+	caller   string
+
+	// newRoleClient creates a Secrets Manager client which assumes role, in region if it is set.
+	newRoleClient func(role, region string) SecretsManager
+	roleClientsMu sync.Mutex
+	roleClients   map[string]SecretsManager
+}
+
+// WithSplitStringLists makes Parameter Store parameters of type StringList resolve to their items
+// separated by spaces rather than commas, so they bind to slice fields, returning the AWSSecretManagerValuePreProcessor.
+// Surrounding whitespace is trimmed from each item. Items containing whitespace, quotes or backslashes are quoted,
+// so that they are bound whole to slice fields tagged `config:",quoted"`, see config.SplitQuoted; other slice fields
+// split them further.
+func (p *AWSSecretManagerValuePreProcessor) WithSplitStringLists() *AWSSecretManagerValuePreProcessor {
+	p.splitStringLists = true
+	return p
+}
+
+// WithRateLimit limits the rate of requests to Secrets Manager and Parameter Store, returning the
+// AWSSecretManagerValuePreProcessor. Requests over the limit wait their turn, up to their context's deadline,
+// so that a fleet restarting at once, each process resolving many values, is less likely to be throttled
+// by AWS and fail to start. The limit applies across both services, and to requests on behalf of
+// assumed roles, allowing bursts of up to limit.Events requests. An unlimited limit, the zero value, removes it.
+func (p *AWSSecretManagerValuePreProcessor) WithRateLimit(limit config.RateLimit) *AWSSecretManagerValuePreProcessor {
+	p.limiter = nil
+	if !limit.Unlimited() {
+		p.limiter = limit.Limiter()
+	}
+	return p
+}
+
+// wait blocks until the rate limit set by WithRateLimit allows a request.
+func (p *AWSSecretManagerValuePreProcessor) wait(ctx context.Context) error {
+	if p.limiter == nil {
+		return nil
+	}
+	return errors.Wrap(p.limiter.Wait(ctx), "config/aws: rate limit")
+}
+
+// PreProcessValue pre-processes a config key/value pair.
+// It panics with a *config.Error with config.CodeSecretFetch if a value cannot be loaded.
+func (p *AWSSecretManagerValuePreProcessor) PreProcessValue(key, value string) string {
+	return p.PreProcessValueContext(p.ctx, key, value)
+}
+
+// PreProcessValueContext pre-processes a config key/value pair, using ctx rather than the context given at construction.
+func (p *AWSSecretManagerValuePreProcessor) PreProcessValueContext(ctx context.Context, key, value string) string {
+	return p.Register(config.NewResolvers(ctx)).PreProcessValueContext(ctx, key, value)
+}
+
+// ExpandValue expands sm-multi:// and sm-json:// values. See SecretsManagerExpander and SecretsManagerJSONExpander.
+func (p *AWSSecretManagerValuePreProcessor) ExpandValue(key, value string) (map[string]string, bool, error) {
+	return p.Register(config.NewResolvers(p.ctx)).ExpandValue(key, value)
+}
+
+// Register registers the Secrets Manager and Parameter Store resolvers, and the Secrets Manager expanders, with r,
+// returning r. This allows AWS references to be resolved alongside those of other providers.
+func (p *AWSSecretManagerValuePreProcessor) Register(r *config.Resolvers) *config.Resolvers {
+	return r.
+		Register(SecretsManagerScheme, p.SecretsManagerResolver()).
+		Register(ParameterStoreScheme, p.ParameterStoreResolver()).
+		RegisterExpander(SecretsManagerMultiScheme, p.SecretsManagerExpander()).
+		RegisterExpander(SecretsManagerJSONScheme, p.SecretsManagerJSONExpander())
+}
+
+// SecretsManagerExpander returns a config.Expander for references to every secret whose name starts with a prefix,
+// written as the prefix followed by "*", e.g. partners/*. Each secret is named by the rest of its name,
+// so with PARTNERS=sm-multi://partners/* the secret partners/acme is bound from PARTNERS__ACME.
+// The SecretsManager client must also be a SecretsManagerLister.
+func (p *AWSSecretManagerValuePreProcessor) SecretsManagerExpander() config.Expander {
+	return config.ExpanderFunc(p.expandSecrets)
+}
+
+func (p *AWSSecretManagerValuePreProcessor) expandSecrets(ctx context.Context, ref string) (map[string]string, error) {
+	if !strings.HasSuffix(ref, "*") {
+		return nil, errors.Errorf("config/aws: %s must end with *", ref)
+	}
+	lister, ok := p.secretsManager.(SecretsManagerLister)
+	if !ok {
+		return nil, errors.New("config/aws: secrets manager client cannot list secrets")
+	}
+	prefix := strings.TrimSuffix(ref, "*")
+
+	names, err := p.listSecrets(ctx, lister, prefix)
+	if err != nil {
+		return nil, err
+	}
+	secrets := make(map[string]string, len(names))
+	for _, name := range names {
+		secret, err := p.loadStringValueFromSecretsManager(ctx, p.secretsManager, name, "")
+		if err != nil {
+			return nil, err
+		}
+		secrets[strings.TrimPrefix(name, prefix)] = secret
+	}
+	return secrets, nil
+}
+
+func (p *AWSSecretManagerValuePreProcessor) listSecrets(ctx context.Context, lister SecretsManagerLister, prefix string) ([]string, error) {
+	input := &secretsmanager.ListSecretsInput{}
+	if prefix != "" {
+		input.Filters = []smtypes.Filter{{Key: smtypes.FilterNameStringTypeName, Values: []string{prefix}}}
+	}
+	var names []string
+	for {
+		if err := p.wait(ctx); err != nil {
+			return nil, err
+		}
+		resp, err := lister.ListSecrets(ctx, input)
+		if isAccessDenied(err) {
+			return nil, p.accessDenied(ctx, err, "secretsmanager:ListSecrets", "secret", "*", "")
+		}
+		if err != nil {
+			return nil, errors.Wrapf(err, "config/aws: error listing secrets %s*", prefix)
+		}
+		for _, entry := range resp.SecretList {
+			// the name filter matches words anywhere in the name, so check the prefix
+			if entry.Name != nil && strings.HasPrefix(*entry.Name, prefix) {
+				names = append(names, *entry.Name)
+			}
+		}
+		if resp.NextToken == nil {
+			return names, nil
+		}
+		input.NextToken = resp.NextToken
+	}
+}
+
+// SecretsManagerResolver returns a config.Resolver for Secrets Manager references.
+// References are secret names or ARNs, optionally prefixed with base64:// if the secret is base64 encoded,
+// and optionally suffixed with #subkey to select a single key of a secret holding a JSON object.
+// Only the last unescaped '#' starts a subkey: write \# for a literal '#', and \\ for a literal backslash.
+//
+// Secrets shared from another account, e.g. a central secrets account, are referenced by ARN,
+// optionally followed by the role to assume to read them:
+//
+//	sm://arn:aws:secretsmanager:eu-west-1:111111111111:secret:shared/db-AbCdEf#password?role=arn:aws:iam::111111111111:role/reader
+//
+// The secret is fetched from the ARN's region. Clients for assumed roles are created on first use and reused.
+func (p *AWSSecretManagerValuePreProcessor) SecretsManagerResolver() config.Resolver {
+	return config.ResolverFunc(p.resolveSecret)
+}
+
+// ParameterStoreResolver returns a config.Resolver for Parameter Store references, which are parameter names or ARNs.
+// A revision can be pinned with a version, e.g. ssm://name:3, or a label, e.g. ssm://name#approved.
+func (p *AWSSecretManagerValuePreProcessor) ParameterStoreResolver() config.Resolver {
+	return config.ResolverFunc(func(ctx context.Context, ref string) (string, error) {
+		name, err := parameterSelector(ref)
+		if err != nil {
+			return "", err
+		}
+		return p.loadStringValueFromParameterStore(ctx, name, p.decryptParameterStoreValues)
+	})
+}
+
+// parameterSelector rewrites a name#label reference in the name:label form GetParameter accepts.
+// name:version references are already in that form.
+func parameterSelector(ref string) (string, error) {
+	i := strings.LastIndex(ref, "#")
+	if i < 0 {
+		return ref, nil
+	}
+	name, label := ref[:i], ref[i+1:]
+	if label == "" {
+		return "", errors.Errorf("config/aws: %s has an empty label", ref)
+	}
+	if _, version, ok := splitVersion(name); ok {
+		return "", errors.Errorf("config/aws: %s has both version %s and label %s", ref, version, label)
+	}
+	return name + ":" + label, nil
+}
+
+// splitVersion splits a name:version reference, reporting false if ref has no version.
+// Only a final all digit segment is a version, so the colons of an ARN are not mistaken for one.
+func splitVersion(ref string) (name, version string, ok bool) {
+	i := strings.LastIndex(ref, ":")
+	if i <= 0 || i == len(ref)-1 {
+		return ref, "", false
+	}
+	for _, r := range ref[i+1:] {
+		if r < '0' || r > '9' {
+			return ref, "", false
+		}
+	}
+	return ref[:i], ref[i+1:], true
+}
+
+func (p *AWSSecretManagerValuePreProcessor) resolveSecret(ctx context.Context, ref string) (string, error) {
+	ref, role, err := splitRole(ref)
+	if err != nil {
+		return "", err
+	}
+	v, base64Encoded := checkPrefixAndStrip(base64EncodingStringRe, ref)
+	v, subKey := splitSubKey(v)
+
+	client := p.secretsManager
+	if role != "" {
+		if client, err = p.roleClient(role, regionOf(v)); err != nil {
+			return "", err
+		}
+	}
+	secret, err := p.loadStringValueFromSecretsManager(ctx, client, v, role)
+	if err != nil {
+		return "", err
+	}
+	if base64Encoded {
+		decodedSecret, err := base64.StdEncoding.DecodeString(secret)
+		if err != nil {
+			return "", errors.Wrap(err, "config/aws: failed to decode the secret")
+		}
+		secret = string(decodedSecret)
+	}
+	if subKey == "" {
+		return secret, nil
+	}
+
+	jsonMap := make(map[string]string)
+	if err := json.Unmarshal([]byte(secret), &jsonMap); err != nil {
+		return "", errors.Wrap(err, "config/aws: error parsing secret map")
+	}
+	subkeySecret, ok := jsonMap[subKey]
+	if !ok {
+		return "", errors.Errorf("config/aws: failed to find subkey %s", subKey)
+	}
+	return subkeySecret, nil
+}
+
+// loadStringValueFromSecretsManager loads the secret name with client, which assumes role if it is set.
+func (p *AWSSecretManagerValuePreProcessor) loadStringValueFromSecretsManager(ctx context.Context, client SecretsManager, name, role string) (string, error) {
+	if err := p.wait(ctx); err != nil {
+		return "", err
+	}
+	resp, err := client.GetSecretValue(ctx, &secretsmanager.GetSecretValueInput{SecretId: aws.String(name)})
+	if isAccessDenied(err) {
+		return "", p.accessDenied(ctx, err, "secretsmanager:GetSecretValue", "secret", name, role)
+	}
+	if err != nil {
+		return "", errors.Wrapf(err, "config/aws: error loading secret %s", name)
+	}
+
+	return *resp.SecretString, nil
+}
+
+// splitRole splits a trailing ?role= query off a Secrets Manager reference. Any other ? is part of the
+// reference, e.g. of a sub key.
+func splitRole(ref string) (string, string, error) {
+	i := strings.LastIndex(ref, "?role=")
+	if i < 0 {
+		return ref, "", nil
+	}
+	role, err := url.QueryUnescape(ref[i+len("?role="):])
+	if err != nil {
+		return "", "", errors.Wrapf(err, "config/aws: invalid role in %s", ref)
+	}
+	if !arn.IsARN(role) {
+		return "", "", errors.Errorf("config/aws: role %q in %s is not an ARN", role, ref)
+	}
+	return ref[:i], role, nil
+}
+
+// regionOf returns the region of a secret referenced by ARN, or "" for secrets referenced by name.
+func regionOf(name string) string {
+	a, err := arn.Parse(name)
+	if err != nil {
+		return ""
+	}
+	return a.Region
+}
+
+// roleClient returns the client for role in region, creating it on first use.
+func (p *AWSSecretManagerValuePreProcessor) roleClient(role, region string) (SecretsManager, error) {
+	if p.newRoleClient == nil {
+		return nil, errors.Errorf("config/aws: cannot assume role %s without an AWS config, use NewAWSSecretManagerValuePreProcessor", role)
+	}
+	p.roleClientsMu.Lock()
+	defer p.roleClientsMu.Unlock()
+	key := role + " " + region
+	if client, ok := p.roleClients[key]; ok {
+		return client, nil
+	}
+	if p.roleClients == nil {
+		p.roleClients = make(map[string]SecretsManager)
+	}
+	client := p.newRoleClient(role, region)
+	p.roleClients[key] = client
+	return client, nil
+}
+
+// roleClientFactory returns a function creating Secrets Manager clients which assume a role, derived from awsConfig.
+func roleClientFactory(awsConfig aws.Config) func(role, region string) SecretsManager {
+	return func(role, region string) SecretsManager {
+		cfg := awsConfig.Copy()
+		cfg.Credentials = aws.NewCredentialsCache(stscreds.NewAssumeRoleProvider(sts.NewFromConfig(awsConfig), role))
+		if region != "" {
+			cfg.Region = region
+		}
+		return secretsmanager.NewFromConfig(cfg)
+	}
+}
+
+func (p *AWSSecretManagerValuePreProcessor) loadStringValueFromParameterStore(ctx context.Context, name string, decrypt bool) (string, error) {
+	resp, err := p.requestParameter(ctx, name, decrypt)
+
+	if isAccessDenied(err) {
+		return "", p.accessDenied(ctx, err, "ssm:GetParameter", "parameter", parameterName(name), "")
+	}
+	if err != nil {
+		return "", errors.Wrapf(err, "config/aws: error loading parameter %s", name)
+	}
+
+	if p.splitStringLists && resp.Parameter.Type == ssmtypes.ParameterTypeStringList {
+		return splitStringList(*resp.Parameter.Value), nil
+	}
+	return *resp.Parameter.Value, nil
+}
+
+// splitStringList rewrites a comma separated StringList with the slice delimiter, space,
+// double quoting items which config.SplitQuoted would otherwise split or unescape.
+func splitStringList(s string) string {
+	items := strings.Split(s, ",")
+	for i, item := range items {
+		item = strings.TrimSpace(item)
+		if strings.ContainsAny(item, " \t\n\v\f\r\"'\\") {
+			item = `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(item) + `"`
+		}
+		items[i] = item
+	}
+	return strings.Join(items, " ")
+}
+
+func (p *AWSSecretManagerValuePreProcessor) requestParameter(ctx context.Context, name string, decrypt bool) (*ssm.GetParameterOutput, error) {
+	if err := p.wait(ctx); err != nil {
+		return nil, err
+	}
+	return p.parameterStore.GetParameter(ctx, &ssm.GetParameterInput{
+		Name:           aws.String(name),
+		WithDecryption: decrypt,
+	})
+}
+
+// compile time assertions
+var (
+	_ config.ContextValuePreProcessor = (*AWSSecretManagerValuePreProcessor)(nil)
+	_ config.ValueExpander            = (*AWSSecretManagerValuePreProcessor)(nil)
+	_ SecretsManagerLister            = (*secretsmanager.Client)(nil)
+	_ S3GetObjectAPI                  = (*s3.Client)(nil)
+	_ ParameterStorePathLister        = (*ssm.Client)(nil)
+)
+
+// S3PutObjectAPI is implemented by S3 clients which can put objects, as needed by S3SchemaPublisher.
+type S3PutObjectAPI interface {
+	PutObject(ctx context.Context, params *s3.PutObjectInput, optFns ...func(*s3.Options)) (*s3.PutObjectOutput, error)
+}
+
+// S3SchemaPublisher is a config.SchemaPublisher which writes schemas to Bucket,
+// as objects named Prefix + service/version.json, with a Content-Type of application/schema+json.
+type S3SchemaPublisher struct {
+	Client S3PutObjectAPI
+	Bucket string
+	Prefix string
+}
+
+// PublishSchema implements config.SchemaPublisher.
+func (p *S3SchemaPublisher) PublishSchema(ctx context.Context, service, version string, schema []byte) error {
+	key := p.Prefix + service + "/" + version + ".json"
+	_, err := p.Client.PutObject(ctx, &s3.PutObjectInput{
+		Bucket:      aws.String(p.Bucket),
+		Key:         aws.String(key),
+		Body:        bytes.NewReader(schema),
+		ContentType: aws.String("application/schema+json"),
+	})
+	if err != nil {
+		return errors.Wrapf(err, "config/aws: error publishing schema to s3://%s/%s", p.Bucket, key)
+	}
+	return nil
+}
@@ -0,0 +1,790 @@
+package aws
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/ed25519"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/kms"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+	smtypes "github.com/aws/aws-sdk-go-v2/service/secretsmanager/types"
+	"github.com/aws/aws-sdk-go-v2/service/ssm"
+	"github.com/aws/aws-sdk-go-v2/service/ssm/types"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/aws/smithy-go"
+	"github.com/aws/smithy-go/middleware"
+	"github.com/imduffy15/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockSecretManagerClient struct {
+	checkInput  func(*secretsmanager.GetSecretValueInput)
+	stringValue *string
+	binaryValue []byte
+	err         error
+}
+
+func (m *mockSecretManagerClient) GetSecretValue(ctx context.Context, params *secretsmanager.GetSecretValueInput, optFns ...func(*secretsmanager.Options)) (*secretsmanager.GetSecretValueOutput, error) {
+	if m.checkInput != nil {
+		m.checkInput(params)
+	}
+	if m.err != nil {
+		return nil, m.err
+	}
+
+	return &secretsmanager.GetSecretValueOutput{
+		ARN:            nil,
+		CreatedDate:    nil,
+		Name:           nil,
+		SecretBinary:   m.binaryValue,
+		SecretString:   m.stringValue,
+		VersionId:      nil,
+		VersionStages:  nil,
+		ResultMetadata: middleware.Metadata{},
+	}, nil
+}
+
+type mockParameterStoreClient struct {
+	checkInput    func(*ssm.GetParameterInput)
+	stringValue   *string
+	binaryValue   []byte
+	parameterType types.ParameterType
+	err           error
+}
+
+func (m mockParameterStoreClient) GetParameter(ctx context.Context, params *ssm.GetParameterInput, optFns ...func(*ssm.Options)) (*ssm.GetParameterOutput, error) {
+	if m.checkInput != nil {
+		m.checkInput(params)
+	}
+	if m.err != nil {
+		return nil, m.err
+	}
+
+	var value *string
+
+	if m.stringValue != nil {
+		value = m.stringValue
+	} else if m.binaryValue != nil {
+		value = aws.String(base64.StdEncoding.EncodeToString(m.binaryValue))
+	}
+
+	return &ssm.GetParameterOutput{
+		Parameter: &types.Parameter{
+			Value: value,
+			Type:  m.parameterType,
+		},
+		ResultMetadata: middleware.Metadata{},
+	}, nil
+}
+
+func TestAWSSecretManagerValuePreProcessor_PreProcessValue(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("NonPrefixedValues", func(t *testing.T) {
+		p := AWSSecretManagerValuePreProcessor{}
+
+		assert.Equal(t, "bar", p.PreProcessValue("FOO_1", "bar"))
+		assert.Equal(t, "test", p.PreProcessValue("FOO_BAR_BAZ", "test"))
+	})
+
+	t.Run("SecretsManager", func(t *testing.T) {
+		manager := &mockSecretManagerClient{}
+
+		p := &AWSSecretManagerValuePreProcessor{
+			decryptParameterStoreValues: true,
+			secretsManager:              manager,
+			ctx:                         ctx,
+		}
+
+		t.Run("Simple", func(t *testing.T) {
+			manager.checkInput = func(input *secretsmanager.GetSecretValueInput) {
+				assert.Equal(t, "foo_bar", *input.SecretId)
+			}
+			manager.stringValue = aws.String("baz")
+
+			assert.Equal(t, "baz", p.PreProcessValue("FOO", "sm://foo_bar"))
+		})
+
+		// "complex" in the sense that this would break using strings.TrimPrefix(...)
+		t.Run("Complex", func(t *testing.T) {
+			manager.checkInput = func(input *secretsmanager.GetSecretValueInput) {
+				assert.Equal(t, "small_foo_bar", *input.SecretId)
+			}
+			manager.stringValue = aws.String("baz")
+
+			assert.Equal(t, "baz", p.PreProcessValue("FOO", "sm://small_foo_bar"))
+		})
+
+		t.Run("Error", func(t *testing.T) {
+			manager.checkInput = nil
+			manager.err = errors.New("AccessDeniedException")
+			defer func() { manager.err = nil }()
+
+			err := recoverError(func() { p.PreProcessValue("FOO", "sm://foo_bar") })
+			assert.Equal(t, config.CodeSecretFetch, config.CodeOf(err))
+			assert.Equal(t, "FOO", err.(*config.Error).Key(), "the key being resolved is reported")
+			assert.Contains(t, err.Error(), "error loading secret foo_bar: AccessDeniedException")
+		})
+	})
+
+	t.Run("ParameterStore", func(t *testing.T) {
+		storeClient := &mockParameterStoreClient{}
+
+		p := &AWSSecretManagerValuePreProcessor{
+			decryptParameterStoreValues: true,
+			parameterStore:              storeClient,
+			ctx:                         ctx,
+		}
+
+		t.Run("Simple", func(t *testing.T) {
+			storeClient.checkInput = func(input *ssm.GetParameterInput) {
+				assert.Equal(t, "foo_bar", *input.Name)
+				assert.True(t, input.WithDecryption)
+			}
+			storeClient.stringValue = aws.String("baz")
+
+			assert.Equal(t, "baz", p.PreProcessValue("FOO", "ssm://foo_bar"))
+		})
+
+		// "complex" in the sense that this would break using strings.TrimPrefix(...)
+		t.Run("Complex", func(t *testing.T) {
+			storeClient.checkInput = func(input *ssm.GetParameterInput) {
+				assert.Equal(t, "ssmall_foo_bar", *input.Name)
+				assert.True(t, input.WithDecryption)
+			}
+			storeClient.stringValue = aws.String("baz")
+
+			assert.Equal(t, "baz", p.PreProcessValue("FOO", "ssm://ssmall_foo_bar"))
+		})
+
+		t.Run("Error", func(t *testing.T) {
+			storeClient.checkInput = nil
+			storeClient.err = errors.New("ParameterNotFound")
+			defer func() { storeClient.err = nil }()
+
+			err := recoverError(func() { p.PreProcessValue("FOO", "ssm://foo_bar") })
+			assert.Equal(t, config.CodeSecretFetch, config.CodeOf(err))
+			assert.Equal(t, "FOO", err.(*config.Error).Key(), "the key being resolved is reported")
+			assert.Contains(t, err.Error(), "ParameterNotFound")
+		})
+	})
+}
+
+func Test_parameterSelector(t *testing.T) {
+	tests := []struct {
+		in, want, wantErr string
+	}{
+		{in: "db_host", want: "db_host"},
+		{in: "/prod/db/host:3", want: "/prod/db/host:3"},
+		{in: "/prod/db/host#approved", want: "/prod/db/host:approved"},
+		{in: "arn:aws:ssm:eu-west-1:123456789012:parameter/prod/db/host", want: "arn:aws:ssm:eu-west-1:123456789012:parameter/prod/db/host"},
+		{in: "arn:aws:ssm:eu-west-1:123456789012:parameter/prod/db/host:12", want: "arn:aws:ssm:eu-west-1:123456789012:parameter/prod/db/host:12"},
+		{in: "arn:aws:ssm:eu-west-1:123456789012:parameter/prod/db/host#live", want: "arn:aws:ssm:eu-west-1:123456789012:parameter/prod/db/host:live"},
+		{in: "db_host#", wantErr: "config/aws: db_host# has an empty label"},
+		{in: "db_host:3#live", wantErr: "config/aws: db_host:3#live has both version 3 and label live"},
+	}
+	for _, tt := range tests {
+		got, err := parameterSelector(tt.in)
+		if tt.wantErr != "" {
+			assert.EqualError(t, err, tt.wantErr, tt.in)
+			continue
+		}
+		assert.NoError(t, err, tt.in)
+		assert.Equal(t, tt.want, got, tt.in)
+	}
+
+	storeClient := &mockParameterStoreClient{stringValue: aws.String("db.internal")}
+	p := &AWSSecretManagerValuePreProcessor{parameterStore: storeClient, ctx: context.Background()}
+	storeClient.checkInput = func(input *ssm.GetParameterInput) {
+		assert.Equal(t, "/prod/db/host:approved", *input.Name)
+	}
+	assert.Equal(t, "db.internal", p.PreProcessValue("HOST", "ssm:///prod/db/host#approved"))
+}
+
+func TestAWSSecretManagerValuePreProcessor_WithSplitStringLists(t *testing.T) {
+	storeClient := &mockParameterStoreClient{stringValue: aws.String("eu-west-1a, eu-west-1b,eu-west-1c"), parameterType: types.ParameterTypeStringList}
+	p := &AWSSecretManagerValuePreProcessor{parameterStore: storeClient, ctx: context.Background()}
+	assert.Equal(t, "eu-west-1a, eu-west-1b,eu-west-1c", p.PreProcessValue("ZONES", "ssm://zones"), "StringLists are left as is by default")
+
+	var got struct {
+		Zones []string
+		Name  string
+	}
+	config.WithValuePreProcessor(p.WithSplitStringLists()).
+		FromSource(config.MapSource(map[string]string{"ZONES": "ssm://zones"})).
+		To(&got)
+	assert.Equal(t, []string{"eu-west-1a", "eu-west-1b", "eu-west-1c"}, got.Zones)
+
+	storeClient.stringValue = aws.String(`Acme Corp, "Quoted", C:\Users,plain`)
+	var quoted struct {
+		Names []string `config:",quoted"`
+	}
+	config.WithValuePreProcessor(p).
+		FromSource(config.MapSource(map[string]string{"NAMES": "ssm://names"})).
+		To(&quoted)
+	assert.Equal(t, []string{"Acme Corp", `"Quoted"`, `C:\Users`, "plain"}, quoted.Names, "items round-trip into quoted slices")
+
+	storeClient.parameterType = types.ParameterTypeString
+	assert.Equal(t, `Acme Corp, "Quoted", C:\Users,plain`, p.PreProcessValue("NAME", "ssm://name"), "only StringLists are split")
+}
+
+func TestAWSSecretManagerValuePreProcessor_WithRateLimit(t *testing.T) {
+	storeClient := &mockParameterStoreClient{stringValue: aws.String("value")}
+	p := &AWSSecretManagerValuePreProcessor{
+		secretsManager: &mockSecretManagerClient{stringValue: aws.String("secret")},
+		parameterStore: storeClient,
+		ctx:            context.Background(),
+	}
+	p.WithRateLimit(config.RateLimit{Events: 2, Interval: 100 * time.Millisecond})
+
+	start := time.Now()
+	assert.Equal(t, "value", p.PreProcessValue("A", "ssm://a"))
+	assert.Equal(t, "secret", p.PreProcessValue("B", "sm://b"))
+	assert.True(t, time.Since(start) < 40*time.Millisecond, "bursts are allowed")
+	assert.Equal(t, "value", p.PreProcessValue("C", "ssm://c"))
+	assert.True(t, time.Since(start) >= 40*time.Millisecond, "requests over the limit wait, after %v", time.Since(start))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err := recoverError(func() { p.PreProcessValueContext(ctx, "D", "ssm://d") })
+	assert.Equal(t, config.CodeSecretFetch, config.CodeOf(err))
+	assert.Contains(t, err.Error(), "rate limit")
+
+	p.WithRateLimit(config.RateLimit{})
+	assert.Nil(t, p.limiter, "an unlimited rate limit removes the limit")
+}
+
+func TestAWSSecretManagerValuePreProcessor_Register(t *testing.T) {
+	ctx := context.Background()
+	p := &AWSSecretManagerValuePreProcessor{
+		secretsManager: &mockSecretManagerClient{stringValue: aws.String("from_sm")},
+		parameterStore: &mockParameterStoreClient{stringValue: aws.String("from_ssm")},
+		ctx:            ctx,
+	}
+	r := p.Register(config.NewResolvers(ctx)).Register("static", config.ResolverFunc(func(ctx context.Context, ref string) (string, error) {
+		return "static_" + ref, nil
+	}))
+
+	assert.Equal(t, []string{"sm", "sm-json", "sm-multi", "ssm", "static"}, r.Schemes())
+	assert.Equal(t, "from_sm", r.PreProcessValue("A", "sm://a"))
+	assert.Equal(t, "from_ssm", r.PreProcessValue("B", "ssm://b"))
+	assert.Equal(t, "static_c", r.PreProcessValue("C", "static://c"))
+	assert.Equal(t, "plain", r.PreProcessValue("D", "plain"))
+}
+
+// mockSecretsStore is a SecretsManager and SecretsManagerLister over a fixed set of secrets,
+// listing one secret per page.
+type mockSecretsStore map[string]string
+
+func (m mockSecretsStore) GetSecretValue(ctx context.Context, params *secretsmanager.GetSecretValueInput, optFns ...func(*secretsmanager.Options)) (*secretsmanager.GetSecretValueOutput, error) {
+	v, ok := m[*params.SecretId]
+	if !ok {
+		return nil, errors.New("ResourceNotFoundException")
+	}
+	return &secretsmanager.GetSecretValueOutput{SecretString: aws.String(v)}, nil
+}
+
+func (m mockSecretsStore) ListSecrets(ctx context.Context, params *secretsmanager.ListSecretsInput, optFns ...func(*secretsmanager.Options)) (*secretsmanager.ListSecretsOutput, error) {
+	var names []string
+	for name := range m {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	page := 0
+	if params.NextToken != nil {
+		page, _ = strconv.Atoi(*params.NextToken)
+	}
+	out := &secretsmanager.ListSecretsOutput{SecretList: []smtypes.SecretListEntry{{Name: aws.String(names[page])}}}
+	if page+1 < len(names) {
+		out.NextToken = aws.String(strconv.Itoa(page + 1))
+	}
+	return out, nil
+}
+
+func TestAWSSecretManagerValuePreProcessor_ExpandValue(t *testing.T) {
+	p := &AWSSecretManagerValuePreProcessor{
+		secretsManager: mockSecretsStore{
+			"partners/acme":   "acme-token",
+			"partners/globex": "globex-token",
+			"other/partners/": "unrelated",
+		},
+		ctx: context.Background(),
+	}
+
+	var got struct {
+		Partners map[string]string `config:",raw"`
+	}
+	config.WithValuePreProcessor(p).
+		FromSource(config.MapSource(map[string]string{"PARTNERS": "sm-multi://partners/*"})).
+		To(&got)
+	assert.Equal(t, map[string]string{"acme": "acme-token", "globex": "globex-token"}, got.Partners)
+
+	_, _, err := p.ExpandValue("PARTNERS", "sm-multi://partners/")
+	assert.EqualError(t, err, "unable to expand sm-multi://partners/: config/aws: partners/ must end with *")
+
+	p.secretsManager = &mockSecretManagerClient{}
+	_, _, err = p.ExpandValue("PARTNERS", "sm-multi://partners/*")
+	assert.Contains(t, err.Error(), "cannot list secrets")
+
+	values, ok, err := p.ExpandValue("PARTNERS", "sm://partners/acme")
+	assert.NoError(t, err)
+	assert.False(t, ok)
+	assert.Nil(t, values)
+}
+
+func Test_splitSubKey(t *testing.T) {
+	tests := []struct {
+		in, name, subKey string
+	}{
+		{in: "db", name: "db"},
+		{in: "db#password", name: "db", subKey: "password"},
+		{in: "db#", name: "db"},
+		{in: "app#1#key", name: "app#1", subKey: "key"},
+		{in: `db#pass\#word`, name: "db", subKey: "pass#word"},
+		{in: `team\#1/db`, name: "team#1/db"},
+		{in: `team\#1/db#user`, name: "team#1/db", subKey: "user"},
+		{in: `db\\#user`, name: `db\`, subKey: "user"},
+		{in: `domain\user#password`, name: `domain\user`, subKey: "password"},
+		{in: "arn:aws:secretsmanager:eu-west-1:123456789012:secret:prod/db-AbCdEf#host", name: "arn:aws:secretsmanager:eu-west-1:123456789012:secret:prod/db-AbCdEf", subKey: "host"},
+		{in: `trailing\`, name: `trailing\`},
+	}
+	for _, tt := range tests {
+		name, subKey := splitSubKey(tt.in)
+		assert.Equal(t, tt.name, name, tt.in)
+		assert.Equal(t, tt.subKey, subKey, tt.in)
+	}
+}
+
+func TestAWSSecretManagerValuePreProcessor_subKeys(t *testing.T) {
+	p := &AWSSecretManagerValuePreProcessor{
+		secretsManager: mockSecretsStore{
+			"app#1":     `{"url": "https://example.com/#/home", "pass#word": "p#1"}`,
+			"plain#tag": "value#with#hashes",
+		},
+		ctx: context.Background(),
+	}
+	assert.Equal(t, "https://example.com/#/home", p.PreProcessValue("A", "sm://app#1#url"))
+	assert.Equal(t, "p#1", p.PreProcessValue("B", `sm://app#1#pass\#word`))
+	assert.Equal(t, "value#with#hashes", p.PreProcessValue("C", `sm://plain\#tag`))
+}
+
+func TestAWSSecretManagerValuePreProcessor_crossAccount(t *testing.T) {
+	const secretARN = "arn:aws:secretsmanager:eu-west-1:111111111111:secret:shared/db-AbCdEf"
+	const role = "arn:aws:iam::111111111111:role/reader"
+
+	var created []string
+	p := &AWSSecretManagerValuePreProcessor{
+		secretsManager: mockSecretsStore{},
+		ctx:            context.Background(),
+		newRoleClient: func(role, region string) SecretsManager {
+			created = append(created, role+" "+region)
+			return mockSecretsStore{secretARN: `{"password": "shared-password"}`}
+		},
+	}
+
+	assert.Equal(t, "shared-password", p.PreProcessValue("A", "sm://"+secretARN+"#password?role="+role))
+	assert.Equal(t, `{"password": "shared-password"}`, p.PreProcessValue("B", "sm://"+secretARN+"?role="+role))
+	assert.Equal(t, []string{role + " eu-west-1"}, created, "the role's client should be reused")
+
+	err := recoverError(func() { p.PreProcessValue("C", "sm://"+secretARN) })
+	assert.Contains(t, err.Error(), "ResourceNotFoundException", "without a role, the default client is used")
+
+	err = recoverError(func() { p.PreProcessValue("D", "sm://"+secretARN+"?role=reader") })
+	assert.Contains(t, err.Error(), `role "reader" in `+secretARN+"?role=reader is not an ARN")
+
+	p.secretsManager = mockSecretsStore{"app": `{"what?": "default", "who?": "other"}`}
+	assert.Equal(t, "default", p.PreProcessValue("E", "sm://app#what?"), "only a trailing ?role= is a query")
+	assert.Equal(t, "other", p.PreProcessValue("E", "sm://app#who?"))
+	p.newRoleClient = func(role, region string) SecretsManager { return mockSecretsStore{"app": `{"what?": "shared"}`} }
+	assert.Equal(t, "shared", p.PreProcessValue("E", "sm://app#what??role="+role))
+
+	p.newRoleClient = nil
+	err = recoverError(func() { p.PreProcessValue("F", "sm://db?role="+role) })
+	assert.Contains(t, err.Error(), "cannot assume role "+role+" without an AWS config")
+}
+
+type mockCallerIdentity struct {
+	calls int
+}
+
+func (m *mockCallerIdentity) GetCallerIdentity(ctx context.Context, params *sts.GetCallerIdentityInput, optFns ...func(*sts.Options)) (*sts.GetCallerIdentityOutput, error) {
+	m.calls++
+	return &sts.GetCallerIdentityOutput{Arn: aws.String("arn:aws:sts::123456789012:assumed-role/billing/i-0abc")}, nil
+}
+
+func TestAWSSecretManagerValuePreProcessor_accessDenied(t *testing.T) {
+	denied := &smithy.GenericAPIError{Code: "AccessDeniedException", Message: "not authorized"}
+	identity := &mockCallerIdentity{}
+	p := &AWSSecretManagerValuePreProcessor{
+		secretsManager: &mockSecretManagerClient{err: denied},
+		parameterStore: mockParameterStoreClient{err: denied},
+		identity:       identity,
+		region:         "eu-west-1",
+		ctx:            context.Background(),
+		newRoleClient: func(role, region string) SecretsManager {
+			return &mockSecretManagerClient{err: denied}
+		},
+	}
+
+	err := recoverError(func() { p.PreProcessValue("A", "sm://billing/db#password") })
+	var accessDenied *AccessDeniedError
+	require.True(t, errors.As(err, &accessDenied), "got %v", err)
+	assert.Equal(t, &AccessDeniedError{
+		Caller:   "arn:aws:sts::123456789012:assumed-role/billing/i-0abc",
+		Action:   "secretsmanager:GetSecretValue",
+		Resource: "arn:aws:secretsmanager:eu-west-1:123456789012:secret:billing/db-*",
+		Err:      denied,
+	}, accessDenied)
+	assert.Contains(t, err.Error(), "arn:aws:sts::123456789012:assumed-role/billing/i-0abc is not allowed secretsmanager:GetSecretValue on arn:aws:secretsmanager:eu-west-1:123456789012:secret:billing/db-*")
+
+	err = recoverError(func() { p.PreProcessValue("B", "ssm:///billing/api-key:3") })
+	require.True(t, errors.As(err, &accessDenied), "got %v", err)
+	assert.Equal(t, "ssm:GetParameter", accessDenied.Action)
+	assert.Equal(t, "arn:aws:ssm:eu-west-1:123456789012:parameter/billing/api-key", accessDenied.Resource)
+	assert.Equal(t, 1, identity.calls, "the caller identity should be cached")
+
+	const secretARN = "arn:aws:secretsmanager:us-east-1:111111111111:secret:shared/db-AbCdEf"
+	const role = "arn:aws:iam::111111111111:role/reader"
+	err = recoverError(func() { p.PreProcessValue("C", "sm://"+secretARN+"?role="+role) })
+	require.True(t, errors.As(err, &accessDenied), "got %v", err)
+	assert.Equal(t, role, accessDenied.Caller, "the assumed role is denied")
+	assert.Equal(t, secretARN, accessDenied.Resource)
+
+	p.secretsManager = &mockSecretManagerClient{err: &smithy.GenericAPIError{Code: "ResourceNotFoundException"}}
+	err = recoverError(func() { p.PreProcessValue("D", "sm://billing/db") })
+	assert.False(t, errors.As(err, &accessDenied), "other errors are returned as they are")
+}
+
+func Test_roleClientFactory(t *testing.T) {
+	client := roleClientFactory(aws.Config{Region: "us-east-1"})("arn:aws:iam::111111111111:role/reader", "eu-west-1")
+	assert.IsType(t, &secretsmanager.Client{}, client)
+}
+
+func recoverError(f func()) (err error) {
+	defer func() {
+		r := recover()
+		if e, ok := r.(error); ok {
+			err = e
+		} else if r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	f()
+	return nil
+}
+
+type mockS3Client struct {
+	input *s3.PutObjectInput
+	body  string
+	err   error
+	// objects holds the objects got by GetObject, by key, with their content type.
+	objects map[string][2]string
+}
+
+func (m *mockS3Client) GetObject(ctx context.Context, params *s3.GetObjectInput, optFns ...func(*s3.Options)) (*s3.GetObjectOutput, error) {
+	object, ok := m.objects[aws.ToString(params.Bucket)+"/"+aws.ToString(params.Key)]
+	if !ok {
+		return nil, &smithy.GenericAPIError{Code: "NoSuchKey"}
+	}
+	out := &s3.GetObjectOutput{Body: io.NopCloser(strings.NewReader(object[0]))}
+	if object[1] != "" {
+		out.ContentType = aws.String(object[1])
+	}
+	return out, nil
+}
+
+func (m *mockS3Client) PutObject(ctx context.Context, params *s3.PutObjectInput, optFns ...func(*s3.Options)) (*s3.PutObjectOutput, error) {
+	m.input = params
+	b, _ := io.ReadAll(params.Body)
+	m.body = string(b)
+	return &s3.PutObjectOutput{}, m.err
+}
+
+func TestS3SchemaPublisher(t *testing.T) {
+	type Config struct {
+		Port int `help:"Port to listen on."`
+	}
+	client := &mockS3Client{}
+	p := &S3SchemaPublisher{Client: client, Bucket: "catalog", Prefix: "schemas/"}
+	err := config.PublishSchema(context.Background(), p, "billing", "1.2.0", &Config{Port: 8080}, "BILLING_")
+	assert.NoError(t, err)
+	assert.Equal(t, "catalog", aws.ToString(client.input.Bucket))
+	assert.Equal(t, "schemas/billing/1.2.0.json", aws.ToString(client.input.Key))
+	assert.Equal(t, "application/schema+json", aws.ToString(client.input.ContentType))
+	assert.Contains(t, client.body, `"BILLING_PORT"`)
+
+	client.err = errors.New("access denied")
+	err = p.PublishSchema(context.Background(), "billing", "1.2.0", []byte("{}"))
+	assert.EqualError(t, err, "config/aws: error publishing schema to s3://catalog/schemas/billing/1.2.0.json: access denied")
+}
+
+func TestAWSSecretManagerValuePreProcessor_FromS3(t *testing.T) {
+	p := &AWSSecretManagerValuePreProcessor{
+		secretsManager: mockSecretsStore{"db": "s3cr3t"},
+		objects: &mockS3Client{objects: map[string][2]string{
+			"bundles/prod/billing.yaml": {"database:\n  host: db.internal\n  password: sm://db\n", ""},
+			"bundles/prod/billing":      {`{"port": 8080}`, "application/json"},
+			"bundles/prod/broken.json":  {"{", ""},
+		}},
+		ctx: context.Background(),
+	}
+	ctx := context.Background()
+
+	var got struct {
+		Port     int
+		Database struct {
+			Host     config.Meta[string]
+			Password string
+		}
+	}
+	p.FromS3(ctx, "bundles", "prod/billing.yaml").FromSource(p.S3Source(ctx, "bundles", "prod/billing")).To(&got)
+	assert.Equal(t, 8080, got.Port, "the format is detected from the content type")
+	assert.Equal(t, "db.internal", got.Database.Host.Value, "the format is detected from the extension")
+	assert.Equal(t, "s3://bundles/prod/billing.yaml", got.Database.Host.Source)
+	assert.Equal(t, "s3cr3t", got.Database.Password, "references in the file are resolved")
+
+	err := recoverError(func() { p.FromS3(ctx, "bundles", "prod/missing.env") })
+	assert.Equal(t, config.CodeSourceLoad, config.CodeOf(err))
+	assert.Contains(t, err.Error(), "config/aws: error downloading s3://bundles/prod/missing.env")
+
+	err = recoverError(func() { p.FromS3(ctx, "bundles", "prod/broken.json") })
+	assert.Contains(t, err.Error(), "config/aws: error reading s3://bundles/prod/broken.json")
+
+	_, err = (&AWSSecretManagerValuePreProcessor{}).S3Source(ctx, "bundles", "prod/billing").Load()
+	assert.EqualError(t, err, "config/aws: cannot download s3://bundles/prod/billing without an AWS config, use NewAWSSecretManagerValuePreProcessor")
+}
+
+func TestAWSSecretManagerValuePreProcessor_RegisterS3Scheme(t *testing.T) {
+	p := &AWSSecretManagerValuePreProcessor{
+		secretsManager: mockSecretsStore{"db": "s3cr3t"},
+		objects: &mockS3Client{objects: map[string][2]string{
+			"bundles/prod/billing.yaml": {"database:\n  password: sm://db\n", ""},
+		}},
+		ctx: context.Background(),
+	}
+	p.RegisterS3Scheme(context.Background())
+	assert.Contains(t, config.SourceSchemes(), S3SourceScheme)
+
+	var got struct {
+		Database struct{ Password config.Meta[string] }
+	}
+	config.WithValuePreProcessor(p).From("s3://bundles/prod/billing.yaml").To(&got)
+	assert.Equal(t, "s3cr3t", got.Database.Password.Value)
+	assert.Equal(t, "s3://bundles/prod/billing.yaml", got.Database.Password.Source)
+
+	err := recoverError(func() { config.From("s3://bundles") })
+	assert.Equal(t, config.CodeSourceLoad, config.CodeOf(err))
+	assert.Contains(t, err.Error(), "config/aws: s3://bundles must be of the form s3://bucket/key")
+}
+
+func TestS3Source_VerifySignature(t *testing.T) {
+	public, private, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+	doc := "PORT=8080\n"
+	client := &mockS3Client{objects: map[string][2]string{
+		"bundles/prod/billing.env":      {doc, ""},
+		"bundles/prod/billing.env.sig":  {base64.StdEncoding.EncodeToString(ed25519.Sign(private, []byte(doc))), ""},
+		"bundles/prod/unsigned.env":     {doc, ""},
+		"bundles/prod/tampered.env":     {"PORT=6666\n", ""},
+		"bundles/prod/tampered.env.sig": {base64.StdEncoding.EncodeToString(ed25519.Sign(private, []byte(doc))), ""},
+	}}
+	ctx := context.Background()
+
+	values, err := S3Source(ctx, client, "bundles", "prod/billing.env", config.VerifySignature(public)).Load()
+	require.NoError(t, err)
+	assert.Equal(t, "8080", values["port"])
+
+	_, err = S3Source(ctx, client, "bundles", "prod/unsigned.env", config.VerifySignature(public)).Load()
+	assert.Contains(t, fmt.Sprint(err), "error downloading s3://bundles/prod/unsigned.env.sig")
+	_, err = S3Source(ctx, client, "bundles", "prod/tampered.env", config.VerifySignature(public)).Load()
+	assert.Contains(t, fmt.Sprint(err), "signature prod/tampered.env.sig does not match")
+}
+
+// mockKMS decrypts ciphertext which is the plaintext prefixed with "kms:".
+type mockKMS struct{}
+
+func (mockKMS) Decrypt(ctx context.Context, params *kms.DecryptInput, optFns ...func(*kms.Options)) (*kms.DecryptOutput, error) {
+	if !bytes.HasPrefix(params.CiphertextBlob, []byte("kms:")) {
+		return nil, &smithy.GenericAPIError{Code: "InvalidCiphertextException"}
+	}
+	return &kms.DecryptOutput{Plaintext: bytes.TrimPrefix(params.CiphertextBlob, []byte("kms:"))}, nil
+}
+
+func TestKMSPayload(t *testing.T) {
+	var compressed bytes.Buffer
+	w := gzip.NewWriter(&compressed)
+	_, err := w.Write([]byte("database:\n  host: db.internal\n"))
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+	encrypted := base64.StdEncoding.EncodeToString(append([]byte("kms:"), compressed.Bytes()...))
+
+	p := &AWSSecretManagerValuePreProcessor{
+		objects: &mockS3Client{objects: map[string][2]string{
+			"bundles/prod/billing.yaml.gz.kms": {encrypted, "application/octet-stream"},
+			"bundles/prod/plain.env.kms":       {"kms:PORT=8080", ""},
+			"bundles/prod/tampered.env.kms":    {"PORT=8080", ""},
+		}},
+		kms: mockKMS{},
+		ctx: context.Background(),
+	}
+	ctx := context.Background()
+
+	var got struct {
+		Port     int
+		Database struct{ Host string }
+	}
+	p.FromS3(ctx, "bundles", "prod/billing.yaml.gz.kms", p.KMSPayload(), config.GzipPayload()).
+		FromSource(p.S3Source(ctx, "bundles", "prod/plain.env.kms", p.KMSPayload())).
+		To(&got)
+	assert.Equal(t, "db.internal", got.Database.Host)
+	assert.Equal(t, 8080, got.Port)
+
+	err = recoverError(func() { p.FromS3(ctx, "bundles", "prod/tampered.env.kms", p.KMSPayload()) })
+	assert.Equal(t, config.CodeSourceLoad, config.CodeOf(err))
+	assert.Contains(t, err.Error(), "config/aws: error decrypting prod/tampered.env.kms with KMS")
+
+	_, err = (&AWSSecretManagerValuePreProcessor{objects: p.objects}).S3Source(ctx, "bundles", "prod/plain.env.kms", (&AWSSecretManagerValuePreProcessor{}).KMSPayload()).Load()
+	assert.Contains(t, fmt.Sprint(err), "cannot decrypt without an AWS config")
+}
+
+// mockParameterTree is a parameter store returning one parameter per page from GetParametersByPath.
+type mockParameterTree struct {
+	mockParameterStoreClient
+	parameters []types.Parameter
+	inputs     []ssm.GetParametersByPathInput
+}
+
+func (m *mockParameterTree) GetParametersByPath(ctx context.Context, params *ssm.GetParametersByPathInput, optFns ...func(*ssm.Options)) (*ssm.GetParametersByPathOutput, error) {
+	m.inputs = append(m.inputs, *params)
+	start, _ := strconv.Atoi(aws.ToString(params.NextToken))
+	out := &ssm.GetParametersByPathOutput{}
+	if start < len(m.parameters) {
+		out.Parameters = m.parameters[start : start+1]
+	}
+	if start+1 < len(m.parameters) {
+		out.NextToken = aws.String(strconv.Itoa(start + 1))
+	}
+	return out, nil
+}
+
+func TestAWSSecretManagerValuePreProcessor_FromParameterStorePath(t *testing.T) {
+	tree := &mockParameterTree{parameters: []types.Parameter{
+		{Name: aws.String("/myapp/prod/port"), Value: aws.String("8080")},
+		{Name: aws.String("/myapp/prod/database/host"), Value: aws.String("db.internal")},
+		{Name: aws.String("/myapp/prod/database/password"), Value: aws.String("sm://db")},
+		{Name: aws.String("/myapp/prod/zones"), Value: aws.String("a, b"), Type: types.ParameterTypeStringList},
+	}}
+	p := (&AWSSecretManagerValuePreProcessor{
+		decryptParameterStoreValues: true,
+		secretsManager:              mockSecretsStore{"db": "s3cr3t"},
+		parameterStore:              tree,
+		ctx:                         context.Background(),
+	}).WithSplitStringLists()
+
+	var got struct {
+		Port     int
+		Zones    []string
+		Database struct {
+			Host     string
+			Password string
+		}
+	}
+	p.FromParameterStorePath(context.Background(), "myapp/prod").To(&got)
+	assert.Equal(t, 8080, got.Port)
+	assert.Equal(t, []string{"a", "b"}, got.Zones)
+	assert.Equal(t, "db.internal", got.Database.Host, "levels are nested")
+	assert.Equal(t, "s3cr3t", got.Database.Password, "references in parameters are resolved")
+	require.Len(t, tree.inputs, 4, "every page is fetched")
+	assert.Equal(t, "/myapp/prod/", aws.ToString(tree.inputs[0].Path))
+	assert.True(t, tree.inputs[0].Recursive)
+	assert.True(t, tree.inputs[0].WithDecryption)
+
+	p.parameterStore = mockParameterStoreClient{}
+	_, err := p.ParameterStorePathSource(context.Background(), "/myapp/prod/").Load()
+	assert.EqualError(t, err, "config/aws: parameter store client cannot get parameters by path")
+}
+
+func TestAWSSecretManagerValuePreProcessor_RegisterParameterStoreScheme(t *testing.T) {
+	tree := &mockParameterTree{parameters: []types.Parameter{
+		{Name: aws.String("/myapp/prod/database/host"), Value: aws.String("db.internal")},
+	}}
+	p := &AWSSecretManagerValuePreProcessor{parameterStore: tree, ctx: context.Background()}
+	p.RegisterParameterStoreScheme(context.Background())
+
+	manifest := filepath.Join(t.TempDir(), "config.sources.yaml")
+	require.NoError(t, os.WriteFile(manifest, []byte("sources:\n  - ssm_path: /myapp/prod/\n"), 0644))
+
+	var got struct {
+		Database struct{ Host string }
+	}
+	config.FromManifest(manifest).To(&got)
+	assert.Equal(t, "db.internal", got.Database.Host)
+	assert.Equal(t, "/myapp/prod/", aws.ToString(tree.inputs[0].Path))
+}
+
+func TestAWSSecretManagerValuePreProcessor_FromSecretsManagerJSON(t *testing.T) {
+	store := mockSecretsStore{
+		"myapp/prod": `{"host": "db.internal", "port": 5432, "pool": {"size": 4}, "zones": ["a", "b"]}`,
+		"broken":     `["a"]`,
+	}
+	p := &AWSSecretManagerValuePreProcessor{secretsManager: store, ctx: context.Background()}
+
+	var got struct {
+		Database struct {
+			Host  config.Meta[string]
+			Port  int
+			Pool  struct{ Size int }
+			Zones []string
+		}
+	}
+	p.FromSecretsManagerJSON(context.Background(), "myapp/prod", "DATABASE").To(&got)
+	assert.Equal(t, "db.internal", got.Database.Host.Value)
+	assert.False(t, got.Database.Host.Resolved.IsZero(), "fields are resolved values")
+	assert.Equal(t, 5432, got.Database.Port)
+	assert.Equal(t, 4, got.Database.Pool.Size)
+	assert.Equal(t, []string{"a", "b"}, got.Database.Zones)
+
+	got.Database.Port = 0
+	config.WithValuePreProcessor(p).FromSource(config.MapSource(map[string]string{"DATABASE": "sm-json://myapp/prod"})).To(&got)
+	assert.Equal(t, 5432, got.Database.Port, "sm-json:// references expand under their key")
+
+	err := recoverError(func() { p.FromSecretsManagerJSON(context.Background(), "broken", "DATABASE") })
+	assert.Equal(t, config.CodeSecretFetch, config.CodeOf(err))
+	assert.Contains(t, err.Error(), "config/aws: error parsing secret broken")
+
+	err = recoverError(func() { p.FromSecretsManagerJSON(context.Background(), "myapp/prod", "") })
+	assert.Equal(t, config.CodeSourceLoad, config.CodeOf(err))
+}
+
+func TestNewAWSSecretManagerValuePreProcessor_WithProfile(t *testing.T) {
+	os.Clearenv()
+	defer os.Clearenv()
+	dir := t.TempDir()
+	configFile := filepath.Join(dir, "config")
+	require.NoError(t, os.WriteFile(configFile, []byte("[profile staging]\nregion = eu-west-2\naws_access_key_id = AKID\naws_secret_access_key = SECRET\n"), 0o600))
+	require.NoError(t, os.Setenv("AWS_CONFIG_FILE", configFile))
+	require.NoError(t, os.Setenv("AWS_SHARED_CREDENTIALS_FILE", filepath.Join(dir, "credentials")))
+	require.NoError(t, os.Setenv("AWS_EC2_METADATA_DISABLED", "true"))
+
+	p, err := NewAWSSecretManagerValuePreProcessor(context.Background(), true, WithProfile("staging"))
+	require.NoError(t, err)
+	assert.NotNil(t, p.secretsManager)
+
+	_, err = NewAWSSecretManagerValuePreProcessor(context.Background(), true, WithProfile("production"))
+	assert.Error(t, err, "the profile must exist")
+}
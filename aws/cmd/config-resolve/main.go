@@ -0,0 +1,99 @@
+// Command config-resolve resolves config once, e.g. in a Kubernetes init container, and writes it to an env file
+// for the application container to read from a shared volume. This reuses the config package's resolution
+// for programs which are not written in Go, or which cannot reach the secret stores themselves:
+//
+//	config-resolve -manifest /etc/config/sources.yaml -keys /etc/config/keys -out /shared/app.env
+//
+// The source manifest, see config.FromManifest, declares the sources and the resolvers to enable;
+// the sm and ssm resolvers, for AWS Secrets Manager and Parameter Store, are registered.
+// If a key manifest, as written by config.WriteManifest, is given, only its keys are written, and any key
+// it declares which is unset is reported. The env file is replaced atomically and left read only by its owner,
+// see config.Builder.WriteEnvFile. It is not sealed: resolved secrets are written in plaintext, protected only by
+// the file's permissions, so the shared volume should be kept in memory, e.g. an emptyDir with medium Memory.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/imduffy15/config"
+	"github.com/imduffy15/config/aws"
+)
+
+func main() {
+	manifest := flag.String("manifest", "", "source manifest declaring the sources to resolve (required)")
+	keys := flag.String("keys", "", "key manifest limiting the keys written")
+	out := flag.String("out", "app.env", "env file to write")
+	profile := flag.String("profile", "", "AWS shared config profile to resolve references with")
+	flag.Parse()
+
+	var opts []aws.Option
+	if *profile != "" {
+		opts = append(opts, aws.WithProfile(*profile))
+	}
+	if err := run(context.Background(), *manifest, *keys, *out, opts...); err != nil {
+		fmt.Fprintln(os.Stderr, "config-resolve:", err)
+		os.Exit(1)
+	}
+}
+
+func run(ctx context.Context, manifest, keys, out string, opts ...aws.Option) (err error) {
+	if manifest == "" {
+		return fmt.Errorf("-manifest is required")
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%v", r)
+		}
+	}()
+
+	p, err := aws.NewAWSSecretManagerValuePreProcessor(ctx, true, opts...)
+	if err != nil {
+		return err
+	}
+	config.RegisterResolver(aws.SecretsManagerScheme, p.SecretsManagerResolver())
+	config.RegisterResolver(aws.ParameterStoreScheme, p.ParameterStoreResolver())
+	return resolve(manifest, keys, out, os.Stderr)
+}
+
+// resolve writes the config declared by manifest to out, limited to the keys of the key manifest keys, if given,
+// reporting any key it declares which is unset to stderr. Resolvers must already be registered.
+func resolve(manifest, keys, out string, stderr io.Writer) error {
+	b := config.FromManifest(manifest)
+	var environOpts []config.EnvironOption
+	if keys != "" {
+		f, err := os.Open(keys)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		declared, err := config.ReadManifest(f)
+		if err != nil {
+			return fmt.Errorf("%s: %w", keys, err)
+		}
+		if missing := unsetKeys(b, declared); len(missing) > 0 {
+			fmt.Fprintf(stderr, "config-resolve: keys not set: %s\n", strings.Join(missing, ", "))
+		}
+		environOpts = append(environOpts, config.EnvironManifest(declared))
+	}
+	return b.WriteEnvFile(out, environOpts...)
+}
+
+// unsetKeys returns the keys of manifest, other than wildcards, which are not set in b.
+func unsetKeys(b *config.Builder, manifest []string) []string {
+	set := make(map[string]bool)
+	for _, kv := range b.Environ() {
+		set[config.NormalizeKey(kv[:strings.Index(kv, "=")])] = true
+	}
+	var missing []string
+	for _, key := range manifest {
+		if !strings.HasSuffix(key, "*") && !set[key] {
+			missing = append(missing, strings.ToUpper(key))
+		}
+	}
+	return missing
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/imduffy15/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolve(t *testing.T) {
+	config.RegisterResolver("test-resolve", config.ResolverFunc(func(ctx context.Context, ref string) (string, error) {
+		return "secret-" + ref, nil
+	}))
+	dir := t.TempDir()
+	files := map[string]string{
+		"sources.yaml": "resolvers: [test-resolve]\nsources:\n  - file: base.config\n",
+		"base.config":  "PASSWORD=test-resolve://db\nHOST=db.internal\nUNDECLARED=x\n",
+		"keys":         "# Config keys\npassword\nhost\nport\nplugins__*\n",
+	}
+	for name, content := range files {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644))
+	}
+
+	out := filepath.Join(dir, "app.env")
+	var stderr bytes.Buffer
+	require.NoError(t, resolve(filepath.Join(dir, "sources.yaml"), filepath.Join(dir, "keys"), out, &stderr))
+	assert.Equal(t, "config-resolve: keys not set: PORT\n", stderr.String())
+
+	b, err := os.ReadFile(out)
+	require.NoError(t, err)
+	assert.Equal(t, "HOST=db.internal\nPASSWORD=secret-db\n", string(b), "only declared keys are written, resolved")
+	info, err := os.Stat(out)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o400), info.Mode().Perm())
+
+	err = resolve(filepath.Join(dir, "sources.yaml"), filepath.Join(dir, "absent"), filepath.Join(dir, "other.env"), &stderr)
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestRun(t *testing.T) {
+	assert.EqualError(t, run(context.Background(), "", "", "app.env"), "-manifest is required")
+}
+
+func TestUnsetKeys(t *testing.T) {
+	b := config.FromSource(config.MapSource(map[string]string{"HOST": "db.internal", "PORT": ""}))
+	assert.Equal(t, []string{"PORT", "PASSWORD"}, unsetKeys(b, []string{"host", "port", "password", "plugins__*"}))
+	assert.Empty(t, unsetKeys(b, []string{"host"}))
+}
@@ -0,0 +1,120 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws/arn"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/aws/smithy-go"
+)
+
+// CallerIdentityAPI is implemented by STS clients, and used to name the caller when a request is denied.
+type CallerIdentityAPI interface {
+	GetCallerIdentity(ctx context.Context, params *sts.GetCallerIdentityInput, optFns ...func(*sts.Options)) (*sts.GetCallerIdentityOutput, error)
+}
+
+// AccessDeniedError is the error of a request to Secrets Manager or Parameter Store which the caller
+// is not allowed to make. Its message names what to grant, to whom, e.g.
+//
+//	config/aws: access denied: arn:aws:sts::123456789012:assumed-role/billing/i-0abc is not allowed
+//	secretsmanager:GetSecretValue on arn:aws:secretsmanager:eu-west-1:123456789012:secret:billing/db-*
+type AccessDeniedError struct {
+	// Caller is the ARN of the denied identity, or "" if it could not be found.
+	Caller string
+	// Action is the IAM action denied, e.g. secretsmanager:GetSecretValue.
+	Action string
+	// Resource is the ARN of the secret or parameter, as it would appear in an IAM policy. A secret referenced
+	// by name is matched with a wildcard, as its ARN has a random suffix.
+	Resource string
+	// Err is the error returned by AWS.
+	Err error
+}
+
+func (e *AccessDeniedError) Error() string {
+	caller := e.Caller
+	if caller == "" {
+		caller = "the caller"
+	}
+	return fmt.Sprintf("config/aws: access denied: %s is not allowed %s on %s, grant it in an IAM policy: %v", caller, e.Action, e.Resource, e.Err)
+}
+
+// Unwrap returns the error returned by AWS.
+func (e *AccessDeniedError) Unwrap() error { return e.Err }
+
+// Cause returns the error returned by AWS, for errors.Cause.
+func (e *AccessDeniedError) Cause() error { return e.Err }
+
+// isAccessDenied returns whether err is an AWS access denied error.
+func isAccessDenied(err error) bool {
+	var apiErr smithy.APIError
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+	switch apiErr.ErrorCode() {
+	case "AccessDeniedException", "AccessDenied":
+		return true
+	}
+	return false
+}
+
+// accessDenied returns an *AccessDeniedError for err, an access denied error, naming the caller,
+// or role if the request was made as an assumed role, and the resource of type resourceType named name.
+func (p *AWSSecretManagerValuePreProcessor) accessDenied(ctx context.Context, err error, action, resourceType, name, role string) error {
+	caller := role
+	if caller == "" {
+		caller = p.callerIdentity(ctx)
+	}
+	return &AccessDeniedError{
+		Caller:   caller,
+		Action:   action,
+		Resource: p.resourceARN(strings.SplitN(action, ":", 2)[0], resourceType, name, caller),
+		Err:      err,
+	}
+}
+
+// callerIdentity returns the ARN of the caller, found with STS on first use, or "" if it cannot be found.
+func (p *AWSSecretManagerValuePreProcessor) callerIdentity(ctx context.Context) string {
+	if p.identity == nil {
+		return ""
+	}
+	p.callerMu.Lock()
+	defer p.callerMu.Unlock()
+	if p.caller == "" {
+		resp, err := p.identity.GetCallerIdentity(ctx, &sts.GetCallerIdentityInput{})
+		if err != nil || resp.Arn == nil {
+			return ""
+		}
+		p.caller = *resp.Arn
+	}
+	return p.caller
+}
+
+// resourceARN returns the ARN of the named resource of service, e.g. secretsmanager, in the account and partition of
+// caller. Names which are already ARNs, or which cannot be made into one, are returned as they are.
+func (p *AWSSecretManagerValuePreProcessor) resourceARN(service, resourceType, name, caller string) string {
+	if arn.IsARN(name) || name == "*" {
+		return name
+	}
+	c, err := arn.Parse(caller)
+	if err != nil || p.region == "" {
+		return name
+	}
+	resource := resourceType + ":" + name + "-*"
+	if resourceType == "parameter" {
+		resource = resourceType + "/" + strings.TrimPrefix(name, "/")
+	}
+	return arn.ARN{Partition: c.Partition, Service: service, Region: p.region, AccountID: c.AccountID, Resource: resource}.String()
+}
+
+// parameterName returns the name of the parameter selected by a name:version or name:label selector.
+// Parameter names cannot contain colons, other than those of an ARN.
+func parameterName(selector string) string {
+	if a, err := arn.Parse(selector); err == nil {
+		a.Resource = strings.SplitN(a.Resource, ":", 2)[0]
+		return a.String()
+	}
+	return strings.SplitN(selector, ":", 2)[0]
+}
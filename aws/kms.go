@@ -0,0 +1,52 @@
+package aws
+
+import (
+	"context"
+	"encoding/base64"
+	"path"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/service/kms"
+	"github.com/imduffy15/config"
+	"github.com/pkg/errors"
+)
+
+// KMSDecryptAPI is implemented by KMS clients which can decrypt, as needed by KMSPayload.
+type KMSDecryptAPI interface {
+	Decrypt(ctx context.Context, params *kms.DecryptInput, optFns ...func(*kms.Options)) (*kms.DecryptOutput, error)
+}
+
+// KMSPayload returns a config.PayloadTransformer decrypting config files encrypted with a KMS key,
+// as aws kms encrypt writes them, so that S3Source and config.URLSource can fetch encrypted config:
+//
+//	p.FromS3(ctx, "config-bundles", "prod/billing.yaml.kms", aws.KMSPayload(kmsClient)).To(&c)
+//
+// The ciphertext may be binary or base64 encoded. A .kms extension is removed from the file's name, so the format
+// of the config beneath is detected. KMS encrypts at most 4KB directly, so larger files are best compressed first,
+// and decompressed with config.GzipPayload after decryption.
+func KMSPayload(client KMSDecryptAPI) config.PayloadTransformer {
+	return config.PayloadTransformerFunc(func(ctx context.Context, p config.Payload) (config.Payload, error) {
+		if client == nil {
+			return config.Payload{}, errors.New("config/aws: cannot decrypt without an AWS config, use NewAWSSecretManagerValuePreProcessor")
+		}
+		ciphertext := p.Data
+		if decoded, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(p.Data))); err == nil {
+			ciphertext = decoded
+		}
+		resp, err := client.Decrypt(ctx, &kms.DecryptInput{CiphertextBlob: ciphertext})
+		if err != nil {
+			return config.Payload{}, errors.Wrapf(err, "config/aws: error decrypting %s with KMS", p.Name)
+		}
+		if ext := path.Ext(p.Name); strings.EqualFold(ext, ".kms") {
+			p.Name = strings.TrimSuffix(p.Name, ext)
+		}
+		p.Data = resp.Plaintext
+		return p, nil
+	})
+}
+
+// KMSPayload returns a config.PayloadTransformer decrypting config files with KMS, using the AWS config loaded by
+// NewAWSSecretManagerValuePreProcessor. See KMSPayload.
+func (p *AWSSecretManagerValuePreProcessor) KMSPayload() config.PayloadTransformer {
+	return KMSPayload(p.kms)
+}
@@ -0,0 +1,95 @@
+package aws
+
+import (
+	"context"
+	"net/url"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ssm"
+	ssmtypes "github.com/aws/aws-sdk-go-v2/service/ssm/types"
+	"github.com/imduffy15/config"
+	"github.com/pkg/errors"
+)
+
+// ParameterStorePathLister is implemented by ParameterStoreManager clients which can get parameters by path,
+// as needed by ParameterStorePathSource.
+type ParameterStorePathLister interface {
+	GetParametersByPath(ctx context.Context, params *ssm.GetParametersByPathInput, optFns ...func(*ssm.Options)) (*ssm.GetParametersByPathOutput, error)
+}
+
+// ParameterStorePathSource returns a config.Source of every parameter under path, e.g. /myapp/prod/, fetched
+// recursively using ctx, so that a service's parameters need not each be referenced with ssm://.
+// Each parameter is named by the rest of its name, with the levels of the hierarchy nested as struct fields are,
+// so /myapp/prod/database/host is bound from DATABASE__HOST. Values are decrypted, and StringList parameters split,
+// as references are. The ParameterStoreManager client must also be a ParameterStorePathLister.
+func (p *AWSSecretManagerValuePreProcessor) ParameterStorePathSource(ctx context.Context, path string) config.Source {
+	return config.SourceFunc(func() (map[string]string, error) {
+		return p.loadParametersByPath(ctx, path)
+	})
+}
+
+// FromParameterStorePath returns a new Builder which pre-processes values with p, populated with every parameter
+// under path. See ParameterStorePathSource.
+//
+//	p.FromParameterStorePath(ctx, "/myapp/prod/").FromEnv().To(&c)
+//
+// The parameters can be merged into an existing Builder with FromSource(p.ParameterStorePathSource(ctx, path)).
+// It panics with a *config.Error with config.CodeSourceLoad if the parameters cannot be fetched.
+func (p *AWSSecretManagerValuePreProcessor) FromParameterStorePath(ctx context.Context, path string) *config.Builder {
+	return config.WithValuePreProcessor(p).FromSource(p.ParameterStorePathSource(ctx, path))
+}
+
+// RegisterParameterStoreScheme registers ParameterStoreScheme with config.RegisterSourceScheme, so that every
+// parameter under a path is loaded by config.From, and by ssm_path sources of source manifests, from locations
+// such as ssm://myapp/prod/, fetched using ctx:
+//
+//	p.RegisterParameterStoreScheme(ctx)
+//	config.WithValuePreProcessor(p).From("ssm://myapp/prod/").FromEnv().To(&c)
+//
+// See ParameterStorePathSource. It panics if the scheme is already registered, so should be called once, e.g. from main.
+func (p *AWSSecretManagerValuePreProcessor) RegisterParameterStoreScheme(ctx context.Context) {
+	config.RegisterSourceScheme(ParameterStoreScheme, func(u *url.URL) (config.Source, error) {
+		return p.ParameterStorePathSource(ctx, u.Host+u.Path), nil
+	})
+}
+
+func (p *AWSSecretManagerValuePreProcessor) loadParametersByPath(ctx context.Context, path string) (map[string]string, error) {
+	lister, ok := p.parameterStore.(ParameterStorePathLister)
+	if !ok {
+		return nil, errors.New("config/aws: parameter store client cannot get parameters by path")
+	}
+	prefix := "/" + strings.Trim(path, "/") + "/"
+	if prefix == "//" {
+		prefix = "/"
+	}
+	input := &ssm.GetParametersByPathInput{
+		Path:           aws.String(prefix),
+		Recursive:      true,
+		WithDecryption: p.decryptParameterStoreValues,
+	}
+	values := make(map[string]string)
+	for {
+		if err := p.wait(ctx); err != nil {
+			return nil, err
+		}
+		resp, err := lister.GetParametersByPath(ctx, input)
+		if isAccessDenied(err) {
+			return nil, p.accessDenied(ctx, err, "ssm:GetParametersByPath", "parameter", strings.TrimSuffix(prefix, "/"), "")
+		}
+		if err != nil {
+			return nil, errors.Wrapf(err, "config/aws: error loading parameters under %s", prefix)
+		}
+		for _, parameter := range resp.Parameters {
+			name, value := aws.ToString(parameter.Name), aws.ToString(parameter.Value)
+			if p.splitStringLists && parameter.Type == ssmtypes.ParameterTypeStringList {
+				value = splitStringList(value)
+			}
+			values[strings.ReplaceAll(strings.TrimPrefix(name, prefix), "/", "__")] = value
+		}
+		if resp.NextToken == nil {
+			return values, nil
+		}
+		input.NextToken = resp.NextToken
+	}
+}
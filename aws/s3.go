@@ -0,0 +1,109 @@
+package aws
+
+import (
+	"context"
+	"io/ioutil"
+	"net/url"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/imduffy15/config"
+	"github.com/pkg/errors"
+)
+
+// S3SourceScheme is the scheme of the locations of config files stored in S3, e.g. s3://bucket/key.
+// See RegisterS3Scheme.
+const S3SourceScheme = "s3"
+
+// S3GetObjectAPI is implemented by S3 clients which can get objects, as needed by S3Source.
+type S3GetObjectAPI interface {
+	GetObject(ctx context.Context, params *s3.GetObjectInput, optFns ...func(*s3.Options)) (*s3.GetObjectOutput, error)
+}
+
+// S3Source returns a config.Source of the config file stored as key in bucket, downloaded with client using ctx.
+// The file is read in the format given by its Content-Type, or without one, by the extension of key,
+// see config.DetectFormat, so per environment bundles can be kept as dotenv, JSON or YAML files.
+// The file is first transformed by transformers, in order, so it can be stored compressed or encrypted,
+// e.g. prod/billing.yaml.gz with config.GzipPayload(). See config.PayloadTransformer.
+func S3Source(ctx context.Context, client S3GetObjectAPI, bucket, key string, transformers ...config.PayloadTransformer) config.Source {
+	return s3Source{ctx: ctx, client: client, bucket: bucket, key: key, transformers: transformers}
+}
+
+type s3Source struct {
+	ctx          context.Context
+	client       S3GetObjectAPI
+	bucket, key  string
+	transformers []config.PayloadTransformer
+}
+
+func (s s3Source) Load() (map[string]string, error) {
+	if s.client == nil {
+		return nil, errors.Errorf("config/aws: cannot download %s without an AWS config, use NewAWSSecretManagerValuePreProcessor", s)
+	}
+	resp, err := s.client.GetObject(s.ctx, &s3.GetObjectInput{Bucket: aws.String(s.bucket), Key: aws.String(s.key)})
+	if err != nil {
+		return nil, errors.Wrapf(err, "config/aws: error downloading %s", s)
+	}
+	defer resp.Body.Close()
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, errors.Wrapf(err, "config/aws: error downloading %s", s)
+	}
+	payload := config.Payload{Name: s.key, ContentType: aws.ToString(resp.ContentType), Data: data, Sibling: s.sibling}
+	values, err := config.LoadPayload(s.ctx, payload, s.transformers...)
+	if err != nil {
+		return nil, errors.Wrapf(err, "config/aws: error reading %s", s)
+	}
+	return values, nil
+}
+
+// sibling downloads the object stored as the key followed by suffix, e.g. the file's signature.
+func (s s3Source) sibling(ctx context.Context, suffix string) ([]byte, error) {
+	resp, err := s.client.GetObject(ctx, &s3.GetObjectInput{Bucket: aws.String(s.bucket), Key: aws.String(s.key + suffix)})
+	if err != nil {
+		return nil, errors.Wrapf(err, "config/aws: error downloading %s%s", s, suffix)
+	}
+	defer resp.Body.Close()
+	return ioutil.ReadAll(resp.Body)
+}
+
+// String describes the source, e.g. in a config.Meta's Source.
+func (s s3Source) String() string {
+	return "s3://" + s.bucket + "/" + s.key
+}
+
+// S3Source returns a config.Source of the config file stored as key in bucket, downloaded using ctx with
+// the AWS config loaded by NewAWSSecretManagerValuePreProcessor. See S3Source.
+func (p *AWSSecretManagerValuePreProcessor) S3Source(ctx context.Context, bucket, key string, transformers ...config.PayloadTransformer) config.Source {
+	return S3Source(ctx, p.objects, bucket, key, transformers...)
+}
+
+// FromS3 returns a new Builder which pre-processes values with p, populated with the config file stored as key
+// in bucket, so that references in the file are resolved as those in the environment would be:
+//
+//	p.FromS3(ctx, "config-bundles", "prod/billing.yaml").FromEnv().To(&c)
+//
+// The file can be merged into an existing Builder with FromSource(p.S3Source(ctx, bucket, key)).
+// It panics with a *config.Error with config.CodeSourceLoad if the file cannot be downloaded or read.
+func (p *AWSSecretManagerValuePreProcessor) FromS3(ctx context.Context, bucket, key string, transformers ...config.PayloadTransformer) *config.Builder {
+	return config.WithValuePreProcessor(p).FromSource(p.S3Source(ctx, bucket, key, transformers...))
+}
+
+// RegisterS3Scheme registers S3SourceScheme with config.RegisterSourceScheme, so that config files stored in S3
+// are loaded by config.From, and source manifests, from locations such as s3://bucket/key, downloaded using ctx
+// with the AWS config loaded by NewAWSSecretManagerValuePreProcessor, and transformed by transformers:
+//
+//	p.RegisterS3Scheme(ctx)
+//	config.WithValuePreProcessor(p).From("s3://config-bundles/prod/billing.yaml").FromEnv().To(&c)
+//
+// See S3Source. It panics if the scheme is already registered, so should be called once, e.g. from main.
+func (p *AWSSecretManagerValuePreProcessor) RegisterS3Scheme(ctx context.Context, transformers ...config.PayloadTransformer) {
+	config.RegisterSourceScheme(S3SourceScheme, func(u *url.URL) (config.Source, error) {
+		key := strings.TrimPrefix(u.Path, "/")
+		if u.Host == "" || key == "" {
+			return nil, errors.Errorf("config/aws: %s must be of the form s3://bucket/key", u)
+		}
+		return p.S3Source(ctx, u.Host, key, transformers...), nil
+	})
+}
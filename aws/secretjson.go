@@ -0,0 +1,49 @@
+package aws
+
+import (
+	"context"
+	"strings"
+
+	"github.com/imduffy15/config"
+	"github.com/pkg/errors"
+)
+
+// SecretsManagerJSONExpander returns a config.Expander for references to a secret holding a JSON object,
+// e.g. myapp/prod, expanding it into a key per field, so that a secret's fields need not each be referenced
+// with sm://name#field, at a request each. Nested objects are nested as struct fields are, so with
+// DATABASE=sm-json://myapp/prod the field {"pool": {"size": 4}} is bound from DATABASE__POOL__SIZE.
+// Arrays of strings, numbers and booleans are bound as slices. References may assume a role with ?role=,
+// as sm:// references do.
+func (p *AWSSecretManagerValuePreProcessor) SecretsManagerJSONExpander() config.Expander {
+	return config.ExpanderFunc(p.expandJSONSecret)
+}
+
+func (p *AWSSecretManagerValuePreProcessor) expandJSONSecret(ctx context.Context, ref string) (map[string]string, error) {
+	secret, err := p.resolveSecret(ctx, ref)
+	if err != nil {
+		return nil, err
+	}
+	values, err := config.ReaderSource(strings.NewReader(secret), config.FormatJSON).Load()
+	if err != nil {
+		return nil, errors.Wrapf(err, "config/aws: error parsing secret %s", ref)
+	}
+	return values, nil
+}
+
+// FromSecretsManagerJSON returns a new Builder which pre-processes values with p, populated with a key per field
+// of the secret name, holding a JSON object, under prefix, using ctx. See SecretsManagerJSONExpander.
+//
+//	p.FromSecretsManagerJSON(ctx, "myapp/prod", "DATABASE").FromEnv().To(&c)
+//
+// The fields are resolved values, so are sealed by WithEncryptedSecrets, and can be merged into an existing Builder
+// by setting prefix to sm-json://name in any source. It panics with a *config.Error with config.CodeSecretFetch
+// if the secret cannot be fetched or parsed, or with config.CodeSourceLoad if prefix is empty.
+func (p *AWSSecretManagerValuePreProcessor) FromSecretsManagerJSON(ctx context.Context, name, prefix string) *config.Builder {
+	if strings.TrimSpace(prefix) == "" {
+		return config.FromSource(config.SourceFunc(func() (map[string]string, error) {
+			return nil, errors.New("config/aws: the prefix of a JSON secret must not be empty")
+		}))
+	}
+	return config.WithValuePreProcessor(p.Register(config.NewResolvers(ctx))).
+		FromSource(config.MapSource(map[string]string{prefix: SecretsManagerJSONScheme + "://" + name}))
+}
@@ -0,0 +1,103 @@
+package config
+
+import (
+	"errors"
+	"math"
+	"math/rand"
+	"time"
+)
+
+// Backoff is a retry policy with exponential backoff, bindable as a nested field:
+//
+//	type Config struct {
+//		Retry config.Backoff // RETRY__INITIAL=100ms RETRY__MAX=10s RETRY__MAX_ATTEMPTS=5 ...
+//	}
+//
+// Unset fields take their defaults, so the zero value retries forever, starting at 100ms and doubling without a cap.
+// Call Validate after To to reject misconfigured policies.
+type Backoff struct {
+	// Initial is the delay before the first retry. Zero means 100ms.
+	Initial time.Duration
+	// Max caps the delay between retries. Zero means no cap.
+	Max time.Duration
+	// Multiplier scales the delay after each retry, and must be at least 1. Zero means 2.
+	Multiplier float64
+	// Jitter randomizes each delay by up to this fraction either side, from 0 for none to 1.
+	Jitter float64
+	// MaxAttempts is the number of attempts, including the first, after which to give up. Zero means retry forever.
+	MaxAttempts int `config:"max_attempts"`
+}
+
+const (
+	defaultBackoffInitial    = 100 * time.Millisecond
+	defaultBackoffMultiplier = 2
+)
+
+// Validate reports whether the policy is usable.
+func (b Backoff) Validate() error {
+	switch {
+	case b.Initial < 0:
+		return errors.New("config: invalid backoff: initial delay must not be negative")
+	case b.Max < 0:
+		return errors.New("config: invalid backoff: max delay must not be negative")
+	case b.Max != 0 && b.Max < b.initial():
+		return errors.New("config: invalid backoff: max delay must not be less than the initial delay")
+	case b.Multiplier != 0 && b.Multiplier < 1:
+		return errors.New("config: invalid backoff: multiplier must be at least 1")
+	case b.Jitter < 0 || b.Jitter > 1:
+		return errors.New("config: invalid backoff: jitter must be between 0 and 1")
+	case b.MaxAttempts < 0:
+		return errors.New("config: invalid backoff: max attempts must not be negative")
+	}
+	return nil
+}
+
+// Next returns the delay before retrying after the given attempt failed, counting the first attempt as 1.
+// It returns false if no attempts remain.
+//
+//	for attempt := 1; ; attempt++ {
+//		if err = call(); err == nil {
+//			break
+//		}
+//		delay, ok := c.Retry.Next(attempt)
+//		if !ok {
+//			break
+//		}
+//		time.Sleep(delay)
+//	}
+func (b Backoff) Next(attempt int) (time.Duration, bool) {
+	return b.NextRand(attempt, rand.Float64)
+}
+
+// NextRand is Next, jittering the delay with random, which returns numbers in [0, 1) as rand.Float64 does.
+// A fixed random, e.g. func() float64 { return 0.5 } for no jitter, makes retries deterministic under test.
+func (b Backoff) NextRand(attempt int, random func() float64) (time.Duration, bool) {
+	if attempt < 1 || (b.MaxAttempts > 0 && attempt >= b.MaxAttempts) {
+		return 0, false
+	}
+	delay := float64(b.initial()) * math.Pow(b.multiplier(), float64(attempt-1))
+	if b.Jitter > 0 {
+		delay += delay * b.Jitter * (2*random() - 1)
+	}
+	if b.Max > 0 && delay > float64(b.Max) {
+		delay = float64(b.Max)
+	}
+	if delay >= math.MaxInt64 {
+		return math.MaxInt64, true
+	}
+	return time.Duration(delay), true
+}
+
+func (b Backoff) initial() time.Duration {
+	if b.Initial == 0 {
+		return defaultBackoffInitial
+	}
+	return b.Initial
+}
+
+func (b Backoff) multiplier() float64 {
+	if b.Multiplier == 0 {
+		return defaultBackoffMultiplier
+	}
+	return b.Multiplier
+}
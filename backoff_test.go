@@ -0,0 +1,109 @@
+package config
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBackoff_Validate(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name    string
+		modify  func(b *Backoff)
+		wantErr string
+	}{
+		{name: "Valid", modify: func(b *Backoff) {}},
+		{name: "Zero", modify: func(b *Backoff) { *b = Backoff{} }},
+		{name: "Uncapped", modify: func(b *Backoff) { b.Max = 0 }},
+		{name: "NegativeInitial", modify: func(b *Backoff) { b.Initial = -1 }, wantErr: "initial delay must not be negative"},
+		{name: "NegativeMax", modify: func(b *Backoff) { b.Max = -1 }, wantErr: "max delay must not be negative"},
+		{name: "MaxBelowInitial", modify: func(b *Backoff) { b.Max = time.Millisecond }, wantErr: "max delay must not be less than"},
+		{name: "Multiplier", modify: func(b *Backoff) { b.Multiplier = 0.5 }, wantErr: "multiplier must be at least 1"},
+		{name: "Jitter", modify: func(b *Backoff) { b.Jitter = 1.5 }, wantErr: "jitter must be between 0 and 1"},
+		{name: "MaxAttempts", modify: func(b *Backoff) { b.MaxAttempts = -1 }, wantErr: "max attempts must not be negative"},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			b := Backoff{Initial: time.Second, Max: time.Minute, Multiplier: 1.5, Jitter: 0.2, MaxAttempts: 5}
+			tt.modify(&b)
+			err := b.Validate()
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
+
+func TestBackoff_Next(t *testing.T) {
+	t.Parallel()
+	b := Backoff{Initial: 100 * time.Millisecond, Max: time.Second, Multiplier: 2, MaxAttempts: 6}
+
+	var delays []time.Duration
+	for attempt := 1; ; attempt++ {
+		delay, ok := b.Next(attempt)
+		if !ok {
+			break
+		}
+		delays = append(delays, delay)
+	}
+	assert.Equal(t, []time.Duration{
+		100 * time.Millisecond,
+		200 * time.Millisecond,
+		400 * time.Millisecond,
+		800 * time.Millisecond,
+		time.Second,
+	}, delays)
+
+	_, ok := b.Next(0)
+	assert.False(t, ok)
+
+	b.MaxAttempts = 0
+	delay, ok := b.Next(1000)
+	assert.True(t, ok, "zero max attempts retries forever")
+	assert.Equal(t, time.Second, delay)
+
+	b.Max = 0
+	delay, _ = b.Next(1000)
+	assert.Equal(t, time.Duration(1<<63-1), delay, "uncapped delays saturate")
+
+	delay, _ = Backoff{}.Next(3)
+	assert.Equal(t, 400*time.Millisecond, delay, "the zero value starts at 100ms and doubles")
+
+	b = Backoff{Initial: time.Second, Multiplier: 1, Jitter: 0.5}
+	for i := 0; i < 100; i++ {
+		delay, _ := b.Next(1)
+		assert.True(t, delay >= 500*time.Millisecond && delay <= 1500*time.Millisecond, "delay %v out of range", delay)
+	}
+
+	delay, _ = b.NextRand(1, func() float64 { return 0 })
+	assert.Equal(t, 500*time.Millisecond, delay)
+	delay, _ = b.NextRand(1, func() float64 { return 0.75 })
+	assert.Equal(t, 1250*time.Millisecond, delay)
+}
+
+func Test_backoffBinding(t *testing.T) {
+	type testConfig struct {
+		Retry Backoff
+	}
+
+	os.Clearenv()
+	defer os.Clearenv()
+	require.NoError(t, os.Setenv("RETRY__INITIAL", "1s"))
+	require.NoError(t, os.Setenv("RETRY__MULTIPLIER", "1.5"))
+	require.NoError(t, os.Setenv("RETRY__JITTER", "0.1"))
+	require.NoError(t, os.Setenv("RETRY__MAX_ATTEMPTS", "3"))
+
+	var got testConfig
+	FromEnv().To(&got)
+	assert.Equal(t, Backoff{Initial: time.Second, Multiplier: 1.5, Jitter: 0.1, MaxAttempts: 3}, got.Retry)
+	assert.NoError(t, got.Retry.Validate())
+}
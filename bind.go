@@ -0,0 +1,44 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// Bind is To, returning an error rather than panicking, and rather than silently leaving fields which fail to convert
+// as their zero value. This lets services handle bad config gracefully at startup:
+//
+//	if err := config.FromEnv().Bind(&c); err != nil {
+//		log.Fatalf("invalid config: %v", err)
+//	}
+//
+// The error is an *Error, or if several fields fail to convert, a list of them which errors.As and CodeOf see into.
+// Fields which fail to convert are still passed to the handler set by WithConversionErrorHandler, if any,
+// and the fields which did convert are set either way. A FailureReport is written on error if one is configured,
+// see WithFailureReport.
+func (c *Builder) Bind(target interface{}) (err error) {
+	if t := reflect.TypeOf(target); t == nil || t.Kind() != reflect.Ptr || t.Elem().Kind() != reflect.Struct || reflect.ValueOf(target).IsNil() {
+		return newError(CodeUnsupportedField, "", fmt.Errorf("cannot bind to %T, it must be a non nil struct pointer", target))
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			err = recoveredError(r)
+		}
+		if err != nil {
+			c.writeFailureReport(target, err)
+		}
+	}()
+	return c.ToWithReport(target).Err()
+}
+
+// recoveredError returns a value recovered from a panic while binding as an error.
+// Panics raised by this package carry an *Error; anything else, such as a panic in a ValuePreProcessor, is wrapped.
+func recoveredError(r interface{}) error {
+	if err, ok := r.(*Error); ok {
+		return err
+	}
+	if err, ok := r.(error); ok {
+		return fmt.Errorf("config: %w", err)
+	}
+	return fmt.Errorf("config: %v", r)
+}
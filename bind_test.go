@@ -0,0 +1,71 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuilder_Bind(t *testing.T) {
+	t.Parallel()
+	type testConfig struct {
+		Port  int
+		Ratio float64
+		Host  string
+	}
+
+	var got testConfig
+	require.NoError(t, FromSource(MapSource(map[string]string{"PORT": "80", "HOST": "h"})).Bind(&got))
+	assert.Equal(t, testConfig{Port: 80, Host: "h"}, got)
+
+	got = testConfig{}
+	err := FromSource(MapSource(map[string]string{"PORT": "eighty", "HOST": "h"})).Bind(&got)
+	assert.EqualError(t, err, `config: CFG002: port: cannot convert "eighty" to int: strconv.ParseInt: parsing "eighty": invalid syntax`)
+	assert.Equal(t, "h", got.Host, "fields which convert are still set")
+
+	err = FromSource(MapSource(map[string]string{"PORT": "eighty", "RATIO": "half"})).Bind(&got)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "2 errors: ")
+	assert.Equal(t, CodeBadType, CodeOf(err))
+	var cfgErr *Error
+	require.True(t, errors.As(err, &cfgErr))
+	assert.Contains(t, []string{"port", "ratio"}, cfgErr.Key())
+}
+
+func TestBuilder_Bind_panics(t *testing.T) {
+	t.Parallel()
+	var s testConfigWithUnsupported
+	err := FromSource(MapSource(map[string]string{"PTR": "x"})).Bind(&s)
+	assert.Equal(t, CodeUnsupportedField, CodeOf(err))
+
+	for _, target := range []interface{}{nil, testConfigWithUnsupported{}, new(int), (*testConfigWithUnsupported)(nil)} {
+		err := FromEnv().Bind(target)
+		assert.Equal(t, CodeUnsupportedField, CodeOf(err), "%T", target)
+		assert.Contains(t, err.Error(), "it must be a non nil struct pointer")
+	}
+
+}
+
+func TestBuilder_Bind_lazy(t *testing.T) {
+	os.Clearenv()
+	defer os.Clearenv()
+	require.NoError(t, os.Setenv("A", "x"))
+
+	var got struct{ A string }
+	err := WithValuePreProcessor(ValuePreProcessorFunc(func(key, value string) string {
+		panic("boom")
+	})).FromEnvLazy().Bind(&got)
+	assert.EqualError(t, err, "config: boom")
+
+	err = WithValuePreProcessor(ValuePreProcessorErrorFunc(func(key, value string) (string, error) {
+		return "", errors.New("denied")
+	})).FromEnvLazy().Bind(&got)
+	assert.Equal(t, CodeSecretFetch, CodeOf(err))
+}
+
+type testConfigWithUnsupported struct {
+	Ptr *string
+}
@@ -0,0 +1,143 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// bitFlagsDelim separates the names of the flags making up a value, e.g. read|write.
+const bitFlagsDelim = "|"
+
+var (
+	bitFlagsMu sync.RWMutex
+	bitFlags   = make(map[reflect.Type][]bitFlag)
+)
+
+type bitFlag struct {
+	name  string
+	value uint64
+}
+
+// RegisterBitFlags registers the names of the bitmask constants of the integer type T, so that fields of type T
+// are bound from the names of the flags they are made of, separated by |:
+//
+//	type Permission uint8
+//
+//	const (
+//		Read Permission = 1 << iota
+//		Write
+//		Admin
+//	)
+//
+//	func init() {
+//		config.RegisterBitFlags(map[string]Permission{"read": Read, "write": Write, "admin": Admin})
+//	}
+//
+//	type Config struct {
+//		Access Permission // bound from ACCESS=read|write as Read|Write
+//	}
+//
+// Names are case insensitive, an empty value is 0, and unknown names are conversion errors.
+// Defaults are written as names too, by help and exports. It is intended to be called from an init function.
+// It panics if flags is empty, a name is empty or contains |, or T is already registered.
+func RegisterBitFlags[T ~int | ~int8 | ~int16 | ~int32 | ~int64 | ~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64](flags map[string]T) {
+	t := reflect.TypeOf(T(0))
+	if len(flags) == 0 {
+		panic("config: RegisterBitFlags called without flags for " + t.String())
+	}
+	registered := make([]bitFlag, 0, len(flags))
+	for name, value := range flags {
+		if name = strings.TrimSpace(name); name == "" || strings.Contains(name, bitFlagsDelim) {
+			panic(fmt.Sprintf("config: RegisterBitFlags flag name %q of %v is empty or contains %s", name, t, bitFlagsDelim))
+		}
+		registered = append(registered, bitFlag{name: name, value: uint64(value)})
+	}
+	sort.Slice(registered, func(i, j int) bool {
+		if registered[i].value != registered[j].value {
+			return registered[i].value < registered[j].value
+		}
+		return registered[i].name < registered[j].name
+	})
+	bitFlagsMu.Lock()
+	defer bitFlagsMu.Unlock()
+	if _, dup := bitFlags[t]; dup {
+		panic("config: RegisterBitFlags called twice for " + t.String())
+	}
+	bitFlags[t] = registered
+}
+
+func registeredBitFlags(t reflect.Type) ([]bitFlag, bool) {
+	bitFlagsMu.RLock()
+	defer bitFlagsMu.RUnlock()
+	flags, ok := bitFlags[t]
+	return flags, ok
+}
+
+// parseBitFlags returns the union of the flags named in s, e.g. read|write.
+func parseBitFlags(flags []bitFlag, s string) (uint64, error) {
+	var bits uint64
+	for _, name := range strings.Split(s, bitFlagsDelim) {
+		if name = strings.TrimSpace(name); name == "" {
+			continue
+		}
+		found := false
+		for _, f := range flags {
+			if strings.EqualFold(f.name, name) {
+				bits |= f.value
+				found = true
+				break
+			}
+		}
+		if !found {
+			names := make([]string, len(flags))
+			for i, f := range flags {
+				names[i] = f.name
+			}
+			return 0, fmt.Errorf("unknown flag %q, expected some of %s", name, strings.Join(names, bitFlagsDelim))
+		}
+	}
+	return bits, nil
+}
+
+// formatBitFlags returns the names of the flags making up bits, e.g. read|write.
+// Bits without a name are written as a number.
+func formatBitFlags(flags []bitFlag, bits uint64) string {
+	var names []string
+	for _, f := range flags {
+		if f.value != 0 && bits&f.value == f.value {
+			names = append(names, f.name)
+			bits &^= f.value
+		}
+	}
+	if bits != 0 || len(names) == 0 {
+		names = append(names, strconv.FormatUint(bits, 10))
+	}
+	return strings.Join(names, bitFlagsDelim)
+}
+
+// setBitFlags sets the integer value v to the flags named in s.
+func setBitFlags(v reflect.Value, flags []bitFlag, s string) error {
+	bits, err := parseBitFlags(flags, s)
+	if err != nil {
+		v.Set(reflect.Zero(v.Type()))
+		return fmt.Errorf("cannot convert %q to %v: %w", s, v.Type(), err)
+	}
+	if v.Kind() >= reflect.Int && v.Kind() <= reflect.Int64 {
+		v.SetInt(int64(bits))
+	} else {
+		v.SetUint(bits)
+	}
+	return nil
+}
+
+// bitFlagsValue returns the integer value v as registered flags' bits.
+func bitFlagsValue(v reflect.Value) uint64 {
+	if v.Kind() >= reflect.Int && v.Kind() <= reflect.Int64 {
+		return uint64(v.Int())
+	}
+	return v.Uint()
+}
@@ -0,0 +1,76 @@
+package config
+
+import (
+	"bytes"
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testPermission uint8
+
+const (
+	testRead testPermission = 1 << iota
+	testWrite
+	testAdmin
+)
+
+type testCapability int
+
+func init() {
+	RegisterBitFlags(map[string]testPermission{"read": testRead, "write": testWrite, "admin": testAdmin, "all": 7})
+	RegisterBitFlags(map[string]testCapability{"net": 1, "sys": -8})
+}
+
+func TestRegisterBitFlags(t *testing.T) {
+	type testConfig struct {
+		Access  testPermission
+		Caps    testCapability
+		Grants  []testPermission
+		Empty   testPermission
+		Unknown testPermission
+	}
+
+	os.Clearenv()
+	defer os.Clearenv()
+	require.NoError(t, os.Setenv("ACCESS", "read | Write"))
+	require.NoError(t, os.Setenv("CAPS", "net|sys"))
+	require.NoError(t, os.Setenv("GRANTS", "read all"))
+	require.NoError(t, os.Setenv("EMPTY", ""))
+	require.NoError(t, os.Setenv("UNKNOWN", "read|root"))
+
+	var errs []error
+	got := testConfig{Unknown: testAdmin}
+	WithConversionErrorHandler(func(key, value string, err error) {
+		errs = append(errs, err)
+	}).FromEnv().To(&got)
+
+	assert.Equal(t, testConfig{
+		Access: testRead | testWrite,
+		Caps:   -7,
+		Grants: []testPermission{testRead, testRead | testWrite | testAdmin},
+	}, got)
+	require.Len(t, errs, 1)
+	assert.Contains(t, errs[0].Error(), `unknown flag "root", expected some of read|write|admin|all`)
+
+	assert.Panics(t, func() { RegisterBitFlags(map[string]testPermission{"x": 1}) })
+	assert.Panics(t, func() { RegisterBitFlags(map[string]uint8{"a|b": 1}) })
+	assert.Panics(t, func() { RegisterBitFlags(map[string]uint16{}) })
+}
+
+func Test_formatBitFlags(t *testing.T) {
+	t.Parallel()
+	flags, ok := registeredBitFlags(reflect.TypeOf(testRead))
+	require.True(t, ok)
+	assert.Equal(t, "read|admin", formatBitFlags(flags, uint64(testRead|testAdmin)))
+	assert.Equal(t, "read|write|admin", formatBitFlags(flags, 7))
+	assert.Equal(t, "write|8", formatBitFlags(flags, 10))
+	assert.Equal(t, "0", formatBitFlags(flags, 0))
+
+	var b bytes.Buffer
+	require.NoError(t, WriteDotenv(&b, &struct{ Access testPermission }{Access: testRead | testWrite}, ""))
+	assert.Contains(t, b.String(), `ACCESS=read|write`)
+}
@@ -0,0 +1,116 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// BudgetError is the underlying error of an *Error with CodeTimeout, raised when the startup budget set by
+// WithStartupBudget runs out.
+type BudgetError struct {
+	// Budget is the budget which ran out.
+	Budget time.Duration
+	// Elapsed is the time spent since the budget was set.
+	Elapsed time.Duration
+	// Pending lists the lookups still pending, sorted: the normalized keys of values yet to be resolved,
+	// and sources yet to load, e.g. "file app.config".
+	Pending []string
+}
+
+func (e *BudgetError) Error() string {
+	return fmt.Sprintf("startup budget of %s exceeded after %s, pending: %s",
+		e.Budget, e.Elapsed.Round(time.Millisecond), strings.Join(e.Pending, ", "))
+}
+
+// startupBudget bounds the time spent loading sources and resolving values.
+type startupBudget struct {
+	budget   time.Duration
+	deadline time.Time
+	clock    Clock
+	// pending holds the lookups yet to complete.
+	pending map[string]bool
+}
+
+// WithStartupBudget creates a new builder with a startup budget.
+func WithStartupBudget(d time.Duration) *Builder {
+	return newBuilder().WithStartupBudget(d)
+}
+
+// WithStartupBudget bounds the total time the Builder spends loading sources and resolving values,
+// including values resolved lazily by the first To, to d from when it is called. This turns a boot hanging on
+// a slow secret store into a prompt failure, e.g. ahead of a Kubernetes startup probe killing the pod:
+//
+//	config.WithStartupBudget(10 * time.Second).
+//		WithValuePreProcessor(p).
+//		FromEnv().
+//		To(&c)
+//
+// When the budget runs out, the Builder panics with an *Error with CodeTimeout, whose BudgetError lists
+// the lookups still pending. The lookup in progress is abandoned rather than cancelled, so it should
+// also be bounded, e.g. by a context deadline, to avoid leaking it. The budget ends once the first To,
+// or Bind, has bound its target. It is measured on the Builder's Clock, so WithClock must be called first.
+func (c *Builder) WithStartupBudget(d time.Duration) *Builder {
+	clock := clockOrSystem(c.clock)
+	c.budget = &startupBudget{budget: d, deadline: clock.Now().Add(d), clock: clock, pending: make(map[string]bool)}
+	return c
+}
+
+// withinBudget calls fn, panicking if the startup budget runs out first.
+// fn runs on its own goroutine when there is a budget, so must not change the Builder.
+func (c *Builder) withinBudget(lookup string, fn func()) {
+	b := c.budget
+	if b == nil {
+		fn()
+		return
+	}
+	b.pending[lookup] = true
+	remaining := b.deadline.Sub(b.clock.Now())
+	if remaining <= 0 {
+		panic(b.exceeded())
+	}
+
+	done := make(chan interface{}, 1)
+	go func() {
+		defer func() { done <- recover() }()
+		fn()
+	}()
+	fired, stop := b.clock.NewTimer(remaining)
+	defer stop()
+	select {
+	case r := <-done:
+		if r != nil {
+			panic(r)
+		}
+		delete(b.pending, lookup)
+	case <-fired:
+		panic(b.exceeded())
+	}
+}
+
+// markPending records keys as pending lookups, until they are marked done.
+func (c *Builder) markPending(keys map[string]string) {
+	if c.budget == nil {
+		return
+	}
+	for k := range keys {
+		c.budget.pending[k] = true
+	}
+}
+
+// markDone records a pending lookup as complete.
+func (c *Builder) markDone(lookup string) {
+	if c.budget != nil {
+		delete(c.budget.pending, lookup)
+	}
+}
+
+func (b *startupBudget) exceeded() *Error {
+	pending := make([]string, 0, len(b.pending))
+	for k := range b.pending {
+		pending = append(pending, k)
+	}
+	sort.Strings(pending)
+	return newError(CodeTimeout, "", &BudgetError{Budget: b.budget, Elapsed: b.budget + b.clock.Now().Sub(b.deadline), Pending: pending})
+}
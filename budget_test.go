@@ -0,0 +1,139 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuilder_WithStartupBudget(t *testing.T) {
+	t.Parallel()
+	release, started := make(chan struct{}), make(chan struct{})
+	defer close(release)
+	slow := ValuePreProcessorFunc(func(key, value string) string {
+		if value == "slow://x" {
+			close(started)
+			<-release
+		}
+		return "resolved:" + value
+	})
+
+	type testConfig struct{ Fast, Slow string }
+	var got testConfig
+	WithStartupBudget(time.Minute).WithValuePreProcessor(slow).FromSource(MapSource(map[string]string{"FAST": "f"})).To(&got)
+	assert.Equal(t, testConfig{Fast: "resolved:f"}, got)
+
+	clock := NewManualClock(time.Now())
+	go func() {
+		<-started
+		clock.BlockUntil(1)
+		clock.Advance(20 * time.Millisecond)
+	}()
+	err := recoverError(func() {
+		WithClock(clock).WithStartupBudget(20 * time.Millisecond).
+			WithValuePreProcessor(slow).
+			FromSource(MapSource(map[string]string{"FAST": "f", "SLOW": "slow://x"}))
+	})
+	assert.Equal(t, CodeTimeout, CodeOf(err))
+	var budgetErr *BudgetError
+	require.True(t, errors.As(err, &budgetErr))
+	assert.Equal(t, 20*time.Millisecond, budgetErr.Budget)
+	assert.Equal(t, 20*time.Millisecond, budgetErr.Elapsed)
+	assert.Contains(t, budgetErr.Pending, "slow")
+	assert.Contains(t, err.Error(), "config: CFG007: startup budget of 20ms exceeded after 20ms, pending: ")
+}
+
+func TestBuilder_WithStartupBudget_clock(t *testing.T) {
+	t.Parallel()
+	release := make(chan struct{})
+	defer close(release)
+	slow := SourceFunc(func() (map[string]string, error) {
+		<-release
+		return nil, nil
+	})
+
+	clock := NewManualClock(time.Now())
+	go func() {
+		clock.BlockUntil(1)
+		clock.Advance(time.Hour)
+	}()
+	err := recoverError(func() { WithClock(clock).WithStartupBudget(time.Hour).FromSource(slow) })
+	var budgetErr *BudgetError
+	require.True(t, errors.As(err, &budgetErr))
+	assert.Equal(t, time.Hour, budgetErr.Elapsed, "the budget is measured on the Builder's Clock")
+}
+
+func TestBuilder_WithStartupBudget_sources(t *testing.T) {
+	t.Parallel()
+	release := make(chan struct{})
+	defer close(release)
+	slow := SourceFunc(func() (map[string]string, error) {
+		<-release
+		return nil, nil
+	})
+
+	clock := NewManualClock(time.Now())
+	go func() {
+		clock.BlockUntil(1)
+		clock.Advance(10 * time.Millisecond)
+	}()
+	err := recoverError(func() { WithClock(clock).WithStartupBudget(10 * time.Millisecond).FromSource(slow) })
+	var budgetErr *BudgetError
+	require.True(t, errors.As(err, &budgetErr))
+	assert.Equal(t, []string{"source config.SourceFunc"}, budgetErr.Pending)
+
+	err = recoverError(func() { WithStartupBudget(0).From("testdata/does-not-matter.config") })
+	require.True(t, errors.As(err, &budgetErr))
+	assert.Equal(t, []string{"file testdata/does-not-matter.config"}, budgetErr.Pending, "a spent budget fails before loading")
+}
+
+func TestBuilder_WithStartupBudget_lazy(t *testing.T) {
+	os.Clearenv()
+	defer os.Clearenv()
+	require.NoError(t, os.Setenv("PATH", "/bin"))
+
+	release := make(chan struct{})
+	defer close(release)
+	clock := NewManualClock(time.Now())
+	b := WithClock(clock).WithStartupBudget(10 * time.Millisecond).WithValuePreProcessor(ValuePreProcessorFunc(func(key, value string) string {
+		<-release
+		return value
+	})).FromEnvLazy()
+
+	go func() {
+		clock.BlockUntil(1)
+		clock.Advance(10 * time.Millisecond)
+	}()
+	var got struct{ Path string }
+	err := b.Bind(&got)
+	assert.Equal(t, CodeTimeout, CodeOf(err), "values resolved by To count against the budget")
+	assert.Contains(t, err.Error(), "pending: path")
+
+	err = recoverError(func() {
+		WithStartupBudget(time.Minute).WithValuePreProcessor(ValuePreProcessorErrorFunc(func(key, value string) (string, error) {
+			return "", errors.New("denied")
+		})).FromSource(MapSource(map[string]string{"A": "x"}))
+	})
+	assert.Equal(t, CodeSecretFetch, CodeOf(err), "failures within the budget are raised as usual")
+}
+
+func TestBuilder_WithStartupBudget_afterStartup(t *testing.T) {
+	os.Clearenv()
+	defer os.Clearenv()
+	require.NoError(t, os.Setenv("PATH", "/bin"))
+	require.NoError(t, os.Setenv("OTHER", "x"))
+
+	clock := NewManualClock(time.Now())
+	b := WithClock(clock).WithStartupBudget(20 * time.Millisecond).FromEnvLazy()
+	var first struct{ Path string }
+	b.To(&first)
+	clock.Advance(40 * time.Millisecond)
+
+	var second struct{ Other string }
+	require.NoError(t, b.Bind(&second), "the budget ends with the first bind")
+	assert.Equal(t, "x", second.Other)
+}
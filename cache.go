@@ -0,0 +1,111 @@
+package config
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// ResolverCache stores resolved values, so that lookups can be shared between Builders,
+// or between processes with a shared store, e.g. a fleet of short lived jobs which would otherwise each hit
+// the same secret store and its rate limits.
+// Values are resolved secrets, so a shared store must be trusted with them.
+type ResolverCache interface {
+	// Get returns the value cached for ref, and whether there is one.
+	Get(ctx context.Context, ref string) (value string, ok bool, err error)
+	// Set caches value for ref, for ttl. A ttl of zero means the value does not expire.
+	Set(ctx context.Context, ref, value string, ttl time.Duration) error
+}
+
+// WithCache makes the Resolvers look up values in cache before resolving them,
+// and cache the values they resolve for ttl, returning the Resolvers.
+// Values are cached by their full scheme://ref reference. The cache is best effort:
+// if it fails, values are resolved as though it was empty. Values re-resolved by a Builder's CheckDrift,
+// WatchRefresh or Refresh bypass the cache, so that rotated secrets are seen, and replace the cached values.
+func (r *Resolvers) WithCache(cache ResolverCache, ttl time.Duration) *Resolvers {
+	r.cache, r.cacheTTL = cache, ttl
+	return r
+}
+
+// refreshing returns a copy of the Resolvers which resolve values afresh rather than from the cache,
+// still caching the values they resolve.
+func (r *Resolvers) refreshing() *Resolvers {
+	fresh := *r
+	fresh.bypassCache = true
+	return &fresh
+}
+
+// cached returns the value cached for value, if the Resolvers have a cache.
+func (r *Resolvers) cached(ctx context.Context, value string) (string, bool) {
+	if r.cache == nil || r.bypassCache {
+		return "", false
+	}
+	resolved, ok, err := r.cache.Get(ctx, value)
+	return resolved, ok && err == nil
+}
+
+// store caches resolved for value, if the Resolvers have a cache.
+func (r *Resolvers) store(ctx context.Context, value, resolved string) {
+	if r.cache != nil {
+		_ = r.cache.Set(ctx, value, resolved, r.cacheTTL)
+	}
+}
+
+// MemoryCache is a ResolverCache held in memory, shared by the Resolvers in a process.
+// The zero value is an empty cache ready to use.
+type MemoryCache struct {
+	mu      sync.Mutex
+	entries map[string]memoryCacheEntry
+	clock   Clock
+}
+
+type memoryCacheEntry struct {
+	value   string
+	expires time.Time
+}
+
+// NewMemoryCache returns an empty MemoryCache.
+func NewMemoryCache() *MemoryCache {
+	return &MemoryCache{}
+}
+
+// WithClock makes the cache expire values by clock rather than SystemClock, returning the MemoryCache.
+func (m *MemoryCache) WithClock(clock Clock) *MemoryCache {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.clock = clock
+	return m
+}
+
+// Get returns the value cached for ref, if it has not expired.
+func (m *MemoryCache) Get(_ context.Context, ref string) (string, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	e, ok := m.entries[ref]
+	if !ok {
+		return "", false, nil
+	}
+	if !e.expires.IsZero() && !clockOrSystem(m.clock).Now().Before(e.expires) {
+		delete(m.entries, ref)
+		return "", false, nil
+	}
+	return e.value, true, nil
+}
+
+// Set caches value for ref, for ttl.
+func (m *MemoryCache) Set(_ context.Context, ref, value string, ttl time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.entries == nil {
+		m.entries = make(map[string]memoryCacheEntry)
+	}
+	e := memoryCacheEntry{value: value}
+	if ttl > 0 {
+		e.expires = clockOrSystem(m.clock).Now().Add(ttl)
+	}
+	m.entries[ref] = e
+	return nil
+}
+
+// compile time assertion
+var _ ResolverCache = (*MemoryCache)(nil)
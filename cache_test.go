@@ -0,0 +1,121 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryCache(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	clock := NewManualClock(time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC))
+	m := NewMemoryCache().WithClock(clock)
+
+	_, ok, err := m.Get(ctx, "sm://a")
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	require.NoError(t, m.Set(ctx, "sm://a", "1", time.Minute))
+	require.NoError(t, m.Set(ctx, "sm://b", "2", 0))
+	v, ok, err := m.Get(ctx, "sm://a")
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, "1", v)
+
+	clock.Advance(time.Minute)
+	_, ok, _ = m.Get(ctx, "sm://a")
+	assert.False(t, ok, "expired")
+	v, ok, _ = m.Get(ctx, "sm://b")
+	assert.True(t, ok, "no ttl never expires")
+	assert.Equal(t, "2", v)
+}
+
+type failingCache struct{}
+
+func (failingCache) Get(context.Context, string) (string, bool, error) {
+	return "stale", true, errors.New("unavailable")
+}
+
+func (failingCache) Set(context.Context, string, string, time.Duration) error {
+	return errors.New("unavailable")
+}
+
+func TestResolvers_WithCache(t *testing.T) {
+	t.Parallel()
+	var calls int
+	resolver := ResolverFunc(func(ctx context.Context, ref string) (string, error) {
+		calls++
+		return "resolved:" + ref, nil
+	})
+	cache := NewMemoryCache()
+	newResolvers := func() *Resolvers {
+		return NewResolvers(context.Background()).Register("a", resolver).Register("b", resolver).WithCache(cache, time.Hour)
+	}
+
+	type testConfig struct{ X, Y, Z string }
+	values := map[string]string{"X": "a://secret", "Y": "b://secret", "Z": "plain"}
+	var got testConfig
+	WithValuePreProcessor(newResolvers()).FromSource(MapSource(values)).To(&got)
+	assert.Equal(t, testConfig{X: "resolved:secret", Y: "resolved:secret", Z: "plain"}, got)
+	assert.Equal(t, 2, calls, "values are cached by scheme as well as ref")
+
+	got = testConfig{}
+	WithValuePreProcessor(newResolvers()).FromSource(MapSource(values)).To(&got)
+	assert.Equal(t, testConfig{X: "resolved:secret", Y: "resolved:secret", Z: "plain"}, got)
+	assert.Equal(t, 2, calls, "a fresh Resolvers sharing the cache does not resolve again")
+
+	r := NewResolvers(context.Background()).Register("a", resolver).WithCache(failingCache{}, time.Hour)
+	assert.Equal(t, "resolved:x", r.PreProcessValue("k", "a://x"), "a failing cache is bypassed")
+	assert.Equal(t, 3, calls)
+}
+
+func TestResolvers_WithCache_reresolve(t *testing.T) {
+	t.Parallel()
+	var mu sync.Mutex
+	secret := "v1"
+	resolver := ResolverFunc(func(ctx context.Context, ref string) (string, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		return secret, nil
+	})
+	cache := NewMemoryCache()
+	r := NewResolvers(context.Background()).Register("a", resolver).WithCache(cache, time.Hour)
+
+	type testConfig struct{ Password string }
+	clock := NewManualClock(time.Now())
+	b := WithClock(clock).WithRefreshInterval(time.Minute).WithValuePreProcessor(r).FromSource(MapSource(map[string]string{"PASSWORD": "a://db"}))
+	var c testConfig
+	b.To(&c)
+	assert.Equal(t, "v1", c.Password)
+
+	mu.Lock()
+	secret = "v2"
+	mu.Unlock()
+	drifts, err := b.CheckDrift(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []Drift{{Key: "password", Reference: "a://db"}}, drifts, "drift is checked against the store, not the cache")
+	v, _, _ := cache.Get(context.Background(), "a://db")
+	assert.Equal(t, "v2", v, "re-resolved values replace those cached")
+
+	mu.Lock()
+	secret = "v3"
+	mu.Unlock()
+	changes := make(chan *testConfig, 1)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	require.NoError(t, b.OnChange(func(old, new interface{}) { changes <- new.(*testConfig) }).Refresh(ctx, &c, nil))
+	clock.BlockUntil(1)
+	clock.Advance(time.Minute)
+	select {
+	case got := <-changes:
+		assert.Equal(t, "v3", got.Password, "refreshed values are not read from the cache")
+	case <-time.After(5 * time.Second):
+		t.Fatal("no change after refresh")
+	}
+}
@@ -0,0 +1,133 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// TriState is a boolean which can also be left unset, so that a default can be chosen
+// based on the environment, e.g. whether to use a pager.
+// It accepts the values strconv.ParseBool does.
+type TriState int
+
+const (
+	// TriStateUnset is the zero value of a TriState, and means the value was not configured.
+	TriStateUnset TriState = iota
+	// TriStateTrue is a TriState configured as true.
+	TriStateTrue
+	// TriStateFalse is a TriState configured as false.
+	TriStateFalse
+)
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (t *TriState) UnmarshalText(text []byte) error {
+	b, err := strconv.ParseBool(strings.TrimSpace(string(text)))
+	if err != nil {
+		return fmt.Errorf("config: invalid boolean %q", text)
+	}
+	*t = TriStateFalse
+	if b {
+		*t = TriStateTrue
+	}
+	return nil
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (t TriState) MarshalText() ([]byte, error) {
+	return []byte(t.String()), nil
+}
+
+// IsSet reports whether t was configured.
+func (t TriState) IsSet() bool {
+	return t != TriStateUnset
+}
+
+// Or returns t as a bool, or def if t is unset.
+func (t TriState) Or(def bool) bool {
+	if t == TriStateUnset {
+		return def
+	}
+	return t == TriStateTrue
+}
+
+// String returns "true", "false" or "unset".
+func (t TriState) String() string {
+	switch t {
+	case TriStateTrue:
+		return "true"
+	case TriStateFalse:
+		return "false"
+	default:
+		return "unset"
+	}
+}
+
+// When is an auto/always/never setting, common for CLI behaviour such as colored output or paging.
+// The zero value is WhenAuto.
+type When int
+
+const (
+	// WhenAuto leaves the decision to the application, typically based on whether output is a terminal.
+	WhenAuto When = iota
+	// WhenAlways enables the behaviour unconditionally.
+	WhenAlways
+	// WhenNever disables the behaviour unconditionally.
+	WhenNever
+)
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+// Values are matched case insensitively. As a convenience, boolean values map to WhenAlways and WhenNever.
+func (w *When) UnmarshalText(text []byte) error {
+	s := strings.ToLower(strings.TrimSpace(string(text)))
+	switch s {
+	case "auto":
+		*w = WhenAuto
+		return nil
+	case "always":
+		*w = WhenAlways
+		return nil
+	case "never":
+		*w = WhenNever
+		return nil
+	}
+	if b, err := strconv.ParseBool(s); err == nil {
+		*w = WhenNever
+		if b {
+			*w = WhenAlways
+		}
+		return nil
+	}
+	return fmt.Errorf("config: invalid value %q, want one of auto, always or never", text)
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (w When) MarshalText() ([]byte, error) {
+	return []byte(w.String()), nil
+}
+
+// Resolve returns whether the behaviour is enabled, calling auto when w is WhenAuto.
+//
+//	color := c.Color.Resolve(func() bool { return isatty(os.Stdout) })
+func (w When) Resolve(auto func() bool) bool {
+	switch w {
+	case WhenAlways:
+		return true
+	case WhenNever:
+		return false
+	default:
+		return auto()
+	}
+}
+
+// String returns "auto", "always" or "never".
+func (w When) String() string {
+	switch w {
+	case WhenAlways:
+		return "always"
+	case WhenNever:
+		return "never"
+	default:
+		return "auto"
+	}
+}
@@ -0,0 +1,93 @@
+package config
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTriState_UnmarshalText(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		in      string
+		want    TriState
+		wantErr bool
+	}{
+		{in: "true", want: TriStateTrue},
+		{in: "1", want: TriStateTrue},
+		{in: "F", want: TriStateFalse},
+		{in: "false", want: TriStateFalse},
+		{in: "maybe", wantErr: true},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.in, func(t *testing.T) {
+			t.Parallel()
+			var got TriState
+			err := got.UnmarshalText([]byte(tt.in))
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	assert.True(t, TriStateUnset.Or(true))
+	assert.False(t, TriStateFalse.Or(true))
+	assert.True(t, TriStateTrue.Or(false))
+}
+
+func TestWhen_UnmarshalText(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		in      string
+		want    When
+		wantErr bool
+	}{
+		{in: "auto", want: WhenAuto},
+		{in: "ALWAYS", want: WhenAlways},
+		{in: "never", want: WhenNever},
+		{in: "true", want: WhenAlways},
+		{in: "0", want: WhenNever},
+		{in: "sometimes", wantErr: true},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.in, func(t *testing.T) {
+			t.Parallel()
+			var got When
+			err := got.UnmarshalText([]byte(tt.in))
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	assert.True(t, WhenAuto.Resolve(func() bool { return true }))
+	assert.True(t, WhenAlways.Resolve(func() bool { return false }))
+	assert.False(t, WhenNever.Resolve(func() bool { return true }))
+}
+
+func Test_cliTypesBinding(t *testing.T) {
+	type testConfig struct {
+		Pager TriState
+		Debug TriState
+		Color When
+	}
+
+	os.Clearenv()
+	defer os.Clearenv()
+	require.NoError(t, os.Setenv("PAGER", "false"))
+	require.NoError(t, os.Setenv("COLOR", "never"))
+
+	var got testConfig
+	FromEnv().To(&got)
+	assert.Equal(t, testConfig{Pager: TriStateFalse, Debug: TriStateUnset, Color: WhenNever}, got)
+}
@@ -0,0 +1,144 @@
+package config
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// Clock tells the time and schedules timers, for the Builder's leases, refreshes, resolution times,
+// startup budget and Deadline fields, and MemoryCache expiry. SystemClock is used by default; tests of reload
+// and rotation behaviour substitute a ManualClock, advancing it rather than sleeping. Durations measured
+// for Stats always use the system clock.
+type Clock interface {
+	// Now returns the current time.
+	Now() time.Time
+	// NewTimer returns a channel which receives the time once d has elapsed, and a function stopping the timer,
+	// reporting whether it stopped it before it fired, as time.NewTimer does.
+	NewTimer(d time.Duration) (<-chan time.Time, func() bool)
+}
+
+// SystemClock is the Clock of the time package.
+var SystemClock Clock = systemClock{}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+func (systemClock) NewTimer(d time.Duration) (<-chan time.Time, func() bool) {
+	t := time.NewTimer(d)
+	return t.C, t.Stop
+}
+
+// WithClock creates a new builder which tells the time with clock.
+func WithClock(clock Clock) *Builder {
+	return newBuilder().WithClock(clock)
+}
+
+// WithClock makes the Builder tell the time, e.g. when a lease expires or a value is due a refresh, with clock,
+// returning the Builder. This makes WatchLeases and WatchRefresh deterministic under test:
+//
+//	clock := config.NewManualClock(time.Now())
+//	b := config.WithClock(clock).WithValuePreProcessor(p).FromEnv()
+//	b.WatchRefresh(ctx, &c, time.Minute, reload)
+//	clock.BlockUntil(1)
+//	clock.Advance(time.Minute)
+func (c *Builder) WithClock(clock Clock) *Builder {
+	c.clock = clock
+	return c
+}
+
+// now returns the current time on the Builder's Clock.
+func (c *Builder) now() time.Time {
+	return clockOrSystem(c.clock).Now()
+}
+
+func clockOrSystem(clock Clock) Clock {
+	if clock == nil {
+		return SystemClock
+	}
+	return clock
+}
+
+// ManualClock is a Clock whose time only changes when it is advanced, for tests.
+// It is safe for concurrent use.
+type ManualClock struct {
+	mu      sync.Mutex
+	changed *sync.Cond
+	now     time.Time
+	timers  []*manualTimer
+}
+
+type manualTimer struct {
+	at time.Time
+	c  chan time.Time
+}
+
+// NewManualClock returns a ManualClock set to now.
+func NewManualClock(now time.Time) *ManualClock {
+	m := &ManualClock{now: now}
+	m.changed = sync.NewCond(&m.mu)
+	return m
+}
+
+// Now returns the clock's time.
+func (m *ManualClock) Now() time.Time {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.now
+}
+
+// NewTimer returns a timer firing once the clock is advanced by d. Timers of d <= 0 fire immediately.
+func (m *ManualClock) NewTimer(d time.Duration) (<-chan time.Time, func() bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	t := &manualTimer{at: m.now.Add(d), c: make(chan time.Time, 1)}
+	if d <= 0 {
+		t.c <- m.now
+		return t.c, func() bool { return false }
+	}
+	m.timers = append(m.timers, t)
+	m.changed.Broadcast()
+	return t.c, func() bool { return m.stop(t) }
+}
+
+func (m *ManualClock) stop(t *manualTimer) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for i, pending := range m.timers {
+		if pending == t {
+			m.timers = append(m.timers[:i], m.timers[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// Advance moves the clock forward by d, firing the timers due by then, earliest first.
+func (m *ManualClock) Advance(d time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.now = m.now.Add(d)
+	sort.SliceStable(m.timers, func(i, j int) bool { return m.timers[i].at.Before(m.timers[j].at) })
+	var pending []*manualTimer
+	for _, t := range m.timers {
+		if t.at.After(m.now) {
+			pending = append(pending, t)
+			continue
+		}
+		t.c <- m.now
+	}
+	m.timers = pending
+}
+
+// BlockUntil blocks until at least n timers are pending, so that a test can advance the clock
+// once the goroutine under test is waiting on it.
+func (m *ManualClock) BlockUntil(n int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for len(m.timers) < n {
+		m.changed.Wait()
+	}
+}
@@ -0,0 +1,83 @@
+package config
+
+import (
+	"context"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestManualClock(t *testing.T) {
+	t.Parallel()
+	start := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := NewManualClock(start)
+
+	late, _ := clock.NewTimer(2 * time.Minute)
+	early, _ := clock.NewTimer(time.Minute)
+	stopped, stop := clock.NewTimer(time.Minute)
+	assert.True(t, stop())
+	assert.False(t, stop(), "a stopped timer cannot be stopped again")
+	now, _ := clock.NewTimer(0)
+	assert.Equal(t, start, <-now, "timers of zero fire immediately")
+
+	clock.Advance(90 * time.Second)
+	assert.Equal(t, start.Add(90*time.Second), clock.Now())
+	assert.Equal(t, start.Add(90*time.Second), <-early)
+	select {
+	case <-late:
+		t.Fatal("the timer fired early")
+	case <-stopped:
+		t.Fatal("a stopped timer fired")
+	default:
+	}
+	clock.Advance(time.Minute)
+	assert.Equal(t, start.Add(150*time.Second), <-late)
+}
+
+func TestBuilder_WithClock(t *testing.T) {
+	os.Clearenv()
+	defer os.Clearenv()
+	require.NoError(t, os.Setenv("FLAGS", "remote://flags"))
+	require.NoError(t, os.Setenv("PASSWORD", "remote://password"))
+
+	var mu sync.Mutex
+	upstream := map[string]string{"remote://flags": "a", "remote://password": "p"}
+	p := ValuePreProcessorFunc(func(key, value string) string {
+		mu.Lock()
+		defer mu.Unlock()
+		if v, ok := upstream[value]; ok {
+			return v
+		}
+		return value
+	})
+	start := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := NewManualClock(start)
+	b := WithClock(clock).WithValuePreProcessor(p).FromEnv()
+
+	var c struct {
+		Flags Meta[string]
+	}
+	b.To(&c)
+	assert.Equal(t, start, c.Flags.Resolved)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	reloaded := make(chan []string, 1)
+	require.NoError(t, b.WatchRefresh(ctx, &refreshTestConfig{}, time.Hour, func(changed []string) {
+		reloaded <- changed
+	}))
+	mu.Lock()
+	upstream["remote://flags"], upstream["remote://password"] = "b", "q"
+	mu.Unlock()
+
+	clock.BlockUntil(1)
+	clock.Advance(20 * time.Millisecond)
+	assert.Equal(t, []string{"flags"}, <-reloaded)
+	clock.BlockUntil(1)
+	clock.Advance(time.Hour)
+	assert.Equal(t, []string{"password"}, <-reloaded, "flags is unchanged since the last reload")
+}
@@ -0,0 +1,73 @@
+// Package cobra registers config struct fields as github.com/spf13/pflag flags, as used by cobra commands,
+// so that command line flags and environment configuration share one struct definition.
+//
+// It lives in its own module, so that applications which do not use cobra do not depend on it.
+package cobra
+
+import (
+	"github.com/imduffy15/config"
+	"github.com/spf13/pflag"
+)
+
+// RegisterFlags defines a flag in fs, e.g. a cobra command's Flags(), for each field target is bound from.
+// See config.Flags for how flags are named. The flags only record their values; merge them into a Builder
+// with Bind or FlagSource once fs is parsed, typically last, so that flags override the environment and files:
+//
+//	var c Config
+//	cmd := &cobra.Command{
+//		Use: "server",
+//		RunE: func(cmd *cobra.Command, args []string) error {
+//			if err := configcobra.Bind(config.FromEnv(), cmd.Flags(), &c); err != nil {
+//				return err
+//			}
+//			return run(c)
+//		},
+//	}
+//	configcobra.RegisterFlags(cmd.Flags(), &c)
+func RegisterFlags(fs *pflag.FlagSet, target interface{}) {
+	for _, f := range config.Flags(target) {
+		pf := fs.VarPF(&flagValue{key: f.Key, typ: f.Type}, f.Name, "", f.Usage)
+		pf.DefValue = f.Default
+		if f.Bool {
+			pf.NoOptDefVal = "true"
+		}
+	}
+}
+
+// FlagSource returns a config.Source of the flags registered with RegisterFlags which were set when fs was parsed.
+// Flags left unset are omitted, so they do not override values from other sources.
+func FlagSource(fs *pflag.FlagSet) config.Source {
+	return config.SourceFunc(func() (map[string]string, error) {
+		values := make(map[string]string)
+		fs.Visit(func(f *pflag.Flag) {
+			if v, ok := f.Value.(*flagValue); ok {
+				values[v.key] = v.value
+			}
+		})
+		return values, nil
+	})
+}
+
+// Bind merges the flags registered with RegisterFlags which were set when fs was parsed into b,
+// over the values from its other sources, and binds target. See config.Builder.Bind for the errors returned.
+func Bind(b *config.Builder, fs *pflag.FlagSet, target interface{}) error {
+	return b.FromSource(FlagSource(fs)).Bind(target)
+}
+
+// flagValue is a pflag.Value recording the value of a flag registered by RegisterFlags.
+type flagValue struct {
+	key, typ, value string
+}
+
+func (v *flagValue) String() string {
+	return v.value
+}
+
+func (v *flagValue) Set(s string) error {
+	v.value = s
+	return nil
+}
+
+func (v *flagValue) Type() string {
+	return v.typ
+}
@@ -0,0 +1,56 @@
+package cobra
+
+import (
+	"bytes"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/imduffy15/config"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegisterFlags(t *testing.T) {
+	type testConfig struct {
+		Port     int `help:"port to listen on"`
+		Timeout  time.Duration
+		Debug    bool
+		Database struct {
+			MaxConns int `config:"max_conns"`
+		}
+	}
+	os.Clearenv()
+	defer os.Clearenv()
+	require.NoError(t, os.Setenv("PORT", "80"))
+	require.NoError(t, os.Setenv("TIMEOUT", "5s"))
+
+	c := testConfig{Port: 8080}
+	cmd := &cobra.Command{
+		Use: "server",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return Bind(config.FromEnv(), cmd.Flags(), &c)
+		},
+	}
+	RegisterFlags(cmd.Flags(), &c)
+	var buf bytes.Buffer
+	cmd.SetOut(&buf)
+	cmd.SetArgs([]string{"--port=9090", "--debug", "--database.max-conns", "3"})
+	require.NoError(t, cmd.Execute())
+
+	assert.Equal(t, 9090, c.Port, "flags override the environment")
+	assert.Equal(t, 5*time.Second, c.Timeout, "unset flags do not override the environment")
+	assert.True(t, c.Debug)
+	assert.Equal(t, 3, c.Database.MaxConns)
+
+	usage := cmd.Flags().FlagUsages()
+	assert.Contains(t, usage, "--port int ")
+	assert.Contains(t, usage, "port to listen on (default 8080)")
+	assert.Contains(t, usage, "--database.max-conns int")
+	assert.Contains(t, usage, "--timeout duration")
+
+	c = testConfig{}
+	cmd.SetArgs([]string{"--port=eighty"})
+	assert.Equal(t, config.CodeBadType, config.CodeOf(cmd.Execute()))
+}
@@ -0,0 +1,116 @@
+package config
+
+import (
+	"io"
+	"strings"
+)
+
+// WriteBashCompletion writes a bash script completing the environment variables target, a struct or struct pointer,
+// is bound from, after prefix, as arguments of export, so operators can tab through MYAPP__DATABASE__... keys:
+//
+//	config.WriteBashCompletion(f, &c, "MYAPP__")
+//
+//	$ source myapp.bash
+//	$ export MYAPP__DATABASE__<TAB>
+//
+// Keys are completed with a trailing =, and other shell variables are still completed.
+// Keys accepting any key under a prefix, see Keys, complete to their prefix.
+// Completion of export is replaced, so only the last script sourced completes.
+func WriteBashCompletion(w io.Writer, target interface{}, prefix string) error {
+	keys, prefixes := completionKeys(target, prefix)
+	name := completionFunc(prefix)
+	ew := &errWriter{w: w}
+	ew.printf("# Bash completion of environment variables, generated by config.WriteBashCompletion.\n")
+	ew.printf("%s_keys=(", name)
+	for _, k := range keys {
+		ew.printf("\n\t%s", k.key)
+	}
+	ew.printf("\n)\n%s_prefixes=(", name)
+	for _, k := range prefixes {
+		ew.printf("\n\t%s", k.key)
+	}
+	ew.printf("\n)\n")
+	ew.printf(`%[1]s_export() {
+	local cur=${COMP_WORDS[COMP_CWORD]} prev=${COMP_WORDS[COMP_CWORD-1]}
+	COMPREPLY=()
+	# Values, after =, are not completed.
+	if [[ $cur == = || $prev == = ]]; then
+		return
+	fi
+	COMPREPLY=($(compgen -W "${%[1]s_keys[*]}" -S = -- "$cur") $(compgen -W "${%[1]s_prefixes[*]}" -- "$cur") $(compgen -v -- "$cur"))
+	compopt -o nospace
+}
+complete -F %[1]s_export export
+`, name)
+	return ew.err
+}
+
+// WriteZshCompletion writes a zsh script completing the environment variables target, a struct or struct pointer,
+// is bound from, after prefix, as arguments of export, with their descriptions. It is sourced after compinit.
+// See WriteBashCompletion.
+func WriteZshCompletion(w io.Writer, target interface{}, prefix string) error {
+	keys, prefixes := completionKeys(target, prefix)
+	name := completionFunc(prefix)
+	ew := &errWriter{w: w}
+	ew.printf("# Zsh completion of environment variables, generated by config.WriteZshCompletion.\n")
+	ew.printf("%s_keys=(", name)
+	for _, k := range keys {
+		ew.printf("\n\t%s", zshQuote(k.key+":"+k.description))
+	}
+	ew.printf("\n)\n%s_prefixes=(", name)
+	for _, k := range prefixes {
+		ew.printf("\n\t%s", zshQuote(k.key+":"+k.description))
+	}
+	ew.printf("\n)\n")
+	ew.printf(`%[1]s_export() {
+	_describe -t config-keys 'config key' %[1]s_keys -S = -- %[1]s_prefixes -S ''
+	_typeset "$@"
+}
+compdef %[1]s_export export
+`, name)
+	return ew.err
+}
+
+type completionKey struct {
+	key, description string
+}
+
+// completionKeys returns the keys of target's fields after prefix, and the prefixes of keys accepting any key
+// under them, with their descriptions on a single line.
+func completionKeys(target interface{}, prefix string) (keys, prefixes []completionKey) {
+	for _, f := range fields(structType(target)) {
+		key := exportKey(f, prefix)
+		if f.remain || f.rawMap {
+			// A remain field of the root struct accepts any key, which there is no use completing.
+			if key = strings.TrimSuffix(key, manifestWildcard); key != "" {
+				prefixes = append(prefixes, completionKey{key, "any key under this prefix"})
+			}
+			continue
+		}
+		description := helpTypeName(f.Type)
+		if help := exportDescription(f); help != "" {
+			description = strings.Join(strings.Fields(help), " ") + " (" + description + ")"
+		}
+		keys = append(keys, completionKey{key, description})
+	}
+	return keys, prefixes
+}
+
+// completionFunc returns the name of the completion function for keys after prefix, e.g. _myapp_config for MYAPP__.
+func completionFunc(prefix string) string {
+	name := strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= '0' && r <= '9' {
+			return r
+		}
+		return '_'
+	}, strings.ToLower(prefix))
+	if name = strings.Trim(name, "_"); name == "" {
+		return "_config"
+	}
+	return "_" + name + "_config"
+}
+
+// zshQuote quotes s in single quotes.
+func zshQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
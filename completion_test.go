@@ -0,0 +1,84 @@
+package config
+
+import (
+	"bytes"
+	"os/exec"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteBashCompletion(t *testing.T) {
+	t.Parallel()
+	var buf bytes.Buffer
+	require.NoError(t, WriteBashCompletion(&buf, &exportTestConfig{}, "APP_"))
+	assert.Equal(t, `# Bash completion of environment variables, generated by config.WriteBashCompletion.
+_app_config_keys=(
+	APP_PORT
+	APP_TIMEOUT
+	APP_WEIGHTS
+	APP_UMASK
+	APP_DEBUG
+	APP_DATABASE__HOST
+	APP_DATABASE__PASSWORD
+)
+_app_config_prefixes=(
+	APP_
+)
+_app_config_export() {
+	local cur=${COMP_WORDS[COMP_CWORD]} prev=${COMP_WORDS[COMP_CWORD-1]}
+	COMPREPLY=()
+	# Values, after =, are not completed.
+	if [[ $cur == = || $prev == = ]]; then
+		return
+	fi
+	COMPREPLY=($(compgen -W "${_app_config_keys[*]}" -S = -- "$cur") $(compgen -W "${_app_config_prefixes[*]}" -- "$cur") $(compgen -v -- "$cur"))
+	compopt -o nospace
+}
+complete -F _app_config_export export
+`, buf.String())
+
+	bash, err := exec.LookPath("bash")
+	if err != nil {
+		t.Skip("bash is not installed")
+	}
+	script := buf.String() + `
+COMP_WORDS=(export APP_DATABASE__); COMP_CWORD=1; _app_config_export 2>/dev/null; echo "${COMPREPLY[@]}"
+COMP_WORDS=(export APP_PORT = 80); COMP_CWORD=3; _app_config_export 2>/dev/null; echo "${#COMPREPLY[@]}"
+`
+	out, err := exec.Command(bash, "--norc", "-c", script).CombinedOutput()
+	require.NoError(t, err, string(out))
+	assert.Equal(t, "APP_DATABASE__HOST= APP_DATABASE__PASSWORD=\n0\n", string(out))
+}
+
+func TestWriteZshCompletion(t *testing.T) {
+	t.Parallel()
+	var buf bytes.Buffer
+	require.NoError(t, WriteZshCompletion(&buf, &exportTestConfig{}, ""))
+	assert.Equal(t, `# Zsh completion of environment variables, generated by config.WriteZshCompletion.
+_config_keys=(
+	'PORT:port to listen on (int)'
+	'TIMEOUT:request timeout (duration)'
+	'WEIGHTS:[]float64'
+	'UMASK:int'
+	'DEBUG:bool'
+	'DATABASE__HOST:database host, or a unix socket (string)'
+	'DATABASE__PASSWORD:string'
+)
+_config_prefixes=(
+)
+_config_export() {
+	_describe -t config-keys 'config key' _config_keys -S = -- _config_prefixes -S ''
+	_typeset "$@"
+}
+compdef _config_export export
+`, buf.String())
+}
+
+func Test_completionFunc(t *testing.T) {
+	t.Parallel()
+	assert.Equal(t, "_config", completionFunc(""))
+	assert.Equal(t, "_myapp_config", completionFunc("MYAPP__"))
+	assert.Equal(t, "_my_app_v2_config", completionFunc("my-app.v2_"))
+}
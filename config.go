@@ -23,10 +23,12 @@
 package config
 
 import (
-	"bufio"
+	"context"
+	"encoding"
 	"fmt"
 	"os"
 	"reflect"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -37,6 +39,37 @@ const (
 	structDelim          = "__"
 	sliceDelim           = " "
 	structTagIgnoreField = "-"
+	structTagOptionDelim = ","
+
+	// tagOptionRemain marks a map[string]string field which receives every key under its struct's prefix
+	// that is not bound to another field.
+	tagOptionRemain = "remain"
+	// tagOptionOctal marks an integer field whose value is written in octal, such as a umask of 0027.
+	tagOptionOctal = "octal"
+	// tagOptionRaw marks a string field which receives its value without conversion, or a map[string]string field
+	// which receives every key under its own key, for passing through to systems which do their own parsing.
+	tagOptionRaw = "raw"
+	// tagOptionLiteral marks a field which receives its value as it was before pre-processing,
+	// e.g. a URL which legitimately starts with sm://. See Declare.
+	tagOptionLiteral = "literal"
+	// tagOptionQuantity marks a numeric field whose value is a Kubernetes style quantity, such as 500m or 1Gi.
+	tagOptionQuantity = "quantity"
+	// tagOptionPath marks a string field whose value is a file path, cleaned and made absolute. See pathRewriter.
+	tagOptionPath = "path"
+	// tagOptionExists, tagOptionDir, tagOptionFile and tagOptionReadable mark path fields whose path must exist,
+	// and be a directory, a regular file, or readable. They imply tagOptionPath.
+	tagOptionExists   = "exists"
+	tagOptionDir      = "dir"
+	tagOptionFile     = "file"
+	tagOptionReadable = "readable"
+	// tagOptionQuoted marks a slice field whose value is split with shell like quoting. See SplitQuoted.
+	tagOptionQuoted = "quoted"
+	// tagOptionRange marks an integer slice field whose entries may be inclusive ranges, such as 8000-8005.
+	tagOptionRange = "range"
+	// tagOptionRequired marks a field whose key must be set. See requiredError.
+	tagOptionRequired = "required"
+	// tagOptionSecret marks a field whose value is secret, so it is read without echo by PromptMissing.
+	tagOptionSecret = "secret"
 )
 
 // ValuePreProcessor is an interface for pre-processing values
@@ -45,13 +78,130 @@ type ValuePreProcessor interface {
 	PreProcessValue(key, value string) string
 }
 
+// ContextValuePreProcessor is a ValuePreProcessor which can also pre-process values with a caller supplied context,
+// e.g. to bound the time spent fetching a value remotely.
+type ContextValuePreProcessor interface {
+	ValuePreProcessor
+	// PreProcessValueContext is PreProcessValue, using ctx for any remote calls.
+	PreProcessValueContext(ctx context.Context, key, value string) string
+}
+
+// ValuePreProcessorFunc adapts a function to a ValuePreProcessor, for small inline pre-processors:
+//
+//	config.WithValuePreProcessor(config.ValuePreProcessorFunc(func(key, value string) string {
+//		return os.ExpandEnv(value)
+//	}))
+type ValuePreProcessorFunc func(key, value string) string
+
+// PreProcessValue calls f.
+func (f ValuePreProcessorFunc) PreProcessValue(key, value string) string {
+	return f(key, value)
+}
+
+// ValuePreProcessorErrorFunc adapts a function which can fail to a ValuePreProcessor.
+type ValuePreProcessorErrorFunc func(key, value string) (string, error)
+
+// PreProcessValue calls f. It panics with an *Error with CodeSecretFetch if f returns an error.
+func (f ValuePreProcessorErrorFunc) PreProcessValue(key, value string) string {
+	processed, err := f(key, value)
+	if err != nil {
+		panic(newError(CodeSecretFetch, key, err))
+	}
+	return processed
+}
+
 // Builder contains the current configuration state.
 type Builder struct {
 	structDelim, sliceDelim string
 	configMap               map[string]string
 	valuePreProcessor       ValuePreProcessor
+	middleware              []Middleware
+
+	// lazyEnv layers the process environment between baseMap and configMap.
+	// baseMap holds values merged before the environment, configMap those merged after.
+	lazyEnv bool
+	baseMap map[string]string
+	// lazyCache holds values looked up lazily which were pre-processed, so they are only resolved once.
+	lazyCache map[string]lazyValue
+
+	// bound holds the keys bound to a field by To, across all targets.
+	bound map[string]bool
+	// literal holds the keys of fields tagged literal in targets passed to Declare, which are never pre-processed.
+	literal map[string]bool
+
+	// resolved holds the keys whose values were changed by the ValuePreProcessor, e.g. secrets fetched from AWS.
+	// references holds their values as they were before pre-processing.
+	resolved   map[string]bool
+	references map[string]string
+	// resolveTimes holds how long resolving each resolved value took.
+	resolveTimes map[string]time.Duration
+	// leases holds the expiry of values returned by a LeasedValuePreProcessor.
+	leases map[string]time.Time
+	// origins holds where each key's value was merged from, for Meta fields.
+	origins map[string]origin
+
+	// sealer, when set, encrypts resolved values at rest. sealed holds the keys whose values are encrypted.
+	sealer *sealer
+	sealed map[string]bool
+
+	duplicatePolicy DuplicatePolicy
+	onDuplicate     func(key, kept, discarded string)
+
+	onConversionError func(key, value string, err error)
+
+	// warnings holds the warnings raised by To, with warned holding them as a set.
+	// found holds the warnings found by the latest bind, including those already raised.
+	warnings []Warning
+	warned   map[Warning]bool
+	found    []Warning
+	// deprecated maps deprecated keys to their replacements. See WithDeprecatedKey.
+	deprecated map[string]string
+	// empty holds the keys whose latest value was empty, and so was dropped.
+	empty map[string]bool
+
+	// strict, when set, holds the prefixes of keys which must be bound to a field. See WithStrict.
+	strict []string
+	// ignored holds the patterns of keys excluded from the unbound keys. See Ignore.
+	ignored []string
+
+	// budget, when set, bounds the time spent loading sources and resolving values.
+	budget *startupBudget
+
+	// stats counts the work done by the Builder. onStats, when set, is called with it after each bind.
+	stats   Stats
+	onStats func(Stats)
+
+	// clock, when set, tells the time in place of SystemClock. See WithClock.
+	clock Clock
+
+	// setters, when set, binds unexported fields through their struct's setters. See WithSetters.
+	setters bool
+
+	// sources holds the sources merged into the config state, in order, so Refresh can re-fetch them.
+	// lazyEnvAt holds how many of them were merged before FromEnvLazy.
+	sources   []Source
+	lazyEnvAt int
+	// refreshInterval, when set, is how often Refresh re-fetches the sources. onChange holds the listeners it calls.
+	refreshInterval time.Duration
+	onChange        []func(old, new interface{})
+
+	// failureReport, when set, is the path a FailureReport is written to. See WithFailureReport.
+	// sourceStatuses holds the outcome of loading each source, for it.
+	failureReport  string
+	sourceStatuses []SourceStatus
 }
 
+// DuplicatePolicy decides which value is kept when a single source contains the same key more than once.
+// Keys are compared case insensitively, so FOO and foo are duplicates.
+type DuplicatePolicy int
+
+const (
+	// LastWins keeps the last value seen for a key. This is the default.
+	LastWins DuplicatePolicy = iota
+	// FirstWins keeps the first value seen for a key.
+	FirstWins
+)
+
 // WithValuePreProcessor creates  a new builder with a ValuePreProcessor.
 // This will be called when adding values to the builder's configMap.
 // An example use would be retrieving values from AWS secret manager.
@@ -67,46 +217,145 @@ func (c *Builder) WithValuePreProcessor(p ValuePreProcessor) *Builder {
 	return c
 }
 
+// WithDuplicatePolicy creates a new builder with a DuplicatePolicy.
+// onDuplicate is optional, and is called with the kept and discarded values whenever a duplicate is found.
+func WithDuplicatePolicy(p DuplicatePolicy, onDuplicate func(key, kept, discarded string)) *Builder {
+	return newBuilder().WithDuplicatePolicy(p, onDuplicate)
+}
+
+// WithDuplicatePolicy sets the DuplicatePolicy applied to keys repeated within a single file or environment.
+// onDuplicate is optional, and is called with the kept and discarded values whenever a duplicate is found.
+// Keys repeated across sources are not duplicates: later sources override earlier ones as usual.
+func (c *Builder) WithDuplicatePolicy(p DuplicatePolicy, onDuplicate func(key, kept, discarded string)) *Builder {
+	c.duplicatePolicy = p
+	c.onDuplicate = onDuplicate
+	return c
+}
+
+// WithConversionErrorHandler creates a new builder with a conversion error handler.
+func WithConversionErrorHandler(h func(key, value string, err error)) *Builder {
+	return newBuilder().WithConversionErrorHandler(h)
+}
+
+// WithConversionErrorHandler sets a handler called by To for each set value which cannot be converted to its field's type.
+// Such fields are still left as their zero value; the handler allows the error to be logged or reported.
+// err is an *Error with CodeBadType.
+func (c *Builder) WithConversionErrorHandler(h func(key, value string, err error)) *Builder {
+	c.onConversionError = h
+	return c
+}
+
+// WithEncryptedSecrets creates a new builder which keeps resolved values encrypted in memory.
+func WithEncryptedSecrets() *Builder {
+	return newBuilder().WithEncryptedSecrets()
+}
+
+// WithEncryptedSecrets keeps values resolved by the ValuePreProcessor, such as secrets fetched from AWS,
+// encrypted in memory with a random per Builder key. They are only decrypted when read, e.g. by To.
+// This keeps plaintext secrets out of heap dumps, but is no defence against an attacker able to read process memory.
+// It applies to values merged after it is called.
+func (c *Builder) WithEncryptedSecrets() *Builder {
+	c.sealer = newSealer()
+	return c
+}
+
 func newBuilder() *Builder {
 	return &Builder{
-		configMap:   make(map[string]string),
-		structDelim: structDelim,
-		sliceDelim:  sliceDelim,
+		configMap:    make(map[string]string),
+		baseMap:      make(map[string]string),
+		resolved:     make(map[string]bool),
+		references:   make(map[string]string),
+		resolveTimes: make(map[string]time.Duration),
+		leases:       make(map[string]time.Time),
+		origins:      make(map[string]origin),
+		sealed:       make(map[string]bool),
+		lazyCache:    make(map[string]lazyValue),
+		bound:        make(map[string]bool),
+		literal:      make(map[string]bool),
+		structDelim:  structDelim,
+		sliceDelim:   sliceDelim,
 	}
 }
 
 // To accepts a struct pointer, and populates it with the current config state.
+// To does not change the config state, so it can be called repeatedly, e.g. once per subsystem of
+// a modular application, each with its own config struct. Values are resolved by the ValuePreProcessor once,
+// however many targets they are bound to. See ToWithReport, Coverage and UnboundKeys for reporting on what each target used.
 // Supported fields:
 //     * all int, uint, float variants
-//     * bool, struct, string
+//     * bool, struct, string, time.Duration
+//     * os.FileMode, written in octal e.g. 0640
+//     * types implementing encoding.TextUnmarshaler, such as TimeOfDay, Date, Deadline, Quantity and RateLimit
 //     * slice of any of the above, except for []struct{}
+//     * map[string]string tagged `config:",remain"`, which receives all unbound keys under its struct's prefix
+//     * string tagged `config:",raw"`, which receives its value without conversion
+//     * map[string]string tagged `config:",raw"`, which receives all keys under its own key, e.g. KAFKA__ for Kafka
+//     * Meta or Optional of any of the above, which also record where the value came from or whether it was set
+// Fields tagged `config:",literal"` receive their value as it was before pre-processing. See Declare.
+// Integer fields, and slices of them, tagged `config:",octal"` are parsed in octal rather than decimal.
+// Numeric fields, and slices of them, tagged `config:",quantity"` accept Kubernetes style quantities such as 500m or 1Gi.
+// String fields, and slices of them, tagged `config:",path"` receive file paths cleaned and made absolute,
+// optionally checked to exist with `config:",path,exists"`, dir, file or readable. See pathRewriter.
 // It panics under the following circumstances:
 //     * target is not a struct pointer
 //     * struct contains unsupported fields (pointers, maps, slice of structs, channels, arrays, funcs, interfaces, complex)
+//     * struct contains unexported fields not tagged `config:"-"`, unless they are bound with setters. See WithSetters
+//     * in strict mode, keys are left unbound. See WithStrict
+//     * fields tagged `config:",required"` are unset. See PromptMissing
+//     * fields tagged with the same group, e.g. `group:"oauth"`, are not all or none set,
+//       or with `group:"auth,at-least-one"`, none are set. See groupError
+// A FailureReport is written before panicking if one is configured. See WithFailureReport.
 func (c *Builder) To(target interface{}) {
+	defer c.reportPanic(target)
+	c.bind(target)
+	if err := c.strictError(); err != nil {
+		panic(err)
+	}
+	if err := c.requiredError(target); err != nil {
+		panic(err)
+	}
+	if err := c.groupError(target); err != nil {
+		panic(err)
+	}
+}
+
+// bind populates target and the registered sections, then raises any warnings.
+func (c *Builder) bind(target interface{}) {
+	start, resolve := time.Now(), c.stats.Resolve
+	c.found = nil
 	c.populateStructRecursively(target, "")
+	c.bindSections()
+	// The startup budget covers loading and the first bind only, so keys looked up later, e.g. by a To
+	// for another subsystem with FromEnvLazy, are not failed once start up is long over.
+	c.budget = nil
+	c.checkWarnings(target)
+	c.stats.Bind += time.Since(start) - (c.stats.Resolve - resolve)
+	if c.onStats != nil {
+		c.onStats(c.stats)
+	}
 }
 
 // From returns a new Builder, populated with the values from file.
-// It panics if unable to open the file.
+// Locations of the form scheme://... are loaded from the source registered for scheme, see RegisterSourceScheme.
+// It panics with an *Error if unable to open the file, or it is malformed. See FileSource.
 func From(file string) *Builder {
 	return newBuilder().From(file)
 }
 
 // From merges new values from file into the current config state, returning the Builder.
-// It panics if unable to open the file.
+// Locations of the form scheme://... are loaded from the source registered for scheme, see RegisterSourceScheme.
+// It panics with an *Error if unable to open the file, or it is malformed. See FileSource.
 func (c *Builder) From(file string) *Builder {
-	f, err := os.Open(file)
+	s, err := SourceFor(file)
 	if err != nil {
-		panic(fmt.Sprintf("oops!: %v", err))
+		panic(newError(CodeSourceLoad, "", err))
 	}
-	defer f.Close()
-	scanner := bufio.NewScanner(f)
-	var ss []string
-	for scanner.Scan() {
-		ss = append(ss, scanner.Text())
+	values, err := c.load(s)
+	if err != nil {
+		panic(c.loadFailed(s, newError(CodeSourceLoad, "", err)))
 	}
-	c.mergeConfig(stringsToMap(ss))
+	c.mergeConfig(values, sourceName(s))
+	c.loaded(s, values)
 	return c
 }
 
@@ -117,34 +366,268 @@ func FromEnv() *Builder {
 
 // FromEnv merges new values from the environment into the current config state, returning the Builder.
 func (c *Builder) FromEnv() *Builder {
-	c.mergeConfig(stringsToMap(os.Environ()))
+	return c.FromSource(EnvSource())
+}
+
+// FromEnvLazy returns a new Builder, backed by the environment without copying it.
+func FromEnvLazy() *Builder {
+	return newBuilder().FromEnvLazy()
+}
+
+// FromEnvLazy layers the environment over the current config state, returning the Builder.
+// Unlike FromEnv, the environment is not copied: each key is looked up when the config is bound,
+// in any case, e.g. HTTP_PROXY or http_proxy, with the DuplicatePolicy deciding between variables matching it.
+// Looked up values are still passed through the ValuePreProcessor.
+// Values merged before this call are overridden by the environment, values merged after it override the environment.
+func (c *Builder) FromEnvLazy() *Builder {
+	for k, v := range c.configMap {
+		c.baseMap[k] = v
+	}
+	c.configMap = make(map[string]string)
+	c.lazyEnv, c.lazyEnvAt = true, len(c.sources)
 	return c
 }
 
-func (c *Builder) mergeConfig(in map[string]string) {
+// mergeConfig merges in, loaded from the source described by source, into the config state.
+func (c *Builder) mergeConfig(in map[string]string, source string) {
+	c.markPending(in)
 	for k, v := range in {
-		if c.valuePreProcessor != nil {
-			v = c.valuePreProcessor.PreProcessValue(k, v)
+		start := time.Now()
+		if expanded, ok := c.expandValue(k, v); ok {
+			c.stats.Resolve += time.Since(start)
+			now := c.now()
+			c.stats.KeysMerged += len(expanded)
+			c.stats.SecretsResolved += len(expanded)
+			for ek, ev := range expanded {
+				c.resolved[ek] = true
+				delete(c.references, ek)
+				delete(c.leases, ek)
+				delete(c.empty, ek)
+				c.store(ek, ev)
+				c.origins[ek] = origin{source: source, resolvedAt: now}
+			}
+			c.markDone(k)
+			continue
+		}
+		delete(c.empty, k)
+		c.stats.KeysMerged++
+		c.store(k, c.preProcessValue(k, v))
+		o := origin{source: source}
+		if c.resolved[k] {
+			o.resolvedAt = c.now()
+		}
+		c.origins[k] = o
+		c.markDone(k)
+	}
+}
+
+// store sets key to value in the config state, sealing it if it was resolved and secrets are encrypted.
+func (c *Builder) store(key, value string) {
+	if c.sealer != nil && c.resolved[key] {
+		value = c.sealer.seal(value)
+		c.sealed[key] = true
+	} else {
+		delete(c.sealed, key)
+	}
+	c.configMap[key] = value
+}
+
+// preProcessValue passes value through the middleware and ValuePreProcessor, if any,
+// recording whether it was changed.
+func (c *Builder) preProcessValue(key, value string) string {
+	if c.literal[key] {
+		delete(c.resolved, key)
+		delete(c.references, key)
+		delete(c.resolveTimes, key)
+		delete(c.leases, key)
+		return value
+	}
+	if c.valuePreProcessor == nil && len(c.middleware) == 0 {
+		return value
+	}
+	delete(c.leases, key)
+	start := time.Now()
+	var processed string
+	var ttl time.Duration
+	c.withinBudget(key, func() {
+		processed = c.runPipeline(key, value, func(value string) string {
+			if c.valuePreProcessor == nil {
+				return value
+			}
+			l, ok := c.valuePreProcessor.(LeasedValuePreProcessor)
+			if !ok {
+				return c.valuePreProcessor.PreProcessValue(key, value)
+			}
+			var processed string
+			processed, ttl = l.PreProcessLeasedValue(key, value)
+			return processed
+		})
+	})
+	if ttl > 0 {
+		c.leases[key] = c.now().Add(ttl)
+	}
+	c.stats.Resolve += time.Since(start)
+	c.resolved[key] = processed != value
+	if c.resolved[key] {
+		c.stats.SecretsResolved++
+		c.references[key] = value
+		c.resolveTimes[key] = time.Since(start)
+	} else {
+		delete(c.references, key)
+		delete(c.resolveTimes, key)
+	}
+	return processed
+}
+
+// lookup returns the value of key in the current config state.
+func (c *Builder) lookup(key string) (string, bool) {
+	if v, ok := c.configMap[key]; ok {
+		return c.unseal(key, v), true
+	}
+	if c.lazyEnv {
+		if v, ok := c.lookupEnv(key); ok {
+			return c.lazyResolve(key, v), true
+		}
+	}
+	v, ok := c.baseMap[key]
+	if ok {
+		v = c.unseal(key, v)
+	}
+	return v, ok
+}
+
+// lookupLiteral returns the value of key in the current config state, as it was before pre-processing.
+func (c *Builder) lookupLiteral(key string) (string, bool) {
+	if _, ok := c.configMap[key]; !ok && c.lazyEnv {
+		if v, ok := c.lookupEnv(key); ok {
+			return v, true
 		}
+	}
+	if reference, ok := c.references[key]; ok {
+		return reference, true
+	}
+	return c.lookup(key)
+}
+
+// lazyValue is a value looked up lazily from the environment, after pre-processing.
+type lazyValue struct {
+	raw, processed string
+	sealed         bool
+	resolvedAt     time.Time
+}
+
+// lazyResolve pre-processes a value looked up lazily from the environment.
+// Resolved values are cached until the environment changes, so that binding several targets,
+// or the same target several times, only fetches each secret once.
+func (c *Builder) lazyResolve(key, raw string) string {
+	if cached, ok := c.lazyCache[key]; ok && cached.raw == raw {
+		if !cached.sealed {
+			return cached.processed
+		}
+		v, err := c.sealer.open(cached.processed)
+		if err != nil {
+			panic(err.Error()) // impossible, the value was sealed by this Builder
+		}
+		return v
+	}
+
+	processed := c.preProcessValue(key, raw)
+	if !c.resolved[key] {
+		delete(c.lazyCache, key)
+		return processed
+	}
+	cached := lazyValue{raw: raw, processed: processed, resolvedAt: c.now()}
+	if c.sealer != nil {
+		cached.processed, cached.sealed = c.sealer.seal(processed), true
+	}
+	c.lazyCache[key] = cached
+	return processed
+}
+
+// unseal decrypts value if it was encrypted by WithEncryptedSecrets.
+func (c *Builder) unseal(key, value string) string {
+	if !c.sealed[key] {
+		return value
+	}
+	v, err := c.sealer.open(value)
+	if err != nil {
+		panic(err.Error()) // impossible, the value was sealed by this Builder
+	}
+	return v
+}
+
+// keys returns every key in the current config state, sorted.
+// When the environment is layered lazily, it is listed on demand.
+func (c *Builder) keys() []string {
+	seen := make(map[string]struct{})
+	for k := range c.baseMap {
+		seen[k] = struct{}{}
+	}
+	for k := range c.configMap {
+		seen[k] = struct{}{}
+	}
+	if c.lazyEnv {
+		for k := range stringsToMap(os.Environ()) {
+			seen[k] = struct{}{}
+		}
+	}
+
+	keys := make([]string, 0, len(seen))
+	for k := range seen {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
 
-		c.configMap[k] = v
+// lookupEnv looks key up in the environment, matching any variable whose normalized key is key, e.g. Http_Proxy
+// for http_proxy. Variables whose names only differ in case are duplicates, kept as the DuplicatePolicy decides,
+// in the order of os.Environ, as with FromEnv; onDuplicate is not called, as keys are looked up with every bind.
+// As with stringsToMap, empty values are treated as unset.
+func (c *Builder) lookupEnv(key string) (string, bool) {
+	value, found := "", false
+	for _, kv := range os.Environ() {
+		k, v, _ := strings.Cut(kv, "=")
+		if v == "" || NormalizeKey(k) != key || (found && c.duplicatePolicy == FirstWins) {
+			continue
+		}
+		value, found = v, true
 	}
+	return value, found
 }
 
 // stringsToMap builds a map from a string slice.
 // The input strings are assumed to be environment variable in style e.g. KEY=VALUE
 // Keys with no value are not added to the map.
+// Duplicate keys are resolved with LastWins.
 func stringsToMap(ss []string) map[string]string {
+	return stringsToMapWithPolicy(ss, LastWins, nil)
+}
+
+// stringsToMapWithPolicy is stringsToMap, resolving duplicate keys with policy.
+// onDuplicate, if not nil, is called for every duplicate found.
+func stringsToMapWithPolicy(ss []string, policy DuplicatePolicy, onDuplicate func(key, kept, discarded string)) map[string]string {
 	m := make(map[string]string)
 	for _, s := range ss {
 		if !strings.Contains(s, "=") {
 			continue // ensures return is always of length 2
 		}
 		split := strings.SplitN(s, "=", 2)
-		key, value := strings.ToLower(split[0]), split[1]
-		if key != "" && value != "" {
-			m[key] = value
+		key, value := NormalizeKey(split[0]), split[1]
+		if key == "" || value == "" {
+			continue
+		}
+		if previous, exists := m[key]; exists {
+			kept, discarded := value, previous
+			if policy == FirstWins {
+				kept, discarded = previous, value
+			}
+			if onDuplicate != nil {
+				onDuplicate(key, kept, discarded)
+			}
+			value = kept
 		}
+		m[key] = value
 	}
 	return m
 }
@@ -153,37 +636,202 @@ func stringsToMap(ss []string) map[string]string {
 // slices and values are set directly.
 // nested structs recurse through this function.
 // values are derived from the field name, prefixed with the field names of any parents.
+// remain fields are populated last, with the keys left unbound by their siblings.
 func (c *Builder) populateStructRecursively(structPtr interface{}, prefix string) {
 	structValue := reflect.ValueOf(structPtr).Elem()
+	var bound []string
+	var remainPtrs []interface{}
 	for i := 0; i < structValue.NumField(); i++ {
 		fieldType := structValue.Type().Field(i)
-		fieldPtr := structValue.Field(i).Addr().Interface()
-
 		possibleKey := getKey(fieldType, prefix)
 		if possibleKey == nil {
 			continue
 		}
+		if !fieldType.IsExported() {
+			bound = append(bound, *possibleKey)
+			c.bindSetter(structValue, fieldType, *possibleKey)
+			continue
+		}
+		fieldPtr := structValue.Field(i).Addr().Interface()
+		wrapper, isWrapper := fieldPtr.(wrapperField)
+		if isWrapper {
+			fieldPtr = wrapper.valuePtr()
+			fieldType.Type = reflect.TypeOf(fieldPtr).Elem()
+		}
+		if hasTagOption(fieldType, tagOptionRemain) {
+			remainPtrs = append(remainPtrs, fieldPtr)
+			continue
+		}
 
 		key := *possibleKey
-		value := c.configMap[key]
+		if lazy, ok := wrapper.(lazyField); ok {
+			bound = append(bound, key)
+			c.bindLazy(lazy, key, fieldType)
+			continue
+		}
+		literal := hasTagOption(fieldType, tagOptionLiteral)
+		if hasTagOption(fieldType, tagOptionRaw) {
+			bound = append(bound, c.setRaw(fieldPtr, key, literal))
+			if isWrapper {
+				wrapper.bound(c, key)
+			}
+			continue
+		}
+		lookup := c.lookup
+		if literal {
+			lookup = c.lookupLiteral
+		}
+		value, ok := lookup(key)
 
-		switch fieldType.Type.Kind() {
-		case reflect.Struct:
+		if isNestedStruct(fieldType.Type) {
+			bound = append(bound, key+c.structDelim)
 			c.populateStructRecursively(fieldPtr, key+c.structDelim)
-		case reflect.Slice:
-			convertAndSetSlice(fieldPtr, stringToSlice(value, c.sliceDelim))
-		default:
-			convertAndSetValue(fieldPtr, value)
+		} else {
+			bound = append(bound, key)
+			c.bound[key] = true
+			err := c.convertField(fieldPtr, fieldType, value)
+			if ok && err == nil {
+				c.stats.FieldsBound++
+			}
+			c.conversionError(key, value, err)
+		}
+		if isWrapper {
+			wrapper.bound(c, key)
+		}
+	}
+
+	for _, fieldPtr := range remainPtrs {
+		c.setRemain(fieldPtr, prefix, bound)
+	}
+}
+
+// convertField sets the value or slice pointed to by fieldPtr to value, rewritten as fieldType's tag options require.
+func (c *Builder) convertField(fieldPtr interface{}, fieldType reflect.StructField, value string) error {
+	rewrite := valueRewriter(fieldType)
+	if fieldType.Type.Kind() == reflect.Slice {
+		values := stringToSlice(value, c.sliceDelim)
+		var err error
+		if hasTagOption(fieldType, tagOptionQuoted) {
+			if values, err = SplitQuoted(value, c.sliceDelim); err != nil {
+				return err
+			}
+		}
+		if hasTagOption(fieldType, tagOptionRange) {
+			if values, err = expandRanges(values); err != nil {
+				return err
+			}
+		}
+		if rewrite != nil {
+			values, err = rewriteAll(values, rewrite)
+		}
+		if err != nil {
+			return err
+		}
+		return convertAndSetSlice(fieldPtr, values)
+	}
+	if rewrite != nil {
+		var err error
+		if value, err = rewrite(value); err != nil {
+			return err
+		}
+	}
+	if d, ok := fieldPtr.(*Deadline); ok {
+		// Relative deadlines count from now on the Builder's Clock, which UnmarshalText cannot otherwise see.
+		*d = Deadline{clock: c.clock}
+	}
+	return convertAndSetValue(fieldPtr, value)
+}
+
+func (c *Builder) conversionError(key, value string, err error) {
+	switch {
+	case err == nil:
+	case c.onConversionError != nil:
+		c.onConversionError(key, value, newError(CodeBadType, key, err))
+	default:
+		c.warn(WarningConversion, key, err.Error())
+	}
+}
+
+// setRemain populates a map[string]string with every key under prefix not matched by bound.
+// bound holds the keys of sibling fields. Entries ending in the struct delimiter are nested structs,
+// and match every key beneath them.
+// Keys are stored with prefix stripped. The map remains nil if there are no such keys.
+func (c *Builder) setRemain(mapPtr interface{}, prefix string, bound []string) {
+	mapValue := reflect.ValueOf(mapPtr).Elem()
+	if mapValue.Type() != reflect.TypeOf(map[string]string(nil)) {
+		panic(newError(CodeUnsupportedField, "", fmt.Errorf("cannot use %v as a %q field, it must be a map[string]string", mapValue.Type(), tagOptionRemain)))
+	}
+
+	for _, key := range c.keys() {
+		if !strings.HasPrefix(key, prefix) || isBound(key, bound) {
+			continue
+		}
+		value, ok := c.lookup(key)
+		if !ok {
+			continue
 		}
+		if mapValue.IsNil() {
+			mapValue.Set(reflect.MakeMap(mapValue.Type()))
+		}
+		c.bound[key] = true
+		mapValue.SetMapIndex(reflect.ValueOf(strings.TrimPrefix(key, prefix)), reflect.ValueOf(value))
 	}
 }
 
+// setRaw populates a field tagged `config:",raw"`, returning the key it is bound from in the form setRemain expects.
+// A string field is set to the value of key as is, bypassing conversion, including any encoding.TextUnmarshaler.
+// A map[string]string field is populated with every key under key, stripped of key and the struct delimiter,
+// and remains nil if there are no such keys.
+func (c *Builder) setRaw(fieldPtr interface{}, key string, literal bool) string {
+	fieldValue := reflect.ValueOf(fieldPtr).Elem()
+	switch {
+	case fieldValue.Kind() == reflect.String:
+		c.bound[key] = true
+		lookup := c.lookup
+		if literal {
+			lookup = c.lookupLiteral
+		}
+		if value, ok := lookup(key); ok {
+			fieldValue.SetString(value)
+		}
+		return key
+	case fieldValue.Type() == reflect.TypeOf(map[string]string(nil)):
+		prefix := key + c.structDelim
+		for _, k := range c.keys() {
+			if !strings.HasPrefix(k, prefix) {
+				continue
+			}
+			value, ok := c.lookup(k)
+			if !ok {
+				continue
+			}
+			if fieldValue.IsNil() {
+				fieldValue.Set(reflect.MakeMap(fieldValue.Type()))
+			}
+			c.bound[k] = true
+			fieldValue.SetMapIndex(reflect.ValueOf(strings.TrimPrefix(k, prefix)), reflect.ValueOf(value))
+		}
+		return prefix
+	}
+	panic(newError(CodeUnsupportedField, key, fmt.Errorf("cannot use %v as a %q field, it must be a string or map[string]string", fieldValue.Type(), tagOptionRaw)))
+}
+
+func isBound(key string, bound []string) bool {
+	for _, b := range bound {
+		if key == b || (strings.HasSuffix(b, structDelim) && strings.HasPrefix(key, b)) {
+			return true
+		}
+	}
+	return false
+}
+
 // getKey returns the string that represents this structField in the config map.
 // If the structField has the appropriate structTag set, it is used.
 // Otherwise, field's name is used.
 func getKey(t reflect.StructField, prefix string) *string {
 	name := t.Name
 	if tag, exists := t.Tag.Lookup(structTagKey); exists {
+		tag = strings.SplitN(tag, structTagOptionDelim, 2)[0]
 		if tag = strings.TrimSpace(tag); tag == structTagIgnoreField {
 			return nil
 		} else if tag != "" {
@@ -191,10 +839,82 @@ func getKey(t reflect.StructField, prefix string) *string {
 		}
 	}
 
-	key := strings.ToLower(prefix + name)
+	key := NormalizeKey(prefix + name)
 	return &key
 }
 
+// hasTagOption reports whether the struct tag of t lists option after its name.
+//
+//	Field map[string]string `config:",remain"`
+func hasTagOption(t reflect.StructField, option string) bool {
+	tag, exists := t.Tag.Lookup(structTagKey)
+	if !exists {
+		return false
+	}
+	options := strings.Split(tag, structTagOptionDelim)
+	for _, o := range options[1:] {
+		if strings.TrimSpace(o) == option {
+			return true
+		}
+	}
+	return false
+}
+
+// NormalizeKey returns the form key takes in the config map.
+// Keys are matched case insensitively, so all keys are normalized to lower case.
+func NormalizeKey(key string) string {
+	return strings.ToLower(key)
+}
+
+// KeyFor returns the normalized key that the field at path within target is bound from.
+// target is a struct or struct pointer, and path is a list of Go field names, outermost first.
+// Struct tags are taken into account exactly as they are by To.
+// The key can be upper cased to produce the matching environment variable name.
+// It panics if path is empty, does not name a field of target, or passes through an ignored field.
+//
+//	config.KeyFor(&c, "Database", "Host") // "database__host"
+func KeyFor(target interface{}, path ...string) string {
+	if len(path) == 0 {
+		panic("config: KeyFor called without a field path")
+	}
+	t := reflect.TypeOf(target)
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	var key string
+	prefix := ""
+	for _, name := range path {
+		if t.Kind() != reflect.Struct {
+			panic(fmt.Sprintf("config: cannot look up field %q in non struct type %v", name, t))
+		}
+		field, ok := fieldByName(t, name)
+		if !ok {
+			panic(fmt.Sprintf("config: %v has no field %q", t, name))
+		}
+		possibleKey := getKey(field, prefix)
+		if possibleKey == nil {
+			panic(fmt.Sprintf("config: field %q of %v is ignored", name, t))
+		}
+		key = *possibleKey
+		prefix = key + structDelim
+		t = wrappedType(field.Type)
+	}
+	return key
+}
+
+// fieldByName returns the direct field of structType called name.
+// Unlike reflect.Type.FieldByName, fields promoted from embedded structs are not returned,
+// as the binder treats embedded structs as nested configs.
+func fieldByName(structType reflect.Type, name string) (reflect.StructField, bool) {
+	for i := 0; i < structType.NumField(); i++ {
+		if f := structType.Field(i); f.Name == name {
+			return f, true
+		}
+	}
+	return reflect.StructField{}, false
+}
+
 // stringToSlice converts a string to a slice of string, using delim.
 // It strips surrounding whitespace of all entries.
 // If the input string is empty or all whitespace, nil is returned.
@@ -220,73 +940,159 @@ func stringToSlice(s, delim string) []string {
 // convertAndSetSlice builds a slice of a dynamic type.
 // It converts each entry in "values" to the elemType of the passed in slice.
 // The slice remains nil if "values" is empty.
-func convertAndSetSlice(slicePtr interface{}, values []string) {
+// Entries which fail to convert are appended as zero values, and the first such error is returned.
+func convertAndSetSlice(slicePtr interface{}, values []string) error {
 	sliceVal := reflect.ValueOf(slicePtr).Elem()
 	elemType := sliceVal.Type().Elem()
 
+	var firstErr error
 	for _, s := range values {
 		valuePtr := reflect.New(elemType)
-		convertAndSetValue(valuePtr.Interface(), s)
+		if err := convertAndSetValue(valuePtr.Interface(), s); err != nil && firstErr == nil {
+			firstErr = err
+		}
 		sliceVal.Set(reflect.Append(sliceVal, valuePtr.Elem()))
 	}
+	return firstErr
+}
+
+// octalToDecimal rewrites an octal integer, optionally prefixed with 0o, in decimal.
+// Empty strings are returned as is.
+func octalToDecimal(s string) (string, error) {
+	if s == "" {
+		return s, nil
+	}
+	trimmed := strings.TrimPrefix(strings.TrimPrefix(s, "0o"), "0O")
+	val, err := strconv.ParseInt(trimmed, 8, 64)
+	if err != nil {
+		return "", fmt.Errorf("cannot convert %q from octal: %w", s, err)
+	}
+	return strconv.FormatInt(val, 10), nil
+}
+
+// valueRewriter returns the function rewriting the values of a field tagged with a parsing option
+// into a form strconv accepts, or nil if the field has no such option.
+func valueRewriter(t reflect.StructField) func(string) (string, error) {
+	switch {
+	case hasTagOption(t, tagOptionOctal):
+		return octalToDecimal
+	case hasTagOption(t, tagOptionQuantity):
+		return quantityToDecimal
+	case hasTagOption(t, tagOptionPath), hasTagOption(t, tagOptionExists), hasTagOption(t, tagOptionDir),
+		hasTagOption(t, tagOptionFile), hasTagOption(t, tagOptionReadable):
+		return pathRewriter(t)
+	}
+	return nil
+}
+
+func rewriteAll(ss []string, rewrite func(string) (string, error)) ([]string, error) {
+	out := make([]string, len(ss))
+	for i, s := range ss {
+		d, err := rewrite(s)
+		if err != nil {
+			return nil, err
+		}
+		out[i] = d
+	}
+	return out, nil
 }
 
 // convertAndSetValue receives a settable of an arbitrary kind, and sets its value to s".
 // It calls the matching strconv function on s, based on the settable's kind.
-// All basic types (bool, int, float, string) are handled by this function.
+// All basic types (bool, int, float, string) are handled by this function,
+// as are types implementing encoding.TextUnmarshaler, which are left untouched when s is empty.
 // Slice and struct are handled elsewhere.
 // Unhandled kinds panic.
-// Errors in string conversion are ignored, and the settable remains a zero value.
-func convertAndSetValue(settable interface{}, s string) {
+// Errors in string conversion leave the settable a zero value, and are returned unless s is empty,
+// as an empty s means the value is unset rather than invalid.
+func convertAndSetValue(settable interface{}, s string) error {
 	settableValue := reflect.ValueOf(settable).Elem()
+	if u, ok := settable.(encoding.TextUnmarshaler); ok {
+		if s == "" {
+			return nil
+		}
+		if err := u.UnmarshalText([]byte(s)); err != nil {
+			settableValue.Set(reflect.Zero(settableValue.Type()))
+			return fmt.Errorf("cannot convert %q to %v: %w", s, settableValue.Type(), err)
+		}
+		return nil
+	}
+	if flags, ok := registeredBitFlags(settableValue.Type()); ok {
+		return setBitFlags(settableValue, flags, s)
+	}
 	i := settableValue.Interface()
 
+	var err error
 	switch i.(type) {
 	case string:
 		settableValue.SetString(s)
 	case time.Duration:
-		d, _ := time.ParseDuration(s)
+		var d time.Duration
+		d, err = time.ParseDuration(s)
 		settableValue.Set(reflect.ValueOf(d))
+	case os.FileMode:
+		// permissions are always written in octal, so 0640 means rw-r-----.
+		var val uint64
+		val, err = strconv.ParseUint(strings.TrimPrefix(strings.TrimPrefix(s, "0o"), "0O"), 8, 32)
+		settableValue.SetUint(val)
 	case int:
-		val, _ := strconv.ParseInt(s, 10, 0)
+		var val int64
+		val, err = strconv.ParseInt(s, 10, 0)
 		settableValue.SetInt(val)
 	case int8:
-		val, _ := strconv.ParseInt(s, 10, 8)
+		var val int64
+		val, err = strconv.ParseInt(s, 10, 8)
 		settableValue.SetInt(val)
 	case int16:
-		val, _ := strconv.ParseInt(s, 10, 16)
+		var val int64
+		val, err = strconv.ParseInt(s, 10, 16)
 		settableValue.SetInt(val)
 	case int32:
-		val, _ := strconv.ParseInt(s, 10, 32)
+		var val int64
+		val, err = strconv.ParseInt(s, 10, 32)
 		settableValue.SetInt(val)
 	case int64:
-		val, _ := strconv.ParseInt(s, 10, 64)
+		var val int64
+		val, err = strconv.ParseInt(s, 10, 64)
 		settableValue.SetInt(val)
 	case uint:
-		val, _ := strconv.ParseUint(s, 10, 0)
+		var val uint64
+		val, err = strconv.ParseUint(s, 10, 0)
 		settableValue.SetUint(val)
 	case uint8:
-		val, _ := strconv.ParseUint(s, 10, 8)
+		var val uint64
+		val, err = strconv.ParseUint(s, 10, 8)
 		settableValue.SetUint(val)
 	case uint16:
-		val, _ := strconv.ParseUint(s, 10, 16)
+		var val uint64
+		val, err = strconv.ParseUint(s, 10, 16)
 		settableValue.SetUint(val)
 	case uint32:
-		val, _ := strconv.ParseUint(s, 10, 32)
+		var val uint64
+		val, err = strconv.ParseUint(s, 10, 32)
 		settableValue.SetUint(val)
 	case uint64:
-		val, _ := strconv.ParseUint(s, 10, 64)
+		var val uint64
+		val, err = strconv.ParseUint(s, 10, 64)
 		settableValue.SetUint(val)
 	case bool:
-		val, _ := strconv.ParseBool(s)
+		var val bool
+		val, err = strconv.ParseBool(s)
 		settableValue.SetBool(val)
 	case float32:
-		val, _ := strconv.ParseFloat(s, 32)
+		var val float64
+		val, err = strconv.ParseFloat(s, 32)
 		settableValue.SetFloat(val)
 	case float64:
-		val, _ := strconv.ParseFloat(s, 64)
+		var val float64
+		val, err = strconv.ParseFloat(s, 64)
 		settableValue.SetFloat(val)
 	default:
-		panic(fmt.Sprintf("cannot handle kind %v\n", settableValue.Type().Kind()))
+		panic(newError(CodeUnsupportedField, "", fmt.Errorf("cannot handle kind %v", settableValue.Type().Kind())))
+	}
+
+	if err != nil && s != "" {
+		return fmt.Errorf("cannot convert %q to %v: %w", s, settableValue.Type(), err)
 	}
+	return nil
 }
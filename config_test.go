@@ -1,6 +1,7 @@
 package config
 
 import (
+	"errors"
 	"io/ioutil"
 	"os"
 	"reflect"
@@ -10,6 +11,7 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"golang.org/x/text/language"
 )
 
 func Test_Integration(t *testing.T) {
@@ -91,6 +93,11 @@ func Test_stringToSlice(t *testing.T) {
 			args: args{in: "  a b c def ghi     ", delim: " "},
 			want: []string{"a", "b", "c", "def", "ghi"},
 		},
+		{
+			name: "values - only the delimiter splits",
+			args: args{in: "a\tb\n c  d", delim: " "},
+			want: []string{"a\tb", "c", "d"},
+		},
 		{
 			name: "values - comma delim",
 			args: args{in: "  a, b, c ,def ,ghi,     ", delim: ","},
@@ -328,6 +335,56 @@ func Test_stringsToMap(t *testing.T) {
 	}
 }
 
+func Test_FileSource_longLine(t *testing.T) {
+	t.Parallel()
+	file, err := ioutil.TempFile("", "testenv")
+	require.NoError(t, err)
+	defer os.Remove(file.Name())
+	_, err = file.Write([]byte("A=1\nB=" + strings.Repeat("x", maxLineLength)))
+	require.NoError(t, err)
+
+	_, err = FileSource(file.Name()).Load()
+	assert.EqualError(t, err, file.Name()+": line 2: bufio.Scanner: token too long")
+}
+
+func Test_stringsToMapWithPolicy(t *testing.T) {
+	t.Parallel()
+	ss := []string{"A=1", "B=1", "a=2", "A=3"}
+
+	type duplicate struct{ key, kept, discarded string }
+	tests := []struct {
+		name           string
+		policy         DuplicatePolicy
+		want           map[string]string
+		wantDuplicates []duplicate
+	}{
+		{
+			name:           "last wins",
+			policy:         LastWins,
+			want:           map[string]string{"a": "3", "b": "1"},
+			wantDuplicates: []duplicate{{"a", "2", "1"}, {"a", "3", "2"}},
+		},
+		{
+			name:           "first wins",
+			policy:         FirstWins,
+			want:           map[string]string{"a": "1", "b": "1"},
+			wantDuplicates: []duplicate{{"a", "1", "2"}, {"a", "1", "3"}},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			var duplicates []duplicate
+			got := stringsToMapWithPolicy(ss, tt.policy, func(key, kept, discarded string) {
+				duplicates = append(duplicates, duplicate{key, kept, discarded})
+			})
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.wantDuplicates, duplicates)
+		})
+	}
+}
+
 func Test_getKey(t *testing.T) {
 	t.Parallel()
 	type args struct {
@@ -394,6 +451,28 @@ func Test_getKey(t *testing.T) {
 			},
 			want: "pre__tag",
 		},
+		{
+			name: "tag with options",
+			args: args{
+				t: reflect.StructField{
+					Name: "name",
+					Tag:  "config:\" tag ,remain\"",
+				},
+				prefix: "pre__",
+			},
+			want: "pre__tag",
+		},
+		{
+			name: "options only",
+			args: args{
+				t: reflect.StructField{
+					Name: "name",
+					Tag:  "config:\",remain\"",
+				},
+				prefix: "pre__",
+			},
+			want: "pre__name",
+		},
 	}
 	for _, tt := range tests {
 		tt := tt
@@ -432,3 +511,271 @@ func Test_structTagIgnore(t *testing.T) {
 
 	assert.Equal(t, want, got)
 }
+
+func Test_FromEnvLazy(t *testing.T) {
+	type testConfig struct {
+		A string
+		B string
+		C string
+	}
+
+	os.Clearenv()
+	defer os.Clearenv()
+	require.NoError(t, os.Setenv("A", "env"))
+	require.NoError(t, os.Setenv("B", "env"))
+
+	file, err := ioutil.TempFile("", "testenv")
+	require.NoError(t, err)
+	defer os.Remove(file.Name())
+	_, err = file.Write([]byte("A=file\nC=file"))
+	require.NoError(t, err)
+
+	var got testConfig
+	From(file.Name()).FromEnvLazy().To(&got)
+	assert.Equal(t, testConfig{A: "env", B: "env", C: "file"}, got)
+
+	// values merged after the environment take precedence
+	got = testConfig{}
+	FromEnvLazy().From(file.Name()).To(&got)
+	assert.Equal(t, testConfig{A: "file", B: "env", C: "file"}, got)
+
+	// the environment is read at bind time
+	b := FromEnvLazy()
+	require.NoError(t, os.Setenv("C", "later"))
+	got = testConfig{}
+	b.To(&got)
+	assert.Equal(t, testConfig{A: "env", B: "env", C: "later"}, got)
+
+	got = testConfig{}
+	WithValuePreProcessor(&upperPreProcessor{}).FromEnvLazy().To(&got)
+	assert.Equal(t, testConfig{A: "ENV", B: "ENV", C: "LATER"}, got)
+
+	// variables are matched in any case, with the DuplicatePolicy deciding between them
+	os.Clearenv()
+	require.NoError(t, os.Setenv("Http_Proxy", "mixed"))
+	require.NoError(t, os.Setenv("HTTP_PROXY", "upper"))
+	var proxy struct {
+		HTTPProxy string `config:"http_proxy"`
+	}
+	FromEnvLazy().To(&proxy)
+	assert.Equal(t, "upper", proxy.HTTPProxy)
+	WithDuplicatePolicy(FirstWins, nil).FromEnvLazy().To(&proxy)
+	assert.Equal(t, "mixed", proxy.HTTPProxy)
+}
+
+type upperPreProcessor struct{}
+
+func (p *upperPreProcessor) PreProcessValue(key, value string) string {
+	return strings.ToUpper(value)
+}
+
+func TestValuePreProcessorFunc(t *testing.T) {
+	t.Parallel()
+	var got struct{ A, B string }
+	WithValuePreProcessor(ValuePreProcessorFunc(func(key, value string) string {
+		return key + "=" + value
+	})).FromSource(MapSource(map[string]string{"A": "1"})).To(&got)
+	assert.Equal(t, "a=1", got.A)
+
+	p := ValuePreProcessorErrorFunc(func(key, value string) (string, error) {
+		if value == "bad" {
+			return "", errors.New("cannot resolve")
+		}
+		return strings.ToUpper(value), nil
+	})
+	WithValuePreProcessor(p).FromSource(MapSource(map[string]string{"A": "ok"})).To(&got)
+	assert.Equal(t, "OK", got.A)
+
+	err := recoverError(func() {
+		WithValuePreProcessor(p).FromSource(MapSource(map[string]string{"B": "bad"}))
+	})
+	assert.EqualError(t, err, "config: CFG003: b: cannot resolve")
+}
+
+func Test_KeyFor(t *testing.T) {
+	t.Parallel()
+	type D struct {
+		E string
+		F string `config:"feRdiNaND"`
+		G string `config:"-"`
+	}
+	type testConfig struct {
+		A int
+		D D `config:"dOg"`
+	}
+
+	assert.Equal(t, "a", KeyFor(testConfig{}, "A"))
+	assert.Equal(t, "dog", KeyFor(&testConfig{}, "D"))
+	assert.Equal(t, "dog__e", KeyFor(&testConfig{}, "D", "E"))
+	assert.Equal(t, "dog__ferdinand", KeyFor(&testConfig{}, "D", "F"))
+
+	assert.Panics(t, func() { KeyFor(&testConfig{}) })
+	assert.Panics(t, func() { KeyFor(&testConfig{}, "Missing") })
+	assert.Panics(t, func() { KeyFor(&testConfig{}, "A", "B") })
+	assert.Panics(t, func() { KeyFor(&testConfig{}, "D", "G") })
+}
+
+func Test_remain(t *testing.T) {
+	type Plugin struct {
+		Name   string
+		Limits struct {
+			CPU string
+		}
+		Ignored string            `config:"-"`
+		Rest    map[string]string `config:",remain"`
+	}
+	type testConfig struct {
+		Plugin Plugin
+		Other  string
+	}
+
+	os.Clearenv()
+	defer os.Clearenv()
+	require.NoError(t, os.Setenv("OTHER", "x"))
+	require.NoError(t, os.Setenv("PLUGIN__NAME", "p"))
+	require.NoError(t, os.Setenv("PLUGIN__LIMITS__CPU", "1"))
+	require.NoError(t, os.Setenv("PLUGIN__IGNORED", "i"))
+	require.NoError(t, os.Setenv("PLUGIN__TIMEOUT", "5s"))
+	require.NoError(t, os.Setenv("PLUGIN__UPSTREAM__HOST", "h"))
+
+	for name, b := range map[string]func() *Builder{"FromEnv": FromEnv, "FromEnvLazy": FromEnvLazy} {
+		var got testConfig
+		b().To(&got)
+		assert.Equal(t, "p", got.Plugin.Name, name)
+		assert.Equal(t, map[string]string{
+			"ignored":        "i",
+			"timeout":        "5s",
+			"upstream__host": "h",
+		}, got.Plugin.Rest, name)
+	}
+
+	os.Clearenv()
+	var got testConfig
+	FromEnv().To(&got)
+	assert.Nil(t, got.Plugin.Rest)
+
+	assert.Panics(t, func() {
+		var bad struct {
+			Rest map[string]int `config:",remain"`
+		}
+		FromEnv().To(&bad)
+	})
+}
+
+type shoutingString string
+
+func (s *shoutingString) UnmarshalText(text []byte) error {
+	*s = shoutingString(strings.ToUpper(string(text)))
+	return nil
+}
+
+func Test_raw(t *testing.T) {
+	type testConfig struct {
+		Kafka   map[string]string `config:",raw"`
+		Filter  shoutingString    `config:",raw"`
+		Parsed  shoutingString
+		Missing map[string]string `config:",raw"`
+		Rest    map[string]string `config:",remain"`
+	}
+
+	os.Clearenv()
+	defer os.Clearenv()
+	require.NoError(t, os.Setenv("KAFKA__BOOTSTRAP.SERVERS", "a:9092,b:9092"))
+	require.NoError(t, os.Setenv("KAFKA__SASL__MECHANISM", "PLAIN"))
+	require.NoError(t, os.Setenv("FILTER", "level >= warn"))
+	require.NoError(t, os.Setenv("PARSED", "level >= warn"))
+	require.NoError(t, os.Setenv("OTHER", "x"))
+
+	b := FromEnv()
+	var got testConfig
+	b.To(&got)
+	assert.Equal(t, testConfig{
+		Kafka:  map[string]string{"bootstrap.servers": "a:9092,b:9092", "sasl__mechanism": "PLAIN"},
+		Filter: "level >= warn",
+		Parsed: "LEVEL >= WARN",
+		Rest:   map[string]string{"other": "x"},
+	}, got)
+	assert.Empty(t, b.UnboundKeys(""))
+	assert.Equal(t, Coverage{
+		Set:   []string{"", "filter", "kafka", "parsed"},
+		Unset: []string{"missing"},
+	}, b.Coverage(&got))
+	assert.Equal(t, []string{"*", "filter", "kafka__*", "missing__*", "parsed"}, Keys(&got))
+
+	err := recoverError(func() {
+		var bad struct {
+			Port int `config:",raw"`
+		}
+		FromEnv().To(&bad)
+	})
+	assert.Equal(t, CodeUnsupportedField, CodeOf(err))
+}
+
+func Test_conversionErrors(t *testing.T) {
+	type testConfig struct {
+		Port     int
+		Ratio    float64
+		Ports    []int
+		Locale   language.Tag
+		Fallback language.Tag
+		Unset    int
+	}
+
+	os.Clearenv()
+	defer os.Clearenv()
+	require.NoError(t, os.Setenv("PORT", "eighty"))
+	require.NoError(t, os.Setenv("RATIO", "0.5"))
+	require.NoError(t, os.Setenv("PORTS", "1 two 3"))
+	require.NoError(t, os.Setenv("LOCALE", "en-GB"))
+	require.NoError(t, os.Setenv("FALLBACK", "not_a-locale!"))
+
+	errs := make(map[string]error)
+	var got testConfig
+	WithConversionErrorHandler(func(key, value string, err error) {
+		errs[key] = err
+	}).FromEnv().To(&got)
+
+	assert.Equal(t, testConfig{
+		Ratio:  0.5,
+		Ports:  []int{1, 0, 3},
+		Locale: language.BritishEnglish,
+	}, got)
+	require.Len(t, errs, 3)
+	assert.EqualError(t, errs["port"], `config: CFG002: port: cannot convert "eighty" to int: strconv.ParseInt: parsing "eighty": invalid syntax`)
+	assert.Equal(t, CodeBadType, CodeOf(errs["port"]))
+	assert.Contains(t, errs["ports"].Error(), `"two"`)
+	assert.Contains(t, errs["fallback"].Error(), `cannot convert "not_a-locale!" to language.Tag`)
+}
+
+func Test_octal(t *testing.T) {
+	type testConfig struct {
+		Mode    os.FileMode
+		Umask   int      `config:",octal"`
+		Modes   []uint32 `config:",octal"`
+		Decimal int
+		Invalid int `config:",octal"`
+	}
+
+	os.Clearenv()
+	defer os.Clearenv()
+	require.NoError(t, os.Setenv("MODE", "0640"))
+	require.NoError(t, os.Setenv("UMASK", "0o027"))
+	require.NoError(t, os.Setenv("MODES", "0755 644"))
+	require.NoError(t, os.Setenv("DECIMAL", "0640"))
+	require.NoError(t, os.Setenv("INVALID", "0689"))
+
+	var errKeys []string
+	var got testConfig
+	WithConversionErrorHandler(func(key, value string, err error) {
+		errKeys = append(errKeys, key)
+	}).FromEnv().To(&got)
+
+	assert.Equal(t, testConfig{
+		Mode:    0640,
+		Umask:   027,
+		Modes:   []uint32{0755, 0644},
+		Decimal: 640,
+	}, got)
+	assert.Equal(t, "-rw-r-----", got.Mode.String())
+	assert.Equal(t, []string{"invalid"}, errKeys)
+}
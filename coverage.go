@@ -0,0 +1,72 @@
+package config
+
+import (
+	"sort"
+	"strings"
+)
+
+// Coverage describes how the current config state covers a target struct's fields.
+type Coverage struct {
+	// Set holds the keys of fields with a value.
+	Set []string
+	// Unset holds the keys of fields without a value, which To leaves as their zero value.
+	Unset []string
+	// Similar maps the keys of unset fields to a similar key in the config state which no field is bound to,
+	// and which is probably a typo, e.g. databse__host for database__host.
+	Similar map[string]string
+}
+
+// Coverage reports which of target's fields have a value in the current config state.
+// target is a struct or struct pointer; it is not modified.
+// Fields tagged `config:",remain"` are set if any key is left for them,
+// and map fields tagged `config:",raw"` if any key is under their key.
+func (c *Builder) Coverage(target interface{}) Coverage {
+	fs := fields(structType(target))
+
+	var cov Coverage
+	for _, f := range fs {
+		set := false
+		switch {
+		case f.remain:
+			set = c.hasUnbound(f.key, siblingKeys(fs, f))
+		case f.rawMap:
+			set = c.hasUnbound(f.key+structDelim, nil)
+		default:
+			_, set = c.lookup(f.key)
+		}
+		if set {
+			cov.Set = append(cov.Set, f.key)
+		} else {
+			cov.Unset = append(cov.Unset, f.key)
+		}
+	}
+	sort.Strings(cov.Set)
+	sort.Strings(cov.Unset)
+	cov.Similar = c.similarKeys(fs)
+	return cov
+}
+
+// hasUnbound reports whether any key under prefix is left for a remain field, as setRemain would.
+func (c *Builder) hasUnbound(prefix string, bound []string) bool {
+	for _, key := range c.keys() {
+		if strings.HasPrefix(key, prefix) && !isBound(key, bound) {
+			return true
+		}
+	}
+	return false
+}
+
+// UnboundKeys returns the keys in the current config state starting with prefix
+// which have not been bound to a field by any call to To, and are not ignored, see Ignore, sorted.
+// After each subsystem of an application has bound its config, these are the keys nothing used.
+// prefix is matched case insensitively; an empty prefix matches every key.
+func (c *Builder) UnboundKeys(prefix string) []string {
+	prefix = NormalizeKey(prefix)
+	var unbound []string
+	for _, key := range c.keys() {
+		if strings.HasPrefix(key, prefix) && !c.bound[key] && !c.isIgnored(key) {
+			unbound = append(unbound, key)
+		}
+	}
+	return unbound
+}
@@ -0,0 +1,95 @@
+package config
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type countingPreProcessor struct {
+	calls int
+}
+
+func (p *countingPreProcessor) PreProcessValue(key, value string) string {
+	if value == "secret://x" {
+		p.calls++
+		return "resolved"
+	}
+	return value
+}
+
+func TestBuilder_multipleTargets(t *testing.T) {
+	type httpConfig struct {
+		Port   int
+		Secret string
+	}
+	type dbConfig struct {
+		Host   string
+		Secret string
+	}
+
+	os.Clearenv()
+	defer os.Clearenv()
+	require.NoError(t, os.Setenv("PORT", "80"))
+	require.NoError(t, os.Setenv("HOST", "db"))
+	require.NoError(t, os.Setenv("SECRET", "secret://x"))
+	require.NoError(t, os.Setenv("TYPO", "y"))
+
+	for name, from := range map[string]func(*Builder) *Builder{
+		"FromEnv":     (*Builder).FromEnv,
+		"FromEnvLazy": (*Builder).FromEnvLazy,
+	} {
+		p := &countingPreProcessor{}
+		b := from(WithValuePreProcessor(p))
+
+		var h httpConfig
+		var d dbConfig
+		b.To(&h)
+		b.To(&d)
+		b.To(&d)
+
+		assert.Equal(t, httpConfig{Port: 80, Secret: "resolved"}, h, name)
+		assert.Equal(t, dbConfig{Host: "db", Secret: "resolved"}, d, name)
+		assert.Equal(t, 1, p.calls, "%s: secrets should be resolved once", name)
+		assert.Equal(t, []string{"typo"}, b.UnboundKeys(""), name)
+	}
+}
+
+func TestBuilder_Coverage(t *testing.T) {
+	t.Parallel()
+	type testConfig struct {
+		Port int
+		DB   struct {
+			Host string
+			User string
+		}
+		Plugin struct {
+			Nested struct {
+				A string
+			}
+			Rest map[string]string `config:",remain"`
+		}
+	}
+
+	b := FromSource(MapSource(map[string]string{
+		"PORT":                "80",
+		"DB__HOST":            "h",
+		"PLUGIN__NESTED__B":   "claimed by the nested struct",
+		"UNRELATED__SETTINGS": "x",
+	}))
+	assert.Equal(t, Coverage{
+		Set:     []string{"db__host", "port"},
+		Unset:   []string{"db__user", "plugin__", "plugin__nested__a"},
+		Similar: map[string]string{"plugin__nested__a": "plugin__nested__b"},
+	}, b.Coverage(&testConfig{}))
+
+	b = FromSource(MapSource(map[string]string{"PLUGIN__EXTRA": "x"}))
+	assert.Contains(t, b.Coverage(testConfig{}).Set, "plugin__")
+
+	var got testConfig
+	b.To(&got)
+	assert.Equal(t, map[string]string{"extra": "x"}, got.Plugin.Rest)
+	assert.Empty(t, b.UnboundKeys(""))
+}
@@ -0,0 +1,28 @@
+package config
+
+// Declare creates a new builder aware of target's fields. See Builder.Declare.
+func Declare(target interface{}) *Builder {
+	return newBuilder().Declare(target)
+}
+
+// Declare tells the Builder about a target before values are merged, returning the Builder.
+// Values for fields tagged `config:",literal"` then bypass the middleware and ValuePreProcessor entirely,
+// so a value which legitimately looks like a reference, such as a URL starting with sm://, never triggers a remote fetch:
+//
+//	type Config struct {
+//		Upstream string `config:",literal"`
+//	}
+//
+//	config.Declare(&c).WithValuePreProcessor(p).FromEnv().To(&c)
+//
+// Without Declare, literal fields still receive their value as it was before pre-processing,
+// but the ValuePreProcessor is called for it when it is merged.
+// target is a struct or struct pointer; it is not modified.
+func (c *Builder) Declare(target interface{}) *Builder {
+	for _, f := range fields(structType(target)) {
+		if hasTagOption(f.StructField, tagOptionLiteral) {
+			c.literal[f.key] = true
+		}
+	}
+	return c
+}
@@ -0,0 +1,70 @@
+package config
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuilder_Declare(t *testing.T) {
+	t.Parallel()
+	type testConfig struct {
+		Upstream string `config:",literal"`
+		Raw      string `config:",raw,literal"`
+		Password string
+	}
+	values := map[string]string{"UPSTREAM": "secret://x", "RAW": "secret://x", "PASSWORD": "secret://x"}
+
+	p := &countingPreProcessor{}
+	var got testConfig
+	b := Declare(&got).WithValuePreProcessor(p).FromSource(MapSource(values))
+	b.To(&got)
+	assert.Equal(t, testConfig{Upstream: "secret://x", Raw: "secret://x", Password: "resolved"}, got)
+	assert.Equal(t, 1, p.calls, "only the undeclared field should be resolved")
+	assert.Equal(t, []string{"PASSWORD=" + Redacted, "RAW=secret://x", "UPSTREAM=secret://x"}, b.Environ(EnvironRedactResolved()))
+
+	p = &countingPreProcessor{}
+	got = testConfig{}
+	WithValuePreProcessor(p).FromSource(MapSource(values)).To(&got)
+	assert.Equal(t, testConfig{Upstream: "secret://x", Raw: "secret://x", Password: "resolved"}, got)
+	assert.Equal(t, 3, p.calls, "without Declare, literal fields are still resolved when merged")
+}
+
+func TestBuilder_Declare_lazy(t *testing.T) {
+	os.Clearenv()
+	defer os.Clearenv()
+	require.NoError(t, os.Setenv("UPSTREAM", "secret://x"))
+
+	var got struct {
+		Upstream string `config:",literal"`
+	}
+	p := &countingPreProcessor{}
+	WithValuePreProcessor(p).FromEnvLazy().To(&got)
+	assert.Equal(t, "secret://x", got.Upstream)
+	assert.Equal(t, 0, p.calls, "lazily looked up values are only resolved when bound")
+
+	p = &countingPreProcessor{}
+	Declare(&got).WithValuePreProcessor(p).FromEnvLazy().To(&got)
+	assert.Equal(t, "secret://x", got.Upstream)
+	assert.Equal(t, 0, p.calls)
+}
+
+func TestBuilder_Declare_expander(t *testing.T) {
+	t.Parallel()
+	calls := 0
+	r := NewResolvers(context.Background()).
+		RegisterExpander("multi", ExpanderFunc(func(ctx context.Context, ref string) (map[string]string, error) {
+			calls++
+			return map[string]string{"a": "1"}, nil
+		}))
+
+	var got struct {
+		Upstream string `config:",literal"`
+	}
+	Declare(&got).WithValuePreProcessor(r).FromSource(MapSource(map[string]string{"UPSTREAM": "multi://x/*"})).To(&got)
+	assert.Equal(t, "multi://x/*", got.Upstream)
+	assert.Equal(t, 0, calls, "literal keys never reach a ValueExpander")
+}
@@ -0,0 +1,55 @@
+package config
+
+import "sync"
+
+var (
+	defaultMu      sync.Mutex
+	defaultBuilder *Builder
+)
+
+// SetDefaultBuilder makes b the Builder returned by Default, so that libraries deep in an application's
+// dependency tree can read its config without the Builder being passed through every constructor:
+//
+//	func main() {
+//		config.SetDefaultBuilder(config.WithValuePreProcessor(p).FromEnv())
+//		...
+//	}
+//
+//	// in a library
+//	var c clientConfig
+//	if err := config.BindDefault(&c); err != nil {
+//		return err
+//	}
+//
+// It is safe to call concurrently with Default and BindDefault, but should be called once, at start up,
+// before anything reads the config.
+func SetDefaultBuilder(b *Builder) {
+	defaultMu.Lock()
+	defer defaultMu.Unlock()
+	defaultBuilder = b
+}
+
+// Default returns the Builder set by SetDefaultBuilder. If none was set, a Builder populated from the environment
+// is created on first use, and kept.
+// A Builder is not safe for concurrent use, so bind the default Builder with BindDefault,
+// rather than with the Builder's own methods, if it is shared between goroutines.
+func Default() *Builder {
+	defaultMu.Lock()
+	defer defaultMu.Unlock()
+	return defaultLocked()
+}
+
+// BindDefault binds target with the default Builder, see Default, as Builder.Bind does.
+// Binds are serialized, so BindDefault is safe for concurrent use.
+func BindDefault(target interface{}) error {
+	defaultMu.Lock()
+	defer defaultMu.Unlock()
+	return defaultLocked().Bind(target)
+}
+
+func defaultLocked() *Builder {
+	if defaultBuilder == nil {
+		defaultBuilder = FromEnv()
+	}
+	return defaultBuilder
+}
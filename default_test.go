@@ -0,0 +1,44 @@
+package config
+
+import (
+	"os"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDefault(t *testing.T) {
+	type testConfig struct {
+		Port int
+	}
+	os.Clearenv()
+	defer os.Clearenv()
+	defer SetDefaultBuilder(nil)
+	require.NoError(t, os.Setenv("PORT", "80"))
+
+	SetDefaultBuilder(nil)
+	var c testConfig
+	require.NoError(t, BindDefault(&c))
+	assert.Equal(t, 80, c.Port, "the default Builder reads the environment")
+	assert.True(t, Default() == Default(), "the default Builder is kept")
+
+	b := FromString("PORT=8080\n")
+	SetDefaultBuilder(b)
+	assert.True(t, b == Default())
+
+	var wg sync.WaitGroup
+	got := make([]testConfig, 8)
+	for i := range got {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			assert.NoError(t, BindDefault(&got[i]))
+		}(i)
+	}
+	wg.Wait()
+	for _, c := range got {
+		assert.Equal(t, 8080, c.Port)
+	}
+}
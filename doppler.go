@@ -0,0 +1,99 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// dopplerAPI is the Doppler API, which DopplerAPI overrides.
+const dopplerAPI = "https://api.doppler.com"
+
+// dopplerMetadata are the keys Doppler adds to every config, which are not secrets of the application.
+var dopplerMetadata = []string{"DOPPLER_PROJECT", "DOPPLER_CONFIG", "DOPPLER_ENVIRONMENT"}
+
+// DopplerOption configures DopplerSource.
+type DopplerOption func(*dopplerOptions)
+
+type dopplerOptions struct {
+	api             string
+	project, config string
+	client          *http.Client
+}
+
+// DopplerConfig downloads the secrets of config, e.g. prd, in project. It is needed for personal and CLI tokens,
+// which can access several configs, but not service tokens, which are scoped to one.
+func DopplerConfig(project, config string) DopplerOption {
+	return func(o *dopplerOptions) {
+		o.project, o.config = project, config
+	}
+}
+
+// DopplerAPI sends requests to apiURL rather than https://api.doppler.com, e.g. through a proxy.
+func DopplerAPI(apiURL string) DopplerOption {
+	return func(o *dopplerOptions) {
+		o.api = strings.TrimSuffix(apiURL, "/")
+	}
+}
+
+// DopplerClient sends requests with client, e.g. one with a timeout, rather than http.DefaultClient.
+func DopplerClient(client *http.Client) DopplerOption {
+	return func(o *dopplerOptions) {
+		o.client = client
+	}
+}
+
+// DopplerSource returns a Source of the secrets of a Doppler config, downloaded using ctx with token,
+// typically a service token given to the application as DOPPLER_TOKEN. The keys Doppler adds to every config,
+// DOPPLER_PROJECT, DOPPLER_CONFIG and DOPPLER_ENVIRONMENT, are left out. Any response other than 2xx is an error.
+func DopplerSource(ctx context.Context, token string, opts ...DopplerOption) Source {
+	o := dopplerOptions{api: dopplerAPI}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	query := url.Values{"format": {"json"}}
+	name := "doppler"
+	if o.project != "" || o.config != "" {
+		query.Set("project", o.project)
+		query.Set("config", o.config)
+		name += " " + o.project + "/" + o.config
+	}
+	u := o.api + "/v3/configs/config/secrets/download?" + query.Encode()
+	return namedSource{name: name, Source: SourceFunc(func() (map[string]string, error) {
+		p, err := fetchURL(ctx, u, urlOptions{client: o.client, header: http.Header{"Authorization": {"Bearer " + token}}})
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		values, err := ReaderSource(bytes.NewReader(p.Data), FormatJSON).Load()
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		for key := range values {
+			for _, metadata := range dopplerMetadata {
+				if NormalizeKey(key) == NormalizeKey(metadata) {
+					delete(values, key)
+				}
+			}
+		}
+		return values, nil
+	})}
+}
+
+// FromDoppler returns a new Builder, populated with the secrets of a Doppler config. See DopplerSource.
+// It panics with an *Error if the secrets cannot be downloaded.
+func FromDoppler(ctx context.Context, token string, opts ...DopplerOption) *Builder {
+	return newBuilder().FromDoppler(ctx, token, opts...)
+}
+
+// FromDoppler merges the secrets of a Doppler config into the current config state, returning the Builder,
+// so that they need not be exported to the environment with doppler run first:
+//
+//	config.FromDoppler(ctx, os.Getenv("DOPPLER_TOKEN")).FromEnv().To(&c)
+//
+// See DopplerSource. It panics with an *Error if the secrets cannot be downloaded.
+func (c *Builder) FromDoppler(ctx context.Context, token string, opts ...DopplerOption) *Builder {
+	return c.FromSource(DopplerSource(ctx, token, opts...))
+}
@@ -0,0 +1,49 @@
+package config
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFromDoppler(t *testing.T) {
+	t.Parallel()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v3/configs/config/secrets/download" || r.URL.Query().Get("format") != "json" {
+			http.NotFound(w, r)
+			return
+		}
+		switch r.Header.Get("Authorization") {
+		case "Bearer dp.st.prd":
+			_, _ = w.Write([]byte(`{"DATABASE__HOST": "db.internal", "PORT": "8080", "DOPPLER_PROJECT": "billing", "DOPPLER_CONFIG": "prd", "DOPPLER_ENVIRONMENT": "prd"}`))
+		case "Bearer dp.pt.personal":
+			_, _ = w.Write([]byte(`{"PORT": "` + r.URL.Query().Get("project") + "/" + r.URL.Query().Get("config") + `"}`))
+		default:
+			http.Error(w, `{"messages": ["Invalid Auth token"]}`, http.StatusUnauthorized)
+		}
+	}))
+	defer srv.Close()
+
+	ctx := context.Background()
+	var c struct {
+		Port     string
+		Database struct{ Host Meta[string] }
+	}
+	b := FromDoppler(ctx, "dp.st.prd", DopplerAPI(srv.URL+"/"), DopplerClient(srv.Client()))
+	b.To(&c)
+	assert.Equal(t, "8080", c.Port)
+	assert.Equal(t, "db.internal", c.Database.Host.Value)
+	assert.Equal(t, "doppler", c.Database.Host.Source)
+	assert.Empty(t, b.UnboundKeys(""), "Doppler's own keys are left out")
+
+	FromDoppler(ctx, "dp.pt.personal", DopplerAPI(srv.URL), DopplerConfig("billing", "dev")).To(&c)
+	assert.Equal(t, "billing/dev", c.Port)
+
+	err := recoverError(func() { FromDoppler(ctx, "bad", DopplerAPI(srv.URL), DopplerConfig("billing", "dev")) })
+	assert.Equal(t, CodeSourceLoad, CodeOf(err))
+	assert.Contains(t, err.Error(), "doppler billing/dev")
+	assert.Contains(t, err.Error(), "401 Unauthorized")
+}
@@ -0,0 +1,122 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// parseDotenv parses the lines of a dotenv file into KEY=VALUE lines, returning an error for the first malformed entry.
+// It accepts the format shared by dotenv libraries in other ecosystems:
+//   - blank lines and lines starting with '#' are ignored, as is an "export " prefix
+//   - unquoted values are trimmed, and end at a '#' preceded by whitespace, which starts a comment
+//   - single quoted values are taken literally
+//   - double quoted values support the escapes \n, \r, \t, \", \\ and \$
+//   - quoted values may span several lines, and may be followed by a comment
+//   - keys with an empty value, e.g. KEY=, are accepted, and dropped when the values are merged
+func parseDotenv(lines []string) ([]string, error) {
+	var entries []string
+	for i := 0; i < len(lines); i++ {
+		line := strings.TrimSpace(strings.TrimSuffix(lines[i], "\r"))
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if rest := strings.TrimPrefix(line, "export"); rest != line && (rest == "" || rest[0] == ' ' || rest[0] == '\t') {
+			if rest = strings.TrimSpace(rest); strings.Contains(rest, "=") {
+				line = rest
+			}
+		}
+
+		split := strings.SplitN(line, "=", 2)
+		key := strings.TrimSpace(split[0])
+		switch {
+		case len(split) != 2:
+			return nil, fmt.Errorf("line %d: missing '=' separator", i+1)
+		case key == "":
+			return nil, fmt.Errorf("line %d: missing key", i+1)
+		case !utf8.ValidString(key):
+			return nil, fmt.Errorf("line %d: key is not valid UTF-8", i+1)
+		}
+
+		start := i
+		value, consumed, err := parseDotenvValue(strings.TrimLeft(split[1], " \t"), lines[i+1:])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", start+1, err)
+		}
+		i += consumed
+		entries = append(entries, key+"="+value)
+	}
+	return entries, nil
+}
+
+// parseDotenvValue parses the value starting s, reading continuation lines from next if it is quoted and spans them.
+// It returns how many lines of next were consumed.
+func parseDotenvValue(s string, next []string) (value string, consumed int, err error) {
+	s = strings.TrimSuffix(s, "\r")
+	if s == "" || (s[0] != '"' && s[0] != '\'') {
+		if i := dotenvCommentIndex(s); i >= 0 {
+			s = s[:i]
+		}
+		return strings.TrimSpace(s), 0, nil
+	}
+
+	quote := s[0]
+	var b strings.Builder
+	rest := s[1:]
+	for {
+		end := -1
+		for j := 0; j < len(rest); j++ {
+			if quote == '"' && rest[j] == '\\' && j+1 < len(rest) {
+				b.WriteString(unescapeDotenv(rest[j+1]))
+				j++
+				continue
+			}
+			if rest[j] == quote {
+				end = j
+				break
+			}
+			b.WriteByte(rest[j])
+		}
+		if end >= 0 {
+			trailing := strings.TrimSpace(rest[end+1:])
+			if trailing != "" && !strings.HasPrefix(trailing, "#") {
+				return "", 0, fmt.Errorf("unexpected %q after quoted value", trailing)
+			}
+			return b.String(), consumed, nil
+		}
+		if consumed == len(next) {
+			return "", 0, errors.New("unterminated quoted value")
+		}
+		b.WriteByte('\n')
+		rest = strings.TrimSuffix(next[consumed], "\r")
+		consumed++
+	}
+}
+
+// dotenvCommentIndex returns the index of the '#' starting a comment in an unquoted value, or -1.
+func dotenvCommentIndex(s string) int {
+	for i := 1; i < len(s); i++ {
+		if s[i] == '#' && (s[i-1] == ' ' || s[i-1] == '\t') {
+			return i
+		}
+	}
+	if strings.HasPrefix(s, "#") {
+		return 0
+	}
+	return -1
+}
+
+func unescapeDotenv(c byte) string {
+	switch c {
+	case 'n':
+		return "\n"
+	case 'r':
+		return "\r"
+	case 't':
+		return "\t"
+	case '"', '\\', '$':
+		return string(c)
+	}
+	return "\\" + string(c)
+}
@@ -0,0 +1,69 @@
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_parseDotenv(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name string
+		in   string
+		want []string
+		err  string
+	}{
+		{name: "plain", in: "A=1\n\n   \nB=\nC=a=b", want: []string{"A=1", "B=", "C=a=b"}},
+		{name: "comments", in: "# comment\n  # indented\nA=1 # trailing\nB=a#b\nC=#", want: []string{"A=1", "B=a#b", "C="}},
+		{name: "whitespace", in: "  A = 1  \r\nB=\t2\t", want: []string{"A=1", "B=2"}},
+		{name: "export", in: "export A=1\nexport\tB=2\nexport=3\nexported=4", want: []string{"A=1", "B=2", "export=3", "exported=4"}},
+		{name: "double quoted", in: `A="a \"b\" # c\n\t\\\$d\x"  # comment`, want: []string{"A=a \"b\" # c\n\t\\$d\\x"}},
+		{name: "single quoted", in: `A='a \n "b" # c'`, want: []string{`A=a \n "b" # c`}},
+		{name: "empty quoted", in: `A=""` + "\nB=''", want: []string{"A=", "B="}},
+		{name: "multi-line", in: "A=\"-----BEGIN-----\nabc\n-----END-----\"\nB='x\r\ny'\nC=3", want: []string{"A=-----BEGIN-----\nabc\n-----END-----", "B=x\ny", "C=3"}},
+		{name: "missing separator", in: "A=1\nB", err: "line 2: missing '=' separator"},
+		{name: "missing key", in: " =1", err: "line 1: missing key"},
+		{name: "invalid key", in: "\xff=1", err: "line 1: key is not valid UTF-8"},
+		{name: "unterminated", in: "A=1\nB=\"abc\nC=3", err: "line 2: unterminated quoted value"},
+		{name: "trailing", in: `A="a" b`, err: `line 1: unexpected "b" after quoted value`},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, err := parseDotenv(strings.Split(tt.in, "\n"))
+			if tt.err != "" {
+				assert.EqualError(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestFileSource_dotenv(t *testing.T) {
+	t.Parallel()
+	file, err := ioutil.TempFile("", "testenv")
+	require.NoError(t, err)
+	defer os.Remove(file.Name())
+	_, err = file.Write([]byte("# generated by docker compose\nexport DATABASE__HOST=db # primary\nCERT=\"line 1\nline 2\"\nEMPTY=\n"))
+	require.NoError(t, err)
+
+	var got struct {
+		Database struct{ Host string }
+		Cert     string
+		Empty    string
+	}
+	b := From(file.Name())
+	b.To(&got)
+	assert.Equal(t, "db", got.Database.Host)
+	assert.Equal(t, "line 1\nline 2", got.Cert)
+	assert.Equal(t, "", got.Empty)
+	assert.Equal(t, []Warning{{Kind: WarningEmptyValue, Key: "empty", Message: "empty value dropped, leaving the field unset"}}, b.Warnings())
+}
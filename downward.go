@@ -0,0 +1,163 @@
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// DownwardAPISource returns a Source of the files in dir, a Kubernetes downward API volume.
+// Each file is bound from its path relative to dir, with directories nested by the struct delimiter,
+// so a file at resources/cpu_limit is bound from RESOURCES__CPU_LIMIT.
+// Files of key="value" lines, such as labels and annotations, are expanded into a key per line beneath the file's key,
+// e.g. LABELS__APP. Such keys often contain characters which cannot appear in struct tags,
+// so they are best bound to a map[string]string tagged `config:",remain"` in a nested struct.
+// Hidden files and directories, such as the ..data link Kubernetes uses for atomic updates, are skipped.
+//
+// Resource limits and requests can be bound to numeric fields tagged `config:",quantity"`,
+// which also accepts quantities such as 500m or 1Gi in other sources.
+func DownwardAPISource(dir string) Source {
+	return namedSource{name: "downward API " + dir, Source: SourceFunc(func() (map[string]string, error) {
+		values := make(map[string]string)
+		if err := loadDir(values, dir, "", true, false); err != nil {
+			return nil, err
+		}
+		return values, nil
+	})}
+}
+
+// FromDownwardAPI returns a new Builder, populated with the files in dir. See DownwardAPISource.
+// It panics with an *Error if dir cannot be read.
+func FromDownwardAPI(dir string) *Builder {
+	return newBuilder().FromDownwardAPI(dir)
+}
+
+// FromDownwardAPI merges the files in dir into the current config state, returning the Builder.
+// See DownwardAPISource. It panics with an *Error if dir cannot be read.
+func (c *Builder) FromDownwardAPI(dir string) *Builder {
+	return c.FromSource(DownwardAPISource(dir))
+}
+
+// DirSource returns a Source of the files in dir, each bound from its name with its content as the value,
+// the layout of a Kubernetes ConfigMap or Secret mounted as a volume, or of Docker secrets in /run/secrets:
+//
+//	config.FromEnv().FromDir("/etc/secrets").To(&c)
+//
+// Files in subdirectories are bound from their path relative to dir, nested by the struct delimiter,
+// so a file at database/password is bound from DATABASE__PASSWORD. A single trailing newline is trimmed
+// from each value, but values are otherwise kept as is.
+// Hidden files and directories, such as the ..data link Kubernetes uses for atomic updates, are skipped,
+// as are subdirectories with DirFlat.
+func DirSource(dir string, opts ...DirOption) Source {
+	var o dirOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return namedSource{name: "directory " + dir, Source: SourceFunc(func() (map[string]string, error) {
+		values := make(map[string]string)
+		if err := loadDir(values, dir, "", false, o.flat); err != nil {
+			return nil, err
+		}
+		return values, nil
+	})}
+}
+
+// DirOption configures DirSource.
+type DirOption func(*dirOptions)
+
+type dirOptions struct {
+	flat bool
+}
+
+// DirFlat makes DirSource read only the files directly in its directory, skipping subdirectories,
+// e.g. for /run/secrets, where Docker mounts each secret as a file, so unrelated directories are not bound.
+func DirFlat() DirOption {
+	return func(o *dirOptions) {
+		o.flat = true
+	}
+}
+
+// FromDir returns a new Builder, populated with the files in dir. See DirSource.
+// It panics with an *Error if dir cannot be read.
+func FromDir(dir string, opts ...DirOption) *Builder {
+	return newBuilder().FromDir(dir, opts...)
+}
+
+// FromDir merges the files in dir into the current config state, returning the Builder.
+// See DirSource. It panics with an *Error if dir cannot be read.
+func (c *Builder) FromDir(dir string, opts ...DirOption) *Builder {
+	return c.FromSource(DirSource(dir, opts...))
+}
+
+// loadDir loads the files under dir into values, keyed by their path after prefix.
+// downwardAPI trims values, and expands files of key="value" lines, as the downward API writes them.
+// flat skips subdirectories.
+func loadDir(values map[string]string, dir, prefix string, downwardAPI, flat bool) error {
+	entries, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		name := entry.Name()
+		if strings.HasPrefix(name, ".") {
+			continue
+		}
+		path := filepath.Join(dir, name)
+		// the visible entries of a mounted volume are symlinks into ..data, so follow them
+		info, err := os.Stat(path)
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			if flat {
+				continue
+			}
+			if err := loadDir(values, path, prefix+name+structDelim, downwardAPI, false); err != nil {
+				return err
+			}
+			continue
+		}
+
+		b, err := ioutil.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		if !downwardAPI {
+			content := strings.TrimSuffix(string(b), "\n")
+			values[prefix+name] = strings.TrimSuffix(content, "\r")
+			continue
+		}
+		content := strings.TrimSpace(string(b))
+		if pairs, ok := parseDownwardAPIPairs(content); ok {
+			for k, v := range pairs {
+				values[prefix+name+structDelim+k] = v
+			}
+			continue
+		}
+		values[prefix+name] = content
+	}
+	return nil
+}
+
+// parseDownwardAPIPairs parses the key="value" lines the downward API writes for labels and annotations.
+// It reports false if content is empty or any line is not of that form.
+func parseDownwardAPIPairs(content string) (map[string]string, bool) {
+	if content == "" {
+		return nil, false
+	}
+	pairs := make(map[string]string)
+	for _, line := range strings.Split(content, "\n") {
+		kv := strings.SplitN(line, "=", 2)
+		if len(kv) != 2 || kv[0] == "" {
+			return nil, false
+		}
+		v, err := strconv.Unquote(kv[1])
+		if err != nil {
+			return nil, false
+		}
+		pairs[kv[0]] = v
+	}
+	return pairs, true
+}
@@ -0,0 +1,113 @@
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuilder_FromDownwardAPI(t *testing.T) {
+	t.Parallel()
+	dir, err := ioutil.TempDir("", "podinfo")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	// lay the volume out as the kubelet does, with visible symlinks into ..data
+	data := filepath.Join(dir, "..data")
+	require.NoError(t, os.MkdirAll(filepath.Join(data, "resources"), 0755))
+	files := map[string]string{
+		"labels":              "app=\"checkout\"\ntier=\"backend\"\n",
+		"annotations":         "example.com/owner=\"payments \\\"team\\\"\"\n",
+		"name":                "checkout-7d9f\n",
+		"resources/cpu_limit": "500m\n",
+		"resources/mem_limit": "268435456\n",
+	}
+	for name, content := range files {
+		require.NoError(t, ioutil.WriteFile(filepath.Join(data, name), []byte(content), 0644))
+	}
+	for _, name := range []string{"labels", "annotations", "name", "resources"} {
+		require.NoError(t, os.Symlink(filepath.Join("..data", name), filepath.Join(dir, name)))
+	}
+
+	values, err := DownwardAPISource(dir).Load()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"labels__app":                    "checkout",
+		"labels__tier":                   "backend",
+		"annotations__example.com/owner": `payments "team"`,
+		"name":                           "checkout-7d9f",
+		"resources__cpu_limit":           "500m",
+		"resources__mem_limit":           "268435456",
+	}, values)
+
+	type testConfig struct {
+		Name   string
+		Labels struct {
+			App string
+		}
+		Annotations struct {
+			All map[string]string `config:",remain"`
+		}
+		Resources struct {
+			CPULimit float64 `config:"cpu_limit,quantity"`
+			MemLimit int64   `config:"mem_limit,quantity"`
+		}
+	}
+	var got testConfig
+	FromDownwardAPI(dir).To(&got)
+	assert.Equal(t, "checkout-7d9f", got.Name)
+	assert.Equal(t, "checkout", got.Labels.App)
+	assert.Equal(t, map[string]string{"example.com/owner": `payments "team"`}, got.Annotations.All)
+	assert.Equal(t, 0.5, got.Resources.CPULimit)
+	assert.Equal(t, int64(268435456), got.Resources.MemLimit)
+
+	err = recoverError(func() { FromDownwardAPI(filepath.Join(dir, "missing")) })
+	assert.Equal(t, CodeSourceLoad, CodeOf(err))
+}
+
+func TestBuilder_FromDir(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	data := filepath.Join(dir, "..data")
+	require.NoError(t, os.MkdirAll(filepath.Join(data, "database"), 0755))
+	files := map[string]string{
+		"api_key":           "k3y\n",
+		"greeting":          "  hello\nworld  \r\n",
+		"database/password": "s3cr3t",
+	}
+	for name, content := range files {
+		require.NoError(t, os.WriteFile(filepath.Join(data, name), []byte(content), 0644))
+	}
+	for _, name := range []string{"api_key", "greeting", "database"} {
+		require.NoError(t, os.Symlink(filepath.Join("..data", name), filepath.Join(dir, name)))
+	}
+
+	values, err := DirSource(dir).Load()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"api_key":            "k3y",
+		"greeting":           "  hello\nworld  ",
+		"database__password": "s3cr3t",
+	}, values, "only a trailing newline is trimmed")
+
+	var got struct {
+		APIKey   string `config:"api_key"`
+		Database struct {
+			Password string
+		}
+	}
+	FromDir(dir).To(&got)
+	assert.Equal(t, "k3y", got.APIKey)
+	assert.Equal(t, "s3cr3t", got.Database.Password)
+
+	values, err = DirSource(dir, DirFlat()).Load()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"api_key": "k3y", "greeting": "  hello\nworld  "}, values, "subdirectories are skipped")
+
+	err = recoverError(func() { FromDir(filepath.Join(dir, "missing")) })
+	assert.Equal(t, CodeSourceLoad, CodeOf(err))
+}
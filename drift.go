@@ -0,0 +1,112 @@
+package config
+
+import (
+	"context"
+	"fmt"
+)
+
+// Drift is a value whose upstream source no longer matches the value the Builder resolved.
+type Drift struct {
+	// Key is the normalized key of the drifted value.
+	Key string
+	// Reference is the value before pre-processing, e.g. "sm://my_secret".
+	// Neither the current nor the upstream value is included, as they are typically secrets.
+	Reference string
+}
+
+// CheckDrift re-resolves every value changed by the ValuePreProcessor, e.g. secrets fetched from AWS,
+// and reports those whose upstream value now differs from the one in the current config state.
+// Nothing is changed: CheckDrift is meant to power alerts on config drift, not to apply it.
+// If the ValuePreProcessor is a ContextValuePreProcessor, ctx is passed to it.
+// Values in an environment layered with FromEnvLazy are resolved on every bind, so never drift.
+// Values are re-resolved bypassing the cache of Resolvers set up with WithCache.
+// An error is returned if ctx is done, or the ValuePreProcessor panics while re-resolving a value.
+func (c *Builder) CheckDrift(ctx context.Context) ([]Drift, error) {
+	var drifts []Drift
+	for _, key := range c.keys() {
+		reference, ok := c.references[key]
+		if !ok {
+			continue
+		}
+		current, ok := c.storedLookup(key)
+		if !ok {
+			continue
+		}
+		if err := ctx.Err(); err != nil {
+			return drifts, err
+		}
+		upstream, err := c.reresolve(ctx, key, reference)
+		if err != nil {
+			return drifts, err
+		}
+		if upstream != current {
+			drifts = append(drifts, Drift{Key: key, Reference: reference})
+		}
+	}
+	return drifts, nil
+}
+
+// storedLookup is lookup, ignoring an environment layered with FromEnvLazy.
+func (c *Builder) storedLookup(key string) (string, bool) {
+	if v, ok := c.configMap[key]; ok {
+		return c.unseal(key, v), true
+	}
+	v, ok := c.baseMap[key]
+	if ok {
+		v = c.unseal(key, v)
+	}
+	return v, ok
+}
+
+// reresolve passes reference through the middleware and ValuePreProcessor again, without recording the result.
+func (c *Builder) reresolve(ctx context.Context, key, reference string) (string, error) {
+	return c.reresolver().reresolve(ctx, key, reference)
+}
+
+// reresolver re-resolves values with a copy of the Builder's middleware and ValuePreProcessor,
+// so that it can be used from another goroutine while the Builder is still changed.
+type reresolver struct {
+	valuePreProcessor ValuePreProcessor
+	middleware        []Middleware
+}
+
+// reresolver returns a reresolver for the Builder's current middleware and ValuePreProcessor,
+// bypassing the cache of Resolvers, see refreshingPreProcessor.
+func (c *Builder) reresolver() reresolver {
+	return reresolver{
+		valuePreProcessor: refreshingPreProcessor(c.valuePreProcessor),
+		middleware:        append([]Middleware(nil), c.middleware...),
+	}
+}
+
+// reresolve passes reference through the middleware and ValuePreProcessor, returning an error if either panics,
+// the *Error panicked with, if any, so that its Code is kept.
+func (r reresolver) reresolve(ctx context.Context, key, reference string) (value string, err error) {
+	defer func() {
+		if rec := recover(); rec != nil {
+			if e, ok := rec.(*Error); ok {
+				err = e
+				return
+			}
+			err = fmt.Errorf("config: unable to re-resolve %s: %v", key, rec)
+		}
+	}()
+	vpp := r.valuePreProcessor
+	return runPipeline(r.middleware, key, reference, func(value string) string {
+		if vpp == nil {
+			return value
+		}
+		if p, ok := vpp.(ContextValuePreProcessor); ok {
+			return p.PreProcessValueContext(ctx, key, value)
+		}
+		return vpp.PreProcessValue(key, value)
+	}), nil
+}
+
+// refreshingPreProcessor returns p, re-resolving values rather than returning those cached if it is a Resolvers.
+func refreshingPreProcessor(p ValuePreProcessor) ValuePreProcessor {
+	if r, ok := p.(*Resolvers); ok && r != nil {
+		return r.refreshing()
+	}
+	return p
+}
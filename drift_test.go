@@ -0,0 +1,59 @@
+package config
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mapPreProcessor struct {
+	values map[string]string
+}
+
+func (p *mapPreProcessor) PreProcessValue(key, value string) string {
+	if ref := strings.TrimPrefix(value, "ref://"); ref != value {
+		v, ok := p.values[ref]
+		if !ok {
+			panic("unknown reference " + ref)
+		}
+		return v
+	}
+	return value
+}
+
+func TestBuilder_CheckDrift(t *testing.T) {
+	os.Clearenv()
+	defer os.Clearenv()
+	require.NoError(t, os.Setenv("A", "ref://a"))
+	require.NoError(t, os.Setenv("B", "ref://b"))
+	require.NoError(t, os.Setenv("C", "plain"))
+
+	p := &mapPreProcessor{values: map[string]string{"a": "1", "b": "2"}}
+	b := WithEncryptedSecrets().WithValuePreProcessor(p).FromEnv()
+
+	drifts, err := b.CheckDrift(context.Background())
+	require.NoError(t, err)
+	assert.Empty(t, drifts)
+
+	p.values["b"] = "3"
+	drifts, err = b.CheckDrift(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []Drift{{Key: "b", Reference: "ref://b"}}, drifts)
+
+	var got struct{ B string }
+	b.To(&got)
+	assert.Equal(t, "2", got.B, "drift should not be applied")
+
+	delete(p.values, "a")
+	_, err = b.CheckDrift(context.Background())
+	assert.EqualError(t, err, "config: unable to re-resolve a: unknown reference a")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = b.CheckDrift(ctx)
+	assert.Equal(t, context.Canceled, err)
+}
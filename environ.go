@@ -0,0 +1,178 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Redacted replaces values hidden by EnvironRedact and EnvironRedactResolved.
+const Redacted = "REDACTED"
+
+// EnvironOption configures Builder.Environ.
+type EnvironOption func(*environOptions)
+
+type environOptions struct {
+	filters []func(key string) bool
+	redacts []func(key string) bool
+
+	redactResolved bool
+}
+
+// EnvironFilter only includes keys for which keep returns true.
+// keep is passed the normalized key.
+func EnvironFilter(keep func(key string) bool) EnvironOption {
+	return func(o *environOptions) {
+		o.filters = append(o.filters, keep)
+	}
+}
+
+// EnvironRedact replaces the value of keys for which redact returns true with Redacted.
+// redact is passed the normalized key.
+func EnvironRedact(redact func(key string) bool) EnvironOption {
+	return func(o *environOptions) {
+		o.redacts = append(o.redacts, redact)
+	}
+}
+
+// EnvironRedactResolved replaces values which were changed by the ValuePreProcessor,
+// such as secrets fetched from AWS, with Redacted.
+func EnvironRedactResolved() EnvironOption {
+	return func(o *environOptions) {
+		o.redactResolved = true
+	}
+}
+
+// EnvironManifest only includes keys in manifest, as read by ReadManifest. See CheckManifest for how entries match.
+func EnvironManifest(manifest []string) EnvironOption {
+	return EnvironFilter(func(key string) bool {
+		return inManifest(key, manifest)
+	})
+}
+
+// Environ returns the current config state as sorted KEY=VALUE pairs,
+// in the form expected by exec.Cmd's Env field.
+// Keys are upper cased, as is conventional for environment variables, and values are as resolved by the ValuePreProcessor.
+//
+//	cmd := exec.Command("child")
+//	cmd.Env = b.Environ()
+func (c *Builder) Environ(opts ...EnvironOption) []string {
+	var o environOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	var env []string
+	for _, key := range c.keys() {
+		if !o.keep(key) {
+			continue
+		}
+		value, ok := c.lookup(key)
+		if !ok {
+			continue
+		}
+		if o.redact(key) || (o.redactResolved && c.resolved[key]) {
+			value = Redacted
+		}
+		env = append(env, strings.ToUpper(key)+"="+value)
+	}
+	return env
+}
+
+// WriteEnvFile writes the current config state, see Environ, to file as KEY=VALUE lines, quoted as needed
+// to be read back by FileSource. This lets an init container resolve secrets once into a volume shared with
+// the application container, for programs which cannot resolve them themselves.
+// The file is written atomically, by renaming a temporary file in the same directory, and is left read only
+// by its owner, so that readers never see a partial file, and the application cannot change it.
+// Values are written in plaintext, so secrets in the file are protected only by its permissions.
+func (c *Builder) WriteEnvFile(file string, opts ...EnvironOption) (err error) {
+	f, err := os.CreateTemp(filepath.Dir(file), "."+filepath.Base(file)+".*")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			f.Close()
+			os.Remove(f.Name())
+		}
+	}()
+	ew := &errWriter{w: f}
+	for _, kv := range c.Environ(opts...) {
+		i := strings.Index(kv, "=")
+		ew.printf("%s=%s\n", kv[:i], dotenvQuote(kv[i+1:]))
+	}
+	if ew.err != nil {
+		return fmt.Errorf("%s: %w", file, ew.err)
+	}
+	if err := f.Chmod(0o400); err != nil {
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), file)
+}
+
+func (o *environOptions) keep(key string) bool {
+	for _, f := range o.filters {
+		if !f(key) {
+			return false
+		}
+	}
+	return true
+}
+
+func (o *environOptions) redact(key string) bool {
+	for _, f := range o.redacts {
+		if f(key) {
+			return true
+		}
+	}
+	return false
+}
+
+// defaultSanitizePatterns are the patterns SanitizeEnviron uses when it is passed none.
+var defaultSanitizePatterns = []string{
+	"*PASSWORD*", "*PASSWD*", "*SECRET*", "*TOKEN*", "*CREDENTIAL*", "*PRIVATE*", "*API_KEY*", "*APIKEY*",
+	"*ACCESS_KEY*", "*AUTH*", "*COOKIE*", "*SESSION*", "*SIGNATURE*", "*DSN*",
+}
+
+// SanitizeEnviron returns a sorted copy of the process environment as KEY=VALUE pairs, with the values of keys
+// matching any of patterns replaced with Redacted, for inclusion in bug reports or logged when recovering a panic:
+//
+//	defer func() {
+//		if r := recover(); r != nil {
+//			log.Printf("panic: %v, environment: %q", r, config.SanitizeEnviron())
+//			panic(r)
+//		}
+//	}()
+//
+// Patterns are matched against upper cased keys with path.Match, e.g. "*_PASSWORD" or "AWS_*".
+// Without patterns, keys containing e.g. PASSWORD, SECRET, TOKEN, API_KEY or AUTH are redacted.
+// A malformed pattern redacts every value, rather than risk leaking one.
+func SanitizeEnviron(patterns ...string) []string {
+	if len(patterns) == 0 {
+		patterns = defaultSanitizePatterns
+	}
+	env := os.Environ()
+	for i, kv := range env {
+		split := strings.SplitN(kv, "=", 2)
+		if len(split) == 2 && sanitizeMatch(patterns, strings.ToUpper(split[0])) {
+			env[i] = split[0] + "=" + Redacted
+		}
+	}
+	sort.Strings(env)
+	return env
+}
+
+func sanitizeMatch(patterns []string, key string) bool {
+	for _, pattern := range patterns {
+		if ok, err := path.Match(strings.ToUpper(pattern), key); ok || err != nil {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,110 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type secretPreProcessor struct{}
+
+func (p *secretPreProcessor) PreProcessValue(key, value string) string {
+	if strings.HasPrefix(value, "secret://") {
+		return "s3cr3t"
+	}
+	return value
+}
+
+func TestBuilder_Environ(t *testing.T) {
+	os.Clearenv()
+	defer os.Clearenv()
+	require.NoError(t, os.Setenv("APP__PASSWORD", "secret://password"))
+	require.NoError(t, os.Setenv("APP__HOST", "localhost"))
+	require.NoError(t, os.Setenv("app__token", "abc"))
+	require.NoError(t, os.Setenv("HOME", "/root"))
+
+	b := WithValuePreProcessor(&secretPreProcessor{}).FromEnv()
+
+	assert.Equal(t, []string{
+		"APP__HOST=localhost",
+		"APP__PASSWORD=s3cr3t",
+		"APP__TOKEN=abc",
+		"HOME=/root",
+	}, b.Environ())
+
+	assert.Equal(t, []string{
+		"APP__HOST=localhost",
+		"APP__PASSWORD=" + Redacted,
+		"APP__TOKEN=" + Redacted,
+	}, b.Environ(
+		EnvironFilter(func(key string) bool { return strings.HasPrefix(key, "app__") }),
+		EnvironRedact(func(key string) bool { return strings.HasSuffix(key, "token") }),
+		EnvironRedactResolved(),
+	))
+
+	lazy := WithValuePreProcessor(&secretPreProcessor{}).FromEnvLazy()
+	assert.Equal(t, []string{
+		"APP__HOST=localhost",
+		"APP__PASSWORD=" + Redacted,
+		"APP__TOKEN=abc",
+		"HOME=/root",
+	}, lazy.Environ(EnvironRedactResolved()))
+}
+
+func TestBuilder_WriteEnvFile(t *testing.T) {
+	t.Parallel()
+	b := WithValuePreProcessor(&secretPreProcessor{}).FromString("APP__PASSWORD=secret://password\nAPP__GREETING=\"hello world\"\nHOME=/root\n")
+	file := filepath.Join(t.TempDir(), "app.env")
+	require.NoError(t, b.WriteEnvFile(file, EnvironManifest([]string{"app__*"})))
+
+	content, err := os.ReadFile(file)
+	require.NoError(t, err)
+	assert.Equal(t, "APP__GREETING=\"hello world\"\nAPP__PASSWORD=s3cr3t\n", string(content))
+	info, err := os.Stat(file)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o400), info.Mode().Perm())
+
+	var c struct {
+		App struct{ Password, Greeting string }
+	}
+	From(file).To(&c)
+	assert.Equal(t, "s3cr3t", c.App.Password)
+	assert.Equal(t, "hello world", c.App.Greeting, "values are read back as written")
+
+	assert.Error(t, b.WriteEnvFile(filepath.Join(t.TempDir(), "missing", "app.env")))
+}
+
+func TestSanitizeEnviron(t *testing.T) {
+	os.Clearenv()
+	defer os.Clearenv()
+	require.NoError(t, os.Setenv("APP__DB__PASSWORD", "hunter2"))
+	require.NoError(t, os.Setenv("GITHUB_TOKEN", "ghp_x"))
+	require.NoError(t, os.Setenv("app__api_key", "k"))
+	require.NoError(t, os.Setenv("HOME", "/root"))
+	require.NoError(t, os.Setenv("EMPTY", ""))
+
+	assert.Equal(t, []string{
+		"APP__DB__PASSWORD=" + Redacted,
+		"EMPTY=",
+		"GITHUB_TOKEN=" + Redacted,
+		"HOME=/root",
+		"app__api_key=" + Redacted,
+	}, SanitizeEnviron())
+	assert.Equal(t, "hunter2", os.Getenv("APP__DB__PASSWORD"), "the environment itself is left as is")
+
+	assert.Equal(t, []string{
+		"APP__DB__PASSWORD=hunter2",
+		"EMPTY=",
+		"GITHUB_TOKEN=ghp_x",
+		"HOME=" + Redacted,
+		"app__api_key=" + Redacted,
+	}, SanitizeEnviron("home", "APP__*_KEY"))
+
+	for _, kv := range SanitizeEnviron("[") {
+		assert.True(t, strings.HasSuffix(kv, "="+Redacted), kv)
+	}
+}
@@ -0,0 +1,62 @@
+package config
+
+import "reflect"
+
+// Equal reports whether a and b, pointers to structs of the same type, hold the same config. The values bound to
+// their fields are compared rather than the structs, so that a Meta field re-resolved at a later time, or a Lazy
+// field, whose state never compares equal, is the same if its value is. Lazy fields are compared by the value they
+// were bound from, without resolving it. Fields which cannot be read, such as unexported fields bound with setters,
+// are not compared. Values of any other type are compared with reflect.DeepEqual.
+func Equal(a, b interface{}) bool {
+	av, bv := reflect.ValueOf(a), reflect.ValueOf(b)
+	if av.Kind() != reflect.Ptr || !bv.IsValid() || av.Type() != bv.Type() || av.IsNil() || bv.IsNil() || av.Elem().Kind() != reflect.Struct {
+		return reflect.DeepEqual(a, b)
+	}
+	for _, f := range fields(structType(a)) {
+		x, ok := boundValue(f, av.Elem())
+		if !ok {
+			continue
+		}
+		if y, _ := boundValue(f, bv.Elem()); !reflect.DeepEqual(x, y) {
+			return false
+		}
+	}
+	return true
+}
+
+// optionalValue is the bound value of an Optional field, which differs from its zero value whether set or not.
+type optionalValue struct {
+	value interface{}
+	set   bool
+}
+
+// boundValue returns the value bound to f in values, an addressable struct, unwrapping wrapper fields,
+// and whether it could be read. Optional fields also record whether they were set, and Lazy fields
+// are the value they were bound from.
+func boundValue(f field, values reflect.Value) (interface{}, bool) {
+	v := values
+	for _, name := range f.path {
+		if v = v.FieldByName(name); !v.IsValid() {
+			return nil, false
+		}
+	}
+	if wrappedType(v.Type()) == v.Type() {
+		if !v.CanInterface() {
+			return nil, false
+		}
+		return v.Interface(), true
+	}
+	if !v.CanAddr() || !v.Addr().CanInterface() {
+		return nil, false
+	}
+	switch w := v.Addr().Interface().(type) {
+	case lazyField:
+		raw, _ := w.boundFrom()
+		return raw, true
+	case interface{ IsSet() bool }:
+		return optionalValue{value: reflect.ValueOf(w.(wrapperField).valuePtr()).Elem().Interface(), set: w.IsSet()}, true
+	case wrapperField:
+		return reflect.ValueOf(w.valuePtr()).Elem().Interface(), true
+	}
+	return nil, false
+}
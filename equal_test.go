@@ -0,0 +1,41 @@
+package config
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEqual(t *testing.T) {
+	t.Parallel()
+	type testConfig struct {
+		Port     Meta[int]
+		Retries  Optional[int]
+		Password Lazy[string]
+		Hosts    []string
+	}
+	bind := func(values map[string]string) *testConfig {
+		var c testConfig
+		WithValuePreProcessor(ValuePreProcessorFunc(func(key, value string) string {
+			return strings.TrimPrefix(value, "secret://")
+		})).FromSource(MapSource(values)).To(&c)
+		return &c
+	}
+	values := map[string]string{"PORT": "secret://80", "PASSWORD": "p", "HOSTS": "a b"}
+	a := bind(values)
+	time.Sleep(time.Millisecond)
+	b := bind(values)
+	assert.False(t, a.Port.Resolved.Equal(b.Port.Resolved))
+	assert.True(t, Equal(a, b), "resolution times and lazy state are not compared")
+
+	assert.False(t, Equal(a, bind(map[string]string{"PORT": "secret://81", "PASSWORD": "p", "HOSTS": "a b"})))
+	assert.False(t, Equal(a, bind(map[string]string{"PORT": "secret://80", "PASSWORD": "q", "HOSTS": "a b"})), "lazy values are compared unresolved")
+	assert.False(t, Equal(a, bind(map[string]string{"PORT": "secret://80", "PASSWORD": "p", "HOSTS": "a b", "RETRIES": "0"})), "a set Optional differs from an unset one")
+	assert.False(t, Equal(a, bind(map[string]string{"PORT": "secret://80", "PASSWORD": "p", "HOSTS": "a"})))
+
+	assert.True(t, Equal(1, 1))
+	assert.False(t, Equal(a, nil))
+	assert.False(t, Equal(a, &struct{}{}))
+}
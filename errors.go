@@ -0,0 +1,104 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Code identifies a class of config failure. Codes are stable, so alerts and runbooks can refer to them.
+type Code string
+
+const (
+	// CodeMissingKey means a required value is unset.
+	CodeMissingKey Code = "CFG001"
+	// CodeBadType means a value cannot be converted to its field's type.
+	CodeBadType Code = "CFG002"
+	// CodeSecretFetch means a value could not be resolved by a ValuePreProcessor, e.g. a secret could not be fetched.
+	CodeSecretFetch Code = "CFG003"
+	// CodeSourceLoad means a source, such as a file, could not be loaded.
+	CodeSourceLoad Code = "CFG004"
+	// CodeUnsupportedField means the target struct contains a field which cannot be bound.
+	CodeUnsupportedField Code = "CFG005"
+	// CodeUnknownKey means a key targeting the application is not bound by any field.
+	CodeUnknownKey Code = "CFG006"
+	// CodeTimeout means the startup budget set by WithStartupBudget ran out. See BudgetError.
+	CodeTimeout Code = "CFG007"
+)
+
+// Error is a config failure with a stable Code.
+// Panics raised by this package while loading or binding config carry an *Error.
+type Error struct {
+	code Code
+	key  string
+	err  error
+}
+
+func newError(code Code, key string, err error) *Error {
+	return &Error{code: code, key: key, err: err}
+}
+
+// Code returns the class of failure.
+func (e *Error) Code() Code {
+	return e.code
+}
+
+// Key returns the normalized key the failure relates to, if any.
+func (e *Error) Key() string {
+	return e.key
+}
+
+// Error returns the failure, prefixed with its code and key, e.g.
+//
+//	config: CFG002: port: cannot convert "eighty" to int: strconv.ParseInt: parsing "eighty": invalid syntax
+func (e *Error) Error() string {
+	if e.key == "" {
+		return fmt.Sprintf("config: %s: %v", e.code, e.err)
+	}
+	return fmt.Sprintf("config: %s: %s: %v", e.code, e.key, e.err)
+}
+
+// Unwrap returns the underlying error.
+func (e *Error) Unwrap() error {
+	return e.err
+}
+
+// CodeOf returns the Code of the first *Error in err's chain, or an empty Code if there is none.
+// As panics raised by this package carry an *Error, CodeOf can also be used on recovered values.
+func CodeOf(err interface{}) Code {
+	e, ok := err.(error)
+	if !ok {
+		return ""
+	}
+	var cfgErr *Error
+	if errors.As(e, &cfgErr) {
+		return cfgErr.code
+	}
+	return ""
+}
+
+// errorList is several errors, as returned by Bind when more than one field fails to convert.
+type errorList []error
+
+func (l errorList) Error() string {
+	msgs := make([]string, len(l))
+	for i, err := range l {
+		msgs[i] = err.Error()
+	}
+	return fmt.Sprintf("%d errors: %s", len(l), strings.Join(msgs, "; "))
+}
+
+// Unwrap returns the errors, so that from Go 1.20 errors.Is and errors.As match any of them.
+func (l errorList) Unwrap() []error {
+	return l
+}
+
+// As matches the first error assignable to target, as errors.As does not descend into lists before Go 1.20.
+func (l errorList) As(target interface{}) bool {
+	for _, err := range l {
+		if errors.As(err, target) {
+			return true
+		}
+	}
+	return false
+}
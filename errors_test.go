@@ -0,0 +1,51 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// recoverError calls f, returning the error it panics with.
+func recoverError(f func()) (err error) {
+	defer func() {
+		r := recover()
+		if e, ok := r.(error); ok {
+			err = e
+		} else if r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	f()
+	return nil
+}
+
+func TestError(t *testing.T) {
+	t.Parallel()
+	inner := errors.New("boom")
+
+	err := newError(CodeSecretFetch, "db__password", inner)
+	assert.EqualError(t, err, "config: CFG003: db__password: boom")
+	assert.Equal(t, CodeSecretFetch, err.Code())
+	assert.Equal(t, "db__password", err.Key())
+	assert.True(t, errors.Is(err, inner))
+
+	assert.EqualError(t, newError(CodeSourceLoad, "", inner), "config: CFG004: boom")
+
+	wrapped := fmt.Errorf("starting: %w", err)
+	assert.Equal(t, CodeSecretFetch, CodeOf(wrapped))
+	assert.Equal(t, Code(""), CodeOf(inner))
+	assert.Equal(t, Code(""), CodeOf("not an error"))
+	assert.Equal(t, Code(""), CodeOf(nil))
+}
+
+func Test_panicCodes(t *testing.T) {
+	t.Parallel()
+	assert.Equal(t, CodeSourceLoad, CodeOf(recoverError(func() { From("/does/not/exist") })))
+	assert.Equal(t, CodeUnsupportedField, CodeOf(recoverError(func() {
+		var c struct{ C chan int }
+		FromSource(MapSource(nil)).To(&c)
+	})))
+}
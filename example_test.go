@@ -110,3 +110,19 @@ func ExampleWithValuePreProcessor() {
 	// Output:
 	// BAR
 }
+
+func ExampleKeyFor() {
+	type Database struct {
+		Host string
+	}
+	type MyConfig struct {
+		Database Database `config:"DB"`
+	}
+
+	fmt.Println(config.KeyFor(&MyConfig{}, "Database", "Host"))
+	fmt.Println(strings.ToUpper(config.KeyFor(&MyConfig{}, "Database", "Host")))
+
+	// Output:
+	// db__host
+	// DB__HOST
+}
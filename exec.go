@@ -0,0 +1,103 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// ExecWithEnv runs argv with the process environment, after resolving its values with every resolver registered
+// with RegisterResolver, and waits for it to exit. This allows a small wrapper binary to pass secrets to
+// programs which know nothing of them, without writing them to an intermediate file:
+//
+//	// env-from-config ./server --port 8080
+//	func main() {
+//		config.RegisterResolver(aws.SecretsManagerScheme, p.SecretsManagerResolver())
+//		err := config.ExecWithEnv(context.Background(), os.Args[1:])
+//		var exitErr *exec.ExitError
+//		if errors.As(err, &exitErr) {
+//			os.Exit(exitErr.ExitCode())
+//		}
+//		if err != nil {
+//			log.Fatal(err)
+//		}
+//	}
+//
+// See Builder.ExecWithEnv.
+func ExecWithEnv(ctx context.Context, argv []string) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = recoveredError(r)
+		}
+	}()
+	r := NewResolvers(ctx)
+	for _, scheme := range ResolverSchemes() {
+		res, _ := registeredResolver(scheme)
+		r.Register(scheme, res)
+	}
+	return WithValuePreProcessor(r).FromEnv().ExecWithEnv(ctx, argv)
+}
+
+// ExecWithEnv runs argv with the current config state as its environment, and waits for it to exit.
+// Variables of the process environment are passed on as spelled, e.g. http_proxy stays lower case,
+// and with empty values, although with the value of their key in the current config state, e.g. a resolved secret.
+// Keys from other sources follow, as by Environ, which also describes opts.
+// The child shares the process's standard input, output and error, and is killed if ctx is done first.
+// It returns an *exec.ExitError if the child fails, whose ExitCode a wrapper binary can exit with in turn.
+func (c *Builder) ExecWithEnv(ctx context.Context, argv []string, opts ...EnvironOption) error {
+	if len(argv) == 0 {
+		return errors.New("config: no command to run")
+	}
+	cmd := exec.CommandContext(ctx, argv[0], argv[1:]...)
+	cmd.Env = c.execEnviron(opts...)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	return cmd.Run()
+}
+
+// execEnviron returns the environment of a child run by ExecWithEnv. A variable whose key has a different value
+// in the current config state is only replaced if the value came from another source than the process environment,
+// or was pre-processed from the variable's own value, so that variables whose keys only differ in case are kept apart.
+func (c *Builder) execEnviron(opts ...EnvironOption) []string {
+	var o environOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	environ := os.Environ()
+	values := make(map[string]map[string]bool)
+	for _, kv := range environ {
+		k, v, _ := strings.Cut(kv, "=")
+		key := NormalizeKey(k)
+		if values[key] == nil {
+			values[key] = make(map[string]bool)
+		}
+		values[key][v] = true
+	}
+
+	var env []string
+	for _, kv := range environ {
+		k, v, _ := strings.Cut(kv, "=")
+		key := NormalizeKey(k)
+		if !o.keep(key) {
+			continue
+		}
+		resolved := false
+		if literal, ok := c.lookupLiteral(key); ok && (literal == v || !values[key][literal]) {
+			v, _ = c.lookup(key)
+			resolved = c.resolved[key]
+		}
+		if o.redact(key) || (o.redactResolved && resolved) {
+			v = Redacted
+		}
+		env = append(env, k+"="+v)
+	}
+	for _, kv := range c.Environ(opts...) {
+		k, _, _ := strings.Cut(kv, "=")
+		if values[NormalizeKey(k)] == nil {
+			env = append(env, kv)
+		}
+	}
+	return env
+}
@@ -0,0 +1,74 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExecWithEnv(t *testing.T) {
+	RegisterResolver("test-exec", ResolverFunc(func(ctx context.Context, ref string) (string, error) {
+		return "secret-" + ref, nil
+	}))
+	os.Clearenv()
+	defer os.Clearenv()
+	require.NoError(t, os.Setenv("PATH", "/bin:/usr/bin"))
+	require.NoError(t, os.Setenv("PASSWORD", "test-exec://db"))
+
+	out := filepath.Join(t.TempDir(), "out")
+	require.NoError(t, ExecWithEnv(context.Background(), []string{"sh", "-c", `printf %s "$PASSWORD" > "$0"`, out}))
+	b, err := ioutil.ReadFile(out)
+	require.NoError(t, err)
+	assert.Equal(t, "secret-db", string(b))
+
+	require.NoError(t, os.Setenv("http_proxy", "proxy:3128"))
+	require.NoError(t, os.Setenv("Http_Proxy", "other:3128"))
+	require.NoError(t, os.Setenv("EMPTY", ""))
+	require.NoError(t, ExecWithEnv(context.Background(), []string{"sh", "-c", `env > "$0"`, out}))
+	b, err = ioutil.ReadFile(out)
+	require.NoError(t, err)
+	env := "\n" + string(b)
+	assert.Contains(t, env, "\nhttp_proxy=proxy:3128\n", "keys keep their case")
+	assert.Contains(t, env, "\nHttp_Proxy=other:3128\n", "keys differing in case are kept apart")
+	assert.NotContains(t, env, "HTTP_PROXY")
+	assert.Contains(t, env, "\nEMPTY=\n", "empty values are kept")
+	assert.Contains(t, env, "\nPASSWORD=secret-db\n")
+
+	err = ExecWithEnv(context.Background(), []string{"sh", "-c", "exit 3"})
+	var exitErr *exec.ExitError
+	require.True(t, errors.As(err, &exitErr))
+	assert.Equal(t, 3, exitErr.ExitCode())
+
+	assert.EqualError(t, ExecWithEnv(context.Background(), nil), "config: no command to run")
+}
+
+func TestBuilder_ExecWithEnv(t *testing.T) {
+	os.Clearenv()
+	defer os.Clearenv()
+	require.NoError(t, os.Setenv("no_proxy", "localhost"))
+	require.NoError(t, os.Setenv("HOST", "localhost"))
+
+	out := filepath.Join(t.TempDir(), "out")
+	b := FromEnv().FromSource(MapSource(map[string]string{"http_proxy": "proxy:3128", "TOKEN": "t", "HOST": "db.internal"}))
+	require.NoError(t, b.ExecWithEnv(context.Background(), []string{"/bin/sh", "-c", `env > "$0"`, out}, EnvironFilter(func(key string) bool {
+		return key != "token"
+	})))
+	env, err := ioutil.ReadFile(out)
+	require.NoError(t, err)
+	assert.Contains(t, string(env), "HTTP_PROXY=proxy:3128\n", "keys from other sources are upper cased")
+	assert.Contains(t, string(env), "no_proxy=localhost\n")
+	assert.Contains(t, string(env), "HOST=db.internal\n", "the current config state overrides the process environment")
+	assert.NotContains(t, string(env), "HOST=localhost")
+	assert.NotContains(t, string(env), "TOKEN")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.Error(t, b.ExecWithEnv(ctx, []string{"/bin/sh", "-c", "sleep 10"}), "the child is killed once ctx is done")
+}
@@ -0,0 +1,89 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// ValueExpander is implemented by ValuePreProcessors which can expand a single value into several keys,
+// e.g. a reference to every secret under a prefix, so dynamic sets of values need not be enumerated.
+// When a value is expanded, each expanded value is stored under the value's key, the struct delimiter and its name,
+// in place of the value itself. They are treated as resolved values, so are sealed by WithEncryptedSecrets
+// and redacted by EnvironRedactResolved, but are not re-resolved by CheckDrift.
+// Values in an environment layered with FromEnvLazy are not expanded.
+type ValueExpander interface {
+	ValuePreProcessor
+	// ExpandValue returns the values value expands to, keyed by name, and whether value is expandable at all.
+	ExpandValue(key, value string) (map[string]string, bool, error)
+}
+
+// Expander expands a reference into several values, keyed by name.
+type Expander interface {
+	// Expand returns the values ref refers to.
+	Expand(ctx context.Context, ref string) (map[string]string, error)
+}
+
+// ExpanderFunc adapts a function to an Expander.
+type ExpanderFunc func(ctx context.Context, ref string) (map[string]string, error)
+
+// Expand calls f.
+func (f ExpanderFunc) Expand(ctx context.Context, ref string) (map[string]string, error) {
+	return f(ctx, ref)
+}
+
+// RegisterExpander makes values of the form scheme://ref expand with e, returning the Resolvers.
+// Registering a scheme again replaces its Expander.
+func (r *Resolvers) RegisterExpander(scheme string, e Expander) *Resolvers {
+	r.expanders[scheme] = e
+	return r
+}
+
+// ExpandValue expands value if it has a scheme registered with RegisterExpander.
+func (r *Resolvers) ExpandValue(key, value string) (map[string]string, bool, error) {
+	scheme, ref, ok := splitReference(value)
+	if !ok {
+		return nil, false, nil
+	}
+	e, ok := r.expanders[scheme]
+	if !ok {
+		return nil, false, nil
+	}
+	values, err := e.Expand(r.ctx, ref)
+	if err != nil {
+		return nil, true, fmt.Errorf("unable to expand %s: %w", value, err)
+	}
+	return values, true, nil
+}
+
+// expandValue expands value with the ValuePreProcessor, if it is a ValueExpander,
+// returning the expanded keys and values.
+// It panics with an *Error with CodeSecretFetch if expansion fails.
+func (c *Builder) expandValue(key, value string) (map[string]string, bool) {
+	e, ok := c.valuePreProcessor.(ValueExpander)
+	// Values of literal fields, see Declare, are never pre-processed, so never expanded either.
+	if !ok || c.literal[key] {
+		return nil, false
+	}
+	var values map[string]string
+	var err error
+	c.withinBudget(key, func() {
+		values, ok, err = e.ExpandValue(key, value)
+	})
+	if err != nil {
+		panic(newError(CodeSecretFetch, key, err))
+	}
+	if !ok {
+		return nil, false
+	}
+	expanded := make(map[string]string, len(values))
+	for name, v := range values {
+		if name = NormalizeKey(strings.Trim(name, "/")); name != "" && v != "" {
+			expanded[key+c.structDelim+strings.ReplaceAll(name, "/", c.structDelim)] = v
+		}
+	}
+	return expanded, true
+}
+
+// compile time assertion
+var _ ValueExpander = (*Resolvers)(nil)
@@ -0,0 +1,71 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuilder_expandValue(t *testing.T) {
+	t.Parallel()
+	partners := map[string]string{
+		"partners/acme":       "acme-token",
+		"partners/Globex/api": "globex-token",
+		"partners/empty":      "",
+		"other/initech":       "initech-token",
+	}
+	r := NewResolvers(context.Background()).
+		Register("secret", ResolverFunc(func(ctx context.Context, ref string) (string, error) {
+			return partners[ref], nil
+		})).
+		RegisterExpander("secrets", ExpanderFunc(func(ctx context.Context, ref string) (map[string]string, error) {
+			prefix := strings.TrimSuffix(ref, "*")
+			matches := make(map[string]string)
+			for name, v := range partners {
+				if strings.HasPrefix(name, prefix) {
+					matches[strings.TrimPrefix(name, prefix)] = v
+				}
+			}
+			return matches, nil
+		})).
+		RegisterExpander("broken", ExpanderFunc(func(ctx context.Context, ref string) (map[string]string, error) {
+			return nil, errors.New("access denied")
+		}))
+	assert.Equal(t, []string{"broken", "secret", "secrets"}, r.Schemes())
+
+	type testConfig struct {
+		Partners struct {
+			Acme   string
+			Tokens map[string]string `config:",remain"`
+		}
+		Single string
+	}
+	b := WithValuePreProcessor(r).WithEncryptedSecrets().FromSource(MapSource(map[string]string{
+		"PARTNERS": "secrets://partners/*",
+		"SINGLE":   "secret://other/initech",
+	}))
+	var got testConfig
+	b.To(&got)
+	assert.Equal(t, "acme-token", got.Partners.Acme)
+	assert.Equal(t, map[string]string{"globex__api": "globex-token"}, got.Partners.Tokens)
+	assert.Equal(t, "initech-token", got.Single)
+	assert.Equal(t, []string{
+		"PARTNERS__ACME=" + Redacted,
+		"PARTNERS__GLOBEX__API=" + Redacted,
+		"SINGLE=" + Redacted,
+	}, b.Environ(EnvironRedactResolved()))
+	assert.True(t, b.sealed["partners__acme"], "expanded values should be sealed")
+
+	drifts, err := b.CheckDrift(context.Background())
+	require.NoError(t, err)
+	assert.Empty(t, drifts)
+
+	err = recoverError(func() {
+		WithValuePreProcessor(r).FromSource(MapSource(map[string]string{"PARTNERS": "broken://partners/*"}))
+	})
+	assert.EqualError(t, err, "config: CFG003: partners: unable to expand broken://partners/*: access denied")
+}
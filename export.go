@@ -0,0 +1,207 @@
+package config
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// WriteDotenv writes a skeleton .env file for the environment variables target, a struct or struct pointer,
+// is bound from: each key after prefix, preceded by a comment with its description and type, and set to its default.
+// As with WriteHelp, defaults are the non zero values of target's fields.
+// Keys accepting any key under a prefix, see Keys, are written as comments.
+func WriteDotenv(w io.Writer, target interface{}, prefix string) error {
+	values := reflect.Indirect(reflect.ValueOf(target))
+	ew := &errWriter{w: w}
+	ew.printf("# Environment variables, generated by config.WriteDotenv.\n")
+	for _, f := range fields(structType(target)) {
+		key := exportKey(f, prefix)
+		if f.remain || f.rawMap {
+			ew.printf("\n# %s: any key under this prefix\n", key)
+			continue
+		}
+		comment := helpTypeName(f.Type)
+		if description := exportDescription(f); description != "" {
+			comment = description + " (" + comment + ")"
+		}
+		ew.printf("\n%s", exportComment(comment, "", "# "))
+		def, _ := envDefault(f, values)
+		ew.printf("%s=%s\n", key, dotenvQuote(def))
+	}
+	return ew.err
+}
+
+// WriteHelmValues writes a skeleton Helm values.yaml, with an env mapping of the environment variables target,
+// a struct or struct pointer, is bound from, to their defaults, for a chart's templates to pass to its containers:
+//
+//	env:
+//	{{- range $key, $value := .Values.env }}
+//	  - name: {{ $key }}
+//	    value: {{ $value | quote }}
+//	{{- end }}
+//
+// Keys are written after prefix, preceded by their descriptions. See WriteDotenv.
+func WriteHelmValues(w io.Writer, target interface{}, prefix string) error {
+	ew := &errWriter{w: w}
+	ew.printf("# Generated by config.WriteHelmValues.\nenv:\n")
+	writeYAMLEnvironment(ew, target, prefix, strconv.Quote)
+	return ew.err
+}
+
+// WriteComposeEnvironment writes a docker-compose environment stanza, mapping the environment variables target,
+// a struct or struct pointer, is bound from to their defaults, to paste into a service.
+// Keys are written after prefix, preceded by their descriptions. See WriteDotenv.
+func WriteComposeEnvironment(w io.Writer, target interface{}, prefix string) error {
+	ew := &errWriter{w: w}
+	ew.printf("# Generated by config.WriteComposeEnvironment.\nenvironment:\n")
+	writeYAMLEnvironment(ew, target, prefix, func(s string) string {
+		// compose interpolates variables in values, so literal dollars are doubled.
+		return strconv.Quote(strings.ReplaceAll(s, "$", "$$"))
+	})
+	return ew.err
+}
+
+func writeYAMLEnvironment(ew *errWriter, target interface{}, prefix string, quote func(string) string) {
+	values := reflect.Indirect(reflect.ValueOf(target))
+	for _, f := range fields(structType(target)) {
+		key := exportKey(f, prefix)
+		if f.remain || f.rawMap {
+			ew.printf("  # %s: any key under this prefix\n", key)
+			continue
+		}
+		ew.printf("%s", exportComment(exportDescription(f), "  ", "# "))
+		def, _ := envDefault(f, values)
+		ew.printf("  %s: %s\n", key, quote(def))
+	}
+}
+
+// WriteTerraformVariables writes a Terraform variable block for each environment variable target,
+// a struct or struct pointer, is bound from, for modules deploying the application to declare its settings.
+// Variables are named after the lower case key, after prefix, e.g. app_database__host, and typed
+// bool, number, string or a list of them. Variables default to the non zero values of target's fields,
+// or to null, so that every variable is optional, as environment variables are.
+// Keys accepting any key under a prefix, see Keys, are written as comments.
+func WriteTerraformVariables(w io.Writer, target interface{}, prefix string) error {
+	values := reflect.Indirect(reflect.ValueOf(target))
+	ew := &errWriter{w: w}
+	ew.printf("# Generated by config.WriteTerraformVariables.\n")
+	for _, f := range fields(structType(target)) {
+		if f.remain || f.rawMap {
+			ew.printf("\n# %s: any key under this prefix\n", exportKey(f, prefix))
+			continue
+		}
+		octal := hasTagOption(f.StructField, tagOptionOctal)
+		def := "null"
+		if v, ok := defaultValue(f, values); ok {
+			def = terraformValue(v, octal)
+		}
+		ew.printf("\nvariable %q {\n", strings.ToLower(prefix+f.key))
+		ew.printf("  type        = %s\n", terraformType(f.Type, octal))
+		if description := exportDescription(f); description != "" {
+			ew.printf("  description = %s\n", hclQuote(description))
+		}
+		ew.printf("  default     = %s\n}\n", def)
+	}
+	return ew.err
+}
+
+// exportKey returns the environment variable f is bound from, after prefix.
+func exportKey(f field, prefix string) string {
+	switch {
+	case f.remain:
+		return prefix + strings.ToUpper(f.key) + manifestWildcard
+	case f.rawMap:
+		return prefix + strings.ToUpper(f.key+structDelim) + manifestWildcard
+	}
+	return prefix + strings.ToUpper(f.key)
+}
+
+func exportDescription(f field) string {
+	return strings.TrimSpace(f.Tag.Get(helpTagKey))
+}
+
+// exportComment returns text as comment lines, each starting with indent and marker, or nothing if text is empty.
+func exportComment(text, indent, marker string) string {
+	if text == "" {
+		return ""
+	}
+	var b strings.Builder
+	for _, line := range strings.Split(text, "\n") {
+		b.WriteString(indent + marker + strings.TrimSpace(line) + "\n")
+	}
+	return b.String()
+}
+
+// envDefault returns the value of f in values as an environment variable would hold it, if it is not zero.
+func envDefault(f field, values reflect.Value) (string, bool) {
+	v, ok := defaultValue(f, values)
+	if !ok {
+		return "", false
+	}
+	return envString(v, hasTagOption(f.StructField, tagOptionOctal)), true
+}
+
+func envString(v reflect.Value, octal bool) string {
+	s := helpFormat(v, octal)
+	if unquoted, err := strconv.Unquote(s); err == nil {
+		return unquoted
+	}
+	return s
+}
+
+// dotenvQuote quotes s for a dotenv file if it would not be read back as is, see parseDotenv.
+func dotenvQuote(s string) string {
+	if s == "" || !strings.ContainsAny(s, " \t\r\n#\"'\\$") {
+		return s
+	}
+	r := strings.NewReplacer(`\`, `\\`, `"`, `\"`, `$`, `\$`, "\n", `\n`, "\r", `\r`, "\t", `\t`)
+	return `"` + r.Replace(s) + `"`
+}
+
+func terraformType(t reflect.Type, octal bool) string {
+	_, flags := registeredBitFlags(t)
+	switch {
+	case t == durationType || t == fileModeType || octal || flags || reflect.PtrTo(t).Implements(textUnmarshalerType):
+		return "string"
+	case t.Kind() == reflect.Bool:
+		return "bool"
+	case t.Kind() >= reflect.Int && t.Kind() <= reflect.Float64:
+		return "number"
+	case t.Kind() == reflect.Slice:
+		return "list(" + terraformType(t.Elem(), octal) + ")"
+	}
+	return "string"
+}
+
+func terraformValue(v reflect.Value, octal bool) string {
+	switch terraformType(v.Type(), octal) {
+	case "bool", "number":
+		return fmt.Sprint(v.Interface())
+	case "string":
+		return hclQuote(envString(v, octal))
+	}
+	items := make([]string, v.Len())
+	for i := range items {
+		items[i] = terraformValue(v.Index(i), octal)
+	}
+	return "[" + strings.Join(items, ", ") + "]"
+}
+
+// hclQuote quotes s as an HCL string literal, escaping template sequences.
+func hclQuote(s string) string {
+	return strings.NewReplacer("${", "$${", "%{", "%%{").Replace(strconv.Quote(s))
+}
+
+// errWriter writes to w until a write fails, keeping the first error.
+type errWriter struct {
+	w   io.Writer
+	err error
+}
+
+func (ew *errWriter) printf(format string, args ...interface{}) {
+	if ew.err == nil {
+		_, ew.err = fmt.Fprintf(ew.w, format, args...)
+	}
+}
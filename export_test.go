@@ -0,0 +1,173 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type exportTestConfig struct {
+	Port     int           `help:"port to listen on"`
+	Timeout  time.Duration `help:"request timeout"`
+	Weights  []float64
+	Umask    int `config:",octal"`
+	Debug    bool
+	Database struct {
+		Host     string `help:"database host,\nor a unix socket"`
+		Password string
+	}
+	Extra map[string]string `config:",remain"`
+}
+
+func exportTestDefaults() exportTestConfig {
+	c := exportTestConfig{Port: 8080, Timeout: 5 * time.Second, Weights: []float64{0.5, 1}, Umask: 0o27}
+	c.Database.Host = "db host"
+	c.Database.Password = `p#ss"${x}`
+	return c
+}
+
+func TestWriteDotenv(t *testing.T) {
+	t.Parallel()
+	c := exportTestDefaults()
+	var buf bytes.Buffer
+	require.NoError(t, WriteDotenv(&buf, &c, "APP_"))
+	assert.Equal(t, `# Environment variables, generated by config.WriteDotenv.
+
+# port to listen on (int)
+APP_PORT=8080
+
+# request timeout (duration)
+APP_TIMEOUT=5s
+
+# []float64
+APP_WEIGHTS="0.5 1"
+
+# int
+APP_UMASK=027
+
+# bool
+APP_DEBUG=
+
+# database host,
+# or a unix socket (string)
+APP_DATABASE__HOST="db host"
+
+# string
+APP_DATABASE__PASSWORD="p#ss\"\${x}"
+
+# APP_*: any key under this prefix
+`, buf.String())
+
+	var got exportTestConfig
+	FromSource(prefixStripped(t, buf.String(), "APP_")).To(&got)
+	assert.Equal(t, c, got, "values are read back as written")
+}
+
+func TestWriteHelmValues(t *testing.T) {
+	t.Parallel()
+	c := exportTestDefaults()
+	var buf bytes.Buffer
+	require.NoError(t, WriteHelmValues(&buf, c, ""))
+	assert.Equal(t, `# Generated by config.WriteHelmValues.
+env:
+  # port to listen on
+  PORT: "8080"
+  # request timeout
+  TIMEOUT: "5s"
+  WEIGHTS: "0.5 1"
+  UMASK: "027"
+  DEBUG: ""
+  # database host,
+  # or a unix socket
+  DATABASE__HOST: "db host"
+  DATABASE__PASSWORD: "p#ss\"${x}"
+  # *: any key under this prefix
+`, buf.String())
+
+	var values struct {
+		Env exportTestConfig
+	}
+	FromReader(&buf, FormatYAML).To(&values)
+	assert.Equal(t, c, values.Env, "values are read back as written")
+}
+
+func TestWriteComposeEnvironment(t *testing.T) {
+	t.Parallel()
+	c := exportTestDefaults()
+	var buf bytes.Buffer
+	require.NoError(t, WriteComposeEnvironment(&buf, c, "APP_"))
+	assert.Contains(t, buf.String(), "environment:\n  # port to listen on\n  APP_PORT: \"8080\"\n")
+	assert.Contains(t, buf.String(), "  APP_DATABASE__PASSWORD: \"p#ss\\\"$${x}\"\n", "dollars are escaped from interpolation")
+}
+
+func TestWriteTerraformVariables(t *testing.T) {
+	t.Parallel()
+	c := exportTestDefaults()
+	var buf bytes.Buffer
+	require.NoError(t, WriteTerraformVariables(&buf, &c, "APP_"))
+	assert.Equal(t, `# Generated by config.WriteTerraformVariables.
+
+variable "app_port" {
+  type        = number
+  description = "port to listen on"
+  default     = 8080
+}
+
+variable "app_timeout" {
+  type        = string
+  description = "request timeout"
+  default     = "5s"
+}
+
+variable "app_weights" {
+  type        = list(number)
+  default     = [0.5, 1]
+}
+
+variable "app_umask" {
+  type        = string
+  default     = "027"
+}
+
+variable "app_debug" {
+  type        = bool
+  default     = null
+}
+
+variable "app_database__host" {
+  type        = string
+  description = "database host,\nor a unix socket"
+  default     = "db host"
+}
+
+variable "app_database__password" {
+  type        = string
+  default     = "p#ss\"$${x}"
+}
+
+# APP_*: any key under this prefix
+`, buf.String())
+
+	require.Error(t, WriteTerraformVariables(failingWriter{}, &c, ""))
+}
+
+// prefixStripped returns a Source of the dotenv file content, with prefix stripped from its keys.
+func prefixStripped(t *testing.T, content, prefix string) Source {
+	lines, err := parseDotenv(strings.Split(content, "\n"))
+	require.NoError(t, err)
+	for i, line := range lines {
+		lines[i] = strings.TrimPrefix(line, prefix)
+	}
+	return MapSource(stringsToMap(lines))
+}
+
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) {
+	return 0, errors.New("disk full")
+}
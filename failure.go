@@ -0,0 +1,131 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"reflect"
+	"time"
+)
+
+// FailureReport describes why config failed to load or bind, as written by WithFailureReport, for orchestrators
+// and wrappers to surface, e.g. as Nomad or Kubernetes events. It holds keys, never values, though conversion
+// errors quote the value which failed to convert, unless it is a secret: the messages of errors relating to
+// values resolved by the ValuePreProcessor, or fields tagged `config:",secret"`, are Redacted.
+type FailureReport struct {
+	Time   time.Time      `json:"time"`
+	Errors []FailureError `json:"errors"`
+	// MissingKeys holds the keys of fields tagged `config:",required"` which are unset.
+	MissingKeys []string `json:"missing_keys,omitempty"`
+	// InvalidKeys holds the keys whose values could not be converted to their field's type.
+	InvalidKeys []string `json:"invalid_keys,omitempty"`
+	// Sources holds the outcome of loading each source, in the order they were merged.
+	Sources []SourceStatus `json:"sources"`
+}
+
+// FailureError is an error in a FailureReport.
+type FailureError struct {
+	Code Code `json:"code"`
+	// Key is the normalized key the error relates to, if any.
+	Key     string `json:"key,omitempty"`
+	Message string `json:"message"`
+}
+
+// SourceStatus is the outcome of loading a source.
+type SourceStatus struct {
+	// Source describes the source, as a Meta's Source does, e.g. "environment".
+	Source string `json:"source"`
+	// Keys is the number of keys loaded from the source.
+	Keys int `json:"keys"`
+	// Error is why the source failed to load, if it did.
+	Error string `json:"error,omitempty"`
+}
+
+// WithFailureReport creates a new builder which writes a FailureReport to path if config fails to load or bind.
+func WithFailureReport(path string) *Builder {
+	return newBuilder().WithFailureReport(path)
+}
+
+// WithFailureReport writes a FailureReport as JSON to path if a source merged after it fails to load, To panics
+// or Bind returns an error, returning the Builder. path may be the Kubernetes termination message path,
+// /dev/termination-log, for the report to show in the pod's status, or a descriptor a wrapper reads, e.g. /dev/fd/3:
+//
+//	err := config.WithFailureReport("/dev/termination-log").FromEnv().Bind(&c)
+//
+// The report is written on a best effort basis, readable only by its owner: failing to write it does not change
+// the error.
+func (c *Builder) WithFailureReport(path string) *Builder {
+	c.failureReport = path
+	return c
+}
+
+// loaded records that s was merged, with values, for Refresh and failure reports.
+func (c *Builder) loaded(s Source, values map[string]string) {
+	c.sources = append(c.sources, s)
+	c.sourceStatuses = append(c.sourceStatuses, SourceStatus{Source: sourceName(s), Keys: len(values)})
+}
+
+// loadFailed records that s, if any, failed to load with err, writing a failure report if one is configured,
+// and returns err.
+func (c *Builder) loadFailed(s Source, err *Error) *Error {
+	if s != nil {
+		c.sourceStatuses = append(c.sourceStatuses, SourceStatus{Source: sourceName(s), Error: err.Error()})
+	}
+	c.writeFailureReport(nil, err)
+	return err
+}
+
+// reportPanic writes a failure report for a panic binding target, if one is configured, and panics again.
+// It must be deferred.
+func (c *Builder) reportPanic(target interface{}) {
+	if c.failureReport == "" {
+		return
+	}
+	if r := recover(); r != nil {
+		c.writeFailureReport(target, recoveredError(r))
+		panic(r)
+	}
+}
+
+// writeFailureReport writes a FailureReport of err, raised loading or binding target, which may be nil,
+// to the path set by WithFailureReport, if any.
+func (c *Builder) writeFailureReport(target interface{}, err error) {
+	if c.failureReport == "" {
+		return
+	}
+	r := FailureReport{Time: c.now().UTC(), Sources: c.sourceStatuses}
+	errs := []error{err}
+	if l, ok := err.(errorList); ok {
+		errs = l
+	}
+	t := reflect.TypeOf(target)
+	bound := t != nil && t.Kind() == reflect.Ptr && t.Elem().Kind() == reflect.Struct && CodeOf(err) != CodeUnsupportedField
+	secret := make(map[string]bool)
+	if bound {
+		for _, f := range fields(structType(target)) {
+			secret[f.key] = hasTagOption(f.StructField, tagOptionSecret)
+		}
+	}
+	for _, err := range errs {
+		fe := FailureError{Code: CodeOf(err), Message: err.Error()}
+		var e *Error
+		if errors.As(err, &e) {
+			fe.Key = e.Key()
+		}
+		if fe.Key != "" && (secret[fe.Key] || c.resolved[fe.Key]) {
+			fe.Message = newError(fe.Code, fe.Key, errors.New(Redacted)).Error()
+		}
+		if fe.Code == CodeBadType && fe.Key != "" {
+			r.InvalidKeys = append(r.InvalidKeys, fe.Key)
+		}
+		r.Errors = append(r.Errors, fe)
+	}
+	if bound {
+		r.MissingKeys = c.missingKeys(target)
+	}
+	b, jsonErr := json.MarshalIndent(r, "", "  ")
+	if jsonErr != nil {
+		return
+	}
+	_ = os.WriteFile(c.failureReport, append(b, '\n'), 0o600)
+}
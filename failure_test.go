@@ -0,0 +1,92 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func readFailureReport(t *testing.T, path string) FailureReport {
+	b, err := os.ReadFile(path)
+	require.NoError(t, err)
+	var r FailureReport
+	require.NoError(t, json.Unmarshal(b, &r))
+	return r
+}
+
+func TestBuilder_WithFailureReport(t *testing.T) {
+	t.Parallel()
+	type testConfig struct {
+		Port int
+		User string `config:",required"`
+	}
+	start := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	remote := namedSource{name: "remote", Source: MapSource(map[string]string{"PORT": "eighty"})}
+
+	path := filepath.Join(t.TempDir(), "failure.json")
+	var c testConfig
+	err := WithClock(NewManualClock(start)).WithFailureReport(path).FromSource(remote).Bind(&c)
+	require.Error(t, err)
+	r := readFailureReport(t, path)
+	assert.Equal(t, start, r.Time)
+	assert.Equal(t, []string{"port"}, r.InvalidKeys)
+	assert.Equal(t, []string{"user"}, r.MissingKeys)
+	assert.Equal(t, []SourceStatus{{Source: "remote", Keys: 1}}, r.Sources)
+	require.Len(t, r.Errors, 2)
+	assert.Equal(t, FailureError{Code: CodeBadType, Key: "port", Message: r.Errors[0].Message}, r.Errors[0])
+	assert.Equal(t, FailureError{Code: CodeMissingKey, Key: "user", Message: "config: CFG001: user: required keys are unset: USER"}, r.Errors[1])
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm(), "the report is only readable by its owner")
+
+	path = filepath.Join(t.TempDir(), "failure.json")
+	err = recoverError(func() { WithFailureReport(path).FromSource(MapSource(map[string]string{"PORT": "80"})).To(&c) })
+	assert.Equal(t, CodeMissingKey, CodeOf(err), "To still panics")
+	r = readFailureReport(t, path)
+	assert.Equal(t, []string{"user"}, r.MissingKeys)
+	assert.Empty(t, r.InvalidKeys)
+
+	path = filepath.Join(t.TempDir(), "failure.json")
+	failing := namedSource{name: "consul", Source: SourceFunc(func() (map[string]string, error) {
+		return nil, errors.New("connection refused")
+	})}
+	err = recoverError(func() { WithFailureReport(path).FromSource(remote).FromSource(failing) })
+	assert.Equal(t, CodeSourceLoad, CodeOf(err))
+	r = readFailureReport(t, path)
+	assert.Equal(t, []SourceStatus{
+		{Source: "remote", Keys: 1},
+		{Source: "consul", Error: "config: CFG004: unable to load source: connection refused"},
+	}, r.Sources)
+	assert.Equal(t, []FailureError{{Code: CodeSourceLoad, Message: "config: CFG004: unable to load source: connection refused"}}, r.Errors)
+
+	type secretConfig struct {
+		Pin     int `config:",secret"`
+		Timeout int
+	}
+	resolve := ValuePreProcessorFunc(func(key, value string) string { return strings.TrimPrefix(value, "secret://") })
+	path = filepath.Join(t.TempDir(), "failure.json")
+	values := MapSource(map[string]string{"PIN": "s3cr3t", "TIMEOUT": "secret://hunter2"})
+	require.Error(t, WithFailureReport(path).WithValuePreProcessor(resolve).FromSource(values).Bind(&secretConfig{}))
+	b, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.NotContains(t, string(b), "s3cr3t", "errors for secret fields are redacted")
+	assert.NotContains(t, string(b), "hunter2", "errors for resolved values are redacted")
+	r = readFailureReport(t, path)
+	assert.Equal(t, []FailureError{
+		{Code: CodeBadType, Key: "pin", Message: "config: CFG002: pin: " + Redacted},
+		{Code: CodeBadType, Key: "timeout", Message: "config: CFG002: timeout: " + Redacted},
+	}, r.Errors)
+	assert.Equal(t, []string{"pin", "timeout"}, r.InvalidKeys)
+
+	path = filepath.Join(t.TempDir(), "failure.json")
+	require.NoError(t, WithFailureReport(path).FromSource(MapSource(map[string]string{"USER": "u"})).Bind(&c))
+	_, err = os.Stat(path)
+	assert.True(t, os.IsNotExist(err), "nothing is written on success")
+}
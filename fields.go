@@ -0,0 +1,111 @@
+package config
+
+import (
+	"encoding"
+	"reflect"
+)
+
+var textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+
+// wrapperField is implemented by pointers to generic field types wrapping the value they are bound to,
+// Meta, Optional and Lazy, so that they can be recognised whatever their type parameter.
+type wrapperField interface {
+	// valuePtr returns a pointer to the wrapped value, which is bound as a field of its type would be.
+	// It must be the wrapper's first field.
+	valuePtr() interface{}
+	// bound is called once the wrapped value is bound from key.
+	bound(c *Builder, key string)
+}
+
+var wrapperFieldType = reflect.TypeOf((*wrapperField)(nil)).Elem()
+
+// wrappedType returns the type of the value wrapped by t if it is a wrapper field type, or t otherwise.
+func wrappedType(t reflect.Type) reflect.Type {
+	if t.Kind() == reflect.Struct && reflect.PtrTo(t).Implements(wrapperFieldType) {
+		return t.Field(0).Type
+	}
+	return t
+}
+
+// field is a field of a target struct which is bound to a single key.
+type field struct {
+	reflect.StructField
+	// key is the normalized key the field is bound from.
+	key string
+	// path is the Go field names leading to the field, from the target struct.
+	path []string
+	// prefixes holds the key prefix of each struct along path, starting with the target struct's empty prefix.
+	prefixes []string
+	// remain is set for map fields tagged `config:",remain"`, which are bound from every unbound key under their
+	// struct's prefix. Their key is the prefix.
+	remain bool
+	// rawMap is set for map fields tagged `config:",raw"`, which are bound from every key under their own key.
+	rawMap bool
+}
+
+// fields returns the fields of structType bound by To, descending into nested structs,
+// in declaration order. Ignored fields are omitted.
+func fields(structType reflect.Type) []field {
+	return appendFields(nil, structType, nil, []string{""})
+}
+
+func appendFields(fs []field, structType reflect.Type, path, prefixes []string) []field {
+	prefix := prefixes[len(prefixes)-1]
+	for i := 0; i < structType.NumField(); i++ {
+		fieldType := structType.Field(i)
+		fieldType.Type = wrappedType(fieldType.Type)
+		possibleKey := getKey(fieldType, prefix)
+		if possibleKey == nil {
+			continue
+		}
+		fieldPath := append(append([]string(nil), path...), fieldType.Name)
+
+		switch {
+		case hasTagOption(fieldType, tagOptionRemain):
+			fs = append(fs, field{StructField: fieldType, key: prefix, path: fieldPath, prefixes: prefixes, remain: true})
+		case hasTagOption(fieldType, tagOptionRaw) && fieldType.Type.Kind() == reflect.Map:
+			fs = append(fs, field{StructField: fieldType, key: *possibleKey, path: fieldPath, prefixes: prefixes, rawMap: true})
+		case isNestedStruct(fieldType.Type):
+			nestedPrefixes := append(append([]string(nil), prefixes...), *possibleKey+structDelim)
+			fs = appendFields(fs, fieldType.Type, fieldPath, nestedPrefixes)
+		default:
+			fs = append(fs, field{StructField: fieldType, key: *possibleKey, path: fieldPath, prefixes: prefixes})
+		}
+	}
+	return fs
+}
+
+// isNestedStruct reports whether t is bound as a nested config, rather than a single value.
+func isNestedStruct(t reflect.Type) bool {
+	return t.Kind() == reflect.Struct && !reflect.PtrTo(t).Implements(textUnmarshalerType)
+}
+
+// structType returns the struct type of target, a struct or struct pointer.
+func structType(target interface{}) reflect.Type {
+	t := reflect.TypeOf(target)
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t
+}
+
+// siblingKeys returns the keys bound by the other fields of remain's struct, in the form setRemain expects:
+// nested structs are represented by their prefix, ending in the struct delimiter.
+func siblingKeys(fs []field, remain field) []string {
+	depth := len(remain.prefixes)
+	parent := remain.prefixes[depth-1]
+	var bound []string
+	for _, f := range fs {
+		if f.remain || len(f.prefixes) < depth || f.prefixes[depth-1] != parent {
+			continue
+		}
+		if len(f.prefixes) == depth && f.rawMap {
+			bound = append(bound, f.key+structDelim)
+		} else if len(f.prefixes) == depth {
+			bound = append(bound, f.key)
+		} else {
+			bound = append(bound, f.prefixes[depth])
+		}
+	}
+	return bound
+}
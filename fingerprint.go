@@ -0,0 +1,23 @@
+package config
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+)
+
+// Fingerprint returns a stable hash of the current config state, suitable for emitting as a metric label
+// to check that a fleet is running with consistent config.
+// Values resolved by the ValuePreProcessor are redacted before hashing, so secrets neither leak into
+// the fingerprint nor change it when rotated.
+// opts are applied as they are by Environ. As the environment typically contains per host values such as HOSTNAME,
+// EnvironFilter should be used to limit the fingerprint to the application's own keys.
+//
+//	fp := b.Fingerprint(config.EnvironFilter(func(key string) bool { return strings.HasPrefix(key, "myapp__") }))
+func (c *Builder) Fingerprint(opts ...EnvironOption) string {
+	h := sha256.New()
+	for _, kv := range c.Environ(append(opts, EnvironRedactResolved())...) {
+		h.Write([]byte(kv))
+		h.Write([]byte{0}) // values may contain newlines, but never NUL
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
@@ -0,0 +1,36 @@
+package config
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuilder_Fingerprint(t *testing.T) {
+	os.Clearenv()
+	defer os.Clearenv()
+	require.NoError(t, os.Setenv("APP__PASSWORD", "secret://password"))
+	require.NoError(t, os.Setenv("APP__HOST", "localhost"))
+	require.NoError(t, os.Setenv("HOSTNAME", "a"))
+
+	appOnly := EnvironFilter(func(key string) bool { return strings.HasPrefix(key, "app__") })
+	fingerprint := func() string {
+		return WithValuePreProcessor(&secretPreProcessor{}).FromEnv().Fingerprint(appOnly)
+	}
+
+	base := fingerprint()
+	assert.Len(t, base, 64)
+	assert.Equal(t, base, fingerprint(), "fingerprint should be stable")
+
+	require.NoError(t, os.Setenv("HOSTNAME", "b"))
+	assert.Equal(t, base, fingerprint(), "filtered keys should not affect the fingerprint")
+
+	require.NoError(t, os.Setenv("APP__PASSWORD", "secret://rotated"))
+	assert.Equal(t, base, fingerprint(), "resolved secrets should not affect the fingerprint")
+
+	require.NoError(t, os.Setenv("APP__HOST", "remote"))
+	assert.NotEqual(t, base, fingerprint())
+}
@@ -0,0 +1,132 @@
+package config
+
+import (
+	"flag"
+	"reflect"
+	"strings"
+)
+
+// Flag describes the command line flag for a key, for adapters registering config fields with flag libraries.
+type Flag struct {
+	// Key is the normalized key the flag sets.
+	Key string
+	// Name is the flag's name, see FlagName.
+	Name string
+	// Type names the field's type, as in WriteHelp, e.g. int, duration or []string.
+	Type string
+	// Usage is the field's help tag.
+	Usage string
+	// Default is the field's value in the target passed to Flags, formatted as in WriteHelp, or empty if it is zero.
+	Default string
+	// Bool is set for bool fields, whose flags need no value.
+	Bool bool
+}
+
+// Flags returns a Flag for each field target, a struct or struct pointer, is bound from, in declaration order.
+// Fields tagged `config:",remain"` and map fields tagged `config:",raw"` have no flags.
+// Like WriteHelp, defaults are the non zero values of target's fields.
+func Flags(target interface{}) []Flag {
+	values := reflect.Indirect(reflect.ValueOf(target))
+	var flags []Flag
+	for _, f := range fields(structType(target)) {
+		if f.remain || f.rawMap {
+			continue
+		}
+		def, _ := helpDefault(f, values)
+		flags = append(flags, Flag{
+			Key:     f.key,
+			Name:    FlagName(f.key),
+			Type:    helpTypeName(f.Type),
+			Usage:   strings.TrimSpace(f.Tag.Get(helpTagKey)),
+			Default: def,
+			Bool:    f.Type.Kind() == reflect.Bool,
+		})
+	}
+	return flags
+}
+
+// FlagName returns the flag name for key: lower case, with the struct delimiter replaced by '.' and '_' by '-',
+// so DATABASE__MAX_CONNS is set by --database.max-conns.
+func FlagName(key string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(NormalizeKey(key), structDelim, "."), "_", "-")
+}
+
+// flagKey is the inverse of FlagName.
+func flagKey(name string) string {
+	return NormalizeKey(strings.ReplaceAll(strings.ReplaceAll(name, "-", "_"), ".", structDelim))
+}
+
+// RegisterFlags defines a flag in fs for each field target, a struct or struct pointer, is bound from. See Flags.
+// The flags only record their values; merge them into a Builder with FlagSource once fs is parsed,
+// typically last, so that flags override the environment and files:
+//
+//	var c Config
+//	config.RegisterFlags(flag.CommandLine, &c)
+//	flag.Parse()
+//	config.FromEnv().FromFlags(flag.CommandLine).To(&c)
+func RegisterFlags(fs *flag.FlagSet, target interface{}) {
+	for _, f := range Flags(target) {
+		fs.Var(&flagValue{isBool: f.Bool}, f.Name, flagUsage(f))
+	}
+}
+
+// flagUsage returns the usage of f, including its default, as flag values have none of their own.
+func flagUsage(f Flag) string {
+	if f.Default == "" {
+		return f.Usage
+	}
+	if f.Usage == "" {
+		return "(default " + f.Default + ")"
+	}
+	return f.Usage + " (default " + f.Default + ")"
+}
+
+// FlagSource returns a Source of the flags registered with RegisterFlags which were set when fs was parsed.
+// Flags left unset are omitted, so they do not override values from other sources.
+func FlagSource(fs *flag.FlagSet) Source {
+	return SourceFunc(func() (map[string]string, error) {
+		values := make(map[string]string)
+		fs.Visit(func(f *flag.Flag) {
+			if v, ok := f.Value.(*flagValue); ok {
+				values[flagKey(f.Name)] = v.value
+			}
+		})
+		return values, nil
+	})
+}
+
+// FromFlags returns a new Builder, populated with the flags registered with RegisterFlags which were set
+// when fs was parsed. See FlagSource.
+func FromFlags(fs *flag.FlagSet) *Builder {
+	return newBuilder().FromFlags(fs)
+}
+
+// FromFlags merges the flags registered with RegisterFlags which were set when fs was parsed
+// into the current config state, returning the Builder. Call it after the environment and files,
+// so that flags override them. See FlagSource.
+func (c *Builder) FromFlags(fs *flag.FlagSet) *Builder {
+	return c.FromSource(FlagSource(fs))
+}
+
+// flagValue is a flag.Value recording the value of a flag registered by RegisterFlags.
+type flagValue struct {
+	value  string
+	isBool bool
+}
+
+func (v *flagValue) String() string {
+	if v == nil {
+		return ""
+	}
+	return v.value
+}
+
+func (v *flagValue) Set(s string) error {
+	v.value = s
+	return nil
+}
+
+// IsBoolFlag lets bool fields' flags be set without a value, as in -debug.
+func (v *flagValue) IsBoolFlag() bool {
+	return v.isBool
+}
@@ -0,0 +1,86 @@
+package config
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFlags(t *testing.T) {
+	t.Parallel()
+	c := helpTestConfig{Port: 8080}
+	flags := Flags(&c)
+	require.Len(t, flags, 8)
+	assert.Equal(t, Flag{Key: "port", Name: "port", Type: "int", Usage: "port to listen on", Default: "8080"}, flags[0])
+	assert.Equal(t, Flag{Key: "debug", Name: "debug", Type: "bool", Bool: true}, flags[6])
+	assert.Equal(t, Flag{Key: "database__hostname", Name: "database.hostname", Type: "string", Usage: "database host,\nor a unix socket"}, flags[7])
+
+	assert.Equal(t, "database.max-conns", FlagName("DATABASE__MAX_CONNS"))
+	assert.Equal(t, "database__max_conns", flagKey("database.max-conns"))
+}
+
+func TestRegisterFlags(t *testing.T) {
+	type testConfig struct {
+		Port     int `help:"port to listen on"`
+		Timeout  time.Duration
+		Debug    bool
+		Database struct {
+			MaxConns int `config:"max_conns"`
+		}
+	}
+	os.Clearenv()
+	defer os.Clearenv()
+	require.NoError(t, os.Setenv("PORT", "80"))
+	require.NoError(t, os.Setenv("TIMEOUT", "5s"))
+
+	c := testConfig{Port: 8080}
+	var buf bytes.Buffer
+	fs := flag.NewFlagSet("server", flag.ContinueOnError)
+	fs.SetOutput(&buf)
+	fs.Bool("v", false, "verbose")
+	RegisterFlags(fs, &c)
+	require.NoError(t, fs.Parse([]string{"-v", "--port=9090", "-debug", "--database.max-conns", "3"}))
+
+	FromEnv().FromSource(FlagSource(fs)).To(&c)
+	assert.Equal(t, 9090, c.Port, "flags override the environment")
+	assert.Equal(t, 5*time.Second, c.Timeout, "unset flags do not override the environment")
+	assert.True(t, c.Debug)
+	assert.Equal(t, 3, c.Database.MaxConns)
+
+	fs.PrintDefaults()
+	assert.Contains(t, buf.String(), "  -port value\n    \tport to listen on (default 8080)\n")
+}
+
+func TestFromFlags(t *testing.T) {
+	type testConfig struct {
+		Port     int
+		Database struct {
+			Host     string
+			MaxConns int `config:"max_conns"`
+		}
+	}
+	os.Clearenv()
+	defer os.Clearenv()
+	require.NoError(t, os.Setenv("PORT", "80"))
+	require.NoError(t, os.Setenv("DATABASE__HOST", "db"))
+
+	var c testConfig
+	fs := flag.NewFlagSet("server", flag.ContinueOnError)
+	RegisterFlags(fs, &c)
+	require.NoError(t, fs.Parse([]string{"--port=9090", "--database.max-conns=3"}))
+
+	FromEnv().FromFlags(fs).To(&c)
+	assert.Equal(t, 9090, c.Port)
+	assert.Equal(t, "db", c.Database.Host)
+	assert.Equal(t, 3, c.Database.MaxConns)
+
+	c = testConfig{}
+	FromFlags(fs).To(&c)
+	assert.Equal(t, 9090, c.Port)
+	assert.Empty(t, c.Database.Host)
+}
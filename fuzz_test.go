@@ -0,0 +1,149 @@
+package config
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+// Fuzz targets for the parsers which handle untrusted input. Run with e.g.
+//
+//	go test -fuzz=FuzzFileSource
+
+func FuzzStringsToMap(f *testing.F) {
+	f.Add("A=1\nB=\n=C\nD==E\n\xff=1")
+	f.Fuzz(func(t *testing.T, in string) {
+		for k, v := range stringsToMap(strings.Split(in, "\n")) {
+			if k == "" || v == "" {
+				t.Fatalf("empty key or value: %q=%q", k, v)
+			}
+			if k != NormalizeKey(k) {
+				t.Fatalf("key %q is not normalized", k)
+			}
+		}
+	})
+}
+
+func FuzzStringToSlice(f *testing.F) {
+	f.Add("a b  c", " ")
+	f.Add("a,\tb,,c", ",")
+	f.Add("\xffa b", " ")
+	f.Fuzz(func(t *testing.T, in, delim string) {
+		if delim == "" {
+			return // programmer error, documented to panic
+		}
+		for _, v := range stringToSlice(in, delim) {
+			if v == "" || v != strings.TrimSpace(v) {
+				t.Fatalf("entry %q is empty or not trimmed", v)
+			}
+		}
+	})
+}
+
+func FuzzSplitQuoted(f *testing.F) {
+	f.Add(`"a b" c 'd,e'`, " ")
+	f.Add(`a\,b, "c" ,`, ",")
+	f.Add(`"\xff`, " ")
+	f.Fuzz(func(t *testing.T, in, delim string) {
+		if delim == "" {
+			return // programmer error, documented to panic
+		}
+		entries, err := SplitQuoted(in, delim)
+		if err != nil {
+			return
+		}
+		// a whitespace delimiter splits quoted slices on any whitespace, but unquoted slices on the delimiter only,
+		// and a delimiter which is not valid UTF-8 splits unquoted slices within runes
+		if strings.TrimSpace(delim) == "" || !utf8.ValidString(delim) {
+			return
+		}
+		if !strings.ContainsAny(in, `"'\`) && fmt.Sprintf("%q", entries) != fmt.Sprintf("%q", stringToSlice(in, delim)) {
+			t.Fatalf("unquoted %q split as %q, not as stringToSlice does", in, entries)
+		}
+	})
+}
+
+func FuzzFileSource(f *testing.F) {
+	f.Add([]byte("A=1\n\nB=2 3\r\n"))
+	f.Add([]byte("no separator"))
+	f.Add([]byte("export A=\"x\\\"\ny\" # c\nB='unterminated"))
+	f.Add([]byte("\xff\xfe=1"))
+	f.Add([]byte(strings.Repeat("A", maxLineLength+1)))
+	f.Fuzz(func(t *testing.T, in []byte) {
+		file, err := ioutil.TempFile("", "fuzz")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer os.Remove(file.Name())
+		if _, err := file.Write(in); err != nil {
+			t.Fatal(err)
+		}
+		file.Close()
+
+		values, err := FileSource(file.Name()).Load()
+		if err != nil {
+			return
+		}
+		for k := range values {
+			if !utf8.ValidString(k) {
+				t.Fatalf("invalid UTF-8 key %q accepted", k)
+			}
+		}
+	})
+}
+
+func FuzzParseTimeOfDay(f *testing.F) {
+	f.Add("22:30")
+	f.Add("7:05:09")
+	f.Add("24:00")
+	f.Fuzz(func(t *testing.T, in string) {
+		tod, err := ParseTimeOfDay(in)
+		if err != nil {
+			return
+		}
+		again, err := ParseTimeOfDay(tod.String())
+		if err != nil || again != tod {
+			t.Fatalf("%q parsed as %v, which does not round trip: %v, %v", in, tod, again, err)
+		}
+	})
+}
+
+func FuzzParseDate(f *testing.F) {
+	f.Add("2025-01-31")
+	f.Add("2025-02-29")
+	f.Fuzz(func(t *testing.T, in string) {
+		d, err := ParseDate(in)
+		if err != nil {
+			return
+		}
+		again, err := ParseDate(d.String())
+		if err != nil || again != d {
+			t.Fatalf("%q parsed as %v, which does not round trip: %v, %v", in, d, again, err)
+		}
+	})
+}
+
+func FuzzConvertAndSetValue(f *testing.F) {
+	f.Add("-2")
+	f.Add("0o777")
+	f.Add("1e400")
+	f.Add("\xff")
+	f.Fuzz(func(t *testing.T, in string) {
+		settables := []interface{}{
+			new(string), new(int), new(int8), new(uint16), new(float32), new(bool),
+			new(os.FileMode), new(TimeOfDay), new(Date), new(TriState), new(When),
+		}
+		for _, settable := range settables {
+			_ = convertAndSetValue(settable, in)
+		}
+		if d, err := octalToDecimal(in); err == nil && in != "" {
+			var i int64
+			if err := convertAndSetValue(&i, d); err != nil {
+				t.Fatalf("octal %q converted to invalid decimal %q", in, d)
+			}
+		}
+	})
+}
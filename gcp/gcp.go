@@ -0,0 +1,152 @@
+// Package gcp provides a config.ValuePreProcessor resolving values from GCP Secret Manager.
+//
+// It lives in its own module, so that applications which do not use GCP do not depend on its client.
+package gcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	secretmanager "cloud.google.com/go/secretmanager/apiv1"
+	"github.com/googleapis/gax-go/v2"
+	"github.com/imduffy15/config"
+	"google.golang.org/api/option"
+	secretmanagerpb "google.golang.org/genproto/googleapis/cloud/secretmanager/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// SecretManagerScheme prefixes values resolved from Secret Manager,
+// e.g. gsm://projects/my-project/secrets/db-password/versions/latest.
+const SecretManagerScheme = "gsm"
+
+// SecretManager is implemented by Secret Manager's *secretmanager.Client.
+type SecretManager interface {
+	AccessSecretVersion(ctx context.Context, req *secretmanagerpb.AccessSecretVersionRequest, opts ...gax.CallOption) (*secretmanagerpb.AccessSecretVersionResponse, error)
+}
+
+// GCPSecretManagerValuePreProcessor is a ValuePreProcessor for GCP Secret Manager.
+type GCPSecretManagerValuePreProcessor struct {
+	secretManager SecretManager
+	// project, when set, is the project of secrets referenced by name alone. See WithProject.
+	project string
+	ctx     context.Context
+	// close closes the client created by NewGCPSecretManagerValuePreProcessor.
+	close func() error
+}
+
+// NewGCPSecretManagerValuePreProcessor creates a new GCPSecretManagerValuePreProcessor with the given context,
+// with a Secret Manager client configured by opts, using Application Default Credentials unless they say otherwise.
+// The client should be closed with Close once values are resolved.
+func NewGCPSecretManagerValuePreProcessor(ctx context.Context, opts ...option.ClientOption) (*GCPSecretManagerValuePreProcessor, error) {
+	client, err := secretmanager.NewClient(ctx, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("config/gcp: error creating secret manager client: %w", err)
+	}
+	return &GCPSecretManagerValuePreProcessor{secretManager: client, ctx: ctx, close: client.Close}, nil
+}
+
+// WithProject makes secrets referenced by name alone, e.g. gsm://db-password or gsm://db-password/versions/3,
+// resolve from project, returning the GCPSecretManagerValuePreProcessor.
+func (p *GCPSecretManagerValuePreProcessor) WithProject(project string) *GCPSecretManagerValuePreProcessor {
+	p.project = project
+	return p
+}
+
+// Close closes the Secret Manager client, if it was created by NewGCPSecretManagerValuePreProcessor.
+func (p *GCPSecretManagerValuePreProcessor) Close() error {
+	if p.close == nil {
+		return nil
+	}
+	return p.close()
+}
+
+// PreProcessValue pre-processes a config key/value pair.
+// It panics with a *config.Error with config.CodeSecretFetch if a value cannot be loaded.
+func (p *GCPSecretManagerValuePreProcessor) PreProcessValue(key, value string) string {
+	return p.PreProcessValueContext(p.ctx, key, value)
+}
+
+// PreProcessValueContext pre-processes a config key/value pair, using ctx rather than the context given at construction.
+func (p *GCPSecretManagerValuePreProcessor) PreProcessValueContext(ctx context.Context, key, value string) string {
+	return p.Register(config.NewResolvers(ctx)).PreProcessValueContext(ctx, key, value)
+}
+
+// Register registers the Secret Manager resolver with r, returning r.
+// This allows Secret Manager references to be resolved alongside those of other providers, such as AWS.
+func (p *GCPSecretManagerValuePreProcessor) Register(r *config.Resolvers) *config.Resolvers {
+	return r.Register(SecretManagerScheme, p.Resolver())
+}
+
+// Resolver returns a config.Resolver for Secret Manager references, which are the resource name of a secret version,
+// e.g. gsm://projects/my-project/secrets/db/versions/latest. Without a version, the latest version is resolved,
+// and with WithProject, the projects/my-project/secrets/ prefix may be left out. As with AWS Secrets Manager,
+// a secret holding a JSON object can be followed by '#' and the key of a value within it, e.g. gsm://db#password.
+// Values which are not strings are returned as JSON, e.g. 3 or true.
+func (p *GCPSecretManagerValuePreProcessor) Resolver() config.Resolver {
+	return config.ResolverFunc(func(ctx context.Context, ref string) (string, error) {
+		name, subKey := ref, ""
+		if i := strings.LastIndex(ref, "#"); i >= 0 {
+			name, subKey = ref[:i], ref[i+1:]
+		}
+		name, err := p.versionName(name)
+		if err != nil {
+			return "", err
+		}
+		secret, err := p.access(ctx, name)
+		if err != nil || subKey == "" {
+			return secret, err
+		}
+		var data map[string]interface{}
+		if err := json.Unmarshal([]byte(secret), &data); err != nil {
+			return "", fmt.Errorf("config/gcp: error parsing secret map %s: %w", name, err)
+		}
+		v, ok := data[subKey]
+		if !ok {
+			return "", fmt.Errorf("config/gcp: failed to find subkey %s", subKey)
+		}
+		if s, ok := v.(string); ok {
+			return s, nil
+		}
+		b, err := json.Marshal(v)
+		return string(b), err
+	})
+}
+
+// versionName returns the resource name of the secret version referenced by name.
+func (p *GCPSecretManagerValuePreProcessor) versionName(name string) (string, error) {
+	if !strings.HasPrefix(name, "projects/") {
+		if p.project == "" {
+			return "", fmt.Errorf("config/gcp: %s is not a secret resource name, e.g. projects/my-project/secrets/%s, and no project is set with WithProject", name, name)
+		}
+		name = "projects/" + p.project + "/secrets/" + name
+	}
+	parts := strings.Split(name, "/")
+	switch {
+	case len(parts) == 4 && parts[2] == "secrets" && parts[1] != "" && parts[3] != "":
+		return name + "/versions/latest", nil
+	case len(parts) == 6 && parts[2] == "secrets" && parts[4] == "versions" && parts[1] != "" && parts[3] != "" && parts[5] != "":
+		return name, nil
+	}
+	return "", fmt.Errorf("config/gcp: %s is not a secret resource name, e.g. projects/my-project/secrets/db/versions/latest", name)
+}
+
+// access returns the payload of the secret version name.
+func (p *GCPSecretManagerValuePreProcessor) access(ctx context.Context, name string) (string, error) {
+	resp, err := p.secretManager.AccessSecretVersion(ctx, &secretmanagerpb.AccessSecretVersionRequest{Name: name})
+	if status.Code(err) == codes.PermissionDenied {
+		return "", fmt.Errorf("config/gcp: access denied to %s, grant secretmanager.versions.access on it, e.g. with roles/secretmanager.secretAccessor: %w", name, err)
+	}
+	if err != nil {
+		return "", fmt.Errorf("config/gcp: error loading secret %s: %w", name, err)
+	}
+	return string(resp.GetPayload().GetData()), nil
+}
+
+// compile time assertions
+var (
+	_ config.ContextValuePreProcessor = (*GCPSecretManagerValuePreProcessor)(nil)
+	_ SecretManager                   = (*secretmanager.Client)(nil)
+)
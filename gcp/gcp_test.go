@@ -0,0 +1,99 @@
+package gcp
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/googleapis/gax-go/v2"
+	"github.com/imduffy15/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	secretmanagerpb "google.golang.org/genproto/googleapis/cloud/secretmanager/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// mockSecretManager holds secret payloads by version resource name.
+type mockSecretManager map[string]string
+
+func (m mockSecretManager) AccessSecretVersion(ctx context.Context, req *secretmanagerpb.AccessSecretVersionRequest, opts ...gax.CallOption) (*secretmanagerpb.AccessSecretVersionResponse, error) {
+	if req.Name == "projects/p/secrets/forbidden/versions/latest" {
+		return nil, status.Error(codes.PermissionDenied, "permission denied")
+	}
+	payload, ok := m[req.Name]
+	if !ok {
+		return nil, status.Error(codes.NotFound, "not found")
+	}
+	return &secretmanagerpb.AccessSecretVersionResponse{Name: req.Name, Payload: &secretmanagerpb.SecretPayload{Data: []byte(payload)}}, nil
+}
+
+func TestGCPSecretManagerValuePreProcessor_PreProcessValue(t *testing.T) {
+	p := &GCPSecretManagerValuePreProcessor{
+		secretManager: mockSecretManager{
+			"projects/p/secrets/password/versions/latest": "s3cr3t",
+			"projects/p/secrets/password/versions/2":      "old",
+			"projects/p/secrets/db/versions/latest":       `{"user": "app", "port": 5432}`,
+		},
+		ctx: context.Background(),
+	}
+
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{"gsm://projects/p/secrets/password/versions/latest", "s3cr3t"},
+		{"gsm://projects/p/secrets/password", "s3cr3t"},
+		{"gsm://projects/p/secrets/password/versions/2", "old"},
+		{"gsm://projects/p/secrets/db#user", "app"},
+		{"gsm://projects/p/secrets/db#port", "5432"},
+		{"plain", "plain"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			assert.Equal(t, tt.want, p.PreProcessValue("KEY", tt.value))
+		})
+	}
+
+	errs := map[string]string{
+		"gsm://password":                         "no project is set with WithProject",
+		"gsm://projects/p/secrets":               "is not a secret resource name",
+		"gsm://projects/p/secrets/db#missing":    "failed to find subkey missing",
+		"gsm://projects/p/secrets/password#user": "error parsing secret map",
+		"gsm://projects/p/secrets/absent":        "error loading secret projects/p/secrets/absent/versions/latest",
+		"gsm://projects/p/secrets/forbidden":     "grant secretmanager.versions.access on it",
+	}
+	for value, want := range errs {
+		err := recoverError(func() { p.PreProcessValue("KEY", value) })
+		assert.Equal(t, config.CodeSecretFetch, config.CodeOf(err), value)
+		assert.Contains(t, fmt.Sprint(err), want, value)
+	}
+
+	p.WithProject("p")
+	assert.Equal(t, "s3cr3t", p.PreProcessValue("KEY", "gsm://password"))
+	assert.Equal(t, "old", p.PreProcessValue("KEY", "gsm://password/versions/2"))
+	assert.Equal(t, "app", p.PreProcessValue("KEY", "gsm://db#user"))
+}
+
+func TestGCPSecretManagerValuePreProcessor_Register(t *testing.T) {
+	p := &GCPSecretManagerValuePreProcessor{
+		secretManager: mockSecretManager{"projects/p/secrets/password/versions/latest": "s3cr3t"},
+		ctx:           context.Background(),
+	}
+	var got struct{ Password string }
+	config.WithValuePreProcessor(p.Register(config.NewResolvers(context.Background()))).
+		FromSource(config.MapSource(map[string]string{"PASSWORD": "gsm://projects/p/secrets/password"})).
+		To(&got)
+	assert.Equal(t, "s3cr3t", got.Password)
+	require.NoError(t, p.Close())
+}
+
+func recoverError(f func()) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = r.(error)
+		}
+	}()
+	f()
+	return nil
+}
@@ -0,0 +1,70 @@
+package config
+
+import "time"
+
+// GetStringDefault returns the value of key, e.g. DATABASE__HOST, in the current config state, or def if it is unset.
+// The Get*Default methods read single keys for scripts and small tools, for which a struct is overkill:
+//
+//	b := config.FromEnv()
+//	batch := b.GetIntDefault("BATCH_SIZE", 100)
+//	timeout := b.GetDurationDefault("TIMEOUT", 30*time.Second)
+//
+// Values are converted as fields of the same type would be. Values which fail to convert are passed to the handler
+// set by WithConversionErrorHandler, or otherwise listed by Warnings, and def is returned.
+// Keys read are counted as bound, so they are not reported as unknown.
+func (c *Builder) GetStringDefault(key, def string) string {
+	return getDefault(c, key, def)
+}
+
+// GetIntDefault returns the value of key as an int, or def if it is unset or invalid. See GetStringDefault.
+func (c *Builder) GetIntDefault(key string, def int) int {
+	return getDefault(c, key, def)
+}
+
+// GetInt64Default returns the value of key as an int64, or def if it is unset or invalid. See GetStringDefault.
+func (c *Builder) GetInt64Default(key string, def int64) int64 {
+	return getDefault(c, key, def)
+}
+
+// GetFloat64Default returns the value of key as a float64, or def if it is unset or invalid. See GetStringDefault.
+func (c *Builder) GetFloat64Default(key string, def float64) float64 {
+	return getDefault(c, key, def)
+}
+
+// GetBoolDefault returns the value of key as a bool, or def if it is unset or invalid. See GetStringDefault.
+func (c *Builder) GetBoolDefault(key string, def bool) bool {
+	return getDefault(c, key, def)
+}
+
+// GetDurationDefault returns the value of key as a time.Duration, e.g. 1m30s, or def if it is unset or invalid.
+// See GetStringDefault.
+func (c *Builder) GetDurationDefault(key string, def time.Duration) time.Duration {
+	return getDefault(c, key, def)
+}
+
+// GetStringsDefault returns the value of key split into a slice, as for a []string field,
+// or def if it is unset. See GetStringDefault.
+func (c *Builder) GetStringsDefault(key string, def []string) []string {
+	key = NormalizeKey(key)
+	c.bound[key] = true
+	value, ok := c.lookup(key)
+	if !ok {
+		return def
+	}
+	return stringToSlice(value, c.sliceDelim)
+}
+
+func getDefault[T any](c *Builder, key string, def T) T {
+	key = NormalizeKey(key)
+	c.bound[key] = true
+	value, ok := c.lookup(key)
+	if !ok {
+		return def
+	}
+	var v T
+	if err := convertAndSetValue(&v, value); err != nil {
+		c.conversionError(key, value, err)
+		return def
+	}
+	return v
+}
@@ -0,0 +1,38 @@
+package config
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuilder_GetDefault(t *testing.T) {
+	os.Clearenv()
+	defer os.Clearenv()
+	require.NoError(t, os.Setenv("BATCH_SIZE", "50"))
+	require.NoError(t, os.Setenv("TIMEOUT", "1m30s"))
+	require.NoError(t, os.Setenv("DEBUG", "true"))
+	require.NoError(t, os.Setenv("RATIO", "0.5"))
+	require.NoError(t, os.Setenv("DATABASE__HOST", "secret://x"))
+	require.NoError(t, os.Setenv("ZONES", "a b"))
+	require.NoError(t, os.Setenv("RETRIES", "many"))
+
+	b := WithValuePreProcessor(&countingPreProcessor{}).FromEnv()
+	assert.Equal(t, 50, b.GetIntDefault("BATCH_SIZE", 100))
+	assert.Equal(t, int64(50), b.GetInt64Default("batch_size", 100), "keys are case insensitive")
+	assert.Equal(t, 90*time.Second, b.GetDurationDefault("TIMEOUT", time.Second))
+	assert.True(t, b.GetBoolDefault("DEBUG", false))
+	assert.Equal(t, 0.5, b.GetFloat64Default("RATIO", 1))
+	assert.Equal(t, "resolved", b.GetStringDefault("DATABASE__HOST", "localhost"), "values are pre-processed")
+	assert.Equal(t, []string{"a", "b"}, b.GetStringsDefault("ZONES", nil))
+
+	assert.Equal(t, "localhost", b.GetStringDefault("HOST", "localhost"))
+	assert.Equal(t, []string{"eu"}, b.GetStringsDefault("REGIONS", []string{"eu"}))
+	assert.Equal(t, time.Second, b.GetDurationDefault("MISSING", time.Second))
+
+	assert.Equal(t, 3, b.GetIntDefault("RETRIES", 3), "invalid values fall back to the default")
+	assert.Equal(t, []Warning{{Kind: WarningConversion, Key: "retries", Message: `cannot convert "many" to int: strconv.ParseInt: parsing "many": invalid syntax`}}, b.Warnings())
+}
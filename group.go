@@ -0,0 +1,79 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// groupTagKey is the struct tag naming the group of a field, e.g. `group:"oauth"`. See groupError.
+const groupTagKey = "group"
+
+// Group policies, given after a group's name, e.g. `group:"auth,at-least-one"`.
+const (
+	// groupAllOrNone requires every field of the group to be set if any is. It is the default policy.
+	groupAllOrNone = "all-or-none"
+	// groupAtLeastOne requires at least one field of the group to be set.
+	groupAtLeastOne = "at-least-one"
+)
+
+type group struct {
+	name, policy string
+	// explicit is set once a field of the group gives its policy.
+	explicit   bool
+	set, unset []string
+}
+
+// groupError returns an *Error with CodeMissingKey describing the groups of target's fields whose policy is broken,
+// if any. Fields tagged with the same group, e.g. `group:"oauth"`, are set together: by default all or none of them,
+// so that setting CLIENT_ID without CLIENT_SECRET fails, or with `group:"auth,at-least-one"`, at least one of them.
+// A field is set if its key has a value. It panics with an *Error with CodeUnsupportedField if the fields
+// of a group disagree on its policy, or a policy is unknown.
+func (c *Builder) groupError(target interface{}) error {
+	var groups []*group
+	byName := make(map[string]*group)
+	for _, f := range fields(structType(target)) {
+		tag, ok := f.Tag.Lookup(groupTagKey)
+		if !ok {
+			continue
+		}
+		name, policy := strings.TrimSpace(tag), ""
+		if i := strings.Index(tag, ","); i >= 0 {
+			name, policy = strings.TrimSpace(tag[:i]), strings.TrimSpace(tag[i+1:])
+			if policy != groupAllOrNone && policy != groupAtLeastOne {
+				panic(newError(CodeUnsupportedField, f.key, fmt.Errorf("unknown group policy %q, use %s or %s", policy, groupAllOrNone, groupAtLeastOne)))
+			}
+		}
+		g, ok := byName[name]
+		if !ok {
+			g = &group{name: name, policy: groupAllOrNone}
+			byName[name] = g
+			groups = append(groups, g)
+		}
+		// The policy need only be given on one of the group's fields.
+		if policy != "" {
+			if g.explicit && g.policy != policy {
+				panic(newError(CodeUnsupportedField, f.key, fmt.Errorf("group %s is both %s and %s", name, g.policy, policy)))
+			}
+			g.policy, g.explicit = policy, true
+		}
+		if _, ok := c.lookup(f.key); ok {
+			g.set = append(g.set, strings.ToUpper(f.key))
+		} else {
+			g.unset = append(g.unset, strings.ToUpper(f.key))
+		}
+	}
+
+	var broken []string
+	for _, g := range groups {
+		switch {
+		case g.policy == groupAllOrNone && len(g.set) > 0 && len(g.unset) > 0:
+			broken = append(broken, fmt.Sprintf("group %s: %s must be set with %s", g.name, strings.Join(g.unset, ", "), strings.Join(g.set, ", ")))
+		case g.policy == groupAtLeastOne && len(g.set) == 0:
+			broken = append(broken, fmt.Sprintf("group %s: one of %s must be set", g.name, strings.Join(g.unset, ", ")))
+		}
+	}
+	if len(broken) == 0 {
+		return nil
+	}
+	return newError(CodeMissingKey, "", fmt.Errorf("%s", strings.Join(broken, "; ")))
+}
@@ -0,0 +1,71 @@
+package config
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_groupError(t *testing.T) {
+	type testConfig struct {
+		OAuth struct {
+			ClientID     string `group:"oauth"`
+			ClientSecret string `group:"oauth"`
+		}
+		Token    string `group:"auth"`
+		Password string `group:"auth,at-least-one"`
+	}
+
+	tests := []struct {
+		name string
+		env  map[string]string
+		want string
+	}{
+		{name: "all set", env: map[string]string{"OAUTH__CLIENTID": "id", "OAUTH__CLIENTSECRET": "secret", "TOKEN": "t"}},
+		{name: "none set", env: map[string]string{"PASSWORD": "p"}},
+		{
+			name: "partly set",
+			env:  map[string]string{"OAUTH__CLIENTID": "id", "TOKEN": "t"},
+			want: "group oauth: OAUTH__CLIENTSECRET must be set with OAUTH__CLIENTID",
+		},
+		{
+			name: "at least one unset",
+			env:  map[string]string{},
+			want: "group auth: one of TOKEN, PASSWORD must be set",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			os.Clearenv()
+			defer os.Clearenv()
+			for k, v := range tt.env {
+				require.NoError(t, os.Setenv(k, v))
+			}
+			var c testConfig
+			err := FromEnv().Bind(&c)
+			if tt.want == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.Equal(t, CodeMissingKey, CodeOf(err))
+			assert.Contains(t, err.Error(), tt.want)
+			assert.Panics(t, func() { FromEnv().To(&c) })
+		})
+	}
+
+	var conflicting struct {
+		A string `group:"g,all-or-none"`
+		B string `group:"g,at-least-one"`
+	}
+	err := recoverError(func() { FromEnv().To(&conflicting) })
+	assert.Equal(t, CodeUnsupportedField, CodeOf(err))
+	assert.Contains(t, err.Error(), "group g is both all-or-none and at-least-one")
+
+	var unknown struct {
+		A string `group:"g,some"`
+	}
+	err = recoverError(func() { FromEnv().To(&unknown) })
+	assert.Contains(t, err.Error(), `unknown group policy "some"`)
+}
@@ -0,0 +1,23 @@
+module github.com/imduffy15/config/hcl
+
+go 1.18
+
+require (
+	github.com/hashicorp/hcl/v2 v2.17.0
+	github.com/imduffy15/config v0.1.0
+	github.com/stretchr/testify v1.2.2
+	github.com/zclconf/go-cty v1.13.0
+)
+
+require (
+	github.com/agext/levenshtein v1.2.1 // indirect
+	github.com/apparentlymart/go-textseg/v13 v13.0.0 // indirect
+	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/mitchellh/go-wordwrap v0.0.0-20150314170334-ad45545899c7 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	golang.org/x/text v0.3.8 // indirect
+	golang.org/x/time v0.3.0 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
+
+replace github.com/imduffy15/config => ../
@@ -0,0 +1,180 @@
+// Package hcl provides a config.Source of HCL files, in the native syntax of HashiCorp Configuration Language 2,
+// as used by Terraform. Importing it registers the parser of config.FormatHCL, so config.FromReader,
+// config.FromURL and the hcl sources of config.FromManifest read HCL:
+//
+//	import _ "github.com/imduffy15/config/hcl"
+//
+// It lives in its own module, so that applications which do not use HCL do not depend on its parser.
+package hcl
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/imduffy15/config"
+	"github.com/zclconf/go-cty/cty"
+)
+
+func init() {
+	config.RegisterFormat(config.FormatHCL, Parse)
+}
+
+// Source returns a config.Source of the HCL file. Attributes and blocks are flattened into keys:
+//   - blocks are nested with the struct delimiter, with their labels, so database "primary" { host = ... }
+//     is bound from DATABASE__PRIMARY__HOST
+//   - blocks repeated with the same type and labels are indexed, e.g. SUBNET__0__ID
+//   - object and list attributes are flattened as by config.JSONSource
+//
+// Attributes are evaluated without variables or functions, so they must be literals, although
+// they may be computed from literals, e.g. timeout = 60 * 5. Nulls are skipped.
+// Numbers are written out in full, so quote values such as file modes, e.g. mode = "0640", to keep them as written.
+func Source(file string) config.Source {
+	return fileSource(file)
+}
+
+type fileSource string
+
+func (s fileSource) Load() (map[string]string, error) {
+	b, err := ioutil.ReadFile(string(s))
+	if err != nil {
+		return nil, err
+	}
+	values, err := Parse(b)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", string(s), err)
+	}
+	return values, nil
+}
+
+// String describes the source, e.g. in a config.Meta's Source.
+func (s fileSource) String() string {
+	return "file " + string(s)
+}
+
+// From returns a new config.Builder, populated with the values in file. See Source.
+// It panics with a *config.Error if file cannot be read or parsed.
+func From(file string) *config.Builder {
+	return config.FromSource(Source(file))
+}
+
+// Parse returns the values of the HCL in b, flattened as by Source. It is the config.FormatParser of config.FormatHCL.
+func Parse(b []byte) (map[string]string, error) {
+	f, diags := hclsyntax.ParseConfig(b, "", hcl.InitialPos)
+	if diags.HasErrors() {
+		return nil, hclError(diags)
+	}
+	object, err := bodyToJSON(f.Body.(*hclsyntax.Body))
+	if err != nil {
+		return nil, err
+	}
+	// The body is flattened by the JSON reader, so HCL and JSON config bind alike.
+	j, err := json.Marshal(object)
+	if err != nil {
+		return nil, err
+	}
+	return config.ReaderSource(bytes.NewReader(j), config.FormatJSON).Load()
+}
+
+// bodyToJSON converts body to the form of a JSON object decoded by encoding/json with UseNumber.
+func bodyToJSON(body *hclsyntax.Body) (map[string]interface{}, error) {
+	object := make(map[string]interface{})
+	for name, attr := range body.Attributes {
+		v, diags := attr.Expr.Value(nil)
+		if diags.HasErrors() {
+			return nil, hclError(diags)
+		}
+		object[name] = ctyToJSON(v)
+	}
+
+	paths := make([][]string, len(body.Blocks))
+	count := make(map[string]int)
+	for i, block := range body.Blocks {
+		paths[i] = append([]string{block.Type}, block.Labels...)
+		count[strings.Join(paths[i], "__")]++
+	}
+	index := make(map[string]int)
+	for i, block := range body.Blocks {
+		path := paths[i]
+		if key := strings.Join(path, "__"); count[key] > 1 {
+			path = append(path, strconv.Itoa(index[key]))
+			index[key]++
+		}
+		nested, err := bodyToJSON(block.Body)
+		if err != nil {
+			return nil, err
+		}
+		parent := object
+		for _, name := range path[:len(path)-1] {
+			child, ok := parent[name].(map[string]interface{})
+			if !ok {
+				child = make(map[string]interface{})
+				parent[name] = child
+			}
+			parent = child
+		}
+		name := path[len(path)-1]
+		if existing, ok := parent[name].(map[string]interface{}); ok {
+			for k, v := range nested {
+				existing[k] = v
+			}
+			continue
+		}
+		parent[name] = nested
+	}
+	return object, nil
+}
+
+// hclError returns an error for the first error in diags, with its line.
+func hclError(diags hcl.Diagnostics) error {
+	for _, d := range diags {
+		if d.Severity != hcl.DiagError {
+			continue
+		}
+		msg := d.Summary
+		if d.Detail != "" {
+			msg += ": " + d.Detail
+		}
+		if d.Subject != nil {
+			return fmt.Errorf("invalid HCL: line %d: %s", d.Subject.Start.Line, msg)
+		}
+		return fmt.Errorf("invalid HCL: %s", msg)
+	}
+	return nil
+}
+
+// ctyToJSON converts v to the form of a value decoded by encoding/json with UseNumber.
+func ctyToJSON(v cty.Value) interface{} {
+	if v.IsNull() || !v.IsKnown() {
+		return nil
+	}
+	t := v.Type()
+	switch {
+	case t == cty.String:
+		return v.AsString()
+	case t == cty.Number:
+		return json.Number(v.AsBigFloat().Text('f', -1))
+	case t == cty.Bool:
+		return v.True()
+	case t.IsListType() || t.IsSetType() || t.IsTupleType():
+		list := make([]interface{}, 0, v.LengthInt())
+		for it := v.ElementIterator(); it.Next(); {
+			_, elem := it.Element()
+			list = append(list, ctyToJSON(elem))
+		}
+		return list
+	case t.IsMapType() || t.IsObjectType():
+		object := make(map[string]interface{}, v.LengthInt())
+		for it := v.ElementIterator(); it.Next(); {
+			k, elem := it.Element()
+			object[k.AsString()] = ctyToJSON(elem)
+		}
+		return object
+	}
+	return nil
+}
@@ -0,0 +1,175 @@
+package hcl
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/imduffy15/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const hclConfig = `
+# Shared with the Terraform module deploying the service.
+log_level = "debug"
+timeout   = 60 * 5
+ratio     = 1.50
+mode      = "0640"
+zones     = ["eu-west-1a", "eu-west-1b"]
+tags      = { team = "platform", cost_centre = 42 }
+password  = null
+
+database "primary" {
+  host = "db.internal"
+  port = 5432
+
+  tls {
+    enabled = true
+  }
+}
+
+database "replica" {
+  host = "replica.internal"
+}
+
+subnet {
+  id = "subnet-1"
+}
+
+subnet {
+  id = "subnet-2"
+}
+`
+
+func TestSource(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"app.hcl":      hclConfig,
+		"invalid.hcl":  "database {\n  host = \n}\n",
+		"variable.hcl": "host = var.host\n",
+	})
+
+	values, err := Source(filepath.Join(dir, "app.hcl")).Load()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"log_level":                       "debug",
+		"timeout":                         "300",
+		"ratio":                           "1.5",
+		"mode":                            "0640",
+		"zones":                           "eu-west-1a eu-west-1b",
+		"tags__team":                      "platform",
+		"tags__cost_centre":               "42",
+		"database__primary__host":         "db.internal",
+		"database__primary__port":         "5432",
+		"database__primary__tls__enabled": "true",
+		"database__replica__host":         "replica.internal",
+		"subnet__0__id":                   "subnet-1",
+		"subnet__1__id":                   "subnet-2",
+	}, values)
+
+	_, err = Source(filepath.Join(dir, "invalid.hcl")).Load()
+	assert.Contains(t, err.Error(), filepath.Join(dir, "invalid.hcl")+": invalid HCL: line 2: ")
+	_, err = Source(filepath.Join(dir, "variable.hcl")).Load()
+	assert.EqualError(t, err, filepath.Join(dir, "variable.hcl")+
+		": invalid HCL: line 1: Variables not allowed: Variables may not be used here.")
+
+	err = recoverError(func() { From(filepath.Join(dir, "missing.hcl")) })
+	assert.Equal(t, config.CodeSourceLoad, config.CodeOf(err))
+}
+
+func TestFrom(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{"app.hcl": hclConfig})
+	os.Clearenv()
+	defer os.Clearenv()
+	require.NoError(t, os.Setenv("DATABASE__PRIMARY__HOST", "localhost"))
+
+	type database struct {
+		Host string
+		Port int
+		TLS  struct {
+			Enabled bool
+		}
+	}
+	var got struct {
+		LogLevel string `config:"log_level"`
+		Timeout  int
+		Mode     os.FileMode
+		Zones    []string
+		Tags     struct {
+			Team       string
+			CostCentre int `config:"cost_centre"`
+		}
+		Database struct {
+			Primary database
+			Replica database
+		}
+	}
+	From(filepath.Join(dir, "app.hcl")).FromEnv().To(&got)
+	assert.Equal(t, "debug", got.LogLevel)
+	assert.Equal(t, 300, got.Timeout)
+	assert.Equal(t, os.FileMode(0o640), got.Mode)
+	assert.Equal(t, []string{"eu-west-1a", "eu-west-1b"}, got.Zones)
+	assert.Equal(t, "platform", got.Tags.Team)
+	assert.Equal(t, 42, got.Tags.CostCentre)
+	assert.Equal(t, "localhost", got.Database.Primary.Host, "the environment overrides the file")
+	assert.Equal(t, 5432, got.Database.Primary.Port)
+	assert.True(t, got.Database.Primary.TLS.Enabled)
+	assert.Equal(t, "replica.internal", got.Database.Replica.Host)
+}
+
+func TestFormatHCL(t *testing.T) {
+	t.Parallel()
+	values, err := config.ReaderSource(strings.NewReader("port = 80\n\ndatabase {\n  host = \"db.internal\"\n}\n"), config.FormatHCL).Load()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"database__host": "db.internal", "port": "80"}, values)
+
+	var meta struct{ Host config.Meta[string] }
+	config.FromReader(strings.NewReader("host = \"h\"\n"), config.FormatHCL).To(&meta)
+	assert.Equal(t, "hcl reader", meta.Host.Source)
+
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"config.sources.yaml": "sources:\n  - hcl: app.hcl\n",
+		"app.hcl":             "log {\n  format = \"json\"\n}\n",
+	})
+	var got struct{ Log struct{ Format string } }
+	config.FromManifest(filepath.Join(dir, "config.sources.yaml")).To(&got)
+	assert.Equal(t, "json", got.Log.Format)
+}
+
+func TestWriteTerraformVariables(t *testing.T) {
+	t.Parallel()
+	var c struct {
+		Host    string `default:"db host" description:"database host,\nor a unix socket"`
+		Enabled *bool
+	}
+	var buf strings.Builder
+	require.NoError(t, config.WriteTerraformVariables(&buf, &c, "APP_"))
+	f, diags := hclsyntax.ParseConfig([]byte(buf.String()), "variables.tf", hcl.InitialPos)
+	require.False(t, diags.HasErrors(), "the variables are valid HCL: %v", diags)
+	assert.Len(t, f.Body.(*hclsyntax.Body).Blocks, 2)
+}
+
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		require.NoError(t, ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0o644))
+	}
+}
+
+func recoverError(f func()) (err error) {
+	defer func() {
+		if e, ok := recover().(error); ok {
+			err = e
+		}
+	}()
+	f()
+	return nil
+}
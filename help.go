@@ -0,0 +1,161 @@
+package config
+
+import (
+	"encoding"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// helpTagKey is the struct tag holding a field's description in help text.
+//
+//	Port int `help:"port to listen on"`
+const helpTagKey = "help"
+
+var (
+	durationType      = reflect.TypeOf(time.Duration(0))
+	fileModeType      = reflect.TypeOf(os.FileMode(0))
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// WriteHelp writes help text for the environment variables target, a struct or struct pointer, is bound from,
+// in the style of flag.PrintDefaults: each key with its type, followed by its description from the field's
+// help tag, and its default. Defaults are the non zero values of target's fields, so set them before calling
+// WriteHelp. Keys are written upper case, after prefix, e.g. the prefix passed to EnvPrefixSource.
+func WriteHelp(w io.Writer, target interface{}, prefix string) error {
+	values := reflect.Indirect(reflect.ValueOf(target))
+	for _, f := range fields(structType(target)) {
+		if _, err := fmt.Fprint(w, helpEntry(f, values, prefix)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Usage returns a function for flag.Usage, or a FlagSet's Usage field, which prints the flags' defaults
+// followed by help for the environment variables target is bound from. See WriteHelp.
+//
+//	var c Config
+//	c.Port = 8080
+//	flag.Usage = config.Usage(flag.CommandLine, &c, "")
+//	flag.Parse()
+//	config.FromEnv().To(&c)
+func Usage(fs *flag.FlagSet, target interface{}, prefix string) func() {
+	return func() {
+		w := fs.Output()
+		if fs.Name() == "" {
+			fmt.Fprintf(w, "Usage:\n")
+		} else {
+			fmt.Fprintf(w, "Usage of %s:\n", fs.Name())
+		}
+		fs.PrintDefaults()
+		fmt.Fprintf(w, "\nEnvironment variables:\n")
+		_ = WriteHelp(w, target, prefix)
+	}
+}
+
+func helpEntry(f field, values reflect.Value, prefix string) string {
+	var b strings.Builder
+	b.WriteString("  ")
+	b.WriteString(prefix)
+	switch {
+	case f.remain:
+		b.WriteString(strings.ToUpper(f.key) + manifestWildcard)
+	case f.rawMap:
+		b.WriteString(strings.ToUpper(f.key+structDelim) + manifestWildcard)
+	default:
+		b.WriteString(strings.ToUpper(f.key))
+		b.WriteString(" ")
+		b.WriteString(helpTypeName(f.Type))
+	}
+
+	description := strings.TrimSpace(f.Tag.Get(helpTagKey))
+	if description == "" && (f.remain || f.rawMap) {
+		description = "any key under this prefix"
+	}
+	if def, ok := helpDefault(f, values); ok {
+		if description != "" {
+			description += " "
+		}
+		description += "(default " + def + ")"
+	}
+	if description != "" {
+		b.WriteString("\n    \t")
+		b.WriteString(strings.ReplaceAll(description, "\n", "\n    \t"))
+	}
+	b.WriteString("\n")
+	return b.String()
+}
+
+// helpTypeName names t in help text, e.g. int, duration, []string or config.Quantity.
+func helpTypeName(t reflect.Type) string {
+	switch {
+	case t == durationType:
+		return "duration"
+	case t.Kind() == reflect.Slice && !reflect.PtrTo(t).Implements(textUnmarshalerType):
+		return "[]" + helpTypeName(t.Elem())
+	}
+	return t.String()
+}
+
+// helpDefault returns the value of f in values, formatted for help text, if values is a struct and it is not zero.
+func helpDefault(f field, values reflect.Value) (string, bool) {
+	v, ok := defaultValue(f, values)
+	if !ok {
+		return "", false
+	}
+	return helpFormat(v, hasTagOption(f.StructField, tagOptionOctal)), true
+}
+
+// defaultValue returns the value of f in values, if values is a struct and it is not zero.
+func defaultValue(f field, values reflect.Value) (reflect.Value, bool) {
+	if values.Kind() != reflect.Struct || f.remain || f.rawMap {
+		return reflect.Value{}, false
+	}
+	v := fieldValue(f, values)
+	if !v.IsValid() || v.IsZero() || !v.CanInterface() {
+		return reflect.Value{}, false
+	}
+	return v, true
+}
+
+// fieldValue returns the value of f in values, a struct, unwrapping Meta and Optional fields.
+func fieldValue(f field, values reflect.Value) reflect.Value {
+	v := values
+	for _, name := range f.path {
+		if v = v.FieldByName(name); v.IsValid() && wrappedType(v.Type()) != v.Type() {
+			v = v.Field(0)
+		}
+	}
+	return v
+}
+
+func helpFormat(v reflect.Value, octal bool) string {
+	if v.Type().Implements(textMarshalerType) {
+		if text, err := v.Interface().(encoding.TextMarshaler).MarshalText(); err == nil {
+			return fmt.Sprintf("%q", text)
+		}
+	}
+	if flags, ok := registeredBitFlags(v.Type()); ok {
+		return fmt.Sprintf("%q", formatBitFlags(flags, bitFlagsValue(v)))
+	}
+	switch {
+	case v.Type() == durationType:
+		return v.Interface().(time.Duration).String()
+	case v.Kind() == reflect.String:
+		return fmt.Sprintf("%q", v.String())
+	case v.Kind() == reflect.Slice:
+		items := make([]string, v.Len())
+		for i := range items {
+			items[i] = fmt.Sprint(v.Index(i).Interface())
+		}
+		return fmt.Sprintf("%q", strings.Join(items, sliceDelim))
+	case octal || v.Type() == fileModeType:
+		return fmt.Sprintf("%#o", v.Interface())
+	}
+	return fmt.Sprint(v.Interface())
+}
@@ -0,0 +1,84 @@
+package config
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type helpTestConfig struct {
+	Port     int           `help:"port to listen on"`
+	Timeout  time.Duration `help:"request timeout"`
+	Hosts    []string
+	Umask    int         `config:",octal"`
+	Mode     os.FileMode `help:"mode of created files"`
+	Limit    Quantity    `help:"memory limit"`
+	Debug    bool
+	Database struct {
+		Host string `config:"hostname" help:"database host,\nor a unix socket"`
+	}
+	Kafka  map[string]string `config:",raw"`
+	Extra  map[string]string `config:",remain"`
+	Ignore string            `config:"-"`
+}
+
+func TestWriteHelp(t *testing.T) {
+	t.Parallel()
+	c := helpTestConfig{Port: 8080, Timeout: 5 * time.Second, Hosts: []string{"a", "b"}, Umask: 0o27, Mode: 0o640}
+	c.Database.Host = "localhost"
+	c.Limit, _ = ParseQuantity("1Gi")
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteHelp(&buf, &c, "APP_"))
+	assert.Equal(t, `  APP_PORT int
+    	port to listen on (default 8080)
+  APP_TIMEOUT duration
+    	request timeout (default 5s)
+  APP_HOSTS []string
+    	(default "a b")
+  APP_UMASK int
+    	(default 027)
+  APP_MODE fs.FileMode
+    	mode of created files (default 0640)
+  APP_LIMIT config.Quantity
+    	memory limit (default "1Gi")
+  APP_DEBUG bool
+  APP_DATABASE__HOSTNAME string
+    	database host,
+    	or a unix socket (default "localhost")
+  APP_KAFKA__*
+    	any key under this prefix
+  APP_*
+    	any key under this prefix
+`, buf.String())
+
+	buf.Reset()
+	require.NoError(t, WriteHelp(&buf, helpTestConfig{}, ""))
+	assert.Contains(t, buf.String(), "  PORT int\n    \tport to listen on\n", "zero values have no default")
+}
+
+func TestUsage(t *testing.T) {
+	t.Parallel()
+	var c struct {
+		Port int `help:"port to listen on"`
+	}
+	var buf bytes.Buffer
+	fs := flag.NewFlagSet("server", flag.ContinueOnError)
+	fs.SetOutput(&buf)
+	fs.Bool("v", false, "verbose")
+	fs.Usage = Usage(fs, &c, "")
+
+	assert.Equal(t, flag.ErrHelp, fs.Parse([]string{"-h"}))
+	assert.Equal(t, `Usage of server:
+  -v	verbose
+
+Environment variables:
+  PORT int
+    	port to listen on
+`, buf.String())
+}
@@ -0,0 +1,98 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"strconv"
+	"strings"
+)
+
+// JSONSource returns a Source of the JSON object in file. Nested values are flattened into keys:
+//   - objects are nested with the struct delimiter, so {"database": {"host": ...}} is bound from DATABASE__HOST
+//   - arrays of primitives are joined with the slice delimiter, so they bind to slice fields
+//   - arrays holding objects or arrays are indexed, e.g. SUBNETS__0__ID
+//
+// Numbers are taken as written, so large numbers stay exact. Nulls are skipped.
+func JSONSource(file string) Source {
+	return namedSource{name: "file " + file, Source: SourceFunc(func() (map[string]string, error) {
+		b, err := ioutil.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		values, err := flattenJSON(b)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", file, err)
+		}
+		return values, nil
+	})}
+}
+
+// FromJSON returns a new Builder, populated with the values in file. See JSONSource.
+// It panics with an *Error if file cannot be read or parsed.
+func FromJSON(file string) *Builder {
+	return newBuilder().FromJSON(file)
+}
+
+// FromJSON merges the values in file into the current config state, returning the Builder.
+// See JSONSource. It panics with an *Error if file cannot be read or parsed.
+func (c *Builder) FromJSON(file string) *Builder {
+	return c.FromSource(JSONSource(file))
+}
+
+func flattenJSON(b []byte) (map[string]string, error) {
+	d := json.NewDecoder(bytes.NewReader(b))
+	d.UseNumber() // keeps large numbers exact
+	var object map[string]interface{}
+	if err := d.Decode(&object); err != nil {
+		return nil, fmt.Errorf("invalid JSON: %w", err)
+	}
+	if object == nil {
+		return nil, errors.New("invalid JSON: top level must be an object")
+	}
+	values := make(map[string]string)
+	for k, v := range object {
+		flattenJSONValue(values, k, v)
+	}
+	return values, nil
+}
+
+// flattenJSONValue flattens v, decoded with UseNumber, into values under key.
+func flattenJSONValue(values map[string]string, key string, v interface{}) {
+	switch v := v.(type) {
+	case nil:
+	case map[string]interface{}:
+		for k, sub := range v {
+			flattenJSONValue(values, key+structDelim+k, sub)
+		}
+	case []interface{}:
+		primitives := make([]string, 0, len(v))
+		for _, sub := range v {
+			s, ok := jsonPrimitive(sub)
+			if !ok {
+				for i, sub := range v {
+					flattenJSONValue(values, key+structDelim+strconv.Itoa(i), sub)
+				}
+				return
+			}
+			primitives = append(primitives, s)
+		}
+		values[key] = strings.Join(primitives, sliceDelim)
+	default:
+		values[key], _ = jsonPrimitive(v)
+	}
+}
+
+func jsonPrimitive(v interface{}) (string, bool) {
+	switch v := v.(type) {
+	case string:
+		return v, true
+	case json.Number:
+		return v.String(), true
+	case bool:
+		return strconv.FormatBool(v), true
+	}
+	return "", false
+}
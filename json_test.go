@@ -0,0 +1,80 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const jsonConfig = `{
+  "api": {"endpoint": "https://api.example.com", "retries": 5, "enabled": true},
+  "database": {"host": "db.internal", "port": 5432, "password": null},
+  "id": 12345678901234567890,
+  "ratio": 1.50,
+  "zones": ["eu-west-1a", "eu-west-1b"],
+  "subnets": [{"id": "subnet-1"}, {"id": "subnet-2"}]
+}`
+
+func TestJSONSource(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"app.json":   jsonConfig,
+		"array.json": `["a"]`,
+		"null.json":  `null`,
+	})
+
+	values, err := JSONSource(filepath.Join(dir, "app.json")).Load()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"api__endpoint":  "https://api.example.com",
+		"api__retries":   "5",
+		"api__enabled":   "true",
+		"database__host": "db.internal",
+		"database__port": "5432",
+		"id":             "12345678901234567890",
+		"ratio":          "1.50",
+		"zones":          "eu-west-1a eu-west-1b",
+		"subnets__0__id": "subnet-1",
+		"subnets__1__id": "subnet-2",
+	}, values)
+
+	_, err = JSONSource(filepath.Join(dir, "array.json")).Load()
+	assert.Contains(t, err.Error(), "array.json: invalid JSON: json: cannot unmarshal array")
+	_, err = JSONSource(filepath.Join(dir, "null.json")).Load()
+	assert.EqualError(t, err, filepath.Join(dir, "null.json")+": invalid JSON: top level must be an object")
+
+	err = recoverError(func() { FromJSON(filepath.Join(dir, "missing.json")) })
+	assert.Equal(t, CodeSourceLoad, CodeOf(err))
+}
+
+func TestBuilder_FromJSON(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{"app.json": jsonConfig})
+	os.Clearenv()
+	defer os.Clearenv()
+	require.NoError(t, os.Setenv("DATABASE__HOST", "localhost"))
+
+	var got struct {
+		API struct {
+			Endpoint string
+			Retries  int
+			Enabled  bool
+		}
+		Database struct {
+			Host string
+			Port int
+		}
+		Zones []string
+	}
+	FromJSON(filepath.Join(dir, "app.json")).FromEnv().To(&got)
+	assert.Equal(t, "https://api.example.com", got.API.Endpoint)
+	assert.Equal(t, 5, got.API.Retries)
+	assert.True(t, got.API.Enabled)
+	assert.Equal(t, "localhost", got.Database.Host, "the environment overrides the file")
+	assert.Equal(t, 5432, got.Database.Port)
+	assert.Equal(t, []string{"eu-west-1a", "eu-west-1b"}, got.Zones)
+}
@@ -0,0 +1,162 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"reflect"
+	"regexp"
+	"strings"
+)
+
+// jsonSchemaDialect is the JSON Schema version written by WriteJSONSchema.
+const jsonSchemaDialect = "https://json-schema.org/draft/2020-12/schema"
+
+// WriteJSONSchema writes a JSON Schema describing the environment variables target, a struct or struct pointer,
+// is bound from, as an object with a property per key, written upper case after prefix.
+// Each property has the JSON type matching its field, its description from the field's help tag,
+// its default, being the non zero value of target's field as with WriteHelp, and its Go type as x-go-type.
+// Keys accepting any key under a prefix, see Keys, are written as patternProperties.
+func WriteJSONSchema(w io.Writer, target interface{}, prefix string) error {
+	b, err := json.MarshalIndent(jsonSchema(target, prefix), "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(append(b, '\n'))
+	return err
+}
+
+type jsonSchemaObject struct {
+	Schema            string                        `json:"$schema"`
+	Type              string                        `json:"type"`
+	Properties        map[string]jsonSchemaProperty `json:"properties"`
+	PatternProperties map[string]jsonSchemaProperty `json:"patternProperties,omitempty"`
+}
+
+type jsonSchemaProperty struct {
+	Type        string              `json:"type"`
+	Description string              `json:"description,omitempty"`
+	Default     interface{}         `json:"default,omitempty"`
+	Items       *jsonSchemaProperty `json:"items,omitempty"`
+	GoType      string              `json:"x-go-type,omitempty"`
+}
+
+func jsonSchema(target interface{}, prefix string) jsonSchemaObject {
+	values := reflect.Indirect(reflect.ValueOf(target))
+	schema := jsonSchemaObject{Schema: jsonSchemaDialect, Type: "object", Properties: make(map[string]jsonSchemaProperty)}
+	for _, f := range fields(structType(target)) {
+		if f.remain || f.rawMap {
+			if schema.PatternProperties == nil {
+				schema.PatternProperties = make(map[string]jsonSchemaProperty)
+			}
+			pattern := "^" + regexp.QuoteMeta(strings.TrimSuffix(exportKey(f, prefix), manifestWildcard))
+			schema.PatternProperties[pattern] = jsonSchemaProperty{Type: "string", Description: exportDescription(f)}
+			continue
+		}
+		octal := hasTagOption(f.StructField, tagOptionOctal)
+		p := jsonSchemaType(f.Type, octal)
+		p.Description = exportDescription(f)
+		p.GoType = helpTypeName(f.Type)
+		if v, ok := defaultValue(f, values); ok {
+			p.Default = jsonSchemaValue(v, octal)
+		}
+		schema.Properties[exportKey(f, prefix)] = p
+	}
+	return schema
+}
+
+func jsonSchemaType(t reflect.Type, octal bool) jsonSchemaProperty {
+	switch terraformType(t, octal) {
+	case "bool":
+		return jsonSchemaProperty{Type: "boolean"}
+	case "number":
+		if t.Kind() == reflect.Float32 || t.Kind() == reflect.Float64 {
+			return jsonSchemaProperty{Type: "number"}
+		}
+		return jsonSchemaProperty{Type: "integer"}
+	case "string":
+		return jsonSchemaProperty{Type: "string"}
+	}
+	items := jsonSchemaType(t.Elem(), octal)
+	return jsonSchemaProperty{Type: "array", Items: &items}
+}
+
+func jsonSchemaValue(v reflect.Value, octal bool) interface{} {
+	switch jsonSchemaType(v.Type(), octal).Type {
+	case "boolean", "integer", "number":
+		return v.Interface()
+	case "array":
+		items := make([]interface{}, v.Len())
+		for i := range items {
+			items[i] = jsonSchemaValue(v.Index(i), octal)
+		}
+		return items
+	}
+	return envString(v, octal)
+}
+
+// SchemaPublisher publishes the JSON Schema of a service's config to a central catalog. See PublishSchema.
+type SchemaPublisher interface {
+	PublishSchema(ctx context.Context, service, version string, schema []byte) error
+}
+
+// PublishSchema publishes the JSON Schema of target, as written by WriteJSONSchema with prefix, with p,
+// as version of service. Call it at startup to keep a catalog of every service's configuration up to date:
+//
+//	p := &config.HTTPSchemaPublisher{URL: "https://config-catalog.internal/schemas"}
+//	if err := config.PublishSchema(ctx, p, "billing", version, &c, "BILLING_"); err != nil {
+//		log.Printf("config: publishing schema: %v", err)
+//	}
+//
+// Publishing is best effort: a catalog being unavailable should rarely stop a service from starting.
+// See the aws module for publishing to S3.
+func PublishSchema(ctx context.Context, p SchemaPublisher, service, version string, target interface{}, prefix string) error {
+	var buf bytes.Buffer
+	if err := WriteJSONSchema(&buf, target, prefix); err != nil {
+		return err
+	}
+	return p.PublishSchema(ctx, service, version, buf.Bytes())
+}
+
+// HTTPSchemaPublisher is a SchemaPublisher which PUTs schemas to URL/service/version,
+// with a Content-Type of application/schema+json.
+type HTTPSchemaPublisher struct {
+	URL string
+	// Client sends the requests. If nil, http.DefaultClient is used.
+	Client *http.Client
+	// Header is added to each request, e.g. for authorization.
+	Header http.Header
+}
+
+// PublishSchema implements SchemaPublisher. Any response other than 2xx is an error.
+func (p *HTTPSchemaPublisher) PublishSchema(ctx context.Context, service, version string, schema []byte) error {
+	target := strings.TrimSuffix(p.URL, "/") + "/" + url.PathEscape(service) + "/" + url.PathEscape(version)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, target, bytes.NewReader(schema))
+	if err != nil {
+		return err
+	}
+	for k, vs := range p.Header {
+		for _, v := range vs {
+			req.Header.Add(k, v)
+		}
+	}
+	req.Header.Set("Content-Type", "application/schema+json")
+	client := p.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("%s: %s", target, resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,67 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteJSONSchema(t *testing.T) {
+	t.Parallel()
+	c := exportTestDefaults()
+	var buf bytes.Buffer
+	require.NoError(t, WriteJSONSchema(&buf, &c, "APP_"))
+	assert.JSONEq(t, `{
+  "$schema": "https://json-schema.org/draft/2020-12/schema",
+  "type": "object",
+  "properties": {
+    "APP_PORT": {"type": "integer", "description": "port to listen on", "default": 8080, "x-go-type": "int"},
+    "APP_TIMEOUT": {"type": "string", "description": "request timeout", "default": "5s", "x-go-type": "duration"},
+    "APP_WEIGHTS": {"type": "array", "items": {"type": "number"}, "default": [0.5, 1], "x-go-type": "[]float64"},
+    "APP_UMASK": {"type": "string", "default": "027", "x-go-type": "int"},
+    "APP_DEBUG": {"type": "boolean", "x-go-type": "bool"},
+    "APP_DATABASE__HOST": {"type": "string", "description": "database host,\nor a unix socket", "default": "db host", "x-go-type": "string"},
+    "APP_DATABASE__PASSWORD": {"type": "string", "default": "p#ss\"${x}", "x-go-type": "string"}
+  },
+  "patternProperties": {
+    "^APP_": {"type": "string"}
+  }
+}`, buf.String())
+}
+
+func TestPublishSchema(t *testing.T) {
+	t.Parallel()
+	var gotPath, gotType, gotAuth string
+	var gotSchema map[string]interface{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPut {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		gotPath, gotType, gotAuth = r.URL.EscapedPath(), r.Header.Get("Content-Type"), r.Header.Get("Authorization")
+		b, _ := ioutil.ReadAll(r.Body)
+		_ = json.Unmarshal(b, &gotSchema)
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer srv.Close()
+
+	p := &HTTPSchemaPublisher{URL: srv.URL + "/schemas/", Header: http.Header{"Authorization": {"Bearer t"}}}
+	require.NoError(t, PublishSchema(context.Background(), p, "billing", "v1.2.0+abc/1", exportTestConfig{}, ""))
+	assert.Equal(t, "/schemas/billing/v1.2.0+abc%2F1", gotPath)
+	assert.Equal(t, "application/schema+json", gotType)
+	assert.Equal(t, "Bearer t", gotAuth)
+	assert.Equal(t, jsonSchemaDialect, gotSchema["$schema"])
+	assert.Contains(t, gotSchema["properties"], "DATABASE__HOST")
+
+	p.URL = srv.URL + "/missing"
+	srv.Config.Handler = http.NotFoundHandler()
+	err := PublishSchema(context.Background(), p, "billing", "v1", exportTestConfig{}, "")
+	assert.EqualError(t, err, srv.URL+"/missing/billing/v1: 404 Not Found")
+}
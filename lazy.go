@@ -0,0 +1,118 @@
+package config
+
+import (
+	"context"
+	"reflect"
+	"sync"
+)
+
+// Lazy is a field type whose value is resolved by the ValuePreProcessor on first access rather than when bound,
+// for rarely used credentials in large configs, so that each start up does not fetch every secret:
+//
+//	type Config struct {
+//		ReportingPassword config.Lazy[string]
+//	}
+//
+//	password, err := c.ReportingPassword.Get()
+//
+// Values are only deferred when looked up from the environment with FromEnvLazy; values merged by other sources,
+// such as FromEnv, are resolved when merged, so only their conversion is deferred.
+// The value is resolved once, and the result, or error, cached. Copies of a bound Lazy share the cache.
+// The value is converted as a field of type T would be, including its tag options, with conversion errors
+// returned by Get rather than warned of. Lazy is not supported as the element of a slice.
+type Lazy[T any] struct {
+	// zero declares the type of the value to the functions describing fields, such as WriteHelp; it is never set.
+	zero  T
+	state *lazyState[T]
+}
+
+type lazyState[T any] struct {
+	// raw is the value the Lazy was bound from, before it is resolved or converted.
+	raw     string
+	once    sync.Once
+	resolve func(valuePtr interface{}) error
+	value   T
+	err     error
+}
+
+// Get resolves the value on first use, returning it, or the error resolving or converting it.
+// It returns the zero value of T if the key is unset, or the Lazy was not bound.
+func (l Lazy[T]) Get() (T, error) {
+	if l.state == nil {
+		return l.zero, nil
+	}
+	l.state.once.Do(func() {
+		l.state.err = l.state.resolve(&l.state.value)
+	})
+	return l.state.value, l.state.err
+}
+
+// MustGet is Get, panicking with the error, if any.
+func (l Lazy[T]) MustGet() T {
+	v, err := l.Get()
+	if err != nil {
+		panic(err)
+	}
+	return v
+}
+
+func (l *Lazy[T]) valuePtr() interface{} {
+	return &l.zero
+}
+
+func (l *Lazy[T]) bound(*Builder, string) {}
+
+func (l *Lazy[T]) setResolver(raw string, resolve func(valuePtr interface{}) error) {
+	l.state = &lazyState[T]{raw: raw, resolve: resolve}
+}
+
+// boundFrom returns the value the Lazy was bound from, without resolving it, and whether it was bound.
+func (l *Lazy[T]) boundFrom() (string, bool) {
+	if l.state == nil {
+		return "", false
+	}
+	return l.state.raw, true
+}
+
+// lazyField is implemented by wrapper fields whose value is resolved on first access. See Lazy.
+type lazyField interface {
+	wrapperField
+	setResolver(raw string, resolve func(valuePtr interface{}) error)
+	boundFrom() (string, bool)
+}
+
+// bindLazy binds a Lazy field to key. A value looked up lazily from the environment is kept as it is,
+// to be pre-processed on first access; any other value was pre-processed when merged.
+// The ValuePreProcessor, middleware and Clock are those of the Builder when bound, so that Get does not race
+// with later changes to the Builder, and the cache of Resolvers set up with WithCache is used.
+func (c *Builder) bindLazy(l lazyField, key string, fieldType reflect.StructField) {
+	c.bound[key] = true
+	literal := hasTagOption(fieldType, tagOptionLiteral)
+	value, deferred := "", false
+	if _, merged := c.configMap[key]; !merged && c.lazyEnv && !literal {
+		value, deferred = c.lookupEnv(key)
+	}
+	if !deferred {
+		lookup := c.lookup
+		if literal {
+			lookup = c.lookupLiteral
+		}
+		value, _ = lookup(key)
+	}
+	resolver := reresolver{valuePreProcessor: c.valuePreProcessor, middleware: append([]Middleware(nil), c.middleware...)}
+	// convertField only reads the slice delimiter and the Clock.
+	o := &Builder{sliceDelim: c.sliceDelim, clock: c.clock}
+	l.setResolver(value, func(valuePtr interface{}) error {
+		v := value
+		if deferred {
+			var err error
+			if v, err = resolver.reresolve(context.Background(), key, value); err != nil {
+				return err
+			}
+		}
+		if err := o.convertField(valuePtr, fieldType, v); err != nil {
+			return newError(CodeBadType, key, err)
+		}
+		return nil
+	})
+}
@@ -0,0 +1,102 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLazy(t *testing.T) {
+	type testConfig struct {
+		Password Lazy[string]
+		Port     Lazy[int]
+		Zones    Lazy[[]string]
+		Mode     Lazy[uint32] `config:",octal"`
+		Missing  Lazy[string]
+	}
+	os.Clearenv()
+	defer os.Clearenv()
+	require.NoError(t, os.Setenv("PASSWORD", "secret://x"))
+	require.NoError(t, os.Setenv("ZONES", "a b"))
+	require.NoError(t, os.Setenv("MODE", "0640"))
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{"app.config": "PORT=80\n"})
+
+	p := &countingPreProcessor{}
+	var c testConfig
+	WithValuePreProcessor(p).From(filepath.Join(dir, "app.config")).FromEnvLazy().To(&c)
+	assert.Equal(t, 0, p.calls, "values are not resolved when bound")
+
+	copied := c
+	assert.Equal(t, "resolved", c.Password.MustGet())
+	assert.Equal(t, "resolved", copied.Password.MustGet(), "copies share the resolved value")
+	assert.Equal(t, 1, p.calls, "values are resolved once")
+
+	assert.Equal(t, 80, c.Port.MustGet())
+	assert.Equal(t, []string{"a", "b"}, c.Zones.MustGet())
+	assert.Equal(t, uint32(0o640), c.Mode.MustGet(), "tag options apply to the value")
+	v, err := c.Missing.Get()
+	assert.NoError(t, err)
+	assert.Empty(t, v)
+
+	var unbound Lazy[int]
+	assert.Equal(t, 0, unbound.MustGet())
+}
+
+func TestLazy_errors(t *testing.T) {
+	type testConfig struct {
+		Port Lazy[int]
+	}
+	os.Clearenv()
+	defer os.Clearenv()
+	require.NoError(t, os.Setenv("PORT", "eighty"))
+
+	var c testConfig
+	FromEnv().To(&c)
+	_, err := c.Port.Get()
+	assert.Equal(t, CodeBadType, CodeOf(err))
+	assert.Empty(t, FromEnv().ToWithReport(&testConfig{}).Warnings, "conversion errors are returned by Get")
+
+	_, err = c.Port.Get()
+	assert.Equal(t, CodeBadType, CodeOf(err), "errors are cached")
+}
+
+func TestLazy_resolvers(t *testing.T) {
+	type testConfig struct {
+		Password Lazy[string]
+		Token    Lazy[string]
+	}
+	os.Clearenv()
+	defer os.Clearenv()
+	require.NoError(t, os.Setenv("PASSWORD", "a://db"))
+	require.NoError(t, os.Setenv("TOKEN", "a://missing"))
+
+	cache := NewMemoryCache()
+	require.NoError(t, cache.Set(context.Background(), "a://db", "cached", time.Hour))
+	r := NewResolvers(context.Background()).Register("a", ResolverFunc(func(ctx context.Context, ref string) (string, error) {
+		if ref == "missing" {
+			return "", errors.New("not found")
+		}
+		return "fetched", nil
+	})).WithCache(cache, time.Hour)
+	b := WithValuePreProcessor(r).FromEnvLazy()
+	var c testConfig
+	b.To(&c)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		b.Use(func(key, value string, next func(string) string) string { return next(value) }).WithValuePreProcessor(nil)
+	}()
+	assert.Equal(t, "cached", c.Password.MustGet(), "the value is read from the cache")
+	_, err := c.Token.Get()
+	<-done
+	assert.Equal(t, CodeSecretFetch, CodeOf(err))
+	assert.Equal(t, "token", err.(*Error).Key())
+}
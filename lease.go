@@ -0,0 +1,67 @@
+package config
+
+import (
+	"context"
+	"sort"
+	"time"
+)
+
+// LeasedValuePreProcessor is a ValuePreProcessor whose values can expire,
+// such as dynamic database credentials from Vault or temporary STS credentials.
+type LeasedValuePreProcessor interface {
+	ValuePreProcessor
+	// PreProcessLeasedValue is PreProcessValue, also returning how long the value remains valid.
+	// A ttl of zero means the value does not expire.
+	PreProcessLeasedValue(key, value string) (processed string, ttl time.Duration)
+}
+
+// Leases returns the expiry time of every leased value in the current config state.
+func (c *Builder) Leases() map[string]time.Time {
+	leases := make(map[string]time.Time, len(c.leases))
+	for k, v := range c.leases {
+		leases[k] = v
+	}
+	return leases
+}
+
+// LeaseExpiry returns the earliest lease expiry in the current config state.
+// ok is false if no values are leased.
+func (c *Builder) LeaseExpiry() (expiry time.Time, ok bool) {
+	for _, e := range c.leases {
+		if !ok || e.Before(expiry) {
+			expiry, ok = e, true
+		}
+	}
+	return expiry, ok
+}
+
+// WatchLeases calls reload once, renewBefore the earliest lease expires, with the keys expiring by then.
+// reload would typically build a fresh Builder and rebind, giving clients the chance to rotate credentials
+// before the old ones are revoked; the fresh Builder's leases can then be watched in turn.
+// WatchLeases returns immediately. Nothing is called if there are no leases or ctx is done first.
+func (c *Builder) WatchLeases(ctx context.Context, renewBefore time.Duration, reload func(expiring []string)) {
+	expiry, ok := c.LeaseExpiry()
+	if !ok {
+		return
+	}
+	deadline := expiry.Add(-renewBefore)
+
+	var expiring []string
+	for k, e := range c.leases {
+		if !e.After(expiry) {
+			expiring = append(expiring, k)
+		}
+	}
+	sort.Strings(expiring)
+
+	clock := clockOrSystem(c.clock)
+	go func() {
+		fired, stop := clock.NewTimer(deadline.Sub(clock.Now()))
+		defer stop()
+		select {
+		case <-ctx.Done():
+		case <-fired:
+			reload(expiring)
+		}
+	}()
+}
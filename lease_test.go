@@ -0,0 +1,67 @@
+package config
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type leasedPreProcessor struct {
+	ttls map[string]time.Duration
+}
+
+func (p *leasedPreProcessor) PreProcessValue(key, value string) string {
+	v, _ := p.PreProcessLeasedValue(key, value)
+	return v
+}
+
+func (p *leasedPreProcessor) PreProcessLeasedValue(key, value string) (string, time.Duration) {
+	if strings.HasPrefix(value, "lease://") {
+		return "credential", p.ttls[key]
+	}
+	return value, 0
+}
+
+func TestBuilder_Leases(t *testing.T) {
+	os.Clearenv()
+	defer os.Clearenv()
+	require.NoError(t, os.Setenv("DB__PASSWORD", "lease://db"))
+	require.NoError(t, os.Setenv("CACHE__PASSWORD", "lease://cache"))
+	require.NoError(t, os.Setenv("HOST", "localhost"))
+
+	p := &leasedPreProcessor{ttls: map[string]time.Duration{
+		"db__password":    50 * time.Millisecond,
+		"cache__password": time.Hour,
+	}}
+
+	start := time.Now()
+	b := WithValuePreProcessor(p).FromEnv()
+
+	leases := b.Leases()
+	require.Len(t, leases, 2)
+	assert.WithinDuration(t, start.Add(time.Hour), leases["cache__password"], time.Second)
+
+	expiry, ok := b.LeaseExpiry()
+	require.True(t, ok)
+	assert.Equal(t, leases["db__password"], expiry)
+
+	reloaded := make(chan []string, 1)
+	b.WatchLeases(context.Background(), 10*time.Millisecond, func(expiring []string) {
+		reloaded <- expiring
+	})
+	select {
+	case expiring := <-reloaded:
+		assert.Equal(t, []string{"db__password"}, expiring)
+		assert.True(t, time.Now().Before(expiry), "reload should happen before expiry")
+	case <-time.After(time.Second):
+		t.Fatal("reload was not triggered")
+	}
+
+	_, ok = FromEnv().LeaseExpiry()
+	assert.False(t, ok)
+}
@@ -0,0 +1,92 @@
+package config
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+const manifestWildcard = "*"
+
+// Keys returns the normalized keys target, a struct or struct pointer, is bound from, sorted.
+// Fields tagged `config:",remain"` accept any key under their struct's prefix, and are listed as the prefix followed by "*".
+// Likewise, map fields tagged `config:",raw"` are listed as their key, the struct delimiter and "*".
+func Keys(target interface{}) []string {
+	var keys []string
+	for _, f := range fields(structType(target)) {
+		switch {
+		case f.remain:
+			keys = append(keys, f.key+manifestWildcard)
+		case f.rawMap:
+			keys = append(keys, f.key+structDelim+manifestWildcard)
+		default:
+			keys = append(keys, f.key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// WriteManifest writes the keys target is bound from to w, one per line.
+// It is intended to be run at build time, e.g. from a go:generate program,
+// with the manifest embedded into the binary and passed to Builder.CheckManifest at startup.
+func WriteManifest(w io.Writer, target interface{}) error {
+	if _, err := fmt.Fprintln(w, "# Config keys, generated by config.WriteManifest. DO NOT EDIT."); err != nil {
+		return err
+	}
+	for _, k := range Keys(target) {
+		if _, err := fmt.Fprintln(w, k); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ReadManifest reads a manifest written by WriteManifest.
+// Blank lines and lines starting with '#' are ignored. Keys are normalized.
+func ReadManifest(r io.Reader) ([]string, error) {
+	var keys []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		keys = append(keys, NormalizeKey(line))
+	}
+	return keys, scanner.Err()
+}
+
+// CheckManifest returns an *Error with CodeUnknownKey if the current config state holds keys starting with prefix
+// that are not in manifest, e.g. stale environment variables left behind after a field was renamed.
+// Manifest entries ending in "*" match any key starting with the rest of the entry, and keys matching a pattern
+// passed to Ignore are never unknown. prefix is matched case insensitively; an empty prefix checks every key.
+// Whether a failed check should stop the application is up to the caller:
+//
+//	if err := b.CheckManifest("myapp__", manifest); err != nil {
+//		log.Fatal(err)
+//	}
+func (c *Builder) CheckManifest(prefix string, manifest []string) error {
+	prefix = NormalizeKey(prefix)
+	var unknown []string
+	for _, key := range c.keys() {
+		if strings.HasPrefix(key, prefix) && !inManifest(key, manifest) && !c.isIgnored(key) {
+			unknown = append(unknown, key)
+		}
+	}
+	if len(unknown) == 0 {
+		return nil
+	}
+	return newError(CodeUnknownKey, "", fmt.Errorf("keys not in manifest: %s", strings.Join(unknown, ", ")))
+}
+
+func inManifest(key string, manifest []string) bool {
+	for _, m := range manifest {
+		if m == key || (strings.HasSuffix(m, manifestWildcard) && strings.HasPrefix(key, strings.TrimSuffix(m, manifestWildcard))) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,60 @@
+package config
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type manifestConfig struct {
+	Database struct {
+		Host string
+		Port int
+	} `config:"DB"`
+	Window  TimeOfDay
+	Ignored string            `config:"-"`
+	Plugins map[string]string `config:",remain"`
+}
+
+func TestKeys(t *testing.T) {
+	t.Parallel()
+	assert.Equal(t, []string{"*", "db__host", "db__port", "window"}, Keys(&manifestConfig{}))
+}
+
+func TestManifest(t *testing.T) {
+	t.Parallel()
+	type testConfig struct {
+		Database struct {
+			Host string
+		} `config:"DB"`
+		Port int
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteManifest(&buf, testConfig{}))
+	manifest, err := ReadManifest(&buf)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"db__host", "port"}, manifest)
+
+	prefixed := func(values map[string]string) *Builder {
+		withPrefix := make(map[string]string)
+		for k, v := range values {
+			withPrefix["MYAPP__"+k] = v
+		}
+		withPrefix["HOME"] = "/root"
+		return FromSource(MapSource(withPrefix))
+	}
+	prefixedManifest := []string{"myapp__db__host", "myapp__port", "myapp__plugins__*"}
+
+	assert.NoError(t, prefixed(map[string]string{"DB__HOST": "h", "PLUGINS__X": "y"}).CheckManifest("MYAPP__", prefixedManifest))
+
+	err = prefixed(map[string]string{"DB__HOST": "h", "DATABASE__HOST": "stale", "PRT": "typo"}).CheckManifest("myapp__", prefixedManifest)
+	assert.EqualError(t, err, "config: CFG006: keys not in manifest: myapp__database__host, myapp__prt")
+	assert.Equal(t, CodeUnknownKey, CodeOf(err))
+
+	b := prefixed(map[string]string{"DB__HOST": "h", "DEBUG__TRACE": "true", "PRT": "typo"}).Ignore("MYAPP__DEBUG__*")
+	err = b.CheckManifest("myapp__", prefixedManifest)
+	assert.EqualError(t, err, "config: CFG006: keys not in manifest: myapp__prt", "ignored keys are not unknown")
+}
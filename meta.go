@@ -0,0 +1,55 @@
+package config
+
+import "time"
+
+// Meta is a field type holding a value together with where it came from, for applications which display
+// their settings, e.g. on an admin page:
+//
+//	type Config struct {
+//		LogLevel config.Meta[string]
+//		Database struct {
+//			Password config.Meta[string]
+//		}
+//	}
+//
+// Value is bound as a field of type T would be, including its tag options.
+// Meta is not supported as the element of a slice.
+type Meta[T any] struct {
+	Value T
+	// Key is the normalized key Value is bound from.
+	Key string
+	// Source describes the source the value was merged from, e.g. "file app.config" or "environment".
+	// It is empty if the key is unset.
+	Source string
+	// Resolved is when the value was resolved by the ValuePreProcessor, e.g. fetched from AWS Secrets Manager.
+	// It is zero if pre-processing left the value unchanged.
+	Resolved time.Time
+}
+
+func (m *Meta[T]) valuePtr() interface{} {
+	return &m.Value
+}
+
+func (m *Meta[T]) bound(c *Builder, key string) {
+	o := c.originOf(key)
+	m.Key, m.Source, m.Resolved = key, o.source, o.resolvedAt
+}
+
+// origin records where a key's value was merged from.
+type origin struct {
+	source     string
+	resolvedAt time.Time
+}
+
+// originOf returns where the value of key in the current config state was merged from.
+func (c *Builder) originOf(key string) origin {
+	if _, ok := c.configMap[key]; !ok && c.lazyEnv {
+		if _, ok := c.lookupEnv(key); ok {
+			return origin{source: sourceName(envSource{}), resolvedAt: c.lazyCache[key].resolvedAt}
+		}
+	}
+	if _, ok := c.lookupLiteral(key); !ok {
+		return origin{}
+	}
+	return c.origins[key]
+}
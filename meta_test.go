@@ -0,0 +1,75 @@
+package config
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type metaTestConfig struct {
+	Host     Meta[string]
+	Port     Meta[int]
+	Password Meta[string]
+	Zones    Meta[[]string]
+	Timeout  Meta[time.Duration] `help:"request timeout"`
+	Database struct {
+		User Meta[string] `config:"username"`
+	}
+}
+
+func TestMeta(t *testing.T) {
+	os.Clearenv()
+	defer os.Clearenv()
+	require.NoError(t, os.Setenv("PORT", "8080"))
+	dir := t.TempDir()
+	file := filepath.Join(dir, "app.config")
+	writeFiles(t, dir, map[string]string{"app.config": "HOST=localhost\nPORT=80\nPASSWORD=secret://x\n"})
+
+	before := time.Now()
+	var got metaTestConfig
+	b := WithValuePreProcessor(&countingPreProcessor{}).From(file).FromEnv().
+		FromSource(MapSource(map[string]string{"ZONES": "a b", "DATABASE__USERNAME": "app"}))
+	b.To(&got)
+
+	assert.Equal(t, Meta[string]{Value: "localhost", Key: "host", Source: "file " + file}, got.Host)
+	assert.Equal(t, Meta[int]{Value: 8080, Key: "port", Source: "environment"}, got.Port)
+	assert.Equal(t, Meta[[]string]{Value: []string{"a", "b"}, Key: "zones", Source: "source config.SourceFunc"}, got.Zones)
+	assert.Equal(t, Meta[string]{Value: "app", Key: "database__username", Source: "source config.SourceFunc"}, got.Database.User)
+	assert.Equal(t, Meta[time.Duration]{Key: "timeout"}, got.Timeout, "unset keys have no source")
+
+	assert.Equal(t, "resolved", got.Password.Value)
+	assert.Equal(t, "file "+file, got.Password.Source)
+	assert.False(t, got.Password.Resolved.Before(before), "resolved values record when they were resolved")
+	assert.True(t, got.Host.Resolved.IsZero())
+
+	assert.Equal(t, "database__username", KeyFor(&got, "Database", "User"))
+	assert.Equal(t, []string{"database__username", "host", "password", "port", "zones"}, b.Coverage(&got).Set)
+}
+
+func TestMeta_lazy(t *testing.T) {
+	os.Clearenv()
+	defer os.Clearenv()
+	require.NoError(t, os.Setenv("PASSWORD", "secret://x"))
+
+	var got metaTestConfig
+	FromSource(MapSource(map[string]string{"HOST": "localhost"})).
+		WithValuePreProcessor(&countingPreProcessor{}).FromEnvLazy().To(&got)
+	assert.Equal(t, "source config.SourceFunc", got.Host.Source)
+	assert.Equal(t, "resolved", got.Password.Value)
+	assert.Equal(t, "environment", got.Password.Source)
+	assert.False(t, got.Password.Resolved.IsZero())
+}
+
+func TestMeta_help(t *testing.T) {
+	t.Parallel()
+	defaults := metaTestConfig{Timeout: Meta[time.Duration]{Value: 5 * time.Second}}
+	var buf bytes.Buffer
+	WriteHelp(&buf, defaults, "")
+	assert.Contains(t, buf.String(), "  TIMEOUT duration\n    \trequest timeout (default 5s)\n")
+	assert.Contains(t, buf.String(), "  DATABASE__USERNAME string\n")
+}
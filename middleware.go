@@ -0,0 +1,45 @@
+package config
+
+// Middleware transforms a value as it is merged into the config state.
+// It is passed the value's normalized key and the value, and calls next to pass the value on down the chain,
+// ending with the ValuePreProcessor. A middleware can therefore act before the ValuePreProcessor,
+// by changing the value it passes to next, after it, by changing the value next returns, or instead of it,
+// by not calling next at all.
+//
+// Middleware is applied to every value, including those looked up lazily from the environment.
+// Values it changes are treated as resolved, like those changed by the ValuePreProcessor.
+type Middleware func(key, value string, next func(value string) string) string
+
+// Use creates a new builder with middleware.
+func Use(mw ...Middleware) *Builder {
+	return newBuilder().Use(mw...)
+}
+
+// Use appends middleware to the chain values pass through before reaching the ValuePreProcessor, returning the Builder.
+// The first middleware used is the outermost, seeing values first and results last, so
+//
+//	config.WithValuePreProcessor(secrets).Use(expandVars, decodeBase64).FromEnv()
+//
+// expands variables in each value, then decodes it, then resolves it as a secret.
+// Like the ValuePreProcessor, middleware applies to values merged after it is added.
+func (c *Builder) Use(mw ...Middleware) *Builder {
+	c.middleware = append(c.middleware, mw...)
+	return c
+}
+
+// runPipeline passes value through the middleware, ending with last.
+func (c *Builder) runPipeline(key, value string, last func(value string) string) string {
+	return runPipeline(c.middleware, key, value, last)
+}
+
+// runPipeline passes value through middleware, ending with last.
+func runPipeline(middleware []Middleware, key, value string, last func(value string) string) string {
+	h := last
+	for i := len(middleware) - 1; i >= 0; i-- {
+		mw, next := middleware[i], h
+		h = func(value string) string {
+			return mw(key, value, next)
+		}
+	}
+	return h(value)
+}
@@ -0,0 +1,68 @@
+package config
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuilder_Use(t *testing.T) {
+	t.Parallel()
+	var calls []string
+	trace := func(name string) Middleware {
+		return func(key, value string, next func(string) string) string {
+			calls = append(calls, name+">"+value)
+			result := next(value + "+" + name)
+			calls = append(calls, name+"<"+result)
+			return result
+		}
+	}
+	type testConfig struct{ A, B string }
+
+	var got testConfig
+	WithValuePreProcessor(&upperPreProcessor{}).
+		Use(trace("outer"), trace("inner")).
+		FromSource(MapSource(map[string]string{"A": "a"})).
+		To(&got)
+	assert.Equal(t, "A+OUTER+INNER", got.A)
+	assert.Equal(t, []string{
+		"outer>a",
+		"inner>a+outer",
+		"inner<A+OUTER+INNER",
+		"outer<A+OUTER+INNER",
+	}, calls)
+
+	skipLiterals := func(key, value string, next func(string) string) string {
+		if strings.HasPrefix(value, "literal:") {
+			return strings.TrimPrefix(value, "literal:")
+		}
+		return next(value)
+	}
+	got = testConfig{}
+	b := Use(skipLiterals).
+		WithValuePreProcessor(&upperPreProcessor{}).
+		FromSource(MapSource(map[string]string{"A": "literal:sm://x", "B": "b"}))
+	b.To(&got)
+	assert.Equal(t, testConfig{A: "sm://x", B: "B"}, got)
+
+	drifts, err := b.CheckDrift(context.Background())
+	require.NoError(t, err)
+	assert.Empty(t, drifts, "re-resolving should pass through the middleware too")
+}
+
+func TestBuilder_Use_lazy(t *testing.T) {
+	os.Clearenv()
+	defer os.Clearenv()
+	require.NoError(t, os.Setenv("A", " padded "))
+
+	trim := func(key, value string, next func(string) string) string {
+		return next(strings.TrimSpace(value))
+	}
+	var got struct{ A string }
+	Use(trim).FromEnvLazy().To(&got)
+	assert.Equal(t, "padded", got.A)
+}
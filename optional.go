@@ -0,0 +1,67 @@
+package config
+
+import (
+	"os"
+	"strings"
+)
+
+// Optional is a field type recording whether its key was set, for settings whose zero value is meaningful,
+// without resorting to pointer fields and nil checks:
+//
+//	type Config struct {
+//		MaxRetries config.Optional[int]
+//	}
+//
+//	retries := 3
+//	if c.MaxRetries.IsSet() {
+//		retries = c.MaxRetries.Get() // may be 0, to disable retries
+//	}
+//
+// A key set to an empty value, e.g. MAX_RETRIES= in a file, counts as set, and leaves the value as its zero value,
+// unless an earlier source set it. The value is bound as a field of type T would be, including its tag options.
+// Optional is not supported as the element of a slice.
+type Optional[T any] struct {
+	value T
+	set   bool
+}
+
+// IsSet reports whether the field's key was set by any source, even to an empty value.
+func (o Optional[T]) IsSet() bool {
+	return o.set
+}
+
+// Get returns the value, which is the zero value of T if the key is unset.
+func (o Optional[T]) Get() T {
+	return o.value
+}
+
+// Or returns the value if the key is set, or def otherwise.
+func (o Optional[T]) Or(def T) T {
+	if o.set {
+		return o.value
+	}
+	return def
+}
+
+func (o *Optional[T]) valuePtr() interface{} {
+	return &o.value
+}
+
+func (o *Optional[T]) bound(c *Builder, key string) {
+	o.set = c.isSet(key)
+}
+
+// isSet reports whether key was set by any source, including keys whose empty value was dropped.
+func (c *Builder) isSet(key string) bool {
+	if _, ok := c.lookupLiteral(key); ok || c.empty[key] {
+		return true
+	}
+	if c.lazyEnv {
+		for _, k := range []string{strings.ToUpper(key), key} {
+			if _, ok := os.LookupEnv(k); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,69 @@
+package config
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type optionalTestConfig struct {
+	Retries Optional[int]
+	Timeout Optional[time.Duration]
+	Name    Optional[string]
+	Zones   Optional[[]string]
+	Mode    Optional[uint32] `config:",octal" help:"file mode"`
+}
+
+func TestOptional(t *testing.T) {
+	os.Clearenv()
+	defer os.Clearenv()
+	require.NoError(t, os.Setenv("MODE", "0640"))
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{"app.config": "RETRIES=0\nNAME=\nZONES=a b\n"})
+
+	var got optionalTestConfig
+	From(filepath.Join(dir, "app.config")).FromEnv().To(&got)
+	assert.True(t, got.Retries.IsSet())
+	assert.Equal(t, 0, got.Retries.Get())
+	assert.Equal(t, 0, got.Retries.Or(3), "a value set to the zero value is still set")
+	assert.True(t, got.Name.IsSet(), "a key set to an empty value is set")
+	assert.Equal(t, "", got.Name.Get())
+	assert.False(t, got.Timeout.IsSet())
+	assert.Equal(t, time.Duration(0), got.Timeout.Get())
+	assert.Equal(t, time.Second, got.Timeout.Or(time.Second))
+	assert.Equal(t, []string{"a", "b"}, got.Zones.Get())
+	assert.Equal(t, uint32(0o640), got.Mode.Get(), "tag options apply to the value")
+
+	got = optionalTestConfig{}
+	FromSource(MapSource(map[string]string{"NAME": "base"})).
+		FromSource(MapSource(map[string]string{"NAME": ""})).To(&got)
+	assert.True(t, got.Name.IsSet())
+	assert.Equal(t, "base", got.Name.Get(), "an empty value keeps the value from an earlier source")
+}
+
+func TestOptional_lazy(t *testing.T) {
+	os.Clearenv()
+	defer os.Clearenv()
+	require.NoError(t, os.Setenv("NAME", ""))
+	require.NoError(t, os.Setenv("retries", "5"))
+
+	var got optionalTestConfig
+	FromEnvLazy().To(&got)
+	assert.True(t, got.Name.IsSet())
+	assert.Equal(t, 5, got.Retries.Get())
+	assert.False(t, got.Timeout.IsSet())
+}
+
+func TestOptional_help(t *testing.T) {
+	t.Parallel()
+	var buf bytes.Buffer
+	WriteHelp(&buf, optionalTestConfig{}, "")
+	assert.Contains(t, buf.String(), "  RETRIES int\n")
+	assert.Contains(t, buf.String(), "  MODE uint32\n    \tfile mode\n")
+	assert.Equal(t, "timeout", KeyFor(&optionalTestConfig{}, "Timeout"))
+}
@@ -0,0 +1,74 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+)
+
+// pathRewriter returns the function rewriting the values of a field tagged path, so that bad mount paths
+// are caught when the config is bound rather than at first use:
+//
+//	type Config struct {
+//		CertsDir string `config:",path,dir"`
+//		Keytab   string `config:",path,file,readable"`
+//	}
+//
+// Paths are written with the separators of the operating system, or forward slashes, which are converted.
+// A leading ~ is the user's home directory, and relative paths are made absolute against the working directory.
+// Paths are then cleaned, as filepath.Clean does. With exists the path must exist; with dir it must be a directory,
+// with file a regular file, and with readable it must be possible to open it for reading. Symbolic links are followed.
+// Empty values are left unset, rather than checked.
+func pathRewriter(t reflect.StructField) func(string) (string, error) {
+	exists, dir, file, readable := hasTagOption(t, tagOptionExists), hasTagOption(t, tagOptionDir),
+		hasTagOption(t, tagOptionFile), hasTagOption(t, tagOptionReadable)
+	return func(s string) (string, error) {
+		if s == "" {
+			return s, nil
+		}
+		path, err := absPath(s)
+		if err != nil {
+			return "", err
+		}
+		if !exists && !dir && !file && !readable {
+			return path, nil
+		}
+		info, err := os.Stat(path)
+		if err != nil {
+			return "", fmt.Errorf("invalid path %q: %w", s, err)
+		}
+		switch {
+		case dir && !info.IsDir():
+			return "", fmt.Errorf("invalid path %q: %s is not a directory", s, path)
+		case file && !info.Mode().IsRegular():
+			return "", fmt.Errorf("invalid path %q: %s is not a regular file", s, path)
+		}
+		if readable {
+			f, err := os.Open(path)
+			if err != nil {
+				return "", fmt.Errorf("invalid path %q: %w", s, err)
+			}
+			f.Close()
+		}
+		return path, nil
+	}
+}
+
+// absPath returns the absolute, clean form of path, with a leading ~ expanded to the user's home directory.
+func absPath(path string) (string, error) {
+	path = filepath.FromSlash(path)
+	if path == "~" || strings.HasPrefix(path, "~"+string(filepath.Separator)) {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("invalid path %q: %w", path, err)
+		}
+		path = home + path[1:]
+	}
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return "", fmt.Errorf("invalid path %q: %w", path, err)
+	}
+	return abs, nil
+}
@@ -0,0 +1,70 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_path(t *testing.T) {
+	os.Clearenv()
+	defer os.Clearenv()
+	dir := t.TempDir()
+	home := filepath.Join(dir, "home")
+	require.NoError(t, os.Setenv("HOME", home))
+	require.NoError(t, os.Setenv("USERPROFILE", home))
+	certs := filepath.Join(dir, "certs")
+	require.NoError(t, os.Mkdir(certs, 0o755))
+	keytab := filepath.Join(dir, "app.keytab")
+	require.NoError(t, os.WriteFile(keytab, []byte("k"), 0o600))
+	wd, err := os.Getwd()
+	require.NoError(t, err)
+
+	type Config struct {
+		Data     string   `config:",path"`
+		Cache    string   `config:",path"`
+		Plugins  []string `config:",path"`
+		CertsDir string   `config:"certs_dir,path,dir"`
+		Keytab   string   `config:",path,file,readable"`
+		Unset    string   `config:",path,exists"`
+	}
+	var c Config
+	err = FromSource(MapSource(map[string]string{
+		"DATA":      "./data/../state/",
+		"CACHE":     "~/cache",
+		"PLUGINS":   "a " + filepath.ToSlash(certs),
+		"CERTS_DIR": filepath.ToSlash(certs) + "/",
+		"KEYTAB":    keytab,
+	})).Bind(&c)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(wd, "state"), c.Data, "relative paths are cleaned and made absolute")
+	assert.Equal(t, filepath.Join(home, "cache"), c.Cache)
+	assert.Equal(t, []string{filepath.Join(wd, "a"), certs}, c.Plugins)
+	assert.Equal(t, certs, c.CertsDir)
+	assert.Equal(t, keytab, c.Keytab)
+	assert.Empty(t, c.Unset, "unset paths are not checked")
+
+	tests := map[string]struct {
+		value string
+		want  string
+	}{
+		"certs_dir": {keytab, "is not a directory"},
+		"keytab":    {certs, "is not a regular file"},
+		"unset":     {filepath.Join(dir, "missing"), "invalid path"},
+	}
+	for key, tt := range tests {
+		err := FromSource(MapSource(map[string]string{key: tt.value})).Bind(&Config{})
+		assert.Equal(t, CodeBadType, CodeOf(err), key)
+		assert.Contains(t, err.Error(), tt.want, key)
+	}
+
+	if runtime.GOOS != "windows" && os.Geteuid() != 0 {
+		require.NoError(t, os.Chmod(keytab, 0o200))
+		err = FromSource(MapSource(map[string]string{"keytab": keytab})).Bind(&Config{})
+		assert.Equal(t, CodeBadType, CodeOf(err), "unreadable files are rejected")
+	}
+}
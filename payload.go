@@ -0,0 +1,91 @@
+package config
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"mime"
+	"path"
+	"strings"
+)
+
+// Payload is a config document fetched by a remote source, such as URLSource, before it is parsed.
+type Payload struct {
+	// Name is the path of the document, e.g. prod/billing.yaml.gz, whose extension gives its format
+	// when ContentType does not. See DetectFormat.
+	Name        string
+	ContentType string
+	Data        []byte
+	// Sibling, when set, fetches the document stored alongside the payload, under its name followed by suffix,
+	// e.g. its detached signature, Name.sig. See VerifySignature.
+	Sibling func(ctx context.Context, suffix string) ([]byte, error)
+}
+
+// PayloadTransformer transforms a Payload before it is parsed, e.g. decompressing, decrypting or verifying it,
+// so that remote config can be stored compressed and encrypted. Transformers which remove an encoding should
+// remove its extension from Name, and its ContentType, so that the format of the document beneath is detected.
+type PayloadTransformer interface {
+	TransformPayload(ctx context.Context, p Payload) (Payload, error)
+}
+
+// PayloadTransformerFunc adapts a function to a PayloadTransformer.
+type PayloadTransformerFunc func(ctx context.Context, p Payload) (Payload, error)
+
+// TransformPayload calls f(ctx, p).
+func (f PayloadTransformerFunc) TransformPayload(ctx context.Context, p Payload) (Payload, error) {
+	return f(ctx, p)
+}
+
+// TransformPayload applies transformers to p in order, as a chain, e.g. decrypting then decompressing it.
+// It is used by sources fetching remote config, such as URLSource, and is exported for those of other modules.
+func TransformPayload(ctx context.Context, p Payload, transformers ...PayloadTransformer) (Payload, error) {
+	for _, t := range transformers {
+		transformed, err := t.TransformPayload(ctx, p)
+		if err != nil {
+			return Payload{}, fmt.Errorf("error transforming %s: %w", p.Name, err)
+		}
+		p = transformed
+	}
+	return p, nil
+}
+
+// LoadPayload transforms p with transformers, then parses it in the format given by its ContentType or Name.
+// See TransformPayload and DetectFormat.
+func LoadPayload(ctx context.Context, p Payload, transformers ...PayloadTransformer) (map[string]string, error) {
+	p, err := TransformPayload(ctx, p, transformers...)
+	if err != nil {
+		return nil, err
+	}
+	return ReaderSource(bytes.NewReader(p.Data), DetectFormat(p.ContentType, p.Name)).Load()
+}
+
+// gzipMagic starts every gzip stream.
+var gzipMagic = []byte{0x1f, 0x8b}
+
+// GzipPayload returns a PayloadTransformer decompressing gzip payloads, e.g. prod/billing.yaml.gz.
+// Payloads which are not gzip streams are left as they are, so it can be used whether or not they are compressed.
+func GzipPayload() PayloadTransformer {
+	return PayloadTransformerFunc(func(ctx context.Context, p Payload) (Payload, error) {
+		if !bytes.HasPrefix(p.Data, gzipMagic) {
+			return p, nil
+		}
+		r, err := gzip.NewReader(bytes.NewReader(p.Data))
+		if err != nil {
+			return Payload{}, err
+		}
+		data, err := ioutil.ReadAll(r)
+		if err != nil {
+			return Payload{}, err
+		}
+		if mediaType, _, _ := mime.ParseMediaType(p.ContentType); mediaType == "application/gzip" || mediaType == "application/x-gzip" {
+			p.ContentType = ""
+		}
+		if ext := path.Ext(p.Name); strings.EqualFold(ext, ".gz") {
+			p.Name = strings.TrimSuffix(p.Name, ext)
+		}
+		p.Data = data
+		return p, nil
+	})
+}
@@ -0,0 +1,79 @@
+package config
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func gzipped(t *testing.T, s string) []byte {
+	var b bytes.Buffer
+	w := gzip.NewWriter(&b)
+	_, err := w.Write([]byte(s))
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+	return b.Bytes()
+}
+
+func TestGzipPayload(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	p, err := GzipPayload().TransformPayload(ctx, Payload{Name: "/prod/app.yaml.GZ", ContentType: "application/gzip", Data: gzipped(t, "a: 1\n")})
+	require.NoError(t, err)
+	assert.Equal(t, Payload{Name: "/prod/app.yaml", Data: []byte("a: 1\n")}, p)
+
+	plain := Payload{Name: "/prod/app.env", ContentType: "text/plain", Data: []byte("A=1")}
+	p, err = GzipPayload().TransformPayload(ctx, plain)
+	require.NoError(t, err)
+	assert.Equal(t, plain, p, "payloads which are not compressed are left as they are")
+
+	_, err = GzipPayload().TransformPayload(ctx, Payload{Data: []byte{0x1f, 0x8b, 0}})
+	assert.Error(t, err)
+}
+
+func TestTransformPayload(t *testing.T) {
+	t.Parallel()
+	upper := PayloadTransformerFunc(func(ctx context.Context, p Payload) (Payload, error) {
+		p.Data = bytes.ToUpper(p.Data)
+		return p, nil
+	})
+	values, err := LoadPayload(context.Background(), Payload{Name: "app.env.gz", Data: gzipped(t, "port=abc")}, GzipPayload(), upper)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"port": "ABC"}, values, "transformers are applied in order")
+
+	failing := PayloadTransformerFunc(func(ctx context.Context, p Payload) (Payload, error) {
+		return Payload{}, errors.New("bad signature")
+	})
+	_, err = TransformPayload(context.Background(), Payload{Name: "app.env"}, upper, failing)
+	assert.EqualError(t, err, "error transforming app.env: bad signature")
+}
+
+func TestURLTransform(t *testing.T) {
+	t.Parallel()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/gzip")
+		_, _ = w.Write(gzipped(t, `{"database": {"host": "db"}}`))
+	}))
+	defer srv.Close()
+
+	var c struct {
+		Database struct{ Host string }
+	}
+	FromURL(context.Background(), srv.URL+"/app.json.gz", URLTransform(GzipPayload())).To(&c)
+	assert.Equal(t, "db", c.Database.Host)
+
+	failing := PayloadTransformerFunc(func(ctx context.Context, p Payload) (Payload, error) {
+		return Payload{}, errors.New("bad signature")
+	})
+	err := recoverError(func() { FromURL(context.Background(), srv.URL+"/app.json.gz", URLTransform(failing)) })
+	assert.Equal(t, CodeSourceLoad, CodeOf(err))
+	assert.True(t, strings.Contains(err.Error(), "error transforming /app.json.gz: bad signature"), err.Error())
+}
@@ -0,0 +1,133 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"time"
+)
+
+// WithRefreshInterval creates a new builder whose sources are re-fetched every d by Refresh.
+func WithRefreshInterval(d time.Duration) *Builder {
+	return newBuilder().WithRefreshInterval(d)
+}
+
+// WithRefreshInterval makes Refresh re-fetch the Builder's sources every d, returning the Builder.
+// This lets rotated secrets and tuned settings held remotely, e.g. in SSM, Secrets Manager, Consul or over HTTP,
+// be picked up without restart:
+//
+//	b := config.WithRefreshInterval(time.Minute).FromURL(ctx, url).FromEnv()
+//	b.To(&c)
+//	store := config.NewStore(&c)
+//	b.OnChange(store.Update)
+//	err := b.Refresh(ctx, &c, func(err error) { log.Print(err) })
+//
+// Readers then load the latest config with store.Load(). See Store.
+func (c *Builder) WithRefreshInterval(d time.Duration) *Builder {
+	c.refreshInterval = d
+	return c
+}
+
+// OnChange registers listener to be called by Refresh with the old and new config whenever it changes,
+// returning the Builder. Listeners are called in the order they were registered.
+func (c *Builder) OnChange(listener func(old, new interface{})) *Builder {
+	c.onChange = append(c.onChange, listener)
+	return c
+}
+
+// Refresh re-fetches every source merged into the Builder, in order, on the interval set by WithRefreshInterval,
+// and binds a fresh struct of target's type from them, as Bind does, calling the OnChange listeners
+// with target, or the config they were last passed as new, and the fresh struct if it differs, see Equal.
+// Values are resolved bypassing the cache of Resolvers set up with WithCache, so rotated secrets are seen.
+// Sources which fail to load, and config which fails to bind, are passed to onError, which may be nil,
+// and the previous config is kept until the next refresh. Listeners are called from a single goroutine,
+// which runs until ctx is done. Neither target nor the Builder is changed, so the Builder can still be used.
+// Refresh returns immediately, with an error if target is not a struct pointer or no interval is set.
+func (c *Builder) Refresh(ctx context.Context, target interface{}, onError func(error)) error {
+	t := reflect.TypeOf(target)
+	if t == nil || t.Kind() != reflect.Ptr || t.Elem().Kind() != reflect.Struct || reflect.ValueOf(target).IsNil() {
+		return newError(CodeUnsupportedField, "", fmt.Errorf("cannot refresh %T, it must be a non nil struct pointer", target))
+	}
+	if c.refreshInterval <= 0 {
+		return errors.New("config: no refresh interval, use WithRefreshInterval")
+	}
+	if onError == nil {
+		onError = func(error) {}
+	}
+	// The sources, options and listeners are copied, so the Builder can still be used while it is refreshed.
+	refetch := c.refetcher(append([]Source(nil), c.sources...))
+	interval, listeners := c.refreshInterval, append([]func(old, new interface{}){}, c.onChange...)
+
+	clock := clockOrSystem(c.clock)
+	go func() {
+		old := target
+		for {
+			fired, stop := clock.NewTimer(interval)
+			select {
+			case <-ctx.Done():
+				stop()
+				return
+			case <-fired:
+			}
+
+			fresh := reflect.New(t.Elem()).Interface()
+			b, err := refetch()
+			if err == nil {
+				err = b.Bind(fresh)
+			}
+			if err != nil {
+				onError(err)
+				continue
+			}
+			if Equal(old, fresh) || ctx.Err() != nil {
+				continue
+			}
+			for _, listener := range listeners {
+				listener(old, fresh)
+			}
+			old = fresh
+		}
+	}()
+	return nil
+}
+
+// refetcher returns a function building a new Builder with the Builder's options, populated by loading
+// sources afresh, which returns an *Error with CodeSourceLoad if a source fails to load.
+// The options are copied, so the Builder can still be changed.
+func (c *Builder) refetcher(sources []Source) func() (*Builder, error) {
+	o := *c
+	literal := make(map[string]bool, len(c.literal))
+	for k := range c.literal {
+		literal[k] = true
+	}
+	return func() (*Builder, error) {
+		b := newBuilder()
+		b.structDelim, b.sliceDelim = o.structDelim, o.sliceDelim
+		b.valuePreProcessor, b.middleware = refreshingPreProcessor(o.valuePreProcessor), o.middleware
+		b.duplicatePolicy, b.onDuplicate = o.duplicatePolicy, o.onDuplicate
+		b.onConversionError = o.onConversionError
+		b.deprecated, b.strict, b.ignored = o.deprecated, o.strict, o.ignored
+		b.clock, b.setters = o.clock, o.setters
+		for k := range literal {
+			b.literal[k] = true
+		}
+		if o.sealer != nil {
+			b.sealer = newSealer()
+		}
+		for i, s := range sources {
+			if o.lazyEnv && i == o.lazyEnvAt {
+				b.FromEnvLazy()
+			}
+			values, err := b.load(s)
+			if err != nil {
+				return nil, newError(CodeSourceLoad, "", fmt.Errorf("error refreshing %s: %w", sourceName(s), err))
+			}
+			b.mergeConfig(values, sourceName(s))
+		}
+		if o.lazyEnv && o.lazyEnvAt == len(sources) {
+			b.FromEnvLazy()
+		}
+		return b, nil
+	}
+}
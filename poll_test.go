@@ -0,0 +1,128 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuilder_Refresh(t *testing.T) {
+	os.Clearenv()
+	defer os.Clearenv()
+	require.NoError(t, os.Setenv("HOST", "env"))
+
+	type testConfig struct {
+		Port int
+		Host string
+	}
+	var mu sync.Mutex
+	remote := map[string]string{"PORT": "8080", "HOST": "remote"}
+	var fail error
+	s := SourceFunc(func() (map[string]string, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		values := make(map[string]string)
+		for k, v := range remote {
+			values[k] = v
+		}
+		return values, fail
+	})
+
+	clock := NewManualClock(time.Now())
+	b := WithClock(clock).WithRefreshInterval(time.Minute).FromSource(s).FromEnv()
+	var c testConfig
+	b.To(&c)
+	assert.Equal(t, testConfig{Port: 8080, Host: "env"}, c)
+
+	type change struct{ old, new *testConfig }
+	changes, errs := make(chan change, 10), make(chan error, 10)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	b.OnChange(func(old, new interface{}) {
+		changes <- change{old.(*testConfig), new.(*testConfig)}
+	})
+	require.NoError(t, b.Refresh(ctx, &c, func(err error) { errs <- err }))
+
+	mu.Lock()
+	remote["PORT"] = "9090"
+	mu.Unlock()
+	clock.BlockUntil(1)
+	clock.Advance(time.Minute)
+	select {
+	case ch := <-changes:
+		assert.Equal(t, &testConfig{Port: 8080, Host: "env"}, ch.old)
+		assert.Equal(t, &testConfig{Port: 9090, Host: "env"}, ch.new, "sources are layered as they were merged")
+	case <-time.After(time.Second):
+		t.Fatal("no change reported")
+	}
+	assert.Equal(t, testConfig{Port: 8080, Host: "env"}, c, "target is never changed")
+
+	mu.Lock()
+	fail = errors.New("unavailable")
+	mu.Unlock()
+	clock.BlockUntil(1)
+	clock.Advance(time.Minute)
+	select {
+	case err := <-errs:
+		assert.Equal(t, CodeSourceLoad, CodeOf(err))
+	case <-time.After(time.Second):
+		t.Fatal("no error reported")
+	}
+
+	mu.Lock()
+	fail = nil
+	mu.Unlock()
+	clock.BlockUntil(1)
+	clock.Advance(time.Minute)
+	clock.BlockUntil(1)
+	select {
+	case ch := <-changes:
+		t.Fatalf("unchanged config should not be reported, got %v", ch.new)
+	default:
+	}
+}
+
+func TestBuilder_Refresh_errors(t *testing.T) {
+	t.Parallel()
+	var c struct{ Port int }
+	assert.Equal(t, CodeUnsupportedField, CodeOf(WithRefreshInterval(time.Minute).Refresh(context.Background(), c, nil)))
+	assert.EqualError(t, FromSource(MapSource(nil)).Refresh(context.Background(), &c, nil), "config: no refresh interval, use WithRefreshInterval")
+}
+
+func TestBuilder_Refresh_wrappers(t *testing.T) {
+	t.Parallel()
+	type testConfig struct {
+		Port     Meta[int]
+		Password Lazy[string]
+	}
+	p := ValuePreProcessorFunc(func(key, value string) string {
+		return strings.TrimPrefix(value, "secret://")
+	})
+	clock := NewManualClock(time.Now())
+	b := WithClock(clock).WithRefreshInterval(time.Minute).WithValuePreProcessor(p).
+		FromSource(MapSource(map[string]string{"PORT": "secret://80", "PASSWORD": "secret://p"}))
+	var c testConfig
+	b.To(&c)
+
+	changes := make(chan interface{}, 10)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	require.NoError(t, b.OnChange(func(old, new interface{}) { changes <- new }).Refresh(ctx, &c, nil))
+	for i := 0; i < 3; i++ {
+		clock.BlockUntil(1)
+		clock.Advance(time.Minute)
+	}
+	clock.BlockUntil(1)
+	select {
+	case c := <-changes:
+		t.Fatalf("unchanged config should not be reported, got %+v", c)
+	default:
+	}
+}
@@ -0,0 +1,124 @@
+package config
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"strings"
+)
+
+// promptSource names the values read by PromptMissing, e.g. in a Meta's Source.
+const promptSource = "prompt"
+
+// errHiddenInput is returned by setEcho where input cannot be hidden.
+var errHiddenInput = errors.New("input cannot be hidden on this platform")
+
+// requiredError returns an *Error with CodeMissingKey listing the keys of target's fields tagged
+// `config:",required"` which are unset, if any.
+func (c *Builder) requiredError(target interface{}) error {
+	missing := c.missingKeys(target)
+	if len(missing) == 0 {
+		return nil
+	}
+	key := missing[0]
+	for i := range missing {
+		missing[i] = strings.ToUpper(missing[i])
+	}
+	return newError(CodeMissingKey, key, fmt.Errorf("required keys are unset: %s", strings.Join(missing, ", ")))
+}
+
+// missingKeys returns the keys of target's fields tagged `config:",required"` which are unset.
+func (c *Builder) missingKeys(target interface{}) []string {
+	var missing []string
+	for _, f := range fields(structType(target)) {
+		if f.remain || f.rawMap || !hasTagOption(f.StructField, tagOptionRequired) {
+			continue
+		}
+		if _, ok := c.lookup(f.key); !ok {
+			missing = append(missing, f.key)
+		}
+	}
+	return missing
+}
+
+// PromptMissing asks on the terminal for the values of target's fields which are unset in the current config state,
+// or with requiredOnly, only those tagged `config:",required"`, merging the answers, returning the Builder.
+// This lets CLI tools ask for what a user has not configured rather than fail:
+//
+//	type Config struct {
+//		User     string `config:",required"`
+//		Password string `config:",required,secret"`
+//	}
+//
+//	config.FromEnv().PromptMissing(&c, os.Stdin, true).To(&c)
+//
+// Prompts are written to stderr, with the field's help and default, if any. Fields tagged `config:",secret"`
+// are read without echo, or skipped on platforms where input cannot be hidden. Empty answers leave a field unset.
+// Nothing is asked unless in is a terminal, so scripts and CI fail on missing keys as they would otherwise.
+// It panics with an *Error with CodeSourceLoad if in cannot be read.
+func (c *Builder) PromptMissing(target interface{}, in *os.File, requiredOnly bool) *Builder {
+	if !isTerminal(in) {
+		return c
+	}
+	values, err := c.prompt(target, bufio.NewReader(in), os.Stderr, requiredOnly, func(on bool) error {
+		return setEcho(in, on)
+	})
+	if err != nil {
+		panic(newError(CodeSourceLoad, "", fmt.Errorf("error prompting for missing keys: %w", err)))
+	}
+	c.mergeConfig(values, promptSource)
+	// Answers are kept as given, rather than asked again, when sources are re-fetched by Refresh.
+	c.loaded(namedSource{name: promptSource, Source: MapSource(values)}, values)
+	return c
+}
+
+// prompt asks w for the values of target's unset fields, reading answers from r, with echo turned off by setEcho
+// while secret fields are read.
+func (c *Builder) prompt(target interface{}, r *bufio.Reader, w io.Writer, requiredOnly bool, setEcho func(on bool) error) (map[string]string, error) {
+	values := make(map[string]string)
+	defaults := reflect.Indirect(reflect.ValueOf(target))
+	for _, f := range fields(structType(target)) {
+		if f.remain || f.rawMap || (requiredOnly && !hasTagOption(f.StructField, tagOptionRequired)) {
+			continue
+		}
+		if _, ok := c.lookup(f.key); ok {
+			continue
+		}
+		key := strings.ToUpper(f.key)
+		secret := hasTagOption(f.StructField, tagOptionSecret)
+		if secret {
+			if err := setEcho(false); err != nil {
+				fmt.Fprintf(w, "%s: cannot be entered here, %v\n", key, err)
+				continue
+			}
+		}
+		question := key
+		if help := exportDescription(f); help != "" {
+			question += " (" + help + ")"
+		}
+		if v, ok := defaultValue(f, defaults); ok && !secret {
+			question += " [" + envString(v, hasTagOption(f.StructField, tagOptionOctal)) + "]"
+		}
+		fmt.Fprintf(w, "%s: ", question)
+		answer, err := r.ReadString('\n')
+		if secret {
+			fmt.Fprintln(w)
+			if err := setEcho(true); err != nil {
+				return nil, err
+			}
+		}
+		if answer = strings.TrimRight(answer, "\r\n"); answer != "" {
+			values[f.key] = answer
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return values, nil
+}
@@ -0,0 +1,83 @@
+package config
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type promptConfig struct {
+	User     string `config:",required" help:"Login name"`
+	Password string `config:",required,secret"`
+	Port     int
+	Host     string
+}
+
+func TestBuilder_prompt(t *testing.T) {
+	t.Parallel()
+	c := promptConfig{Port: 8080}
+	var echoes []bool
+	echo := func(on bool) error {
+		echoes = append(echoes, on)
+		return nil
+	}
+
+	var out bytes.Buffer
+	values, err := FromSource(MapSource(nil)).prompt(&c, bufio.NewReader(strings.NewReader("alice\nhunter2\n\nexample.com")), &out, false, echo)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"user": "alice", "password": "hunter2", "host": "example.com"}, values)
+	assert.Equal(t, "USER (Login name): PASSWORD: \nPORT [8080]: HOST: ", out.String())
+	assert.Equal(t, []bool{false, true}, echoes, "echo is off while the secret is read")
+
+	out.Reset()
+	values, err = FromSource(MapSource(map[string]string{"USER": "bob"})).prompt(&c, bufio.NewReader(strings.NewReader("")), &out, true, echo)
+	require.NoError(t, err)
+	assert.Empty(t, values)
+	assert.Equal(t, "PASSWORD: \n", out.String(), "set and optional keys are not asked")
+
+	out.Reset()
+	noEcho := func(on bool) error { return errHiddenInput }
+	values, err = FromSource(MapSource(nil)).prompt(&c, bufio.NewReader(strings.NewReader("alice\n")), &out, true, noEcho)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"user": "alice"}, values)
+	assert.Equal(t, "USER (Login name): PASSWORD: cannot be entered here, input cannot be hidden on this platform\n", out.String())
+
+	_, err = FromSource(MapSource(nil)).prompt(&c, bufio.NewReader(strings.NewReader("alice\n")), &out, true, func(on bool) error {
+		if on {
+			return errors.New("broken terminal")
+		}
+		return nil
+	})
+	assert.EqualError(t, err, "broken terminal")
+}
+
+func TestBuilder_PromptMissing(t *testing.T) {
+	r, w, err := os.Pipe()
+	require.NoError(t, err)
+	defer r.Close()
+	defer w.Close()
+
+	var c promptConfig
+	err = recoverError(func() { FromSource(MapSource(map[string]string{"USER": "bob"})).PromptMissing(&c, r, true).To(&c) })
+	assert.Equal(t, CodeMissingKey, CodeOf(err), "nothing is asked unless stdin is a terminal")
+}
+
+func TestBuilder_requiredError(t *testing.T) {
+	t.Parallel()
+	var c promptConfig
+	err := FromSource(MapSource(map[string]string{"PORT": "1"})).Bind(&c)
+	assert.Equal(t, CodeMissingKey, CodeOf(err))
+	assert.Contains(t, err.Error(), "required keys are unset: USER, PASSWORD")
+
+	err = FromSource(MapSource(map[string]string{"USER": "bob", "PASSWORD": ""})).Bind(&c)
+	assert.Contains(t, err.Error(), "required keys are unset: PASSWORD", "empty values are unset")
+
+	require.NoError(t, FromSource(MapSource(map[string]string{"USER": "bob", "PASSWORD": "p"})).Bind(&c))
+	assert.Equal(t, "bob", c.User)
+}
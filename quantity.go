@@ -0,0 +1,208 @@
+package config
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var quantityRe = regexp.MustCompile(`^([+-]?(?:[0-9]+(?:\.[0-9]*)?|\.[0-9]+))([a-zA-Z]*|[eE][+-]?[0-9]+)$`)
+
+// quantitySuffixes maps the suffixes of Kubernetes quantities to their multipliers, as powers of base.
+var quantitySuffixes = map[string]struct{ base, exp int64 }{
+	"n":  {10, -9},
+	"u":  {10, -6},
+	"m":  {10, -3},
+	"":   {10, 0},
+	"k":  {10, 3},
+	"M":  {10, 6},
+	"G":  {10, 9},
+	"T":  {10, 12},
+	"P":  {10, 15},
+	"E":  {10, 18},
+	"Ki": {2, 10},
+	"Mi": {2, 20},
+	"Gi": {2, 30},
+	"Ti": {2, 40},
+	"Pi": {2, 50},
+	"Ei": {2, 60},
+}
+
+// parseQuantity parses a Kubernetes style quantity, such as 500m, 1.5, 2Gi or 1e3, into its exact value.
+// It reports whether the quantity was written with a binary suffix.
+func parseQuantity(s string) (*big.Rat, bool, error) {
+	m := quantityRe.FindStringSubmatch(strings.TrimSpace(s))
+	if m == nil {
+		return nil, false, fmt.Errorf("cannot parse %q as a quantity", s)
+	}
+	r, ok := new(big.Rat).SetString(m[1])
+	if !ok {
+		return nil, false, fmt.Errorf("cannot parse %q as a quantity", s)
+	}
+
+	suffix := m[2]
+	base, exp := int64(10), int64(0)
+	if f, ok := quantitySuffixes[suffix]; ok {
+		base, exp = f.base, f.exp
+	} else if strings.HasPrefix(suffix, "e") || strings.HasPrefix(suffix, "E") {
+		e, err := strconv.ParseInt(suffix[1:], 10, 64)
+		if err != nil || e < -18 || e > 18 {
+			return nil, false, fmt.Errorf("cannot parse %q as a quantity: invalid exponent %q", s, suffix)
+		}
+		exp = e
+	} else {
+		return nil, false, fmt.Errorf("cannot parse %q as a quantity: unknown suffix %q", s, suffix)
+	}
+
+	multiplier := new(big.Int).Exp(big.NewInt(base), big.NewInt(abs(exp)), nil)
+	if exp < 0 {
+		return r.Quo(r, new(big.Rat).SetInt(multiplier)), false, nil
+	}
+	return r.Mul(r, new(big.Rat).SetInt(multiplier)), base == 2, nil
+}
+
+func abs(i int64) int64 {
+	if i < 0 {
+		return -i
+	}
+	return i
+}
+
+// quantityToDecimal rewrites a Kubernetes style quantity in plain decimal, e.g. 1Ki as 1024 and 500m as 0.5.
+// Empty strings are returned as is.
+func quantityToDecimal(s string) (string, error) {
+	if s == "" {
+		return s, nil
+	}
+	r, _, err := parseQuantity(s)
+	if err != nil {
+		return "", err
+	}
+	return ratToDecimal(r), nil
+}
+
+// ratToDecimal formats r in decimal, to at most 18 decimal places, without trailing zeros.
+func ratToDecimal(r *big.Rat) string {
+	if r.IsInt() {
+		return r.Num().String()
+	}
+	return strings.TrimRight(r.FloatString(18), "0")
+}
+
+// Quantity is an amount of a resource, such as CPU or memory, written in the style of Kubernetes resource quantities.
+// It is parsed from a decimal number, optionally followed by a decimal suffix (n, u, m, k, M, G, T, P, E),
+// a binary suffix (Ki, Mi, Gi, Ti, Pi, Ei) or an exponent (e3), e.g. "250m", "1.5", "2Gi" or "1e3".
+// The zero value is a quantity of 0.
+type Quantity struct {
+	value  *big.Rat
+	binary bool
+}
+
+// ParseQuantity parses a "250m", "1.5" or "2Gi" style value.
+func ParseQuantity(s string) (Quantity, error) {
+	r, binary, err := parseQuantity(s)
+	if err != nil {
+		return Quantity{}, fmt.Errorf("config: %w", err)
+	}
+	return Quantity{value: r, binary: binary}, nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (q *Quantity) UnmarshalText(text []byte) error {
+	parsed, err := ParseQuantity(string(text))
+	if err != nil {
+		return err
+	}
+	*q = parsed
+	return nil
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (q Quantity) MarshalText() ([]byte, error) {
+	return []byte(q.String()), nil
+}
+
+// String returns the quantity in canonical form: with the largest suffix that represents it exactly,
+// keeping binary suffixes for quantities parsed with one, e.g. "1.5Gi" is "1536Mi" and "0.5" is "500m".
+func (q Quantity) String() string {
+	r := q.rat()
+	if r.Sign() == 0 {
+		return "0"
+	}
+	if q.binary && r.IsInt() {
+		for _, suffix := range []string{"Ei", "Pi", "Ti", "Gi", "Mi", "Ki"} {
+			if scaled := scaleQuantity(r, suffix); scaled.IsInt() {
+				return scaled.Num().String() + suffix
+			}
+		}
+	}
+	for _, suffix := range []string{"E", "P", "T", "G", "M", "k", "", "m", "u", "n"} {
+		if scaled := scaleQuantity(r, suffix); scaled.IsInt() {
+			return scaled.Num().String() + suffix
+		}
+	}
+	return ratToDecimal(r)
+}
+
+// Value returns the quantity as an integer, rounded up, e.g. 1 for "250m".
+// Quantities beyond the range of int64 are clamped to it.
+func (q Quantity) Value() int64 {
+	return ceilInt64(q.rat())
+}
+
+// MilliValue returns the quantity in thousandths, rounded up, e.g. 250 for "250m".
+// Quantities beyond the range of int64 are clamped to it.
+func (q Quantity) MilliValue() int64 {
+	return ceilInt64(new(big.Rat).Mul(q.rat(), big.NewRat(1000, 1)))
+}
+
+// Float64 returns the nearest float64 to the quantity.
+func (q Quantity) Float64() float64 {
+	f, _ := q.rat().Float64()
+	return f
+}
+
+// Cmp compares q and other, returning -1, 0 or +1 as q is less than, equal to or greater than other.
+func (q Quantity) Cmp(other Quantity) int {
+	return q.rat().Cmp(other.rat())
+}
+
+// IsZero reports whether the quantity is 0.
+func (q Quantity) IsZero() bool {
+	return q.rat().Sign() == 0
+}
+
+func (q Quantity) rat() *big.Rat {
+	if q.value == nil {
+		return new(big.Rat)
+	}
+	return q.value
+}
+
+// scaleQuantity returns r in units of suffix.
+func scaleQuantity(r *big.Rat, suffix string) *big.Rat {
+	f := quantitySuffixes[suffix]
+	multiplier := new(big.Rat).SetInt(new(big.Int).Exp(big.NewInt(f.base), big.NewInt(abs(f.exp)), nil))
+	if f.exp < 0 {
+		return new(big.Rat).Mul(r, multiplier)
+	}
+	return new(big.Rat).Quo(r, multiplier)
+}
+
+func ceilInt64(r *big.Rat) int64 {
+	q, m := new(big.Int).DivMod(r.Num(), r.Denom(), new(big.Int))
+	if m.Sign() != 0 {
+		q.Add(q, big.NewInt(1))
+	}
+	switch {
+	case q.IsInt64():
+		return q.Int64()
+	case q.Sign() > 0:
+		return math.MaxInt64
+	default:
+		return math.MinInt64
+	}
+}
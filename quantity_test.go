@@ -0,0 +1,160 @@
+package config
+
+import (
+	"math"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_quantityToDecimal(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		in      string
+		want    string
+		wantErr bool
+	}{
+		{in: "", want: ""},
+		{in: "1", want: "1"},
+		{in: "1.5", want: "1.5"},
+		{in: ".5", want: "0.5"},
+		{in: "500m", want: "0.5"},
+		{in: "250m", want: "0.25"},
+		{in: "100n", want: "0.0000001"},
+		{in: "2k", want: "2000"},
+		{in: "1Ki", want: "1024"},
+		{in: "1Gi", want: "1073741824"},
+		{in: "1.5Gi", want: "1610612736"},
+		{in: "128M", want: "128000000"},
+		{in: "1e3", want: "1000"},
+		{in: "2E", want: "2000000000000000000"},
+		{in: "1Ei", want: "1152921504606846976"},
+		{in: "1E-3", want: "0.001"},
+		{in: "-2Mi", want: "-2097152"},
+		{in: " 1Ki ", want: "1024"},
+		{in: "1Xi", wantErr: true},
+		{in: "Gi", wantErr: true},
+		{in: "1 Gi", wantErr: true},
+		{in: "1e99", wantErr: true},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.in, func(t *testing.T) {
+			t.Parallel()
+			got, err := quantityToDecimal(tt.in)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_quantity(t *testing.T) {
+	type testConfig struct {
+		CPU      float64 `config:",quantity"`
+		Memory   int64   `config:",quantity"`
+		Sizes    []int   `config:",quantity"`
+		Fraction int     `config:",quantity"`
+		Plain    int
+	}
+
+	os.Clearenv()
+	defer os.Clearenv()
+	require.NoError(t, os.Setenv("CPU", "250m"))
+	require.NoError(t, os.Setenv("MEMORY", "512Mi"))
+	require.NoError(t, os.Setenv("SIZES", "1Ki 2k 3"))
+	require.NoError(t, os.Setenv("FRACTION", "500m"))
+	require.NoError(t, os.Setenv("PLAIN", "1Ki"))
+
+	var errKeys []string
+	var got testConfig
+	WithConversionErrorHandler(func(key, value string, err error) {
+		errKeys = append(errKeys, key)
+	}).FromEnv().To(&got)
+
+	assert.Equal(t, testConfig{
+		CPU:    0.25,
+		Memory: 512 << 20,
+		Sizes:  []int{1024, 2000, 3},
+	}, got)
+	assert.Equal(t, []string{"fraction", "plain"}, errKeys)
+}
+
+func TestParseQuantity(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		in         string
+		str        string
+		value      int64
+		milliValue int64
+		float      float64
+	}{
+		{in: "250m", str: "250m", value: 1, milliValue: 250, float: 0.25},
+		{in: "0.5", str: "500m", value: 1, milliValue: 500, float: 0.5},
+		{in: "1.5", str: "1500m", value: 2, milliValue: 1500, float: 1.5},
+		{in: "2000", str: "2k", value: 2000, milliValue: 2000000, float: 2000},
+		{in: "1e3", str: "1k", value: 1000, milliValue: 1000000, float: 1000},
+		{in: "2Gi", str: "2Gi", value: 2 << 30, milliValue: 2000 << 30, float: 2 << 30},
+		{in: "1.5Gi", str: "1536Mi", value: 1536 << 20, milliValue: 1536000 << 20, float: 1536 << 20},
+		{in: "0.5Ki", str: "512", value: 512, milliValue: 512000, float: 512},
+		{in: "-250m", str: "-250m", value: 0, milliValue: -250, float: -0.25},
+		{in: "0", str: "0", value: 0, milliValue: 0, float: 0},
+		{in: "100E", str: "100E", value: math.MaxInt64, milliValue: math.MaxInt64, float: 1e20},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.in, func(t *testing.T) {
+			t.Parallel()
+			q, err := ParseQuantity(tt.in)
+			require.NoError(t, err)
+			assert.Equal(t, tt.str, q.String())
+			assert.Equal(t, tt.value, q.Value())
+			assert.Equal(t, tt.milliValue, q.MilliValue())
+			assert.Equal(t, tt.float, q.Float64())
+
+			text, err := q.MarshalText()
+			require.NoError(t, err)
+			var roundTrip Quantity
+			require.NoError(t, roundTrip.UnmarshalText(text))
+			assert.Equal(t, 0, q.Cmp(roundTrip))
+		})
+	}
+
+	_, err := ParseQuantity("lots")
+	assert.EqualError(t, err, `config: cannot parse "lots" as a quantity`)
+
+	var zero Quantity
+	assert.True(t, zero.IsZero())
+	assert.Equal(t, "0", zero.String())
+	one, err := ParseQuantity("1")
+	require.NoError(t, err)
+	assert.Equal(t, -1, zero.Cmp(one))
+}
+
+func Test_quantityBinding(t *testing.T) {
+	type testConfig struct {
+		CPU    Quantity
+		Memory Quantity
+		Limits []Quantity
+		Unset  Quantity
+	}
+
+	os.Clearenv()
+	defer os.Clearenv()
+	require.NoError(t, os.Setenv("CPU", "250m"))
+	require.NoError(t, os.Setenv("MEMORY", "1Gi"))
+	require.NoError(t, os.Setenv("LIMITS", "1 2Mi"))
+
+	var got testConfig
+	FromEnv().To(&got)
+	assert.Equal(t, int64(250), got.CPU.MilliValue())
+	assert.Equal(t, int64(1<<30), got.Memory.Value())
+	require.Len(t, got.Limits, 2)
+	assert.Equal(t, "2Mi", got.Limits[1].String())
+	assert.True(t, got.Unset.IsZero())
+}
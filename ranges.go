@@ -0,0 +1,50 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// maxRangeEntries bounds the entries ranges expand to, so that a value such as 0-9999999999 cannot exhaust memory.
+const maxRangeEntries = 1 << 16
+
+// expandRanges expands the entries of an integer slice tagged `config:",range"` which are inclusive ranges,
+// such as 8000-8005, into each of their integers. Other entries are kept as they are, so 1 3-5 9 is 1 3 4 5 9.
+// It returns an error if a range is not of two decimal integers, its end is before its start,
+// or the ranges expand to more than maxRangeEntries entries.
+func expandRanges(values []string) ([]string, error) {
+	var out []string
+	for _, v := range values {
+		// The start of the range may be negative, e.g. -5--1.
+		i := -1
+		if v != "" {
+			i = strings.Index(v[1:], "-") + 1
+		}
+		if i < 1 {
+			out = append(out, v)
+			continue
+		}
+		start, err := strconv.ParseInt(strings.TrimSpace(v[:i]), 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("cannot parse %q as a range: %w", v, err)
+		}
+		end, err := strconv.ParseInt(strings.TrimSpace(v[i+1:]), 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("cannot parse %q as a range: %w", v, err)
+		}
+		if end < start {
+			return nil, fmt.Errorf("cannot use %q as a range, it ends before it starts", v)
+		}
+		if n := end - start; n < 0 || n >= maxRangeEntries-int64(len(out)) {
+			return nil, fmt.Errorf("cannot expand %q, ranges are limited to %d entries", v, maxRangeEntries)
+		}
+		for n := start; ; n++ {
+			out = append(out, strconv.FormatInt(n, 10))
+			if n == end {
+				break
+			}
+		}
+	}
+	return out, nil
+}
@@ -0,0 +1,76 @@
+package config
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_expandRanges(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		in      []string
+		want    []string
+		wantErr bool
+	}{
+		{in: nil, want: nil},
+		{in: []string{"1", "3"}, want: []string{"1", "3"}},
+		{in: []string{"8000-8003"}, want: []string{"8000", "8001", "8002", "8003"}},
+		{in: []string{"1", "3-5", "9"}, want: []string{"1", "3", "4", "5", "9"}},
+		{in: []string{"7-7"}, want: []string{"7"}},
+		{in: []string{"-2-1"}, want: []string{"-2", "-1", "0", "1"}},
+		{in: []string{"-5--4"}, want: []string{"-5", "-4"}},
+		{in: []string{"-3"}, want: []string{"-3"}},
+		{in: []string{""}, want: []string{""}},
+		{in: []string{"9223372036854775806-9223372036854775807"}, want: []string{"9223372036854775806", "9223372036854775807"}},
+		{in: []string{"5-3"}, wantErr: true},
+		{in: []string{"a-3"}, wantErr: true},
+		{in: []string{"1-"}, wantErr: true},
+		{in: []string{"1-2-3"}, wantErr: true},
+		{in: []string{"0-99999999999"}, wantErr: true},
+		{in: []string{"-9223372036854775808-9223372036854775807"}, wantErr: true},
+		{in: []string{"0-40000", "0-40000"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run("", func(t *testing.T) {
+			t.Parallel()
+			got, err := expandRanges(tt.in)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_range(t *testing.T) {
+	type testConfig struct {
+		Ports  []int    `config:",range"`
+		Shards []uint16 `config:",range"`
+		Plain  []int
+		Bad    []int `config:",range"`
+	}
+
+	os.Clearenv()
+	defer os.Clearenv()
+	require.NoError(t, os.Setenv("PORTS", "8000-8002 9000"))
+	require.NoError(t, os.Setenv("SHARDS", "0-3"))
+	require.NoError(t, os.Setenv("PLAIN", "1-2"))
+	require.NoError(t, os.Setenv("BAD", "3-1"))
+
+	var errKeys []string
+	var got testConfig
+	WithConversionErrorHandler(func(key, value string, err error) {
+		errKeys = append(errKeys, key)
+	}).FromEnv().To(&got)
+
+	assert.Equal(t, []int{8000, 8001, 8002, 9000}, got.Ports)
+	assert.Equal(t, []uint16{0, 1, 2, 3}, got.Shards)
+	assert.Nil(t, got.Bad)
+	assert.Equal(t, []string{"plain", "bad"}, errKeys)
+}
@@ -0,0 +1,96 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+const unlimited = "unlimited"
+
+// RateLimit is a number of events allowed per interval, e.g. the requests per second a client may make.
+// It is parsed from "100/s", "5000/m", "10/1h30m" or "unlimited" style values: a count, a slash,
+// and either a unit (s, m, h) or a time.Duration. The zero value is unlimited.
+type RateLimit struct {
+	Events   int
+	Interval time.Duration
+}
+
+// ParseRateLimit parses a "100/s" style value. No events, e.g. "0/s", is an error, as such a limit would
+// allow none; "unlimited" removes the limit.
+func ParseRateLimit(s string) (RateLimit, error) {
+	if s == unlimited {
+		return RateLimit{}, nil
+	}
+	parts := strings.SplitN(s, "/", 2)
+	if len(parts) != 2 {
+		return RateLimit{}, fmt.Errorf("config: invalid rate limit %q, want EVENTS/INTERVAL e.g. 100/s", s)
+	}
+	events, err := strconv.Atoi(parts[0])
+	if err != nil || events <= 0 {
+		return RateLimit{}, fmt.Errorf("config: invalid rate limit %q, events must be a positive integer, or use unlimited", s)
+	}
+	interval := parts[1]
+	if interval == "s" || interval == "m" || interval == "h" {
+		interval = "1" + interval
+	}
+	d, err := time.ParseDuration(interval)
+	if err != nil || d <= 0 {
+		return RateLimit{}, fmt.Errorf("config: invalid rate limit %q, interval must be s, m, h or a positive duration", s)
+	}
+	return RateLimit{Events: events, Interval: d}, nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (r *RateLimit) UnmarshalText(text []byte) error {
+	parsed, err := ParseRateLimit(string(text))
+	if err != nil {
+		return err
+	}
+	*r = parsed
+	return nil
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (r RateLimit) MarshalText() ([]byte, error) {
+	return []byte(r.String()), nil
+}
+
+// String returns the rate limit as EVENTS/INTERVAL, using a unit for whole seconds, minutes and hours.
+func (r RateLimit) String() string {
+	if r.Unlimited() {
+		return unlimited
+	}
+	interval := r.Interval.String()
+	switch r.Interval {
+	case time.Second:
+		interval = "s"
+	case time.Minute:
+		interval = "m"
+	case time.Hour:
+		interval = "h"
+	}
+	return strconv.Itoa(r.Events) + "/" + interval
+}
+
+// Unlimited reports whether the rate limit allows any number of events, as the zero value does.
+func (r RateLimit) Unlimited() bool {
+	return r.Interval == 0
+}
+
+// Limit returns the rate limit as events per second, or rate.Inf if it is unlimited.
+func (r RateLimit) Limit() rate.Limit {
+	if r.Unlimited() {
+		return rate.Inf
+	}
+	return rate.Limit(float64(r.Events) / r.Interval.Seconds())
+}
+
+// Limiter returns a rate.Limiter enforcing the rate limit, allowing bursts of up to Events events.
+// Use rate.NewLimiter(r.Limit(), burst) for a different burst size.
+func (r RateLimit) Limiter() *rate.Limiter {
+	return rate.NewLimiter(r.Limit(), r.Events)
+}
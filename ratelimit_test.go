@@ -0,0 +1,79 @@
+package config
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/time/rate"
+)
+
+func TestParseRateLimit(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		in      string
+		want    RateLimit
+		str     string
+		limit   rate.Limit
+		wantErr bool
+	}{
+		{in: "100/s", want: RateLimit{Events: 100, Interval: time.Second}, str: "100/s", limit: 100},
+		{in: "5000/m", want: RateLimit{Events: 5000, Interval: time.Minute}, str: "5000/m", limit: rate.Limit(5000.0 / 60)},
+		{in: "36/h", want: RateLimit{Events: 36, Interval: time.Hour}, str: "36/h", limit: 0.01},
+		{in: "10/500ms", want: RateLimit{Events: 10, Interval: 500 * time.Millisecond}, str: "10/500ms", limit: 20},
+		{in: "1/1m", want: RateLimit{Events: 1, Interval: time.Minute}, str: "1/m", limit: rate.Limit(1.0 / 60)},
+		{in: "unlimited", want: RateLimit{}, str: "unlimited", limit: rate.Inf},
+		{in: "100", wantErr: true},
+		{in: "0/s", wantErr: true},
+		{in: "-1/s", wantErr: true},
+		{in: "ten/s", wantErr: true},
+		{in: "10/d", wantErr: true},
+		{in: "10/0s", wantErr: true},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.in, func(t *testing.T) {
+			t.Parallel()
+			got, err := ParseRateLimit(tt.in)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.str, got.String())
+			assert.InDelta(t, float64(tt.limit), float64(got.Limit()), 1e-9)
+		})
+	}
+}
+
+func TestRateLimit_Limiter(t *testing.T) {
+	t.Parallel()
+	l := RateLimit{Events: 2, Interval: time.Hour}.Limiter()
+	assert.Equal(t, 2, l.Burst())
+	assert.True(t, l.Allow())
+	assert.True(t, l.Allow())
+	assert.False(t, l.Allow())
+
+	assert.Equal(t, rate.Inf, RateLimit{}.Limiter().Limit())
+}
+
+func Test_rateLimitBinding(t *testing.T) {
+	type testConfig struct {
+		API     RateLimit
+		Default RateLimit
+	}
+
+	os.Clearenv()
+	defer os.Clearenv()
+	require.NoError(t, os.Setenv("API", "100/s"))
+
+	got := testConfig{Default: RateLimit{Events: 5, Interval: time.Second}}
+	FromEnv().To(&got)
+	assert.Equal(t, testConfig{
+		API:     RateLimit{Events: 100, Interval: time.Second},
+		Default: RateLimit{Events: 5, Interval: time.Second},
+	}, got)
+}
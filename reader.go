@@ -0,0 +1,201 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime"
+	"os"
+	"path"
+	"strings"
+	"sync"
+)
+
+// Format is the format of the config read by ReaderSource.
+type Format int
+
+const (
+	// FormatDotenv is KEY=VALUE lines, parsed as by FileSource.
+	FormatDotenv Format = iota
+	// FormatYAML is a YAML document, flattened as by YAMLSource.
+	FormatYAML
+	// FormatJSON is a JSON object, flattened as by JSONSource.
+	FormatJSON
+	// FormatHCL is an HCL file, parsed by the github.com/imduffy15/config/hcl module once it is imported,
+	// see RegisterFormat.
+	FormatHCL
+)
+
+var formatNames = map[Format]string{
+	FormatDotenv: "dotenv",
+	FormatYAML:   "yaml",
+	FormatJSON:   "json",
+	FormatHCL:    "hcl",
+}
+
+func (f Format) String() string {
+	if name, ok := formatNames[f]; ok {
+		return name
+	}
+	return fmt.Sprintf("Format(%d)", int(f))
+}
+
+// FormatParser parses config in a Format, returning its values flattened into keys, e.g. database__host.
+type FormatParser func(b []byte) (map[string]string, error)
+
+var (
+	formatParsersMu sync.RWMutex
+	formatParsers   = make(map[Format]FormatParser)
+)
+
+// RegisterFormat makes ReaderSource, and so URLSource and the hcl sources of FromManifest, parse config in format
+// with parse. As with RegisterSourceScheme, it is intended to be called from the init function of the package
+// implementing the format, so that its dependencies are only pulled in by applications using it:
+//
+//	import _ "github.com/imduffy15/config/hcl"
+//
+// It panics if parse is nil, or format is built in or already registered.
+func RegisterFormat(format Format, parse FormatParser) {
+	formatParsersMu.Lock()
+	defer formatParsersMu.Unlock()
+	if parse == nil {
+		panic("config: RegisterFormat parser is nil")
+	}
+	if _, dup := formatParsers[format]; dup || format == FormatDotenv || format == FormatYAML || format == FormatJSON {
+		panic("config: RegisterFormat called twice for format " + format.String())
+	}
+	formatParsers[format] = parse
+}
+
+func registeredFormat(format Format) (FormatParser, bool) {
+	formatParsersMu.RLock()
+	defer formatParsersMu.RUnlock()
+	parse, ok := formatParsers[format]
+	return parse, ok
+}
+
+// ReaderSource returns a Source of the config in r, in format, for config which is not in a file,
+// e.g. a response body, a decrypted buffer or a test fixture:
+//
+//	config.FromReader(strings.NewReader("PORT=8080\n"), config.FormatDotenv).To(&c)
+//
+// r is read to its end when the source is loaded, so the source can only be loaded once.
+// If r is an io.Closer, it is not closed.
+func ReaderSource(r io.Reader, format Format) Source {
+	if format == FormatDotenv {
+		return dotenvReaderSource{r}
+	}
+	return namedSource{name: format.String() + " reader", Source: SourceFunc(func() (map[string]string, error) {
+		b, err := ioutil.ReadAll(r)
+		if err != nil {
+			return nil, err
+		}
+		switch format {
+		case FormatYAML:
+			return flattenYAML(b)
+		case FormatJSON:
+			return flattenJSON(b)
+		}
+		if parse, ok := registeredFormat(format); ok {
+			return parse(b)
+		}
+		if format == FormatHCL {
+			return nil, errors.New("no parser registered for format hcl, import github.com/imduffy15/config/hcl")
+		}
+		return nil, fmt.Errorf("unknown format %v", format)
+	})}
+}
+
+// formatFileSource returns a Source of file, in format, parsed as by ReaderSource.
+func formatFileSource(file string, format Format) Source {
+	return namedSource{name: "file " + file, Source: SourceFunc(func() (map[string]string, error) {
+		f, err := os.Open(file)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		values, err := ReaderSource(f, format).Load()
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", file, err)
+		}
+		return values, nil
+	})}
+}
+
+type dotenvReaderSource struct {
+	r io.Reader
+}
+
+func (s dotenvReaderSource) Load() (map[string]string, error) {
+	return loadLines(s)
+}
+
+func (s dotenvReaderSource) lines() ([]string, error) {
+	return readDotenv(s.r)
+}
+
+// DetectFormat returns the format of config downloaded with contentType, as chosen by URLSource:
+// JSON for application/json, YAML for application/yaml, application/x-yaml and text/yaml, HCL for application/hcl,
+// and otherwise KEY=VALUE lines. Without a contentType, or with application/octet-stream, the format is chosen
+// by the extension of name, e.g. .json or .yaml, so sources of stored objects can share the rules.
+func DetectFormat(contentType, name string) Format {
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	switch mediaType {
+	case "application/json":
+		return FormatJSON
+	case "application/yaml", "application/x-yaml", "text/yaml":
+		return FormatYAML
+	case "application/hcl":
+		return FormatHCL
+	case "", "application/octet-stream":
+		switch strings.ToLower(path.Ext(name)) {
+		case ".json":
+			return FormatJSON
+		case ".yaml", ".yml":
+			return FormatYAML
+		case ".hcl", ".tfvars":
+			return FormatHCL
+		}
+	}
+	return FormatDotenv
+}
+
+// FromReader returns a new Builder, populated with the config in r. See ReaderSource.
+// It panics with an *Error if r cannot be read or parsed.
+func FromReader(r io.Reader, format Format) *Builder {
+	return newBuilder().FromReader(r, format)
+}
+
+// FromReader merges the config in r into the current config state, returning the Builder.
+// See ReaderSource. It panics with an *Error if r cannot be read or parsed.
+func (c *Builder) FromReader(r io.Reader, format Format) *Builder {
+	return c.FromSource(ReaderSource(r, format))
+}
+
+// FromString returns a new Builder, populated with the KEY=VALUE lines in s.
+func FromString(s string) *Builder {
+	return newBuilder().FromString(s)
+}
+
+// FromString merges new values from the KEY=VALUE lines in s, parsed as by FileSource, into the current config state,
+// returning the Builder. This suits config received in an API response or generated at runtime:
+//
+//	config.FromString("PORT=8080\nHOST=localhost\n").FromEnv().To(&c)
+//
+// It panics with an *Error if s is malformed.
+func (c *Builder) FromString(s string) *Builder {
+	return c.FromReader(strings.NewReader(s), FormatDotenv)
+}
+
+// FromBytes returns a new Builder, populated with the KEY=VALUE lines in b. See FromString.
+func FromBytes(b []byte) *Builder {
+	return newBuilder().FromBytes(b)
+}
+
+// FromBytes merges new values from the KEY=VALUE lines in b into the current config state, returning the Builder.
+// See FromString. It panics with an *Error if b is malformed.
+func (c *Builder) FromBytes(b []byte) *Builder {
+	return c.FromReader(bytes.NewReader(b), FormatDotenv)
+}
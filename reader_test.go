@@ -0,0 +1,80 @@
+package config
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReaderSource(t *testing.T) {
+	t.Parallel()
+	for _, tc := range []struct {
+		format Format
+		in     string
+	}{
+		{FormatDotenv, "# fixture\nDATABASE__HOST=db.internal\nexport PORT=\"80\"\n"},
+		{FormatYAML, "database: {host: db.internal}\nport: 80\n"},
+		{FormatJSON, `{"database": {"host": "db.internal"}, "port": 80}`},
+	} {
+		values, err := ReaderSource(strings.NewReader(tc.in), tc.format).Load()
+		require.NoError(t, err, tc.format.String())
+		assert.Equal(t, map[string]string{"database__host": "db.internal", "port": "80"}, values, tc.format.String())
+	}
+
+	_, err := ReaderSource(strings.NewReader("PORT"), FormatDotenv).Load()
+	assert.EqualError(t, err, "line 1: missing '=' separator")
+	_, err = ReaderSource(strings.NewReader("[1]"), FormatJSON).Load()
+	assert.EqualError(t, err, "invalid JSON: json: cannot unmarshal array into Go value of type map[string]interface {}")
+	_, err = ReaderSource(iotest.ErrReader(errors.New("connection reset")), FormatYAML).Load()
+	assert.EqualError(t, err, "connection reset")
+	_, err = ReaderSource(strings.NewReader("port = 80\n"), FormatHCL).Load()
+	assert.EqualError(t, err, "no parser registered for format hcl, import github.com/imduffy15/config/hcl")
+	_, err = ReaderSource(strings.NewReader(""), Format(42)).Load()
+	assert.EqualError(t, err, "unknown format Format(42)")
+}
+
+func TestBuilder_FromReader(t *testing.T) {
+	t.Parallel()
+	var got struct {
+		Port int
+		Host string
+	}
+	var duplicates []string
+	WithDuplicatePolicy(FirstWins, func(key, kept, discarded string) { duplicates = append(duplicates, key) }).
+		FromReader(strings.NewReader("PORT=80\nPORT=81\nHOST=localhost\n"), FormatDotenv).
+		FromReader(strings.NewReader(`{"host": "db.internal"}`), FormatJSON).
+		To(&got)
+	assert.Equal(t, 80, got.Port, "the DuplicatePolicy applies to dotenv readers")
+	assert.Equal(t, []string{"port"}, duplicates)
+	assert.Equal(t, "db.internal", got.Host)
+
+	err := recoverError(func() { FromReader(strings.NewReader("host: [\n"), FormatYAML) })
+	assert.Equal(t, CodeSourceLoad, CodeOf(err))
+
+	var meta struct{ Host Meta[string] }
+	FromReader(strings.NewReader(`{"host": "h"}`), FormatJSON).To(&meta)
+	assert.Equal(t, "json reader", meta.Host.Source)
+}
+
+func TestBuilder_FromString(t *testing.T) {
+	t.Parallel()
+	type testConfig struct {
+		Port int
+		Host string
+		Cert string
+	}
+	var got testConfig
+	FromString("PORT=80\nHOST=localhost\n").
+		FromBytes([]byte("PORT=8080\nCERT=\"line 1\nline 2\"\n")).
+		To(&got)
+	assert.Equal(t, testConfig{Port: 8080, Host: "localhost", Cert: "line 1\nline 2"}, got)
+
+	err := recoverError(func() { FromString("HOST") })
+	assert.EqualError(t, err, "config: CFG004: unable to load source: line 1: missing '=' separator")
+	err = recoverError(func() { FromBytes([]byte("A='unterminated")) })
+	assert.Equal(t, CodeSourceLoad, CodeOf(err))
+}
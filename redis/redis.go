@@ -0,0 +1,53 @@
+// Package redis provides a config.ResolverCache backed by Redis, so that a fleet of processes
+// can share resolved values rather than each fetching them from the secret store.
+//
+// It lives in its own module, so that applications which do not cache in Redis do not depend on a Redis client.
+package redis
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/imduffy15/config"
+	goredis "github.com/redis/go-redis/v9"
+)
+
+// DefaultPrefix is prepended to the references cached by a Cache created with an empty prefix.
+const DefaultPrefix = "config:"
+
+// Cache is a config.ResolverCache storing values in Redis.
+// Values are stored as plain strings, so access to the Redis instance should be restricted as for the secrets themselves.
+type Cache struct {
+	client goredis.UniversalClient
+	prefix string
+}
+
+// NewCache returns a Cache storing values with client, under keys of prefix followed by the reference.
+// An empty prefix means DefaultPrefix.
+func NewCache(client goredis.UniversalClient, prefix string) *Cache {
+	if prefix == "" {
+		prefix = DefaultPrefix
+	}
+	return &Cache{client: client, prefix: prefix}
+}
+
+// Get returns the value cached for ref.
+func (c *Cache) Get(ctx context.Context, ref string) (string, bool, error) {
+	v, err := c.client.Get(ctx, c.prefix+ref).Result()
+	if errors.Is(err, goredis.Nil) {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, err
+	}
+	return v, true, nil
+}
+
+// Set caches value for ref, expiring after ttl. A ttl of zero means the value does not expire.
+func (c *Cache) Set(ctx context.Context, ref, value string, ttl time.Duration) error {
+	return c.client.Set(ctx, c.prefix+ref, value, ttl).Err()
+}
+
+// compile time assertion
+var _ config.ResolverCache = (*Cache)(nil)
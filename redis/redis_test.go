@@ -0,0 +1,72 @@
+package redis
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/imduffy15/config"
+	goredis "github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestCache(t *testing.T, prefix string) (*Cache, *miniredis.Miniredis) {
+	s := miniredis.RunT(t)
+	client := goredis.NewClient(&goredis.Options{Addr: s.Addr()})
+	t.Cleanup(func() { client.Close() })
+	return NewCache(client, prefix), s
+}
+
+func TestCache(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	c, s := newTestCache(t, "")
+
+	_, ok, err := c.Get(ctx, "sm://a")
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	require.NoError(t, c.Set(ctx, "sm://a", "1", time.Minute))
+	require.NoError(t, c.Set(ctx, "sm://b", "2", 0))
+	v, ok, err := c.Get(ctx, "sm://a")
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, "1", v)
+	assert.True(t, s.Exists("config:sm://a"))
+	assert.Equal(t, time.Minute, s.TTL("config:sm://a"))
+	assert.Equal(t, time.Duration(0), s.TTL("config:sm://b"))
+
+	s.FastForward(time.Minute)
+	_, ok, err = c.Get(ctx, "sm://a")
+	require.NoError(t, err)
+	assert.False(t, ok, "expired")
+
+	s.Close()
+	_, _, err = c.Get(ctx, "sm://b")
+	assert.Error(t, err)
+}
+
+func TestCache_Resolvers(t *testing.T) {
+	t.Parallel()
+	c, s := newTestCache(t, "app:")
+	var calls int
+	newResolvers := func() *config.Resolvers {
+		return config.NewResolvers(context.Background()).
+			Register("sm", config.ResolverFunc(func(ctx context.Context, ref string) (string, error) {
+				calls++
+				return "secret", nil
+			})).
+			WithCache(c, time.Hour)
+	}
+
+	var got struct{ Password string }
+	config.WithValuePreProcessor(newResolvers()).FromSource(config.MapSource(map[string]string{"PASSWORD": "sm://db"})).To(&got)
+	config.WithValuePreProcessor(newResolvers()).FromSource(config.MapSource(map[string]string{"PASSWORD": "sm://db"})).To(&got)
+	assert.Equal(t, "secret", got.Password)
+	assert.Equal(t, 1, calls)
+	v, err := s.Get("app:sm://db")
+	require.NoError(t, err)
+	assert.Equal(t, "secret", v)
+}
@@ -0,0 +1,132 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// refreshTagKey is the struct tag holding a field's refresh policy. See WatchRefresh.
+const refreshTagKey = "refresh"
+
+// RefreshNever is the refresh policy of fields whose values are never refreshed, e.g. `refresh:"never"`.
+const RefreshNever = "never"
+
+// RefreshPolicies returns how often the value of each field of target, a struct or struct pointer, is refreshed
+// by WatchRefresh, by key. Fields are tagged with an interval, e.g. `refresh:"30s"`, or `refresh:"never"`;
+// untagged fields are refreshed every def, or never if def is zero.
+// Fields which are never refreshed are omitted. It returns an *Error with CodeUnsupportedField
+// if a refresh tag is neither a positive duration nor never.
+func RefreshPolicies(target interface{}, def time.Duration) (map[string]time.Duration, error) {
+	policies := make(map[string]time.Duration)
+	for _, f := range fields(structType(target)) {
+		if f.remain || f.rawMap {
+			continue
+		}
+		interval := def
+		if tag, ok := f.Tag.Lookup(refreshTagKey); ok {
+			tag = strings.TrimSpace(tag)
+			if tag == RefreshNever {
+				continue
+			}
+			d, err := time.ParseDuration(tag)
+			if err != nil || d <= 0 {
+				return nil, newError(CodeUnsupportedField, f.key, fmt.Errorf("invalid %s tag %q, it must be a positive duration or %q", refreshTagKey, tag, RefreshNever))
+			}
+			interval = d
+		}
+		if interval > 0 {
+			policies[f.key] = interval
+		}
+	}
+	return policies, nil
+}
+
+// WatchRefresh re-resolves the values of target's fields on their refresh policy, see RefreshPolicies,
+// and calls reload with the keys whose upstream values changed since they were resolved or last reloaded.
+// Volatile values, such as feature flags, can so be refreshed often without stable ones, such as a database host,
+// triggering rebinds:
+//
+//	type Config struct {
+//		Flags    string `refresh:"30s"`
+//		Database struct {
+//			Host string `refresh:"never"`
+//		}
+//	}
+//
+// As with CheckDrift, only values changed by the ValuePreProcessor, e.g. secrets fetched from AWS, are refreshed,
+// bypassing the cache of Resolvers set up with WithCache.
+// Nothing is changed: reload would typically build a fresh Builder and rebind. Values which fail to re-resolve
+// are retried at their next refresh. reload is called from a single goroutine, which runs until ctx is done.
+// WatchRefresh returns immediately, with an error if target has an invalid refresh tag.
+func (c *Builder) WatchRefresh(ctx context.Context, target interface{}, def time.Duration, reload func(changed []string)) error {
+	policies, err := RefreshPolicies(target, def)
+	if err != nil {
+		return err
+	}
+	// The state is copied, so the Builder can still be used while values are refreshed.
+	references, current := make(map[string]string), make(map[string]string)
+	for key := range policies {
+		reference, ok := c.references[key]
+		if !ok {
+			continue
+		}
+		if v, ok := c.storedLookup(key); ok {
+			references[key], current[key] = reference, v
+		}
+	}
+	if len(references) == 0 {
+		return nil
+	}
+
+	clock, resolver := clockOrSystem(c.clock), c.reresolver()
+	go func() {
+		due := make(map[string]time.Time, len(references))
+		now := clock.Now()
+		for key := range references {
+			due[key] = now.Add(policies[key])
+		}
+		for {
+			next := nextRefresh(due)
+			fired, stop := clock.NewTimer(next.Sub(clock.Now()))
+			select {
+			case <-ctx.Done():
+				stop()
+				return
+			case <-fired:
+			}
+
+			var changed []string
+			for key, at := range due {
+				if at.After(next) {
+					continue
+				}
+				due[key] = at.Add(policies[key])
+				upstream, err := resolver.reresolve(ctx, key, references[key])
+				if err != nil || upstream == current[key] {
+					continue
+				}
+				current[key] = upstream
+				changed = append(changed, key)
+			}
+			if len(changed) > 0 && ctx.Err() == nil {
+				sort.Strings(changed)
+				reload(changed)
+			}
+		}
+	}()
+	return nil
+}
+
+// nextRefresh returns the earliest time in due.
+func nextRefresh(due map[string]time.Time) time.Time {
+	var next time.Time
+	for _, at := range due {
+		if next.IsZero() || at.Before(next) {
+			next = at
+		}
+	}
+	return next
+}
@@ -0,0 +1,126 @@
+package config
+
+import (
+	"context"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type refreshTestConfig struct {
+	Flags    string `refresh:"20ms"`
+	Password string
+	Database struct {
+		Host string `refresh:"never"`
+	}
+}
+
+func TestRefreshPolicies(t *testing.T) {
+	t.Parallel()
+	policies, err := RefreshPolicies(&refreshTestConfig{}, time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]time.Duration{"flags": 20 * time.Millisecond, "password": time.Hour}, policies)
+
+	policies, err = RefreshPolicies(&refreshTestConfig{}, 0)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]time.Duration{"flags": 20 * time.Millisecond}, policies)
+
+	_, err = RefreshPolicies(&struct {
+		Port int `refresh:"often"`
+	}{}, 0)
+	assert.Equal(t, CodeUnsupportedField, CodeOf(err))
+}
+
+func TestBuilder_WatchRefresh(t *testing.T) {
+	os.Clearenv()
+	defer os.Clearenv()
+	require.NoError(t, os.Setenv("FLAGS", "remote://flags"))
+	require.NoError(t, os.Setenv("PASSWORD", "remote://password"))
+	require.NoError(t, os.Setenv("DATABASE__HOST", "remote://host"))
+
+	var mu sync.Mutex
+	upstream := map[string]string{"flags": "a", "password": "p", "host": "h"}
+	p := ValuePreProcessorFunc(func(key, value string) string {
+		mu.Lock()
+		defer mu.Unlock()
+		if name := strings.TrimPrefix(value, "remote://"); name != value {
+			return upstream[name]
+		}
+		return value
+	})
+	b := WithValuePreProcessor(p).FromEnv()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	reloaded := make(chan []string, 10)
+	require.NoError(t, b.WatchRefresh(ctx, &refreshTestConfig{}, time.Hour, func(changed []string) {
+		reloaded <- changed
+	}))
+
+	mu.Lock()
+	upstream["flags"], upstream["password"], upstream["host"] = "b", "q", "i"
+	mu.Unlock()
+	select {
+	case changed := <-reloaded:
+		assert.Equal(t, []string{"flags"}, changed, "only fields due a refresh are re-resolved")
+	case <-time.After(time.Second):
+		t.Fatal("reload was not triggered")
+	}
+	select {
+	case changed := <-reloaded:
+		t.Fatalf("unchanged values should not trigger a reload, got %v", changed)
+	case <-time.After(60 * time.Millisecond):
+	}
+
+	err := b.WatchRefresh(ctx, &struct {
+		Flags string `refresh:"-1s"`
+	}{}, 0, func([]string) {})
+	assert.Equal(t, CodeUnsupportedField, CodeOf(err))
+}
+
+func TestBuilder_WatchRefresh_builderStillUsed(t *testing.T) {
+	os.Clearenv()
+	defer os.Clearenv()
+	require.NoError(t, os.Setenv("FLAGS", "remote://flags"))
+
+	var mu sync.Mutex
+	upstream := "a"
+	p := ValuePreProcessorFunc(func(key, value string) string {
+		mu.Lock()
+		defer mu.Unlock()
+		if value == "remote://flags" {
+			return upstream
+		}
+		return value
+	})
+	clock := NewManualClock(time.Unix(0, 0))
+	b := WithClock(clock).WithValuePreProcessor(p).FromEnv()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	reloaded := make(chan []string, 1)
+	require.NoError(t, b.WatchRefresh(ctx, &refreshTestConfig{}, 0, func(changed []string) {
+		reloaded <- changed
+	}))
+	clock.BlockUntil(1)
+
+	mu.Lock()
+	upstream = "b"
+	mu.Unlock()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		clock.Advance(20 * time.Millisecond)
+	}()
+	b.Use(func(key, value string, next func(string) string) string {
+		return strings.ToUpper(next(value))
+	}).WithValuePreProcessor(nil)
+	<-done
+
+	assert.Equal(t, []string{"flags"}, <-reloaded, "values are re-resolved as the Builder was when WatchRefresh was called")
+}
@@ -0,0 +1,114 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// SourceFactory creates a Source from a URL, e.g. consul://host/prefix.
+type SourceFactory func(u *url.URL) (Source, error)
+
+var (
+	sourceSchemesMu sync.RWMutex
+	sourceSchemes   = make(map[string]SourceFactory)
+)
+
+// RegisterSourceScheme makes From dispatch locations with the given URL scheme to factory.
+// It is intended to be called from the init function of the package implementing the source,
+// so that heavy integrations only need to be imported, for their side effect, by applications using them:
+//
+//	import _ "example.com/config-consul"
+//
+//	config.From("consul://localhost:8500/myapp").FromEnv().To(&c)
+//
+// Schemes are case insensitive. It panics if factory is nil or the scheme is already registered.
+func RegisterSourceScheme(scheme string, factory SourceFactory) {
+	sourceSchemesMu.Lock()
+	defer sourceSchemesMu.Unlock()
+	scheme = strings.ToLower(scheme)
+	if factory == nil {
+		panic("config: RegisterSourceScheme factory is nil")
+	}
+	if _, dup := sourceSchemes[scheme]; dup {
+		panic("config: RegisterSourceScheme called twice for scheme " + scheme)
+	}
+	sourceSchemes[scheme] = factory
+}
+
+// SourceSchemes returns the registered source schemes, sorted.
+func SourceSchemes() []string {
+	sourceSchemesMu.RLock()
+	defer sourceSchemesMu.RUnlock()
+	schemes := make([]string, 0, len(sourceSchemes))
+	for s := range sourceSchemes {
+		schemes = append(schemes, s)
+	}
+	sort.Strings(schemes)
+	return schemes
+}
+
+// SourceFor returns the Source for location, as used by From.
+// Locations of the form scheme://... are created by the factory registered for scheme with RegisterSourceScheme,
+// except for file://path, which like any other location is read with FileSource.
+func SourceFor(location string) (Source, error) {
+	if !strings.Contains(location, "://") {
+		return FileSource(location), nil
+	}
+	u, err := url.Parse(location)
+	if err != nil {
+		return nil, err
+	}
+	scheme := strings.ToLower(u.Scheme)
+	if scheme == "file" {
+		return FileSource(u.Host + u.Path), nil
+	}
+
+	sourceSchemesMu.RLock()
+	factory, ok := sourceSchemes[scheme]
+	sourceSchemesMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("no source registered for scheme %q", u.Scheme)
+	}
+	return factory(u)
+}
+
+var (
+	resolversMu sync.RWMutex
+	resolvers   = make(map[string]Resolver)
+)
+
+// RegisterResolver makes r available to source manifests, which enable resolvers by scheme. See FromManifest.
+// It panics if r is nil or the scheme is already registered.
+func RegisterResolver(scheme string, r Resolver) {
+	resolversMu.Lock()
+	defer resolversMu.Unlock()
+	if r == nil {
+		panic("config: RegisterResolver resolver is nil")
+	}
+	if _, dup := resolvers[scheme]; dup {
+		panic("config: RegisterResolver called twice for scheme " + scheme)
+	}
+	resolvers[scheme] = r
+}
+
+// ResolverSchemes returns the schemes registered with RegisterResolver, sorted.
+func ResolverSchemes() []string {
+	resolversMu.RLock()
+	defer resolversMu.RUnlock()
+	schemes := make([]string, 0, len(resolvers))
+	for s := range resolvers {
+		schemes = append(schemes, s)
+	}
+	sort.Strings(schemes)
+	return schemes
+}
+
+func registeredResolver(scheme string) (Resolver, bool) {
+	resolversMu.RLock()
+	defer resolversMu.RUnlock()
+	r, ok := resolvers[scheme]
+	return r, ok
+}
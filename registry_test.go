@@ -0,0 +1,67 @@
+package config
+
+import (
+	"context"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegisterSourceScheme(t *testing.T) {
+	RegisterSourceScheme("Test-KV", func(u *url.URL) (Source, error) {
+		return MapSource(map[string]string{"HOST": u.Host, "PREFIX": u.Path}), nil
+	})
+
+	assert.Contains(t, SourceSchemes(), "test-kv")
+	assert.Panics(t, func() { RegisterSourceScheme("test-kv", func(*url.URL) (Source, error) { return nil, nil }) })
+	assert.Panics(t, func() { RegisterSourceScheme("nil", nil) })
+
+	var got struct {
+		Host   string
+		Prefix string
+	}
+	From("test-kv://localhost:8500/myapp").To(&got)
+	assert.Equal(t, "localhost:8500", got.Host)
+	assert.Equal(t, "/myapp", got.Prefix)
+
+	err := recoverError(func() { From("unknown://x") })
+	assert.EqualError(t, err, `config: CFG004: no source registered for scheme "unknown"`)
+}
+
+func TestRegisterResolver(t *testing.T) {
+	RegisterResolver("test-resolver", ResolverFunc(func(ctx context.Context, ref string) (string, error) {
+		return "resolved:" + ref, nil
+	}))
+
+	assert.Contains(t, ResolverSchemes(), "test-resolver")
+	assert.Panics(t, func() {
+		RegisterResolver("test-resolver", ResolverFunc(func(context.Context, string) (string, error) { return "", nil }))
+	})
+	assert.Panics(t, func() { RegisterResolver("nil", nil) })
+	r, ok := registeredResolver("test-resolver")
+	require.True(t, ok)
+	v, err := r.Resolve(context.Background(), "x")
+	require.NoError(t, err)
+	assert.Equal(t, "resolved:x", v)
+}
+
+func TestSourceFor_file(t *testing.T) {
+	t.Parallel()
+	file, err := ioutil.TempFile("", "testenv")
+	require.NoError(t, err)
+	defer os.Remove(file.Name())
+	_, err = file.Write([]byte("A=1"))
+	require.NoError(t, err)
+
+	for _, location := range []string{file.Name(), "file://" + file.Name()} {
+		s, err := SourceFor(location)
+		require.NoError(t, err)
+		values, err := s.Load()
+		require.NoError(t, err)
+		assert.Equal(t, map[string]string{"a": "1"}, values, location)
+	}
+}
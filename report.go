@@ -0,0 +1,175 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// FieldOutcome is the result of binding a single field.
+type FieldOutcome string
+
+const (
+	// FieldSet means the field was set from a value.
+	FieldSet FieldOutcome = "set"
+	// FieldUnset means there was no value for the field, so it was left as its zero value.
+	FieldUnset FieldOutcome = "unset"
+	// FieldInvalid means the field's value could not be converted to its type, so it was left as its zero value.
+	FieldInvalid FieldOutcome = "invalid"
+)
+
+// FieldReport is the outcome of binding a single field.
+type FieldReport struct {
+	// Key is the normalized key the field is bound from.
+	// For fields tagged `config:",remain"` it is the prefix of the keys the field receives.
+	Key string `json:"key"`
+	// Field is the path to the field from the target struct, e.g. "Database.Host".
+	Field   string       `json:"field"`
+	Outcome FieldOutcome `json:"outcome"`
+	// SimilarKey is set for unset fields to a similar key in the config state which no field is bound to,
+	// and which is probably a typo of Key.
+	SimilarKey string `json:"similar_key,omitempty"`
+}
+
+// Resolution records how long resolving a value took, e.g. fetching a secret from AWS.
+type Resolution struct {
+	Key      string        `json:"key"`
+	Duration time.Duration `json:"duration"`
+}
+
+// Report describes the outcome of binding a target with ToWithReport, for logging, asserting on in tests,
+// or exposing on an admin endpoint. It holds keys and outcomes, never values.
+type Report struct {
+	// Fields holds the outcome of each of the target's fields, in declaration order.
+	Fields []FieldReport `json:"fields"`
+	// Warnings holds findings which do not prevent binding, but may indicate a mistake: the warnings found by
+	// the bind, as Builder.Warnings holds them, including any already raised by an earlier bind, as strings.
+	Warnings []string `json:"warnings,omitempty"`
+	// UnusedKeys holds the keys in the config state not bound by this or any earlier target, sorted.
+	UnusedKeys []string `json:"unused_keys,omitempty"`
+	// Resolutions holds the time taken to resolve each value changed by the ValuePreProcessor, sorted by key.
+	Resolutions []Resolution `json:"resolutions,omitempty"`
+	// Errors holds the conversion errors raised while binding, each an *Error with CodeBadType.
+	Errors []error `json:"-"`
+}
+
+// OK reports whether the target was bound without errors.
+func (r *Report) OK() bool {
+	return len(r.Errors) == 0
+}
+
+// Err returns the report's errors as a single error, or nil if there are none.
+func (r *Report) Err() error {
+	switch len(r.Errors) {
+	case 0:
+		return nil
+	case 1:
+		return r.Errors[0]
+	}
+	return errorList(r.Errors)
+}
+
+// Outcome returns the outcome of the field bound from key, and whether there is such a field.
+func (r *Report) Outcome(key string) (FieldOutcome, bool) {
+	key = NormalizeKey(key)
+	for _, f := range r.Fields {
+		if f.Key == key {
+			return f.Outcome, true
+		}
+	}
+	return "", false
+}
+
+// String summarizes the report on a single line, e.g. for logging at startup.
+func (r *Report) String() string {
+	counts := make(map[FieldOutcome]int)
+	for _, f := range r.Fields {
+		counts[f.Outcome]++
+	}
+	var slowest Resolution
+	for _, res := range r.Resolutions {
+		if res.Duration > slowest.Duration {
+			slowest = res
+		}
+	}
+	s := fmt.Sprintf("%d fields set, %d unset, %d invalid; %d unused keys; %d values resolved",
+		counts[FieldSet], counts[FieldUnset], counts[FieldInvalid], len(r.UnusedKeys), len(r.Resolutions))
+	if slowest.Key != "" {
+		s += fmt.Sprintf(", slowest %s in %v", slowest.Key, slowest.Duration)
+	}
+	if len(r.Warnings) > 0 {
+		s += "; warnings: " + strings.Join(r.Warnings, "; ")
+	}
+	if len(r.Errors) > 0 {
+		msgs := make([]string, len(r.Errors))
+		for i, err := range r.Errors {
+			msgs[i] = err.Error()
+		}
+		s += "; errors: " + strings.Join(msgs, "; ")
+	}
+	return s
+}
+
+// ToWithReport is To, also returning a Report of the outcome.
+// Conversion errors are still passed to the handler set by WithConversionErrorHandler, if any.
+// In strict mode, unbound keys are reported in Errors rather than panicking.
+func (c *Builder) ToWithReport(target interface{}) *Report {
+	r := &Report{}
+	invalid := make(map[string]bool)
+	onConversionError := c.onConversionError
+	c.onConversionError = func(key, value string, err error) {
+		invalid[key] = true
+		r.Errors = append(r.Errors, err)
+		if onConversionError != nil {
+			onConversionError(key, value, err)
+		}
+	}
+	defer func() { c.onConversionError = onConversionError }()
+	c.bind(target)
+	if err := c.strictError(); err != nil {
+		r.Errors = append(r.Errors, err)
+	}
+	if err := c.requiredError(target); err != nil {
+		r.Errors = append(r.Errors, err)
+	}
+	if err := c.groupError(target); err != nil {
+		r.Errors = append(r.Errors, err)
+	}
+
+	fs := fields(structType(target))
+	similar := c.similarKeys(fs)
+	for _, f := range fs {
+		fr := FieldReport{Key: f.key, Field: strings.Join(f.path, "."), Outcome: FieldUnset}
+		switch {
+		case f.remain:
+			if c.hasUnbound(f.key, siblingKeys(fs, f)) {
+				fr.Outcome = FieldSet
+			}
+		case f.rawMap:
+			if c.hasUnbound(f.key+structDelim, nil) {
+				fr.Outcome = FieldSet
+			}
+		case invalid[f.key]:
+			fr.Outcome = FieldInvalid
+		default:
+			if _, ok := c.lookup(f.key); ok {
+				fr.Outcome = FieldSet
+			}
+		}
+		if similar, ok := similar[f.key]; ok && fr.Outcome == FieldUnset {
+			fr.SimilarKey = similar
+		}
+		r.Fields = append(r.Fields, fr)
+	}
+	for _, w := range c.found {
+		r.Warnings = append(r.Warnings, w.String())
+	}
+
+	r.UnusedKeys = c.UnboundKeys("")
+	for key, d := range c.resolveTimes {
+		r.Resolutions = append(r.Resolutions, Resolution{Key: key, Duration: d})
+	}
+	sort.Slice(r.Resolutions, func(i, j int) bool { return r.Resolutions[i].Key < r.Resolutions[j].Key })
+	return r
+}
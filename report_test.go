@@ -0,0 +1,73 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuilder_ToWithReport(t *testing.T) {
+	t.Parallel()
+	type testConfig struct {
+		Port     int
+		Host     string
+		Password string
+		Database struct {
+			Name string
+		}
+		Plugin struct {
+			Name string
+			Rest map[string]string `config:",remain"`
+		}
+	}
+
+	var handled []string
+	b := WithValuePreProcessor(&secretPreProcessor{}).
+		WithConversionErrorHandler(func(key, value string, err error) {
+			handled = append(handled, key)
+		}).
+		FromSource(MapSource(map[string]string{
+			"PORT":          "eighty",
+			"PASSWORD":      "secret://db",
+			"PLUGIN__DEBUG": "true",
+			"UNUSED":        "x",
+		}))
+
+	var got testConfig
+	r := b.ToWithReport(&got)
+	assert.Equal(t, "s3cr3t", got.Password)
+	assert.Equal(t, []FieldReport{
+		{Key: "port", Field: "Port", Outcome: FieldInvalid},
+		{Key: "host", Field: "Host", Outcome: FieldUnset},
+		{Key: "password", Field: "Password", Outcome: FieldSet},
+		{Key: "database__name", Field: "Database.Name", Outcome: FieldUnset},
+		{Key: "plugin__name", Field: "Plugin.Name", Outcome: FieldUnset},
+		{Key: "plugin__", Field: "Plugin.Rest", Outcome: FieldSet},
+	}, r.Fields)
+	assert.Equal(t, []string{"plugin__: received by Plugin.Rest, as no other field binds them, check them for typos"}, r.Warnings)
+	assert.Equal(t, []Warning{{Kind: WarningUnknownKey, Key: "plugin__", Message: "received by Plugin.Rest, as no other field binds them, check them for typos"}},
+		b.Warnings(), "the report's warnings are the Builder's")
+	assert.Equal(t, []string{"unused"}, r.UnusedKeys)
+	require.Len(t, r.Resolutions, 1)
+	assert.Equal(t, "password", r.Resolutions[0].Key)
+	require.Len(t, r.Errors, 1)
+	assert.Equal(t, CodeBadType, CodeOf(r.Errors[0]))
+	assert.Equal(t, []string{"port"}, handled, "the conversion error handler should still be called")
+	assert.False(t, r.OK())
+
+	outcome, ok := r.Outcome("PORT")
+	assert.True(t, ok)
+	assert.Equal(t, FieldInvalid, outcome)
+	_, ok = r.Outcome("missing")
+	assert.False(t, ok)
+
+	assert.Contains(t, r.String(), "2 fields set, 3 unset, 1 invalid; 1 unused keys; 1 values resolved, slowest password in ")
+	assert.Contains(t, r.String(), `errors: config: CFG002: port: cannot convert "eighty" to int`)
+
+	assert.Equal(t, r.Warnings, b.ToWithReport(&got).Warnings, "warnings already raised are reported by each bind")
+
+	handled = nil
+	b.To(&got)
+	assert.Equal(t, []string{"port"}, handled, "the conversion error handler should be restored")
+}
@@ -0,0 +1,122 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Resolver resolves references, such as the name of a secret, into values.
+type Resolver interface {
+	// Resolve returns the value ref refers to.
+	Resolve(ctx context.Context, ref string) (string, error)
+}
+
+// ResolverFunc adapts a function to a Resolver.
+type ResolverFunc func(ctx context.Context, ref string) (string, error)
+
+// Resolve calls f.
+func (f ResolverFunc) Resolve(ctx context.Context, ref string) (string, error) {
+	return f(ctx, ref)
+}
+
+// Resolvers is a ValuePreProcessor which resolves values of the form scheme://ref
+// with the Resolver registered for scheme, leaving all other values untouched.
+// It allows resolvers from several packages, e.g. one per cloud provider, to be used together:
+//
+//	r := config.NewResolvers(ctx)
+//	awsPreProcessor.Register(r) // sm:// and ssm://
+//	r.Register("vault", vaultResolver)
+//	config.WithValuePreProcessor(r).FromEnv().To(&c)
+type Resolvers struct {
+	ctx       context.Context
+	resolvers map[string]Resolver
+	expanders map[string]Expander
+	cache     ResolverCache
+	cacheTTL  time.Duration
+	// bypassCache makes values resolve afresh rather than from the cache, see refreshing.
+	bypassCache bool
+}
+
+// NewResolvers returns an empty Resolvers, which passes ctx to its resolvers.
+func NewResolvers(ctx context.Context) *Resolvers {
+	return &Resolvers{ctx: ctx, resolvers: make(map[string]Resolver), expanders: make(map[string]Expander)}
+}
+
+// Register makes values of the form scheme://ref resolve with r, returning the Resolvers.
+// Schemes are case sensitive. Registering a scheme again replaces its Resolver.
+func (r *Resolvers) Register(scheme string, res Resolver) *Resolvers {
+	r.resolvers[scheme] = res
+	return r
+}
+
+// Schemes returns the schemes registered with Register or RegisterExpander, sorted.
+func (r *Resolvers) Schemes() []string {
+	schemes := make([]string, 0, len(r.resolvers)+len(r.expanders))
+	for s := range r.resolvers {
+		schemes = append(schemes, s)
+	}
+	for s := range r.expanders {
+		if _, dup := r.resolvers[s]; !dup {
+			schemes = append(schemes, s)
+		}
+	}
+	sort.Strings(schemes)
+	return schemes
+}
+
+// PreProcessValue resolves value if it has a registered scheme.
+// It panics with an *Error with CodeSecretFetch if resolution fails.
+func (r *Resolvers) PreProcessValue(key, value string) string {
+	return r.PreProcessValueContext(r.ctx, key, value)
+}
+
+// PreProcessValueContext is PreProcessValue, passing ctx to the Resolver.
+func (r *Resolvers) PreProcessValueContext(ctx context.Context, key, value string) string {
+	resolved, err := r.ResolveValue(ctx, value)
+	if err != nil {
+		panic(newError(CodeSecretFetch, key, err))
+	}
+	return resolved
+}
+
+// ResolveValue resolves value if it has a registered scheme, returning it untouched otherwise.
+// Values are looked up in the cache set by WithCache first, if any.
+func (r *Resolvers) ResolveValue(ctx context.Context, value string) (string, error) {
+	res, ref, ok := r.resolverFor(value)
+	if !ok {
+		return value, nil
+	}
+	if resolved, ok := r.cached(ctx, value); ok {
+		return resolved, nil
+	}
+	resolved, err := res.Resolve(ctx, ref)
+	if err != nil {
+		return "", fmt.Errorf("unable to resolve %s: %w", value, err)
+	}
+	r.store(ctx, value, resolved)
+	return resolved, nil
+}
+
+func (r *Resolvers) resolverFor(value string) (res Resolver, ref string, ok bool) {
+	scheme, ref, ok := splitReference(value)
+	if !ok {
+		return nil, "", false
+	}
+	res, ok = r.resolvers[scheme]
+	return res, ref, ok
+}
+
+// splitReference splits a scheme://ref value.
+func splitReference(value string) (scheme, ref string, ok bool) {
+	i := strings.Index(value, "://")
+	if i <= 0 {
+		return "", "", false
+	}
+	return value[:i], value[i+len("://"):], true
+}
+
+// compile time assertion
+var _ ContextValuePreProcessor = (*Resolvers)(nil)
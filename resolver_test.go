@@ -0,0 +1,35 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResolvers(t *testing.T) {
+	t.Parallel()
+	r := NewResolvers(context.Background()).
+		Register("upper", ResolverFunc(func(ctx context.Context, ref string) (string, error) {
+			return "UPPER:" + ref, nil
+		})).
+		Register("fail", ResolverFunc(func(ctx context.Context, ref string) (string, error) {
+			return "", errors.New("access denied")
+		}))
+
+	assert.Equal(t, []string{"fail", "upper"}, r.Schemes())
+	assert.Equal(t, "UPPER:a/b#c", r.PreProcessValue("k", "upper://a/b#c"))
+	assert.Equal(t, "other://x", r.PreProcessValue("k", "other://x"))
+	assert.Equal(t, "://x", r.PreProcessValue("k", "://x"))
+	assert.Equal(t, "plain", r.PreProcessValue("k", "plain"))
+
+	err := recoverError(func() { r.PreProcessValue("db__password", "fail://secret") })
+	assert.EqualError(t, err, "config: CFG003: db__password: unable to resolve fail://secret: access denied")
+	assert.Equal(t, CodeSecretFetch, CodeOf(err))
+
+	var got struct{ A, B string }
+	WithValuePreProcessor(r).FromSource(MapSource(map[string]string{"A": "upper://x", "B": "y"})).To(&got)
+	assert.Equal(t, "UPPER:x", got.A)
+	assert.Equal(t, "y", got.B)
+}
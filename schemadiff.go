@@ -0,0 +1,180 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// SchemaChangeKind classifies a SchemaChange.
+type SchemaChangeKind int
+
+const (
+	// KeyAdded means a key is only bound by the new struct.
+	KeyAdded SchemaChangeKind = iota
+	// KeyRemoved means a key is only bound by the old struct.
+	KeyRemoved
+	// KeyRenamed means a field is bound from a different key by the new struct,
+	// either as its Go field path is unchanged, or as it is the only similar key of the same type.
+	KeyRenamed
+	// KeyRetyped means a key is bound to a field of a different type by the new struct.
+	KeyRetyped
+)
+
+var schemaChangeKindNames = map[SchemaChangeKind]string{
+	KeyAdded:   "added",
+	KeyRemoved: "removed",
+	KeyRenamed: "renamed",
+	KeyRetyped: "retyped",
+}
+
+func (k SchemaChangeKind) String() string {
+	if name, ok := schemaChangeKindNames[k]; ok {
+		return name
+	}
+	return fmt.Sprintf("SchemaChangeKind(%d)", int(k))
+}
+
+// SchemaChange is a difference between the keys bound by two versions of a config struct.
+type SchemaChange struct {
+	Kind SchemaChangeKind
+	// Key is the normalized key in the new struct, or in the old struct if it was removed.
+	Key string
+	// OldKey is the normalized key in the old struct of a renamed key.
+	OldKey string
+	// Type and OldType are the types of the field in the new and old struct, as listed by WriteHelp.
+	// Type is empty if the key was removed, and OldType if it was added.
+	Type, OldType string
+}
+
+// String describes the change for release notes, with keys written as environment variables,
+// e.g. "renamed DB_HOST to DATABASE__HOST".
+func (c SchemaChange) String() string {
+	switch c.Kind {
+	case KeyAdded:
+		return fmt.Sprintf("added %s (%s)", strings.ToUpper(c.Key), c.Type)
+	case KeyRemoved:
+		return fmt.Sprintf("removed %s (%s)", strings.ToUpper(c.Key), c.OldType)
+	case KeyRenamed:
+		return fmt.Sprintf("renamed %s to %s", strings.ToUpper(c.OldKey), strings.ToUpper(c.Key))
+	case KeyRetyped:
+		return fmt.Sprintf("retyped %s from %s to %s", strings.ToUpper(c.Key), c.OldType, c.Type)
+	}
+	return c.Kind.String() + " " + strings.ToUpper(c.Key)
+}
+
+// SchemaDiff reports the keys added, removed, renamed or retyped between oldTarget and newTarget,
+// two versions of a config struct, sorted by key. Both are structs or struct pointers, and are not modified.
+// Fields tagged `config:",remain"` are not compared, as they are not bound from a key of their own.
+//
+// A removed key and an added key are reported as a rename if the field's Go path, e.g. Database.Host,
+// is the same in both structs, or otherwise if they are the only keys of the same type similar to each other,
+// with the same last segment, e.g. DB__HOST and DATABASE__HOST, or differing by a typo.
+// Renames can be registered with WithDeprecatedKey, so deployments setting the old key are warned:
+//
+//	for _, change := range config.SchemaDiff(&v1.Config{}, &Config{}) {
+//		if change.Kind == config.KeyRenamed {
+//			b.WithDeprecatedKey(change.OldKey, change.Key)
+//		}
+//	}
+func SchemaDiff(oldTarget, newTarget interface{}) []SchemaChange {
+	oldFields, newFields := schemaFields(oldTarget), schemaFields(newTarget)
+	var changes []SchemaChange
+	var removed, added []field
+	for key, f := range oldFields {
+		nf, ok := newFields[key]
+		switch {
+		case !ok:
+			removed = append(removed, f)
+		case helpTypeName(f.Type) != helpTypeName(nf.Type):
+			changes = append(changes, SchemaChange{Kind: KeyRetyped, Key: key, Type: helpTypeName(nf.Type), OldType: helpTypeName(f.Type)})
+		}
+	}
+	for key, f := range newFields {
+		if _, ok := oldFields[key]; !ok {
+			added = append(added, f)
+		}
+	}
+	sortFields(removed)
+	sortFields(added)
+
+	renamed := make(map[string]bool)
+	rename := func(from, to field) {
+		renamed[from.key], renamed[to.key] = true, true
+		changes = append(changes, SchemaChange{Kind: KeyRenamed, Key: to.key, OldKey: from.key,
+			Type: helpTypeName(to.Type), OldType: helpTypeName(from.Type)})
+	}
+	for _, from := range removed {
+		for _, to := range added {
+			if !renamed[to.key] && strings.Join(from.path, ".") == strings.Join(to.path, ".") {
+				rename(from, to)
+				break
+			}
+		}
+	}
+	for _, from := range removed {
+		if renamed[from.key] {
+			continue
+		}
+		to, ok := onlySimilarField(from, added, renamed)
+		if !ok {
+			continue
+		}
+		if back, ok := onlySimilarField(to, removed, renamed); ok && back.key == from.key {
+			rename(from, to)
+		}
+	}
+
+	for _, f := range removed {
+		if !renamed[f.key] {
+			changes = append(changes, SchemaChange{Kind: KeyRemoved, Key: f.key, OldType: helpTypeName(f.Type)})
+		}
+	}
+	for _, f := range added {
+		if !renamed[f.key] {
+			changes = append(changes, SchemaChange{Kind: KeyAdded, Key: f.key, Type: helpTypeName(f.Type)})
+		}
+	}
+	sort.SliceStable(changes, func(i, j int) bool { return changes[i].Key < changes[j].Key })
+	return changes
+}
+
+// schemaFields returns the fields of target bound from a key of their own, by key.
+func schemaFields(target interface{}) map[string]field {
+	fs := make(map[string]field)
+	for _, f := range fields(structType(target)) {
+		if !f.remain {
+			fs[f.key] = f
+		}
+	}
+	return fs
+}
+
+func sortFields(fs []field) {
+	sort.Slice(fs, func(i, j int) bool { return fs[i].key < fs[j].key })
+}
+
+// onlySimilarField returns the only field of candidates, not yet renamed, which is similar to f and of the same type.
+func onlySimilarField(f field, candidates []field, renamed map[string]bool) (field, bool) {
+	var similar []field
+	for _, c := range candidates {
+		if !renamed[c.key] && helpTypeName(c.Type) == helpTypeName(f.Type) && similarKey(f.key, c.key) {
+			similar = append(similar, c)
+		}
+	}
+	if len(similar) != 1 {
+		return field{}, false
+	}
+	return similar[0], true
+}
+
+// similarKey reports whether a and b share their last segment, or are within maxSuggestionDistance of each other.
+func similarKey(a, b string) bool {
+	lastSegment := func(key string) string {
+		if i := strings.LastIndex(key, structDelim); i >= 0 {
+			return key[i+len(structDelim):]
+		}
+		return key
+	}
+	return lastSegment(a) == lastSegment(b) || levenshtein(a, b, maxSuggestionDistance+1) <= maxSuggestionDistance
+}
@@ -0,0 +1,90 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSchemaDiff(t *testing.T) {
+	t.Parallel()
+	type v1 struct {
+		DBHost  string `config:"db_host"`
+		DBPort  int    `config:"db_port"`
+		Timeout int
+		Verbose bool
+		Cache   struct {
+			Size int
+		}
+		Plugins map[string]string `config:",remain"`
+	}
+	type v2 struct {
+		Database struct {
+			Host string
+			Port int
+		}
+		DBPort  int `config:"database__port"`
+		Timeout time.Duration
+		Cache   struct {
+			Size int `config:"max_size"`
+		}
+		LogLevel string
+		Extra    map[string]string `config:",remain"`
+	}
+
+	changes := SchemaDiff(&v1{}, v2{})
+	assert.Equal(t, []SchemaChange{
+		{Kind: KeyRenamed, Key: "cache__max_size", OldKey: "cache__size", Type: "int", OldType: "int"},
+		{Kind: KeyAdded, Key: "database__host", Type: "string"},
+		{Kind: KeyRenamed, Key: "database__port", OldKey: "db_port", Type: "int", OldType: "int"},
+		{Kind: KeyRemoved, Key: "db_host", OldType: "string"},
+		{Kind: KeyAdded, Key: "loglevel", Type: "string"},
+		{Kind: KeyRetyped, Key: "timeout", Type: "duration", OldType: "int"},
+		{Kind: KeyRemoved, Key: "verbose", OldType: "bool"},
+	}, changes)
+
+	var notes []string
+	for _, c := range changes {
+		notes = append(notes, c.String())
+	}
+	assert.Equal(t, []string{
+		"renamed CACHE__SIZE to CACHE__MAX_SIZE",
+		"added DATABASE__HOST (string)",
+		"renamed DB_PORT to DATABASE__PORT",
+		"removed DB_HOST (string)",
+		"added LOGLEVEL (string)",
+		"retyped TIMEOUT from int to duration",
+		"removed VERBOSE (bool)",
+	}, notes)
+
+	assert.Empty(t, SchemaDiff(v2{}, &v2{}))
+}
+
+func TestSchemaDiff_similarKeys(t *testing.T) {
+	t.Parallel()
+	type v1 struct {
+		Hots     string
+		Replicas int
+		Database struct {
+			Name string
+		}
+	}
+	type v2 struct {
+		Host  string
+		Store struct {
+			Name     string
+			Replicas int
+		}
+		Primary struct {
+			Name string
+		}
+	}
+	assert.Equal(t, []SchemaChange{
+		{Kind: KeyRemoved, Key: "database__name", OldType: "string"},
+		{Kind: KeyRenamed, Key: "host", OldKey: "hots", Type: "string", OldType: "string"},
+		{Kind: KeyAdded, Key: "primary__name", Type: "string"},
+		{Kind: KeyAdded, Key: "store__name", Type: "string"},
+		{Kind: KeyRenamed, Key: "store__replicas", OldKey: "replicas", Type: "int", OldType: "int"},
+	}, SchemaDiff(v1{}, v2{}), "ambiguous renames are reported as removed and added keys")
+}
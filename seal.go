@@ -0,0 +1,54 @@
+package config
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+	"fmt"
+)
+
+// sealer encrypts values held in memory with a random key generated for each Builder.
+// It does not protect against an attacker able to read arbitrary memory, as the key lives in the same heap,
+// but it keeps plaintext secrets out of heap dumps, core files and debug output.
+type sealer struct {
+	aead cipher.AEAD
+}
+
+func newSealer() *sealer {
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		panic(fmt.Sprintf("config: unable to generate encryption key: %v", err))
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		panic(fmt.Sprintf("config: unable to create cipher: %v", err)) // impossible with a 32 byte key
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		panic(fmt.Sprintf("config: unable to create cipher: %v", err)) // impossible for AES
+	}
+	return &sealer{aead: aead}
+}
+
+// seal returns plaintext encrypted, prefixed with its nonce.
+func (s *sealer) seal(plaintext string) string {
+	nonce := make([]byte, s.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		panic(fmt.Sprintf("config: unable to generate nonce: %v", err))
+	}
+	return string(s.aead.Seal(nonce, nonce, []byte(plaintext), nil))
+}
+
+// open decrypts a value returned by seal.
+func (s *sealer) open(sealed string) (string, error) {
+	n := s.aead.NonceSize()
+	if len(sealed) < n {
+		return "", errors.New("config: sealed value too short")
+	}
+	plaintext, err := s.aead.Open(nil, []byte(sealed[:n]), []byte(sealed[n:]), nil)
+	if err != nil {
+		return "", fmt.Errorf("config: unable to decrypt sealed value: %w", err)
+	}
+	return string(plaintext), nil
+}
@@ -0,0 +1,59 @@
+package config
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_sealer(t *testing.T) {
+	t.Parallel()
+	s := newSealer()
+
+	sealed := s.seal("s3cr3t")
+	assert.NotContains(t, sealed, "s3cr3t")
+	assert.NotEqual(t, sealed, s.seal("s3cr3t"), "nonces should differ")
+
+	opened, err := s.open(sealed)
+	require.NoError(t, err)
+	assert.Equal(t, "s3cr3t", opened)
+
+	_, err = newSealer().open(sealed)
+	assert.Error(t, err)
+	_, err = s.open("x")
+	assert.Error(t, err)
+}
+
+func TestBuilder_WithEncryptedSecrets(t *testing.T) {
+	type testConfig struct {
+		Password string
+		Host     string
+	}
+
+	os.Clearenv()
+	defer os.Clearenv()
+	require.NoError(t, os.Setenv("PASSWORD", "secret://password"))
+	require.NoError(t, os.Setenv("HOST", "localhost"))
+
+	b := WithEncryptedSecrets().WithValuePreProcessor(&secretPreProcessor{}).FromEnv()
+	for k, v := range b.configMap {
+		if k == "password" {
+			assert.False(t, strings.Contains(v, "s3cr3t"), "secret should be encrypted at rest")
+		}
+	}
+	assert.Equal(t, "localhost", b.configMap["host"], "plain values should not be encrypted")
+
+	var got testConfig
+	b.To(&got)
+	assert.Equal(t, testConfig{Password: "s3cr3t", Host: "localhost"}, got)
+	assert.Contains(t, b.Environ(), "PASSWORD=s3cr3t")
+
+	// sealed values survive being layered under a lazy environment
+	os.Clearenv()
+	got = testConfig{}
+	b.FromEnvLazy().To(&got)
+	assert.Equal(t, testConfig{Password: "s3cr3t", Host: "localhost"}, got)
+}
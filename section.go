@@ -0,0 +1,66 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+var (
+	sectionsMu sync.Mutex
+	sections   []section
+)
+
+type section struct {
+	prefix string
+	target interface{}
+}
+
+// Section registers target, a struct pointer, to be bound under prefix, e.g. kafka, by every subsequent To, Bind
+// or ToWithReport, whatever the target they are given. This lets a library declare its own config, without
+// the application embedding it in its root struct:
+//
+//	// in the library
+//	var cfg struct {
+//		Brokers []string // bound from KAFKA__BROKERS
+//	}
+//
+//	func init() {
+//		config.Section("kafka", &cfg)
+//	}
+//
+//	// in the application
+//	config.FromEnv().To(&c)
+//
+// The prefix is a key, so kafka and KAFKA__ are equivalent. Sections are populated after target, with their keys
+// counted as bound, so strict mode and unknown key warnings take them into account. As they are set by the
+// application's To, they should be read after the application has bound its config, and not concurrently with it.
+// It panics if target is not a non nil struct pointer, or prefix is empty or already registered.
+func Section(prefix string, target interface{}) {
+	if t := reflect.TypeOf(target); t == nil || t.Kind() != reflect.Ptr || t.Elem().Kind() != reflect.Struct || reflect.ValueOf(target).IsNil() {
+		panic(newError(CodeUnsupportedField, "", fmt.Errorf("cannot register section %T, it must be a non nil struct pointer", target)))
+	}
+	prefix = NormalizeKey(strings.TrimSuffix(strings.TrimSpace(prefix), structDelim))
+	if prefix == "" {
+		panic("config: Section prefix is empty")
+	}
+	sectionsMu.Lock()
+	defer sectionsMu.Unlock()
+	for _, s := range sections {
+		if s.prefix == prefix {
+			panic("config: Section called twice for prefix " + prefix)
+		}
+	}
+	sections = append(sections, section{prefix: prefix, target: target})
+}
+
+// bindSections populates the targets registered with Section.
+func (c *Builder) bindSections() {
+	sectionsMu.Lock()
+	registered := append([]section(nil), sections...)
+	sectionsMu.Unlock()
+	for _, s := range registered {
+		c.populateStructRecursively(s.target, s.prefix+c.structDelim)
+	}
+}
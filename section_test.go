@@ -0,0 +1,45 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// withSection registers target under prefix until the returned function is called.
+// Tests using it must not be parallel, as sections are bound by every To.
+func withSection(prefix string, target interface{}) func() {
+	Section(prefix, target)
+	return func() {
+		sectionsMu.Lock()
+		defer sectionsMu.Unlock()
+		sections = sections[:len(sections)-1]
+	}
+}
+
+func TestSection(t *testing.T) {
+	var kafka struct {
+		Brokers []string
+		Client  struct {
+			ID string
+		}
+	}
+	defer withSection("KAFKA__", &kafka)()
+
+	var c struct {
+		Port int
+	}
+	b := FromString("PORT=8080\nKAFKA__BROKERS=a b\nKAFKA__CLIENT__ID=billing\nKAFKA__CLIENTID=typo").WithStrict("kafka__")
+	err := recoverError(func() { b.To(&c) })
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "kafka__clientid", "section keys are bound in strict mode")
+	assert.Equal(t, 8080, c.Port)
+	assert.Equal(t, []string{"a", "b"}, kafka.Brokers)
+	assert.Equal(t, "billing", kafka.Client.ID)
+
+	assert.Panics(t, func() { Section("kafka", &struct{}{}) }, "prefixes are registered once")
+	assert.Panics(t, func() { Section("", &struct{}{}) })
+	err = recoverError(func() { Section("redis", struct{}{}) })
+	assert.Equal(t, CodeUnsupportedField, CodeOf(err))
+}
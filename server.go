@@ -0,0 +1,135 @@
+package config
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// serverEnvironPath is the path the Server serves the environment on.
+const serverEnvironPath = "/v1/environ"
+
+// Server serves a Builder's resolved config over a Unix socket to other processes, e.g. sidecars in the same pod,
+// so that secrets are resolved once rather than once per process. Clients read it with UnixSocketSource.
+// Requests must carry the server's token, and the socket is only accessible to its owner.
+type Server struct {
+	token  string
+	values map[string]string
+}
+
+// NewServer returns a Server of the current config state of b, as returned by Environ with opts.
+// The state is captured once, so b may be used, or discarded, once NewServer returns.
+// Clients must present token, which should be random and shared with them out of band, e.g. through a file.
+func NewServer(b *Builder, token string, opts ...EnvironOption) *Server {
+	values := make(map[string]string)
+	for _, kv := range b.Environ(opts...) {
+		split := strings.SplitN(kv, "=", 2)
+		values[split[0]] = split[1]
+	}
+	return &Server{token: token, values: values}
+}
+
+// ListenAndServe serves on a Unix socket at path, readable and writable by its owner only, until ctx is done.
+// It then shuts down, waiting for requests in progress, and removes the socket. Pass it a context from
+// signal.NotifyContext to shut down cleanly on SIGTERM:
+//
+//	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGTERM, os.Interrupt)
+//	defer stop()
+//	err := config.NewServer(b, token).ListenAndServe(ctx, "/run/config/config.sock")
+//
+// A stale socket left at path by a process which did not shut down cleanly is replaced.
+func (s *Server) ListenAndServe(ctx context.Context, path string) error {
+	if s.token == "" {
+		return errors.New("config: server token must not be empty")
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	l, err := net.Listen("unix", path)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(path)
+	if err := os.Chmod(path, 0o600); err != nil {
+		l.Close()
+		return err
+	}
+
+	srv := &http.Server{Handler: s, ReadHeaderTimeout: 5 * time.Second}
+	done := make(chan error, 1)
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		done <- srv.Shutdown(shutdownCtx)
+	}()
+	if err := srv.Serve(l); !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return <-done
+}
+
+// ServeHTTP serves the config as a JSON object, to requests authorized with the server's token.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != serverEnvironPath {
+		http.NotFound(w, r)
+		return
+	}
+	if r.Method != http.MethodGet {
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+	token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if s.token == "" || subtle.ConstantTimeCompare([]byte(token), []byte(s.token)) != 1 {
+		http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	_ = json.NewEncoder(w).Encode(s.values)
+}
+
+// UnixSocketSource returns a Source of the config served by a Server on the Unix socket at path,
+// authenticating with token.
+//
+//	config.FromSource(config.UnixSocketSource("/run/config/config.sock", token)).To(&c)
+func UnixSocketSource(path, token string) Source {
+	return SourceFunc(func() (map[string]string, error) {
+		client := &http.Client{
+			Timeout: 30 * time.Second,
+			Transport: &http.Transport{
+				DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+					var d net.Dialer
+					return d.DialContext(ctx, "unix", path)
+				},
+			},
+		}
+		defer client.CloseIdleConnections()
+
+		req, err := http.NewRequest(http.MethodGet, "http://config"+serverEnvironPath, nil)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+		resp, err := client.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("%s: %s", path, resp.Status)
+		}
+		var values map[string]string
+		if err := json.NewDecoder(resp.Body).Decode(&values); err != nil {
+			return nil, fmt.Errorf("%s: invalid response: %w", path, err)
+		}
+		return values, nil
+	})
+}
@@ -0,0 +1,84 @@
+package config
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServer(t *testing.T) {
+	t.Parallel()
+	p := &countingPreProcessor{}
+	b := WithValuePreProcessor(p).FromSource(MapSource(map[string]string{"PASSWORD": "secret://x", "PORT": "80", "DEBUG": "true"}))
+	srv := NewServer(b, "token", EnvironFilter(func(key string) bool { return key != "debug" }))
+
+	path := filepath.Join(t.TempDir(), "config.sock")
+	ctx, cancel := context.WithCancel(context.Background())
+	served := make(chan error, 1)
+	go func() { served <- srv.ListenAndServe(ctx, path) }()
+	for deadline := time.Now().Add(time.Second); time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+		if _, err := os.Stat(path); err == nil {
+			break
+		}
+	}
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+
+	for i := 0; i < 2; i++ {
+		var got struct {
+			Password string
+			Port     int
+			Debug    bool
+		}
+		FromSource(UnixSocketSource(path, "token")).To(&got)
+		assert.Equal(t, "resolved", got.Password)
+		assert.Equal(t, 80, got.Port)
+		assert.False(t, got.Debug, "filtered out by the server")
+	}
+	assert.Equal(t, 1, p.calls, "secrets are resolved once, however many clients read them")
+
+	_, err = UnixSocketSource(path, "wrong").Load()
+	assert.EqualError(t, err, path+": 401 Unauthorized")
+
+	cancel()
+	require.NoError(t, <-served)
+	_, err = os.Stat(path)
+	assert.True(t, os.IsNotExist(err), "the socket is removed on shutdown")
+	_, err = UnixSocketSource(path, "token").Load()
+	assert.Error(t, err)
+}
+
+func TestServer_ServeHTTP(t *testing.T) {
+	t.Parallel()
+	srv := NewServer(FromSource(MapSource(map[string]string{"A": "1"})), "token")
+	tests := []struct {
+		method, path, auth string
+		want               int
+	}{
+		{http.MethodGet, "/v1/environ", "Bearer token", http.StatusOK},
+		{http.MethodGet, "/v1/environ", "", http.StatusUnauthorized},
+		{http.MethodGet, "/v1/environ", "token", http.StatusOK},
+		{http.MethodPost, "/v1/environ", "Bearer token", http.StatusMethodNotAllowed},
+		{http.MethodGet, "/", "Bearer token", http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		r := httptest.NewRequest(tt.method, tt.path, nil)
+		if tt.auth != "" {
+			r.Header.Set("Authorization", tt.auth)
+		}
+		w := httptest.NewRecorder()
+		srv.ServeHTTP(w, r)
+		assert.Equal(t, tt.want, w.Code, "%s %s %q", tt.method, tt.path, tt.auth)
+	}
+
+	assert.EqualError(t, NewServer(newBuilder(), "").ListenAndServe(context.Background(), filepath.Join(t.TempDir(), "s")), "config: server token must not be empty")
+}
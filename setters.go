@@ -0,0 +1,89 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"unicode"
+	"unicode/utf8"
+)
+
+var errorType = reflect.TypeOf((*error)(nil)).Elem()
+
+// WithSetters creates a new builder which binds unexported fields through their struct's setters.
+func WithSetters() *Builder {
+	return newBuilder().WithSetters()
+}
+
+// WithSetters makes To bind unexported fields through a setter on their struct's pointer, named Set followed by
+// the field's name, returning the Builder. This allows binding into types which enforce invariants:
+//
+//	type Pool struct {
+//		size int // bound from POOL__SIZE with SetSize
+//	}
+//
+//	func (p *Pool) SetSize(n int) error {
+//		if n < 1 {
+//			return errors.New("size must be positive")
+//		}
+//		p.size = n
+//		return nil
+//	}
+//
+// A setter takes a single value of any type a field can have, and returns nothing or an error. The value is converted
+// as a field of that type would be, including the field's tag options, and the setter is only called if the key
+// is set. Errors returned by the setter are handled as conversion errors, see WithConversionErrorHandler and Bind.
+// Unexported fields without a setter, whether or not setters are enabled, must be tagged `config:"-"`.
+func (c *Builder) WithSetters() *Builder {
+	c.setters = true
+	return c
+}
+
+// setter returns the setter of the unexported field fieldType of the struct structValue, if setters are enabled.
+// It panics with an *Error with CodeUnsupportedField if there is no usable setter.
+func (c *Builder) setter(structValue reflect.Value, fieldType reflect.StructField, key string) reflect.Value {
+	name := "Set" + upperFirst(fieldType.Name)
+	if !c.setters {
+		panic(newError(CodeUnsupportedField, key, fmt.Errorf("cannot set unexported field %s, tag it `config:\"-\"` or add a %s setter and use WithSetters", fieldType.Name, name)))
+	}
+	m := structValue.Addr().MethodByName(name)
+	if !m.IsValid() {
+		panic(newError(CodeUnsupportedField, key, fmt.Errorf("cannot set unexported field %s without a %s method, or tag it `config:\"-\"`", fieldType.Name, name)))
+	}
+	t := m.Type()
+	if t.NumIn() != 1 || t.NumOut() > 1 || (t.NumOut() == 1 && t.Out(0) != errorType) {
+		panic(newError(CodeUnsupportedField, key, fmt.Errorf("cannot use %s as a setter, it must take a single value and return nothing or an error", name)))
+	}
+	return m
+}
+
+// bindSetter binds key to the unexported field fieldType of structValue through its setter. See WithSetters.
+func (c *Builder) bindSetter(structValue reflect.Value, fieldType reflect.StructField, key string) {
+	set := c.setter(structValue, fieldType, key)
+	c.bound[key] = true
+	lookup := c.lookup
+	if hasTagOption(fieldType, tagOptionLiteral) {
+		lookup = c.lookupLiteral
+	}
+	value, ok := lookup(key)
+	if !ok {
+		return
+	}
+	fieldType.Type = set.Type().In(0)
+	arg := reflect.New(fieldType.Type)
+	err := c.convertField(arg.Interface(), fieldType, value)
+	if err == nil {
+		if out := set.Call([]reflect.Value{arg.Elem()}); len(out) == 1 && !out[0].IsNil() {
+			err = out[0].Interface().(error)
+		}
+	}
+	if err == nil {
+		c.stats.FieldsBound++
+	}
+	c.conversionError(key, value, err)
+}
+
+// upperFirst returns s with its first letter in upper case.
+func upperFirst(s string) string {
+	r, n := utf8.DecodeRuneInString(s)
+	return string(unicode.ToUpper(r)) + s[n:]
+}
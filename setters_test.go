@@ -0,0 +1,74 @@
+package config
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type setterPool struct {
+	size    int
+	timeout time.Duration `config:"idle_timeout"`
+	zones   []string
+	calls   int `config:"-"`
+}
+
+func (p *setterPool) SetSize(n int) error {
+	p.calls++
+	if n < 1 {
+		return errors.New("size must be positive")
+	}
+	p.size = n
+	return nil
+}
+
+func (p *setterPool) SetTimeout(d time.Duration) { p.timeout = d }
+
+func (p *setterPool) SetZones(zones []string) { p.zones = zones }
+
+func TestBuilder_WithSetters(t *testing.T) {
+	t.Parallel()
+	var c struct {
+		Pool setterPool
+	}
+	WithSetters().FromString("POOL__SIZE=4\nPOOL__IDLE_TIMEOUT=1m\nPOOL__ZONES=a b").To(&c)
+	assert.Equal(t, 4, c.Pool.size)
+	assert.Equal(t, time.Minute, c.Pool.timeout, "tags are honoured")
+	assert.Equal(t, []string{"a", "b"}, c.Pool.zones)
+	assert.Equal(t, 1, c.Pool.calls, "setters are only called for set keys")
+
+	c.Pool = setterPool{}
+	err := WithSetters().FromString("POOL__SIZE=0").Bind(&c)
+	assert.Equal(t, CodeBadType, CodeOf(err))
+	assert.Contains(t, err.Error(), "size must be positive")
+	assert.Equal(t, 0, c.Pool.size)
+
+	err = WithSetters().FromString("POOL__SIZE=many").Bind(&c)
+	assert.Equal(t, CodeBadType, CodeOf(err))
+	assert.Equal(t, 1, c.Pool.calls, "values which fail to convert are not set")
+}
+
+func TestBuilder_WithSetters_unsupported(t *testing.T) {
+	t.Parallel()
+	err := recoverError(func() { FromString("POOL__SIZE=4").To(&struct{ Pool setterPool }{}) })
+	require.Error(t, err)
+	assert.Equal(t, CodeUnsupportedField, CodeOf(err))
+	assert.Contains(t, err.Error(), "add a SetSize setter and use WithSetters")
+
+	err = recoverError(func() { WithSetters().To(&struct{ port int }{}) })
+	assert.Equal(t, CodeUnsupportedField, CodeOf(err))
+	assert.Contains(t, err.Error(), "without a SetPort method")
+
+	err = recoverError(func() { WithSetters().To(&struct{ Pool setterBadPool }{}) })
+	assert.Equal(t, CodeUnsupportedField, CodeOf(err))
+	assert.Contains(t, err.Error(), "cannot use SetSize as a setter")
+}
+
+type setterBadPool struct {
+	size int
+}
+
+func (p *setterBadPool) SetSize(n int) bool { return true }
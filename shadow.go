@@ -0,0 +1,104 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Discrepancy is a field whose value differs between the primary and shadow config. See Shadow.
+type Discrepancy struct {
+	// Key is the normalized key the field is bound from.
+	Key string
+	// Field is the path to the field from the target struct, e.g. "Database.Host".
+	Field string
+	// Primary and Shadow are the field's values in each config, as an environment variable would hold them,
+	// or Redacted for maps, fields tagged `config:",secret"` and values resolved by the ValuePreProcessor.
+	Primary, Shadow string
+	// PrimarySource and ShadowSource describe where each value came from, as a Meta's Source does,
+	// and are empty if the key is unset.
+	PrimarySource, ShadowSource string
+}
+
+// String describes the discrepancy on a single line, e.g. for logging.
+func (d Discrepancy) String() string {
+	describe := func(value, source string) string {
+		if source == "" {
+			return "unset"
+		}
+		return fmt.Sprintf("%q from %s", value, source)
+	}
+	return fmt.Sprintf("%s differs: primary %s, shadow %s", strings.ToUpper(d.Key),
+		describe(d.Primary, d.PrimarySource), describe(d.Shadow, d.ShadowSource))
+}
+
+// Shadow binds target from the current config state, as To does, and a fresh struct of its type from the Builder
+// returned by shadow, as Bind does, calling onDiscrepancy with each field whose value differs. target is never set
+// from the shadow config, so config can be migrated between backends, e.g. from Consul to SSM, by serving from
+// the legacy one while checking the new one agrees:
+//
+//	legacy := config.FromSource(consulSource)
+//	err := legacy.Shadow(&c, func() *config.Builder { return p.FromParameterStorePath(ctx, "/billing/") },
+//		func(d config.Discrepancy) { log.Printf("config migration: %v", d) })
+//
+// shadow is called once; it may panic, e.g. if a source fails to load, as the error is returned instead.
+// The error binding the shadow config, if any, is returned after discrepancies are reported, with fields
+// which failed to convert left as their zero value. Wrapper fields are compared by their values, see Equal.
+// Fields whose values cannot be read, such as unexported fields bound with setters, are not compared.
+// It panics as To does if target cannot be bound from the current config state.
+func (c *Builder) Shadow(target interface{}, shadow func() *Builder, onDiscrepancy func(Discrepancy)) error {
+	c.To(target)
+	fresh := reflect.New(reflect.TypeOf(target).Elem())
+	var s *Builder
+	err := func() (err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				err = recoveredError(r)
+			}
+		}()
+		s = shadow()
+		return s.Bind(fresh.Interface())
+	}()
+	if s == nil {
+		return err
+	}
+
+	primaryValues, shadowValues := reflect.Indirect(reflect.ValueOf(target)), fresh.Elem()
+	for _, f := range fields(structType(target)) {
+		pv, ok := boundValue(f, primaryValues)
+		if !ok {
+			continue
+		}
+		if sv, _ := boundValue(f, shadowValues); reflect.DeepEqual(pv, sv) {
+			continue
+		}
+		d := Discrepancy{
+			Key:           f.key,
+			Field:         strings.Join(f.path, "."),
+			Primary:       Redacted,
+			Shadow:        Redacted,
+			PrimarySource: c.originOf(f.key).source,
+			ShadowSource:  s.originOf(f.key).source,
+		}
+		if !hasTagOption(f.StructField, tagOptionSecret) && !c.resolved[f.key] && !s.resolved[f.key] {
+			octal := hasTagOption(f.StructField, tagOptionOctal)
+			d.Primary, d.Shadow = discrepancyString(f, primaryValues, octal), discrepancyString(f, shadowValues, octal)
+		}
+		onDiscrepancy(d)
+	}
+	return err
+}
+
+// discrepancyString returns the value bound to f in values as an environment variable would hold it,
+// or Redacted for maps, which may hold secrets under any key.
+func discrepancyString(f field, values reflect.Value, octal bool) string {
+	v, _ := boundValue(f, values)
+	if o, ok := v.(optionalValue); ok {
+		v = o.value
+	}
+	rv := reflect.ValueOf(v)
+	if !rv.IsValid() || rv.Kind() == reflect.Map {
+		return Redacted
+	}
+	return envString(rv, octal)
+}
@@ -0,0 +1,57 @@
+package config
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuilder_Shadow(t *testing.T) {
+	t.Parallel()
+	type testConfig struct {
+		Port     int
+		Host     string
+		Password string `config:",secret"`
+		Timeout  Meta[string]
+		Debug    bool
+		Retries  Optional[int]
+	}
+	legacy := namedSource{name: "consul", Source: MapSource(map[string]string{
+		"PORT": "8080", "HOST": "a", "PASSWORD": "p", "TIMEOUT": "5s", "DEBUG": "true", "RETRIES": "1",
+	})}
+	next := namedSource{name: "ssm", Source: MapSource(map[string]string{
+		"PORT": "9090", "HOST": "a", "PASSWORD": "q", "TIMEOUT": "5s", "RETRIES": "2",
+	})}
+
+	var c testConfig
+	var got []Discrepancy
+	err := FromSource(legacy).Shadow(&c, func() *Builder { return FromSource(next) }, func(d Discrepancy) {
+		got = append(got, d)
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 8080, c.Port, "config is served from the primary")
+	assert.Equal(t, []Discrepancy{
+		{Key: "port", Field: "Port", Primary: "8080", Shadow: "9090", PrimarySource: "consul", ShadowSource: "ssm"},
+		{Key: "password", Field: "Password", Primary: Redacted, Shadow: Redacted, PrimarySource: "consul", ShadowSource: "ssm"},
+		{Key: "debug", Field: "Debug", Primary: "true", Shadow: "false", PrimarySource: "consul"},
+		{Key: "retries", Field: "Retries", Primary: "1", Shadow: "2", PrimarySource: "consul", ShadowSource: "ssm"},
+	}, got, "values are compared, not where they came from")
+	assert.Equal(t, `PORT differs: primary "8080" from consul, shadow "9090" from ssm`, got[0].String())
+	assert.Equal(t, `DEBUG differs: primary "true" from consul, shadow unset`, got[2].String())
+
+	got = nil
+	err = FromSource(legacy).Shadow(&c, func() *Builder {
+		return FromSource(SourceFunc(func() (map[string]string, error) { return nil, errors.New("unavailable") }))
+	}, func(d Discrepancy) { got = append(got, d) })
+	assert.Equal(t, CodeSourceLoad, CodeOf(err), "a failing shadow is an error, not a panic")
+	assert.Empty(t, got)
+	assert.Equal(t, "a", c.Host)
+
+	got = nil
+	err = FromSource(legacy).Shadow(&c, func() *Builder {
+		return FromSource(MapSource(map[string]string{"PORT": "eighty", "HOST": "a", "PASSWORD": "p", "TIMEOUT": "5s", "DEBUG": "true", "RETRIES": "1"}))
+	}, func(d Discrepancy) { got = append(got, d) })
+	assert.Equal(t, CodeBadType, CodeOf(err))
+	assert.Len(t, got, 1, "fields which fail to convert are compared as their zero value")
+}
@@ -0,0 +1,76 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+)
+
+// signatureSuffix is appended to the name of a payload to find its detached signature, as cosign sign-blob writes it.
+const signatureSuffix = ".sig"
+
+// VerifySignature returns a PayloadTransformer refusing payloads whose detached signature, stored alongside them
+// as Name.sig, is missing or does not verify with publicKey, so that only config signed by a trusted key is merged:
+//
+//	key, err := config.ParsePublicKey(pemBytes)
+//	config.FromURL(ctx, "https://config.internal/billing.yaml", config.URLTransform(config.VerifySignature(key)))
+//
+// publicKey is an ed25519.PublicKey, whose signatures are of the payload, or an *ecdsa.PublicKey, such as a cosign
+// key, whose signatures are ASN.1 encoded signatures of the payload's SHA-256 digest, as cosign sign-blob writes them.
+// Signatures may be raw or base64 encoded. As signatures cover the payload as stored, VerifySignature should come
+// before any transformer decompressing or decrypting it. The source must support fetching the signature,
+// as URLSource and the S3 source of github.com/imduffy15/config/aws do, see Payload's Sibling.
+func VerifySignature(publicKey crypto.PublicKey) PayloadTransformer {
+	return PayloadTransformerFunc(func(ctx context.Context, p Payload) (Payload, error) {
+		if p.Sibling == nil {
+			return Payload{}, errors.New("cannot fetch the signature of this source")
+		}
+		sig, err := p.Sibling(ctx, signatureSuffix)
+		if err != nil {
+			return Payload{}, fmt.Errorf("unsigned, error fetching signature %s%s: %w", p.Name, signatureSuffix, err)
+		}
+		if decoded, err := base64.StdEncoding.DecodeString(string(bytes.TrimSpace(sig))); err == nil {
+			sig = decoded
+		}
+		var ok bool
+		switch key := publicKey.(type) {
+		case ed25519.PublicKey:
+			ok = ed25519.Verify(key, p.Data, sig)
+		case *ecdsa.PublicKey:
+			digest := sha256.Sum256(p.Data)
+			ok = ecdsa.VerifyASN1(key, digest[:], sig)
+		default:
+			return Payload{}, fmt.Errorf("cannot verify signatures with a %T key, it must be ed25519 or ECDSA", publicKey)
+		}
+		if !ok {
+			return Payload{}, fmt.Errorf("signature %s%s does not match, the config may have been tampered with", p.Name, signatureSuffix)
+		}
+		return p, nil
+	})
+}
+
+// ParsePublicKey parses a PEM encoded public key, as written by cosign generate-key-pair
+// or openssl pkey -pubout, for VerifySignature.
+func ParsePublicKey(pemBytes []byte) (crypto.PublicKey, error) {
+	block, _ := pem.Decode(pemBytes)
+	if block == nil {
+		return nil, errors.New("no PEM encoded public key found")
+	}
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	switch key.(type) {
+	case ed25519.PublicKey, *ecdsa.PublicKey:
+		return key, nil
+	}
+	return nil, fmt.Errorf("cannot verify signatures with a %T key, it must be ed25519 or ECDSA", key)
+}
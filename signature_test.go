@@ -0,0 +1,95 @@
+package config
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func publicKeyPEM(t *testing.T, key interface{}) []byte {
+	der, err := x509.MarshalPKIXPublicKey(key)
+	require.NoError(t, err)
+	return pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})
+}
+
+func TestVerifySignature(t *testing.T) {
+	t.Parallel()
+	edPublic, edPrivate, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	ecPrivate, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	doc := []byte("PORT=8080\n")
+	digest := sha256.Sum256(doc)
+	ecSig, err := ecdsa.SignASN1(rand.Reader, ecPrivate, digest[:])
+	require.NoError(t, err)
+	files := map[string][]byte{
+		"/ed.env":           doc,
+		"/ed.env.sig":       ed25519.Sign(edPrivate, doc),
+		"/ec.env":           doc,
+		"/ec.env.sig":       []byte(base64.StdEncoding.EncodeToString(ecSig) + "\n"),
+		"/tampered.env":     []byte("PORT=6666\n"),
+		"/tampered.env.sig": ed25519.Sign(edPrivate, doc),
+		"/unsigned.env":     doc,
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, ok := files[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write(b)
+	}))
+	defer srv.Close()
+
+	edKey, err := ParsePublicKey(publicKeyPEM(t, edPublic))
+	require.NoError(t, err)
+	ecKey, err := ParsePublicKey(publicKeyPEM(t, &ecPrivate.PublicKey))
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	var c struct{ Port int }
+	FromURL(ctx, srv.URL+"/ed.env", URLTransform(VerifySignature(edKey))).To(&c)
+	assert.Equal(t, 8080, c.Port)
+	c.Port = 0
+	FromURL(ctx, srv.URL+"/ec.env?token=x", URLTransform(VerifySignature(ecKey))).To(&c)
+	assert.Equal(t, 8080, c.Port, "signatures of cosign keys are base64 encoded")
+
+	errs := map[string]string{
+		"/tampered.env": "signature /tampered.env.sig does not match",
+		"/unsigned.env": "unsigned, error fetching signature /unsigned.env.sig",
+		"/ec.env":       "signature /ec.env.sig does not match",
+	}
+	for path, want := range errs {
+		err := recoverError(func() { FromURL(ctx, srv.URL+path, URLTransform(VerifySignature(edKey))).To(&c) })
+		assert.Equal(t, CodeSourceLoad, CodeOf(err), path)
+		assert.Contains(t, err.Error(), want, path)
+	}
+
+	_, err = VerifySignature(edKey).TransformPayload(ctx, Payload{Name: "app.env", Data: doc})
+	assert.EqualError(t, err, "cannot fetch the signature of this source")
+}
+
+func TestParsePublicKey(t *testing.T) {
+	t.Parallel()
+	_, err := ParsePublicKey([]byte("not pem"))
+	assert.EqualError(t, err, "no PEM encoded public key found")
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	_, err = ParsePublicKey(publicKeyPEM(t, &rsaKey.PublicKey))
+	assert.EqualError(t, err, "cannot verify signatures with a *rsa.PublicKey key, it must be ed25519 or ECDSA")
+}
@@ -0,0 +1,288 @@
+package config
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"strings"
+	"time"
+)
+
+// Source provides values to a Builder.
+// Sources implementing fmt.Stringer are described by their String method, e.g. in a Meta's Source.
+type Source interface {
+	// Load returns the source's values.
+	// Keys are normalized, and keys with empty values dropped, when the values are merged.
+	Load() (map[string]string, error)
+}
+
+// SourceFunc adapts a function to a Source.
+type SourceFunc func() (map[string]string, error)
+
+// Load calls f.
+func (f SourceFunc) Load() (map[string]string, error) {
+	return f()
+}
+
+// linesSource is implemented by sources of KEY=VALUE lines,
+// allowing the Builder's DuplicatePolicy to be applied to them.
+type linesSource interface {
+	lines() ([]string, error)
+}
+
+// maxLineLength is the longest line FileSource accepts, leaving room for certificates and other large values.
+const maxLineLength = 1 << 20
+
+// FileSource returns a Source of the KEY=VALUE lines in file, as read by From.
+// The file is parsed as a dotenv file, so .env files from other ecosystems load as they do there:
+// comments, "export " prefixes, and single or double quoted values, which may span lines, are supported.
+// Loading fails if a line is longer than 1MiB or is malformed: each entry must have a non empty,
+// valid UTF-8 key followed by '=', and each quoted value must be terminated.
+func FileSource(file string) Source {
+	return fileSource(file)
+}
+
+type fileSource string
+
+func (s fileSource) Load() (map[string]string, error) {
+	return loadLines(s)
+}
+
+func (s fileSource) lines() ([]string, error) {
+	f, err := os.Open(string(s))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	entries, err := readDotenv(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", string(s), err)
+	}
+	return entries, nil
+}
+
+// readDotenv reads the dotenv file r, see parseDotenv, rejecting lines longer than maxLineLength.
+func readDotenv(r io.Reader) ([]string, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, maxLineLength)
+	var ss []string
+	for scanner.Scan() {
+		ss = append(ss, scanner.Text())
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("line %d: %w", len(ss)+1, err)
+	}
+	return parseDotenv(ss)
+}
+
+// FSSource returns a Source of the KEY=VALUE lines in the file at path in fsys, parsed as by FileSource.
+// This allows defaults to be compiled into the binary with go:embed, and layered under the environment:
+//
+//	//go:embed defaults.config
+//	var defaults embed.FS
+//
+//	config.FromFS(defaults, "defaults.config").FromEnv().To(&c)
+func FSSource(fsys fs.FS, path string) Source {
+	return fsSource{fsys: fsys, path: path}
+}
+
+type fsSource struct {
+	fsys fs.FS
+	path string
+}
+
+func (s fsSource) Load() (map[string]string, error) {
+	return loadLines(s)
+}
+
+func (s fsSource) lines() ([]string, error) {
+	f, err := s.fsys.Open(s.path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	entries, err := readDotenv(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", s.path, err)
+	}
+	return entries, nil
+}
+
+// FromFS returns a new Builder, populated with the values from the file at path in fsys. See FSSource.
+// It panics with an *Error if the file cannot be opened, or it is malformed.
+func FromFS(fsys fs.FS, path string) *Builder {
+	return newBuilder().FromFS(fsys, path)
+}
+
+// FromFS merges new values from the file at path in fsys into the current config state, returning the Builder.
+// See FSSource. It panics with an *Error if the file cannot be opened, or it is malformed.
+func (c *Builder) FromFS(fsys fs.FS, path string) *Builder {
+	return c.FromSource(FSSource(fsys, path))
+}
+
+// EnvSource returns a Source of the environment, as read by FromEnv.
+func EnvSource() Source {
+	return envSource{}
+}
+
+type envSource struct{}
+
+func (s envSource) Load() (map[string]string, error) {
+	return loadLines(s)
+}
+
+func (envSource) lines() ([]string, error) {
+	return os.Environ(), nil
+}
+
+// EnvPrefixSource returns a Source of the environment variables starting with prefix, with prefix removed,
+// so that with a prefix of "APP_", APP_PORT is bound to Port. prefix is matched case sensitively.
+func EnvPrefixSource(prefix string) Source {
+	return envPrefixSource(prefix)
+}
+
+type envPrefixSource string
+
+func (s envPrefixSource) Load() (map[string]string, error) {
+	return loadLines(s)
+}
+
+func (s envPrefixSource) lines() ([]string, error) {
+	var ss []string
+	for _, kv := range os.Environ() {
+		if strings.HasPrefix(kv, string(s)) {
+			ss = append(ss, strings.TrimPrefix(kv, string(s)))
+		}
+	}
+	return ss, nil
+}
+
+func loadLines(s linesSource) (map[string]string, error) {
+	ss, err := s.lines()
+	if err != nil {
+		return nil, err
+	}
+	return stringsToMap(ss), nil
+}
+
+// namedSource is a Source with a description for sourceName.
+type namedSource struct {
+	Source
+	name string
+}
+
+// sourceName describes s, e.g. in a BudgetError or a Meta's Source.
+func sourceName(s Source) string {
+	switch s := s.(type) {
+	case fileSource:
+		return "file " + string(s)
+	case fsSource:
+		return fmt.Sprintf("file %s in %T", s.path, s.fsys)
+	case envSource:
+		return "environment"
+	case envPrefixSource:
+		return "environment prefix " + string(s)
+	case namedSource:
+		return s.name
+	case dotenvReaderSource:
+		return FormatDotenv.String() + " reader"
+	case fmt.Stringer:
+		return s.String()
+	}
+	return fmt.Sprintf("source %T", s)
+}
+
+// MapSource returns a Source of fixed values.
+func MapSource(values map[string]string) Source {
+	return SourceFunc(func() (map[string]string, error) {
+		return values, nil
+	})
+}
+
+// FromSource returns a new Builder, populated with the values from s.
+// It panics with an *Error if s fails to load.
+func FromSource(s Source) *Builder {
+	return newBuilder().FromSource(s)
+}
+
+// FromSource merges new values from s into the current config state, returning the Builder.
+// It panics with an *Error if s fails to load.
+func (c *Builder) FromSource(s Source) *Builder {
+	values, err := c.load(s)
+	if err != nil {
+		panic(c.loadFailed(s, newError(CodeSourceLoad, "", fmt.Errorf("unable to load source: %w", err))))
+	}
+	c.mergeConfig(values, sourceName(s))
+	c.loaded(s, values)
+	return c
+}
+
+// WithOverlayIf merges new values from s into the current config state only if cond is true, returning the Builder.
+// This allows an extra layer to be applied to a subset of instances, e.g. canaries:
+//
+//	config.From("base.config").
+//		WithOverlayIf(os.Getenv("CANARY") == "true", config.FileSource("canary.config")).
+//		FromEnv().
+//		To(&c)
+//
+// It panics with an *Error if cond is true and s fails to load.
+func (c *Builder) WithOverlayIf(cond bool, s Source) *Builder {
+	if !cond {
+		return c
+	}
+	return c.FromSource(s)
+}
+
+// markEmpty records that key was set to an empty value, which was dropped.
+func (c *Builder) markEmpty(key string) {
+	if key == "" {
+		return
+	}
+	if c.empty == nil {
+		c.empty = make(map[string]bool)
+	}
+	c.empty[key] = true
+}
+
+// load loads s, normalizing keys, dropping empty values,
+// and applying the DuplicatePolicy to sources of KEY=VALUE lines.
+func (c *Builder) load(s Source) (map[string]string, error) {
+	start := time.Now()
+	defer func() { c.stats.Load += time.Since(start) }()
+	if ls, ok := s.(linesSource); ok {
+		var ss []string
+		var err error
+		c.withinBudget(sourceName(s), func() {
+			ss, err = ls.lines()
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, s := range ss {
+			if key := strings.TrimSuffix(s, "="); key != s && !strings.Contains(key, "=") {
+				c.markEmpty(NormalizeKey(key))
+			}
+		}
+		return stringsToMapWithPolicy(ss, c.duplicatePolicy, c.onDuplicate), nil
+	}
+
+	var values map[string]string
+	var err error
+	c.withinBudget(sourceName(s), func() {
+		values, err = s.Load()
+	})
+	if err != nil {
+		return nil, err
+	}
+	normalized := make(map[string]string, len(values))
+	for k, v := range values {
+		if k = NormalizeKey(k); k != "" && v != "" {
+			normalized[k] = v
+		} else if v == "" {
+			c.markEmpty(k)
+		}
+	}
+	return normalized, nil
+}
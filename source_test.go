@@ -0,0 +1,117 @@
+package config
+
+import (
+	"errors"
+	"io/fs"
+	"io/ioutil"
+	"os"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuilder_FromSource(t *testing.T) {
+	t.Parallel()
+	type testConfig struct {
+		A string
+		B string
+	}
+
+	var got testConfig
+	FromSource(MapSource(map[string]string{"A": "1", "b": "", "C": "3"})).
+		FromSource(MapSource(map[string]string{"b": "2"})).
+		To(&got)
+	assert.Equal(t, testConfig{A: "1", B: "2"}, got)
+
+	err := recoverError(func() {
+		FromSource(SourceFunc(func() (map[string]string, error) {
+			return nil, errors.New("boom")
+		}))
+	})
+	assert.EqualError(t, err, "config: CFG004: unable to load source: boom")
+}
+
+func TestFileSource(t *testing.T) {
+	t.Parallel()
+	file, err := ioutil.TempFile("", "testenv")
+	require.NoError(t, err)
+	defer os.Remove(file.Name())
+	_, err = file.Write([]byte("A=1\nA=2\nB=3"))
+	require.NoError(t, err)
+
+	values, err := FileSource(file.Name()).Load()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"a": "2", "b": "3"}, values)
+
+	var got struct{ A string }
+	WithDuplicatePolicy(FirstWins, nil).FromSource(FileSource(file.Name())).To(&got)
+	assert.Equal(t, "1", got.A, "the builder's duplicate policy should apply")
+
+	_, err = FileSource("/does/not/exist").Load()
+	assert.Error(t, err)
+}
+
+func TestFSSource(t *testing.T) {
+	t.Parallel()
+	fsys := fstest.MapFS{
+		"config/defaults.config": {Data: []byte("# defaults\nPORT=80\nHOST=localhost\nPORT=81\n")},
+		"config/invalid.config":  {Data: []byte("PORT\n")},
+	}
+
+	values, err := FSSource(fsys, "config/defaults.config").Load()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"port": "81", "host": "localhost"}, values)
+
+	var got struct {
+		Port int
+		Host Meta[string]
+	}
+	WithDuplicatePolicy(FirstWins, nil).FromFS(fsys, "config/defaults.config").
+		FromSource(MapSource(map[string]string{"HOST": ""})).To(&got)
+	assert.Equal(t, 80, got.Port, "the builder's duplicate policy should apply")
+	assert.Equal(t, "file config/defaults.config in fstest.MapFS", got.Host.Source)
+
+	_, err = FSSource(fsys, "config/invalid.config").Load()
+	assert.EqualError(t, err, "config/invalid.config: line 1: missing '=' separator")
+	_, err = FSSource(fsys, "missing.config").Load()
+	assert.True(t, errors.Is(err, fs.ErrNotExist))
+	err = recoverError(func() { FromFS(fsys, "missing.config") })
+	assert.Equal(t, CodeSourceLoad, CodeOf(err))
+}
+
+func TestBuilder_WithOverlayIf(t *testing.T) {
+	t.Parallel()
+	type testConfig struct {
+		Replicas int
+		Debug    bool
+	}
+	base := map[string]string{"REPLICAS": "3"}
+	canary := map[string]string{"DEBUG": "true"}
+
+	var got testConfig
+	FromSource(MapSource(base)).WithOverlayIf(false, MapSource(canary)).To(&got)
+	assert.Equal(t, testConfig{Replicas: 3}, got)
+
+	got = testConfig{}
+	FromSource(MapSource(base)).WithOverlayIf(true, MapSource(canary)).To(&got)
+	assert.Equal(t, testConfig{Replicas: 3, Debug: true}, got)
+
+	assert.NotPanics(t, func() {
+		FromSource(MapSource(base)).WithOverlayIf(false, FileSource("/does/not/exist"))
+	}, "skipped overlays should not be loaded")
+}
+
+func TestEnvPrefixSource(t *testing.T) {
+	os.Clearenv()
+	defer os.Clearenv()
+	require.NoError(t, os.Setenv("APP_PORT", "80"))
+	require.NoError(t, os.Setenv("APP_DB__HOST", "db"))
+	require.NoError(t, os.Setenv("OTHER_PORT", "81"))
+	require.NoError(t, os.Setenv("app_lower", "x"))
+
+	values, err := EnvPrefixSource("APP_").Load()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"port": "80", "db__host": "db"}, values)
+}
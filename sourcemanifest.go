@@ -0,0 +1,172 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// sourceManifest is the format of the file read by FromManifest.
+type sourceManifest struct {
+	Resolvers []string               `yaml:"resolvers"`
+	Sources   []sourceManifestSource `yaml:"sources"`
+}
+
+// sourceManifestSource is one source of a sourceManifest. Exactly one of its kinds must be set.
+type sourceManifestSource struct {
+	File string `yaml:"file"`
+	Env  *struct {
+		Prefix string `yaml:"prefix"`
+	} `yaml:"env"`
+	YAML             string `yaml:"yaml"`
+	JSON             string `yaml:"json"`
+	HCL              string `yaml:"hcl"`
+	Location         string `yaml:"location"`
+	TerraformOutputs string `yaml:"terraform_outputs"`
+	DownwardAPI      string `yaml:"downward_api"`
+	SSMPath          string `yaml:"ssm_path"`
+	Vault            string `yaml:"vault"`
+	Optional         bool   `yaml:"optional"`
+}
+
+// FromManifest returns a new Builder, populated from the sources declared in file. See Builder.FromManifest.
+func FromManifest(file string) *Builder {
+	return newBuilder().FromManifest(file)
+}
+
+// FromManifest merges values from the sources declared in file, a YAML source manifest, returning the Builder.
+// This allows the sources used, and their order, to change per environment without code changes:
+//
+//	resolvers: [sm, ssm]   # schemes registered with RegisterResolver
+//	sources:               # merged in order, later values overriding earlier ones
+//	  - file: base.config
+//	  - file: local.config
+//	    optional: true     # skipped if it does not exist
+//	  - yaml: app.yaml
+//	  - json: app.json
+//	  - hcl: app.hcl     # see the hcl module
+//	  - terraform_outputs: outputs.json
+//	  - downward_api: /etc/podinfo
+//	  - location: consul://localhost:8500/myapp   # any location accepted by From
+//	  - ssm_path: /myapp/prod/   # see the aws module's RegisterParameterStoreScheme
+//	  - vault: secret/data/app   # see the vault module's RegisterSourceScheme
+//	  - env: {prefix: APP_}
+//
+// ssm_path and vault sources are loaded from the ssm:// and vault:// locations, so their source schemes must be
+// registered, as RegisterSourceScheme describes. Relative paths are relative to the directory of file. Each resolver is registered under its scheme in a Resolvers,
+// which becomes the Builder's ValuePreProcessor, and so cannot be combined with WithValuePreProcessor.
+// It panics with an *Error if the manifest cannot be read or is invalid, or a source fails to load.
+func (c *Builder) FromManifest(file string) *Builder {
+	m, err := readSourceManifest(file)
+	if err != nil {
+		panic(newError(CodeSourceLoad, "", fmt.Errorf("%s: %w", file, err)))
+	}
+
+	if len(m.Resolvers) > 0 {
+		if c.valuePreProcessor != nil {
+			panic(newError(CodeSourceLoad, "", fmt.Errorf("%s: resolvers cannot be declared for a Builder with a ValuePreProcessor", file)))
+		}
+		r := NewResolvers(context.Background())
+		for _, scheme := range m.Resolvers {
+			res, ok := registeredResolver(scheme)
+			if !ok {
+				panic(newError(CodeSourceLoad, "", fmt.Errorf("%s: no resolver registered for scheme %q", file, scheme)))
+			}
+			r.Register(scheme, res)
+		}
+		c.WithValuePreProcessor(r)
+	}
+
+	dir := filepath.Dir(file)
+	for i, ms := range m.Sources {
+		s, err := ms.source(dir)
+		if err == nil {
+			var values map[string]string
+			if values, err = c.load(s); err == nil {
+				c.mergeConfig(values, sourceName(s))
+				c.loaded(s, values)
+				continue
+			}
+		}
+		if ms.Optional && errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		panic(c.loadFailed(s, newError(CodeSourceLoad, "", fmt.Errorf("%s: source %d: %w", file, i+1, err))))
+	}
+	return c
+}
+
+func readSourceManifest(file string) (sourceManifest, error) {
+	var m sourceManifest
+	b, err := ioutil.ReadFile(file)
+	if err != nil {
+		return m, err
+	}
+	d := yaml.NewDecoder(bytes.NewReader(b))
+	d.KnownFields(true)
+	if err := d.Decode(&m); err != nil {
+		return m, fmt.Errorf("invalid source manifest: %w", err)
+	}
+	return m, nil
+}
+
+// source returns the Source declared by s, resolving relative paths against dir.
+func (s sourceManifestSource) source(dir string) (Source, error) {
+	var sources []Source
+	if s.File != "" {
+		sources = append(sources, FileSource(relativeTo(dir, s.File)))
+	}
+	if s.YAML != "" {
+		sources = append(sources, YAMLSource(relativeTo(dir, s.YAML)))
+	}
+	if s.JSON != "" {
+		sources = append(sources, JSONSource(relativeTo(dir, s.JSON)))
+	}
+	if s.HCL != "" {
+		sources = append(sources, formatFileSource(relativeTo(dir, s.HCL), FormatHCL))
+	}
+	if s.Env != nil {
+		sources = append(sources, EnvPrefixSource(s.Env.Prefix))
+	}
+	if s.Location != "" {
+		src, err := SourceFor(s.Location)
+		if err != nil {
+			return nil, err
+		}
+		sources = append(sources, src)
+	}
+	for _, l := range []struct{ scheme, path string }{{"ssm", s.SSMPath}, {"vault", s.Vault}} {
+		if l.path == "" {
+			continue
+		}
+		src, err := SourceFor(l.scheme + "://" + strings.TrimPrefix(l.path, "/"))
+		if err != nil {
+			return nil, err
+		}
+		sources = append(sources, src)
+	}
+	if s.TerraformOutputs != "" {
+		sources = append(sources, TerraformOutputsSource(relativeTo(dir, s.TerraformOutputs)))
+	}
+	if s.DownwardAPI != "" {
+		sources = append(sources, DownwardAPISource(relativeTo(dir, s.DownwardAPI)))
+	}
+	if len(sources) != 1 {
+		return nil, errors.New("exactly one of file, yaml, json, hcl, env, location, terraform_outputs, downward_api, ssm_path or vault must be set")
+	}
+	return sources[0], nil
+}
+
+func relativeTo(dir, path string) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(dir, path)
+}
@@ -0,0 +1,119 @@
+package config
+
+import (
+	"context"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	for name, content := range files {
+		require.NoError(t, ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0644))
+	}
+}
+
+func TestFromManifest(t *testing.T) {
+	RegisterResolver("test-manifest", ResolverFunc(func(ctx context.Context, ref string) (string, error) {
+		return "secret-" + ref, nil
+	}))
+	os.Clearenv()
+	defer os.Clearenv()
+	require.NoError(t, os.Setenv("APP_PORT", "8080"))
+	require.NoError(t, os.Setenv("PORT", "1"))
+
+	dir, err := ioutil.TempDir("", "manifest")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	writeFiles(t, dir, map[string]string{
+		"config.sources.yaml": `
+resolvers: [test-manifest]
+sources:
+  - file: base.config
+  - file: local.config
+    optional: true
+  - terraform_outputs: outputs.json
+  - yaml: app.yaml
+  - json: app.json
+  - env: {prefix: APP_}
+`,
+		"base.config":  "PORT=80\nHOST=localhost\nPASSWORD=test-manifest://db\n",
+		"outputs.json": `{"host": {"value": "db.internal"}}`,
+		"app.yaml":     "log: {level: debug}\n",
+		"app.json":     `{"log": {"format": "json"}}`,
+	})
+
+	type testConfig struct {
+		Host     string
+		Port     int
+		Password string
+		Log      struct{ Level, Format string }
+	}
+	var got testConfig
+	FromManifest(filepath.Join(dir, "config.sources.yaml")).To(&got)
+	want := testConfig{Host: "db.internal", Port: 8080, Password: "secret-db"}
+	want.Log.Level, want.Log.Format = "debug", "json"
+	assert.Equal(t, want, got)
+}
+
+func TestFromManifest_schemes(t *testing.T) {
+	for _, scheme := range []string{"ssm", "vault"} {
+		scheme := scheme
+		RegisterSourceScheme(scheme, func(u *url.URL) (Source, error) {
+			return MapSource(map[string]string{"path": scheme + ":" + u.Host + u.Path}), nil
+		})
+	}
+	dir, err := ioutil.TempDir("", "manifest")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	writeFiles(t, dir, map[string]string{
+		"ssm.yaml":   "sources:\n  - ssm_path: /myapp/prod/\n",
+		"vault.yaml": "sources:\n  - vault: secret/data/app\n",
+	})
+
+	for name, want := range map[string]string{"ssm.yaml": "ssm:myapp/prod/", "vault.yaml": "vault:secret/data/app"} {
+		var got struct{ Path string }
+		FromManifest(filepath.Join(dir, name)).To(&got)
+		assert.Equal(t, want, got.Path, name)
+	}
+}
+
+func TestFromManifest_errors(t *testing.T) {
+	t.Parallel()
+	dir, err := ioutil.TempDir("", "manifest")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	writeFiles(t, dir, map[string]string{
+		"missing.yaml":   "sources:\n  - file: missing.config\n",
+		"ambiguous.yaml": "sources:\n  - file: a.config\n    location: b.config\n",
+		"unknown.yaml":   "sources:\n  - files: a.config\n",
+		"resolver.yaml":  "resolvers: [not-registered]\n",
+		"hcl.yaml":       "sources:\n  - hcl: app.hcl\n",
+		"app.hcl":        "log {}\n",
+	})
+
+	tests := map[string]string{
+		"missing.yaml":   "source 1: open " + filepath.Join(dir, "missing.config"),
+		"ambiguous.yaml": "source 1: exactly one of file, yaml, json, hcl, env, location, terraform_outputs, downward_api, ssm_path or vault must be set",
+		"unknown.yaml":   "invalid source manifest",
+		"resolver.yaml":  `no resolver registered for scheme "not-registered"`,
+		"absent.yaml":    "no such file or directory",
+		"hcl.yaml":       "no parser registered for format hcl",
+	}
+	for name, want := range tests {
+		err := recoverError(func() { FromManifest(filepath.Join(dir, name)) })
+		require.Error(t, err, name)
+		assert.Equal(t, CodeSourceLoad, CodeOf(err), name)
+		assert.Contains(t, err.Error(), want, name)
+	}
+
+	err = recoverError(func() {
+		WithValuePreProcessor(&upperPreProcessor{}).FromManifest(filepath.Join(dir, "resolver.yaml"))
+	})
+	assert.Contains(t, err.Error(), "resolvers cannot be declared for a Builder with a ValuePreProcessor")
+}
@@ -0,0 +1,87 @@
+package config
+
+import (
+	"errors"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// SplitQuoted splits s into a slice on delim, as a slice field tagged `config:",quoted"` is, honouring shell like
+// quoting so that entries may contain the delimiter or surrounding whitespace:
+//
+//	SplitQuoted(`"a b" c 'd,e'`, " ") // [a b, c, d,e]
+//
+// Double quoted text may escape a double quote or backslash with a backslash, single quoted text is taken as it is,
+// and outside quotes a backslash escapes the character after it, such as a delimiter. Unquoted whitespace around
+// entries is stripped and empty entries are dropped, unless quoted, e.g. "". Unlike unquoted slices, which split on
+// the delimiter only, a whitespace delimiter splits on any run of whitespace, as shells do. nil is returned
+// if there are no entries.
+// It returns an error if a quote is unterminated or s ends with a backslash.
+func SplitQuoted(s, delim string) ([]string, error) {
+	if delim == "" {
+		panic("empty delimiter") // impossible or programmer error
+	}
+	whitespace := strings.TrimSpace(delim) == ""
+	var (
+		entries []string
+		entry   strings.Builder
+		// end is the length of entry up to its last quoted, escaped or non whitespace character,
+		// so that unquoted trailing whitespace is stripped.
+		end    int
+		quoted bool
+	)
+	flush := func() {
+		if end > 0 || quoted {
+			entries = append(entries, entry.String()[:end])
+		}
+		entry.Reset()
+		end, quoted = 0, false
+	}
+	keep := func(str string) {
+		entry.WriteString(str)
+		end = entry.Len()
+	}
+	for i := 0; i < len(s); {
+		r, n := utf8.DecodeRuneInString(s[i:])
+		switch {
+		case r == '"' || r == '\'':
+			j := i + 1
+			for ; j < len(s) && s[j] != byte(r); j++ {
+				if r == '"' && s[j] == '\\' && j+1 < len(s) && (s[j+1] == '"' || s[j+1] == '\\') {
+					j++
+				}
+				keep(s[j : j+1])
+			}
+			if j == len(s) {
+				return nil, errors.New("unterminated quote")
+			}
+			quoted = true
+			end = entry.Len()
+			i = j + 1
+		case r == '\\':
+			if i+1 == len(s) {
+				return nil, errors.New("trailing backslash")
+			}
+			_, m := utf8.DecodeRuneInString(s[i+1:])
+			keep(s[i+1 : i+1+m])
+			i += 1 + m
+		case whitespace && unicode.IsSpace(r):
+			flush()
+			i += n
+		case !whitespace && strings.HasPrefix(s[i:], delim):
+			flush()
+			i += len(delim)
+		case unicode.IsSpace(r):
+			if end > 0 || quoted {
+				entry.WriteString(s[i : i+n])
+			}
+			i += n
+		default:
+			keep(s[i : i+n])
+			i += n
+		}
+	}
+	flush()
+	return entries, nil
+}
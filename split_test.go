@@ -0,0 +1,79 @@
+package config
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSplitQuoted(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		in      string
+		delim   string
+		want    []string
+		wantErr bool
+	}{
+		{in: "", delim: " ", want: nil},
+		{in: "  \t ", delim: " ", want: nil},
+		{in: "a b\t\nc", delim: " ", want: []string{"a", "b", "c"}},
+		{in: `"a b" c "d,e"`, delim: " ", want: []string{"a b", "c", "d,e"}},
+		{in: `'a "b"' c`, delim: " ", want: []string{`a "b"`, "c"}},
+		{in: `"a \"b\" \\ \n"`, delim: " ", want: []string{`a "b" \ \n`}},
+		{in: `a\ b c`, delim: " ", want: []string{"a b", "c"}},
+		{in: `"" a ''`, delim: " ", want: []string{"", "a", ""}},
+		{in: `pre"fix a"post b`, delim: " ", want: []string{"prefix apost", "b"}},
+		{in: `a, "b, c" ,, d `, delim: ",", want: []string{"a", "b, c", "d"}},
+		{in: `a x, " b "`, delim: ",", want: []string{"a x", " b "}},
+		{in: `a\,b,c`, delim: ",", want: []string{"a,b", "c"}},
+		{in: `a::"b::c"`, delim: "::", want: []string{"a", "b::c"}},
+		{in: `"héllo wörld" ü`, delim: " ", want: []string{"héllo wörld", "ü"}},
+		{in: `"a b`, delim: " ", wantErr: true},
+		{in: `a 'b`, delim: " ", wantErr: true},
+		{in: `a\`, delim: " ", wantErr: true},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.in, func(t *testing.T) {
+			t.Parallel()
+			got, err := SplitQuoted(tt.in, tt.delim)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_quoted(t *testing.T) {
+	type testConfig struct {
+		Args    []string `config:",quoted"`
+		Ports   []int    `config:",quoted"`
+		Plain   []string
+		Invalid []string `config:",quoted"`
+	}
+
+	os.Clearenv()
+	defer os.Clearenv()
+	require.NoError(t, os.Setenv("ARGS", `--name "a b" 'c d'`))
+	require.NoError(t, os.Setenv("PORTS", `"80" 443`))
+	require.NoError(t, os.Setenv("PLAIN", `"a b"`))
+	require.NoError(t, os.Setenv("INVALID", `"a b`))
+
+	var errKeys []string
+	var got testConfig
+	WithConversionErrorHandler(func(key, value string, err error) {
+		errKeys = append(errKeys, key)
+	}).FromEnv().To(&got)
+
+	assert.Equal(t, testConfig{
+		Args:  []string{"--name", "a b", "c d"},
+		Ports: []int{80, 443},
+		Plain: []string{`"a`, `b"`},
+	}, got)
+	assert.Equal(t, []string{"invalid"}, errKeys)
+}
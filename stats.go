@@ -0,0 +1,47 @@
+package config
+
+import "time"
+
+// Stats counts the work done by a Builder, and the time spent on each phase, for tracking config loading
+// against an SLO across a fleet, or catching regressions in benchmarks. Counts and durations accumulate
+// over the Builder's life, across all its sources and targets.
+type Stats struct {
+	// KeysMerged counts the values merged from sources, including those expanded from a single value,
+	// e.g. by sm-multi://. Keys merged by several sources are counted once per source.
+	KeysMerged int `json:"keys_merged"`
+	// FieldsBound counts the fields set from a value by To, Bind and ToWithReport. Fields left unset,
+	// or whose value failed to convert, are not counted, nor are raw and remain fields.
+	FieldsBound int `json:"fields_bound"`
+	// SecretsResolved counts the values changed by the ValuePreProcessor, e.g. secrets fetched from AWS.
+	SecretsResolved int `json:"secrets_resolved"`
+
+	// Load is the time spent loading sources.
+	Load time.Duration `json:"load"`
+	// Resolve is the time spent passing values through the middleware and ValuePreProcessor,
+	// including values resolved lazily while binding.
+	Resolve time.Duration `json:"resolve"`
+	// Bind is the time spent binding targets, excluding the time spent resolving values lazily.
+	Bind time.Duration `json:"bind"`
+}
+
+// Stats returns the Builder's Stats so far.
+func (c *Builder) Stats() Stats {
+	return c.stats
+}
+
+// WithStatsHandler creates a new builder which calls h with its Stats after binding each target.
+func WithStatsHandler(h func(Stats)) *Builder {
+	return newBuilder().WithStatsHandler(h)
+}
+
+// WithStatsHandler sets a handler called with the Builder's Stats after each target is bound by To, Bind
+// or ToWithReport, returning the Builder. This lets the stats be exported, e.g. to Prometheus:
+//
+//	config.WithStatsHandler(func(s config.Stats) {
+//		configResolveSeconds.Set(s.Resolve.Seconds())
+//		configSecretsResolved.Set(float64(s.SecretsResolved))
+//	}).WithValuePreProcessor(p).FromEnv().To(&c)
+func (c *Builder) WithStatsHandler(h func(Stats)) *Builder {
+	c.onStats = h
+	return c
+}
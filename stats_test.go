@@ -0,0 +1,46 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuilder_Stats(t *testing.T) {
+	type Config struct {
+		Port     int
+		Secret   string
+		Timeout  time.Duration
+		Database struct {
+			Host string
+		}
+	}
+
+	var handled []Stats
+	p := &countingPreProcessor{}
+	b := WithStatsHandler(func(s Stats) { handled = append(handled, s) }).
+		WithValuePreProcessor(p).
+		FromSource(MapSource(map[string]string{"PORT": "8080", "SECRET": "secret://x", "TIMEOUT": "soon"})).
+		FromSource(MapSource(map[string]string{"DATABASE__HOST": "db", "PORT": "9090"}))
+
+	s := b.Stats()
+	assert.Equal(t, 5, s.KeysMerged, "keys merged by several sources are counted once per source")
+	assert.Equal(t, 1, s.SecretsResolved)
+	assert.Equal(t, 0, s.FieldsBound)
+	assert.Equal(t, time.Duration(0), s.Bind)
+	assert.Empty(t, handled, "the handler is called after binding")
+
+	var c Config
+	b.To(&c)
+	s = b.Stats()
+	assert.Equal(t, 3, s.FieldsBound, "the timeout fails to convert")
+	assert.True(t, s.Load > 0 && s.Resolve > 0 && s.Bind > 0, "got %+v", s)
+	assert.Equal(t, []Stats{s}, handled)
+
+	var again struct{ Port int }
+	assert.NoError(t, b.Bind(&again))
+	assert.Equal(t, 4, b.Stats().FieldsBound, "stats accumulate across targets")
+	assert.Len(t, handled, 2)
+	assert.Equal(t, 1, p.calls)
+}
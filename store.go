@@ -0,0 +1,53 @@
+package config
+
+import (
+	"fmt"
+	"sync/atomic"
+)
+
+// Store holds the latest config of type T, for services reloading config at runtime. Readers load an immutable
+// snapshot rather than reading a struct while it is rebound, which is a data race:
+//
+//	var c Config
+//	b := config.WithRefreshInterval(time.Minute).FromEnv()
+//	b.To(&c)
+//	store := config.NewStore(&c)
+//	err := b.OnChange(store.Update).Refresh(ctx, &c, onError)
+//
+//	timeout := store.Load().Timeout
+//
+// Update is an OnChange listener for Refresh, and an onChange function for watch.Watch, so either keeps the Store
+// up to date. Neither ever changes a config once passed, as a config in a Store must not be changed:
+// configs are replaced rather than rebound. The config is held in an atomic.Value, as this module supports Go 1.18,
+// which lacks atomic.Pointer. The zero Store is empty, and ready to use.
+type Store[T any] struct {
+	v atomic.Value
+}
+
+// NewStore returns a Store holding initial.
+func NewStore[T any](initial *T) *Store[T] {
+	s := &Store[T]{}
+	s.Set(initial)
+	return s
+}
+
+// Load returns the latest config, or nil if the Store is empty.
+func (s *Store[T]) Load() *T {
+	c, _ := s.v.Load().(*T)
+	return c
+}
+
+// Set replaces the config with c, which must not be changed afterwards.
+func (s *Store[T]) Set(c *T) {
+	s.v.Store(c)
+}
+
+// Update replaces the config with new, ignoring old, as an OnChange listener.
+// It panics with an *Error with CodeUnsupportedField if new is not a *T.
+func (s *Store[T]) Update(old, new interface{}) {
+	c, ok := new.(*T)
+	if !ok {
+		panic(newError(CodeUnsupportedField, "", fmt.Errorf("cannot store %T in a Store of %T", new, c)))
+	}
+	s.Set(c)
+}
@@ -0,0 +1,74 @@
+package config
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStore(t *testing.T) {
+	t.Parallel()
+	type testConfig struct{ Port int }
+	var empty Store[testConfig]
+	assert.Nil(t, empty.Load())
+
+	first := &testConfig{Port: 80}
+	s := NewStore(first)
+	assert.True(t, s.Load() == first)
+
+	second := &testConfig{Port: 8080}
+	s.Update(first, second)
+	assert.True(t, s.Load() == second)
+
+	err := recoverError(func() { s.Update(second, testConfig{}) })
+	assert.Equal(t, CodeUnsupportedField, CodeOf(err))
+	assert.EqualError(t, err, "config: CFG005: cannot store config.testConfig in a Store of *config.testConfig")
+}
+
+func TestStore_Refresh(t *testing.T) {
+	t.Parallel()
+	type testConfig struct{ Port int }
+	var mu sync.Mutex
+	port := "80"
+	s := SourceFunc(func() (map[string]string, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		return map[string]string{"PORT": port}, nil
+	})
+
+	clock := NewManualClock(time.Now())
+	b := WithClock(clock).WithRefreshInterval(time.Minute).FromSource(s)
+	var c testConfig
+	b.To(&c)
+	store := NewStore(&c)
+	updated := make(chan struct{}, 10)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	b.OnChange(store.Update).OnChange(func(old, new interface{}) { updated <- struct{}{} })
+	require.NoError(t, b.Refresh(ctx, &c, nil))
+
+	// Readers load snapshots while the config is replaced, which the race detector checks.
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for store.Load().Port != 8080 {
+			time.Sleep(time.Millisecond)
+		}
+	}()
+	mu.Lock()
+	port = "8080"
+	mu.Unlock()
+	clock.BlockUntil(1)
+	clock.Advance(time.Minute)
+	select {
+	case <-updated:
+	case <-time.After(time.Second):
+		t.Fatal("store was not updated")
+	}
+	<-done
+	assert.Equal(t, 80, c.Port, "the initial config is never changed")
+}
@@ -0,0 +1,80 @@
+package config
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+// WithStrict creates a new builder in strict mode.
+func WithStrict(prefixes ...string) *Builder {
+	return newBuilder().WithStrict(prefixes...)
+}
+
+// WithStrict makes To fail with an *Error with CodeUnknownKey if, after binding, the config state holds keys
+// starting with any of prefixes which no field is bound to, catching typos such as DATABSE__HOST
+// which would otherwise silently leave a field unset. Keys collected by a remain field count as bound.
+// prefixes are matched case insensitively. Without prefixes every key is checked, which suits files;
+// when reading the environment, pass the application's prefix, so that PATH and friends are ignored:
+//
+//	config.WithStrict("myapp__").FromEnv().To(&c)
+//
+// Keys bound by earlier calls to To count as bound, so with several targets,
+// the prefixes should only cover keys the targets bound so far can use.
+func (c *Builder) WithStrict(prefixes ...string) *Builder {
+	c.strict = []string{""}
+	if len(prefixes) > 0 {
+		c.strict = make([]string, len(prefixes))
+		for i, p := range prefixes {
+			c.strict[i] = NormalizeKey(p)
+		}
+	}
+	return c
+}
+
+// strictError returns an *Error with CodeUnknownKey listing the unbound keys within the strict prefixes, if any.
+func (c *Builder) strictError() error {
+	var unknown []string
+	seen := make(map[string]bool)
+	for _, prefix := range c.strict {
+		for _, key := range c.UnboundKeys(prefix) {
+			if !seen[key] {
+				seen[key] = true
+				unknown = append(unknown, key)
+			}
+		}
+	}
+	if len(unknown) == 0 {
+		return nil
+	}
+	return newError(CodeUnknownKey, "", fmt.Errorf("keys not bound to any field: %s", strings.Join(unknown, ", ")))
+}
+
+// Ignore excludes keys matching any of patterns from the unbound keys, as listed by UnboundKeys,
+// so that they fail neither WithStrict nor CheckManifest, nor raise unknown key warnings, returning the Builder.
+// This lets whole subtrees, such as experimental settings read by other means, be left out
+// while adopting strict mode incrementally:
+//
+//	config.WithStrict("myapp__").Ignore("myapp__debug__*").FromEnv().To(&c)
+//
+// Patterns are matched case insensitively against whole keys with path.Match, so * matches any run of characters.
+// It panics with an *Error with CodeUnsupportedField if a pattern is malformed.
+func (c *Builder) Ignore(patterns ...string) *Builder {
+	for _, p := range patterns {
+		if _, err := path.Match(p, ""); err != nil {
+			panic(newError(CodeUnsupportedField, "", fmt.Errorf("Ignore pattern %q is malformed: %w", p, err)))
+		}
+		c.ignored = append(c.ignored, NormalizeKey(p))
+	}
+	return c
+}
+
+// isIgnored reports whether key matches a pattern passed to Ignore.
+func (c *Builder) isIgnored(key string) bool {
+	for _, p := range c.ignored {
+		if ok, _ := path.Match(p, key); ok {
+			return true
+		}
+	}
+	return false
+}
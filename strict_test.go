@@ -0,0 +1,63 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuilder_WithStrict(t *testing.T) {
+	t.Parallel()
+	type testConfig struct {
+		Database struct{ Host string }
+		Extra    map[string]string `config:",remain"`
+	}
+	type plainConfig struct {
+		Database struct{ Host string }
+	}
+	values := map[string]string{"DATABSE__HOST": "db", "DATABASE__HOST": "db", "PATH": "/bin"}
+
+	var got plainConfig
+	err := recoverError(func() { WithStrict("database", "databse").FromSource(MapSource(values)).To(&got) })
+	assert.EqualError(t, err, "config: CFG006: keys not bound to any field: databse__host")
+	assert.Equal(t, CodeUnknownKey, CodeOf(err))
+	assert.Equal(t, "db", got.Database.Host, "fields are bound before failing")
+
+	err = recoverError(func() { WithStrict().FromSource(MapSource(values)).To(&got) })
+	assert.EqualError(t, err, "config: CFG006: keys not bound to any field: databse__host, path")
+
+	assert.Nil(t, recoverError(func() { WithStrict("database").FromSource(MapSource(values)).To(&got) }))
+	assert.Nil(t, recoverError(func() { FromSource(MapSource(values)).To(&got) }), "not strict by default")
+
+	var withRemain testConfig
+	assert.Nil(t, recoverError(func() { WithStrict().FromSource(MapSource(values)).To(&withRemain) }), "remain fields collect unbound keys")
+
+	err = WithStrict("DATABSE").FromSource(MapSource(values)).Bind(&got)
+	assert.Equal(t, CodeUnknownKey, CodeOf(err))
+
+	r := WithStrict("databse").FromSource(MapSource(values)).ToWithReport(&got)
+	require.Len(t, r.Errors, 1)
+	assert.Equal(t, CodeUnknownKey, CodeOf(r.Errors[0]))
+}
+
+func TestBuilder_Ignore(t *testing.T) {
+	t.Parallel()
+	type testConfig struct {
+		Database struct{ Host string }
+	}
+	values := map[string]string{"DATABASE__HOST": "db", "DEBUG__TRACE": "true", "DEBUG__PROFILE": "cpu", "DATABSE__PORT": "5432"}
+
+	var got testConfig
+	err := recoverError(func() { WithStrict().Ignore("DEBUG__*").FromSource(MapSource(values)).To(&got) })
+	assert.EqualError(t, err, "config: CFG006: keys not bound to any field: databse__port", "ignored keys are not unknown")
+
+	b := WithStrict().Ignore("debug__*", "databse__port").FromSource(MapSource(values))
+	assert.Nil(t, recoverError(func() { b.To(&got) }))
+	assert.Empty(t, b.UnboundKeys(""))
+	assert.Empty(t, b.Warnings())
+
+	err = recoverError(func() { FromEnv().Ignore("debug__[") })
+	assert.Equal(t, CodeUnsupportedField, CodeOf(err))
+	assert.Contains(t, err.Error(), `Ignore pattern "debug__[" is malformed`)
+}
@@ -0,0 +1,94 @@
+package config
+
+import "strings"
+
+// maxSuggestionDistance is the largest edit distance at which a key is suggested for an unset field.
+const maxSuggestionDistance = 2
+
+// similarKeys returns, for each of fs's fields without a value, the most similar key in the current config state
+// which is not bound to a field, either by an earlier call to To or by one of fs, if it is within
+// maxSuggestionDistance. Such a key is probably a typo of the field's key, e.g. DATABSE__HOST for DATABASE__HOST.
+func (c *Builder) similarKeys(fs []field) map[string]string {
+	fieldKeys := make(map[string]bool, len(fs))
+	for _, f := range fs {
+		fieldKeys[f.key] = true
+	}
+	var candidates []string
+	for _, key := range c.keys() {
+		if !c.bound[key] && !fieldKeys[key] && !c.isIgnored(key) {
+			candidates = append(candidates, key)
+		}
+	}
+
+	var similar map[string]string
+	for _, f := range fs {
+		if f.remain || f.rawMap {
+			continue
+		}
+		if _, ok := c.lookupLiteral(f.key); ok {
+			continue
+		}
+		best, bestDistance := "", maxSuggestionDistance+1
+		for _, key := range candidates {
+			if d := levenshtein(f.key, key, bestDistance); d < bestDistance {
+				best, bestDistance = key, d
+			}
+		}
+		if best != "" {
+			if similar == nil {
+				similar = make(map[string]string)
+			}
+			similar[f.key] = best
+		}
+	}
+	return similar
+}
+
+// didYouMean returns a hint suggesting key, written as an environment variable.
+func didYouMean(key string) string {
+	return "did you mean " + strings.ToUpper(key) + "?"
+}
+
+// levenshtein returns the edit distance between a and b, or max if it is at least max.
+func levenshtein(a, b string, max int) int {
+	ra, rb := []rune(a), []rune(b)
+	if d := len(ra) - len(rb); d >= max || -d >= max {
+		return max
+	}
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		rowMin := curr[0]
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = minInt(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+			if curr[j] < rowMin {
+				rowMin = curr[j]
+			}
+		}
+		if rowMin >= max {
+			return max
+		}
+		prev, curr = curr, prev
+	}
+	if prev[len(rb)] > max {
+		return max
+	}
+	return prev[len(rb)]
+}
+
+func minInt(first int, rest ...int) int {
+	for _, v := range rest {
+		if v < first {
+			first = v
+		}
+	}
+	return first
+}
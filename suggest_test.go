@@ -0,0 +1,62 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLevenshtein(t *testing.T) {
+	t.Parallel()
+	for _, tc := range []struct {
+		a, b string
+		want int
+	}{
+		{"", "", 0},
+		{"database__host", "database__host", 0},
+		{"database__host", "databse__host", 1},
+		{"database__host", "database__hots", 2},
+		{"database__host", "db__host", 3},
+		{"port", "", 3},
+		{"héllo", "hello", 1},
+	} {
+		assert.Equal(t, tc.want, levenshtein(tc.a, tc.b, 3), "%s, %s", tc.a, tc.b)
+		assert.Equal(t, tc.want, levenshtein(tc.b, tc.a, 3), "%s, %s", tc.b, tc.a)
+	}
+}
+
+func TestBuilder_similarKeys(t *testing.T) {
+	t.Parallel()
+	type testConfig struct {
+		Database struct {
+			Host string
+			Port int
+		}
+		Debug bool
+	}
+
+	b := FromSource(MapSource(map[string]string{
+		"DATABSE__HOST":  "db",
+		"DATABASE__PORT": "5432",
+		"DEBUGGING_MODE": "true",
+	}))
+	assert.Equal(t, map[string]string{"database__host": "databse__host"}, b.Coverage(&testConfig{}).Similar)
+
+	var got testConfig
+	r := b.ToWithReport(&got)
+	for _, f := range r.Fields {
+		if f.Key == "database__host" {
+			assert.Equal(t, FieldUnset, f.Outcome)
+			assert.Equal(t, "databse__host", f.SimilarKey)
+		} else {
+			assert.Empty(t, f.SimilarKey, f.Key)
+		}
+	}
+	assert.Equal(t, []string{"databse__host: not bound by any field, did you mean DATABASE__HOST?"}, r.Warnings)
+	assert.Equal(t, []Warning{
+		{Kind: WarningUnknownKey, Key: "databse__host", Message: "not bound by any field, did you mean DATABASE__HOST?"},
+	}, b.Warnings())
+
+	b = FromSource(MapSource(map[string]string{"DATABASE__HOST": "db", "DATABASE__HOTS": "db"}))
+	assert.Nil(t, b.Coverage(&testConfig{}).Similar, "fields with a value have no suggestion")
+}
@@ -0,0 +1,9 @@
+package config
+
+import "syscall"
+
+// ioctl requests getting and setting a terminal's attributes.
+const (
+	ioctlGetTermios = syscall.TCGETS
+	ioctlSetTermios = syscall.TCSETS
+)
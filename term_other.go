@@ -0,0 +1,16 @@
+//go:build !linux && !darwin
+
+package config
+
+import "os"
+
+// isTerminal returns whether f is a character device, as terminals are.
+func isTerminal(f *os.File) bool {
+	fi, err := f.Stat()
+	return err == nil && fi.Mode()&os.ModeCharDevice != 0
+}
+
+// setEcho returns errHiddenInput, as echo cannot be turned off here.
+func setEcho(f *os.File, on bool) error {
+	return errHiddenInput
+}
@@ -0,0 +1,59 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+)
+
+// TerraformOutputsSource returns a Source of the outputs in file, as written by `terraform output -json`.
+// Structured outputs are flattened into keys:
+//   - object and map attributes are nested with the struct delimiter, so db = { host = "..." } is bound from DB__HOST
+//   - lists and sets of primitives are joined with the slice delimiter, so they bind to slice fields
+//   - lists and sets holding objects or collections are indexed, e.g. SUBNETS__0__ID
+//
+// Null values are skipped. Sensitive outputs are included, as `terraform output -json` writes them in plain text.
+func TerraformOutputsSource(file string) Source {
+	return namedSource{name: "file " + file, Source: SourceFunc(func() (map[string]string, error) {
+		b, err := ioutil.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		values, err := flattenTerraformOutputs(b)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", file, err)
+		}
+		return values, nil
+	})}
+}
+
+// FromTerraformOutputs returns a new Builder, populated with the outputs in file. See TerraformOutputsSource.
+// It panics with an *Error if file cannot be read or parsed.
+func FromTerraformOutputs(file string) *Builder {
+	return newBuilder().FromTerraformOutputs(file)
+}
+
+// FromTerraformOutputs merges the outputs in file into the current config state, returning the Builder.
+// See TerraformOutputsSource. It panics with an *Error if file cannot be read or parsed.
+func (c *Builder) FromTerraformOutputs(file string) *Builder {
+	return c.FromSource(TerraformOutputsSource(file))
+}
+
+type terraformOutput struct {
+	Value interface{} `json:"value"`
+}
+
+func flattenTerraformOutputs(b []byte) (map[string]string, error) {
+	d := json.NewDecoder(bytes.NewReader(b))
+	d.UseNumber() // keeps large numbers exact
+	var outputs map[string]terraformOutput
+	if err := d.Decode(&outputs); err != nil {
+		return nil, fmt.Errorf("invalid terraform outputs: %w", err)
+	}
+	values := make(map[string]string)
+	for name, output := range outputs {
+		flattenJSONValue(values, name, output.Value)
+	}
+	return values, nil
+}
@@ -0,0 +1,82 @@
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const terraformOutputs = `{
+  "api_endpoint": {"sensitive": false, "type": "string", "value": "https://api.example.com"},
+  "db": {
+    "sensitive": true,
+    "type": ["object", {"host": "string", "port": "number"}],
+    "value": {"host": "db.internal", "port": 5432}
+  },
+  "zones": {"sensitive": false, "type": ["list", "string"], "value": ["eu-west-1a", "eu-west-1b"]},
+  "subnets": {
+    "sensitive": false,
+    "type": ["list", ["object", {"id": "string"}]],
+    "value": [{"id": "subnet-1"}, {"id": "subnet-2"}]
+  },
+  "replicas": {"sensitive": false, "type": "number", "value": 3},
+  "public": {"sensitive": false, "type": "bool", "value": false},
+  "unset": {"sensitive": false, "type": "string", "value": null}
+}`
+
+func TestBuilder_FromTerraformOutputs(t *testing.T) {
+	t.Parallel()
+	file, err := ioutil.TempFile("", "outputs.json")
+	require.NoError(t, err)
+	defer os.Remove(file.Name())
+	_, err = file.Write([]byte(terraformOutputs))
+	require.NoError(t, err)
+
+	values, err := TerraformOutputsSource(file.Name()).Load()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"api_endpoint":   "https://api.example.com",
+		"db__host":       "db.internal",
+		"db__port":       "5432",
+		"zones":          "eu-west-1a eu-west-1b",
+		"subnets__0__id": "subnet-1",
+		"subnets__1__id": "subnet-2",
+		"replicas":       "3",
+		"public":         "false",
+	}, values)
+
+	type testConfig struct {
+		APIEndpoint string `config:"api_endpoint"`
+		DB          struct {
+			Host string
+			Port int
+		}
+		Zones    []string
+		Replicas int
+	}
+	var got testConfig
+	FromTerraformOutputs(file.Name()).To(&got)
+	assert.Equal(t, "https://api.example.com", got.APIEndpoint)
+	assert.Equal(t, "db.internal", got.DB.Host)
+	assert.Equal(t, 5432, got.DB.Port)
+	assert.Equal(t, []string{"eu-west-1a", "eu-west-1b"}, got.Zones)
+	assert.Equal(t, 3, got.Replicas)
+}
+
+func TestTerraformOutputsSource_errors(t *testing.T) {
+	t.Parallel()
+	file, err := ioutil.TempFile("", "outputs.json")
+	require.NoError(t, err)
+	defer os.Remove(file.Name())
+	_, err = file.Write([]byte("not json"))
+	require.NoError(t, err)
+
+	_, err = TerraformOutputsSource(file.Name()).Load()
+	assert.Error(t, err)
+
+	err = recoverError(func() { FromTerraformOutputs("/does/not/exist") })
+	assert.Equal(t, CodeSourceLoad, CodeOf(err))
+}
@@ -0,0 +1,195 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+const (
+	timeOfDayLayout        = "15:04"
+	timeOfDayLayoutSeconds = "15:04:05"
+	dateLayout             = "2006-01-02"
+)
+
+// TimeOfDay is a wall clock time without a date, e.g. the start of a maintenance window.
+// It is parsed from "15:04" or "15:04:05" style values, in 24 hour format.
+type TimeOfDay struct {
+	Hour, Minute, Second int
+}
+
+// ParseTimeOfDay parses a "22:30" or "22:30:15" style value.
+func ParseTimeOfDay(s string) (TimeOfDay, error) {
+	layout := timeOfDayLayout
+	if len(s) > len(timeOfDayLayout) {
+		layout = timeOfDayLayoutSeconds
+	}
+	t, err := time.Parse(layout, s)
+	if err != nil {
+		return TimeOfDay{}, fmt.Errorf("config: invalid time of day %q, want HH:MM or HH:MM:SS", s)
+	}
+	return TimeOfDay{Hour: t.Hour(), Minute: t.Minute(), Second: t.Second()}, nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (t *TimeOfDay) UnmarshalText(text []byte) error {
+	parsed, err := ParseTimeOfDay(string(text))
+	if err != nil {
+		return err
+	}
+	*t = parsed
+	return nil
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (t TimeOfDay) MarshalText() ([]byte, error) {
+	return []byte(t.String()), nil
+}
+
+// String returns the time of day as HH:MM:SS.
+func (t TimeOfDay) String() string {
+	return fmt.Sprintf("%02d:%02d:%02d", t.Hour, t.Minute, t.Second)
+}
+
+// SinceMidnight returns the time of day as an offset from midnight.
+func (t TimeOfDay) SinceMidnight() time.Duration {
+	return time.Duration(t.Hour)*time.Hour + time.Duration(t.Minute)*time.Minute + time.Duration(t.Second)*time.Second
+}
+
+// On returns the time of day on the date of d, in d's location.
+func (t TimeOfDay) On(d time.Time) time.Time {
+	return time.Date(d.Year(), d.Month(), d.Day(), t.Hour, t.Minute, t.Second, 0, d.Location())
+}
+
+// Date is a calendar date without a time, e.g. a cutoff date.
+// It is parsed from "2006-01-02" style values.
+type Date struct {
+	Year  int
+	Month time.Month
+	Day   int
+}
+
+// ParseDate parses a "2025-01-31" style value.
+func ParseDate(s string) (Date, error) {
+	t, err := time.Parse(dateLayout, s)
+	if err != nil {
+		return Date{}, fmt.Errorf("config: invalid date %q, want YYYY-MM-DD", s)
+	}
+	return Date{Year: t.Year(), Month: t.Month(), Day: t.Day()}, nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (d *Date) UnmarshalText(text []byte) error {
+	parsed, err := ParseDate(string(text))
+	if err != nil {
+		return err
+	}
+	*d = parsed
+	return nil
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (d Date) MarshalText() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+// String returns the date as YYYY-MM-DD.
+func (d Date) String() string {
+	return fmt.Sprintf("%04d-%02d-%02d", d.Year, d.Month, d.Day)
+}
+
+// In returns midnight at the start of the date in loc.
+func (d Date) In(loc *time.Location) time.Time {
+	return time.Date(d.Year, d.Month, d.Day, 0, 0, 0, 0, loc)
+}
+
+// IsZero reports whether d is unset.
+func (d Date) IsZero() bool {
+	return d == Date{}
+}
+
+// Deadline is a point in time configured either relative to startup, as a duration such as "30s" or "2h",
+// or absolutely, as an RFC 3339 timestamp such as "2025-01-31T18:00:00Z", for jobs given either kind of cutoff.
+// Relative deadlines are resolved when they are parsed, i.e. when bound, on the Builder's Clock, see WithClock.
+// With SystemClock, they keep the monotonic clock reading of time.Now, so Remaining and Expired are unaffected
+// by wall clock changes.
+type Deadline struct {
+	at       time.Time
+	relative time.Duration
+	isRel    bool
+	clock    Clock
+}
+
+// ParseDeadline parses a duration, counting from now, or an RFC 3339 timestamp.
+func ParseDeadline(s string) (Deadline, error) {
+	return ParseDeadlineWithClock(s, SystemClock)
+}
+
+// ParseDeadlineWithClock is ParseDeadline, telling the time, when parsing and later, with clock.
+func ParseDeadlineWithClock(s string, clock Clock) (Deadline, error) {
+	if d, err := time.ParseDuration(s); err == nil {
+		if d < 0 {
+			return Deadline{}, fmt.Errorf("config: invalid deadline %q, durations must not be negative", s)
+		}
+		return Deadline{at: clockOrSystem(clock).Now().Add(d), relative: d, isRel: true, clock: clock}, nil
+	}
+	t, err := time.Parse(time.RFC3339Nano, s)
+	if err != nil {
+		return Deadline{}, fmt.Errorf("config: invalid deadline %q, want a duration such as 30s or an RFC 3339 timestamp", s)
+	}
+	return Deadline{at: t, clock: clock}, nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler. The deadline is parsed on the Clock d was bound with,
+// if any, or SystemClock.
+func (d *Deadline) UnmarshalText(text []byte) error {
+	parsed, err := ParseDeadlineWithClock(string(text), d.clock)
+	if err != nil {
+		return err
+	}
+	*d = parsed
+	return nil
+}
+
+// MarshalText implements encoding.TextMarshaler, writing the deadline as an RFC 3339 timestamp.
+func (d Deadline) MarshalText() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+// String returns the deadline as an RFC 3339 timestamp, or an empty string if it is unset.
+func (d Deadline) String() string {
+	if d.IsZero() {
+		return ""
+	}
+	return d.at.Format(time.RFC3339Nano)
+}
+
+// Time returns the deadline.
+func (d Deadline) Time() time.Time {
+	return d.at
+}
+
+// Relative returns the duration the deadline was configured with, if it was configured as one.
+func (d Deadline) Relative() (time.Duration, bool) {
+	return d.relative, d.isRel
+}
+
+// Remaining returns the time left until the deadline, which is negative once it has passed.
+func (d Deadline) Remaining() time.Duration {
+	return d.at.Sub(clockOrSystem(d.clock).Now())
+}
+
+// Expired reports whether the deadline has passed.
+func (d Deadline) Expired() bool {
+	return !clockOrSystem(d.clock).Now().Before(d.at)
+}
+
+// Context returns a copy of parent which is cancelled at the deadline, as context.WithDeadline.
+func (d Deadline) Context(parent context.Context) (context.Context, context.CancelFunc) {
+	return context.WithDeadline(parent, d.at)
+}
+
+// IsZero reports whether d is unset.
+func (d Deadline) IsZero() bool {
+	return d.at.IsZero()
+}
@@ -0,0 +1,171 @@
+package config
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTimeOfDay(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		in      string
+		want    TimeOfDay
+		wantErr bool
+	}{
+		{in: "22:30", want: TimeOfDay{Hour: 22, Minute: 30}},
+		{in: "07:05:09", want: TimeOfDay{Hour: 7, Minute: 5, Second: 9}},
+		{in: "00:00", want: TimeOfDay{}},
+		{in: "24:00", wantErr: true},
+		{in: "12:60", wantErr: true},
+		{in: "10pm", wantErr: true},
+		{in: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.in, func(t *testing.T) {
+			t.Parallel()
+			got, err := ParseTimeOfDay(tt.in)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestTimeOfDay(t *testing.T) {
+	t.Parallel()
+	tod := TimeOfDay{Hour: 22, Minute: 30}
+	assert.Equal(t, "22:30:00", tod.String())
+	assert.Equal(t, 22*time.Hour+30*time.Minute, tod.SinceMidnight())
+	day := time.Date(2025, time.January, 31, 8, 0, 0, 0, time.UTC)
+	assert.Equal(t, time.Date(2025, time.January, 31, 22, 30, 0, 0, time.UTC), tod.On(day))
+}
+
+func TestParseDate(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		in      string
+		want    Date
+		wantErr bool
+	}{
+		{in: "2025-01-31", want: Date{Year: 2025, Month: time.January, Day: 31}},
+		{in: "2024-02-29", want: Date{Year: 2024, Month: time.February, Day: 29}},
+		{in: "2025-02-29", wantErr: true},
+		{in: "31/01/2025", wantErr: true},
+		{in: "2025-01-31T00:00:00Z", wantErr: true},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.in, func(t *testing.T) {
+			t.Parallel()
+			got, err := ParseDate(tt.in)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.in, got.String())
+		})
+	}
+}
+
+func Test_timeTypesBinding(t *testing.T) {
+	type testConfig struct {
+		Window  TimeOfDay
+		Cutoff  Date
+		Invalid Date
+		Holiday []Date
+	}
+
+	os.Clearenv()
+	defer os.Clearenv()
+	require.NoError(t, os.Setenv("WINDOW", "22:30"))
+	require.NoError(t, os.Setenv("CUTOFF", "2025-01-31"))
+	require.NoError(t, os.Setenv("INVALID", "2025-13-01"))
+	require.NoError(t, os.Setenv("HOLIDAY", "2025-12-25 2025-12-26"))
+
+	var got testConfig
+	FromEnv().To(&got)
+
+	assert.Equal(t, testConfig{
+		Window: TimeOfDay{Hour: 22, Minute: 30},
+		Cutoff: Date{Year: 2025, Month: time.January, Day: 31},
+		Holiday: []Date{
+			{Year: 2025, Month: time.December, Day: 25},
+			{Year: 2025, Month: time.December, Day: 26},
+		},
+	}, got)
+}
+
+func TestParseDeadline(t *testing.T) {
+	t.Parallel()
+	before := time.Now()
+	d, err := ParseDeadline("30s")
+	require.NoError(t, err)
+	assert.False(t, d.Time().Before(before.Add(30*time.Second)))
+	assert.False(t, d.Time().After(time.Now().Add(30*time.Second)))
+	rel, ok := d.Relative()
+	assert.True(t, ok)
+	assert.Equal(t, 30*time.Second, rel)
+	assert.True(t, d.Remaining() > 29*time.Second)
+	assert.False(t, d.Expired())
+
+	d, err = ParseDeadline("2025-01-31T18:00:00+01:00")
+	require.NoError(t, err)
+	assert.True(t, d.Time().Equal(time.Date(2025, time.January, 31, 17, 0, 0, 0, time.UTC)))
+	_, ok = d.Relative()
+	assert.False(t, ok)
+	assert.True(t, d.Expired())
+	assert.True(t, d.Remaining() < 0)
+	assert.Equal(t, "2025-01-31T18:00:00+01:00", d.String())
+
+	ctx, cancel := d.Context(context.Background())
+	defer cancel()
+	deadline, _ := ctx.Deadline()
+	assert.True(t, deadline.Equal(d.Time()))
+	assert.Error(t, ctx.Err())
+
+	for _, in := range []string{"", "-5s", "tomorrow", "2025-01-31"} {
+		_, err := ParseDeadline(in)
+		assert.Error(t, err, in)
+	}
+	assert.True(t, Deadline{}.IsZero())
+	assert.Equal(t, "", Deadline{}.String())
+}
+
+func Test_deadlineBinding(t *testing.T) {
+	type testConfig struct {
+		Timeout Deadline
+		Cutoff  Deadline
+	}
+
+	os.Clearenv()
+	defer os.Clearenv()
+	require.NoError(t, os.Setenv("TIMEOUT", "1h"))
+	require.NoError(t, os.Setenv("CUTOFF", "2030-06-01T00:00:00Z"))
+
+	var got testConfig
+	FromEnv().To(&got)
+	rel, ok := got.Timeout.Relative()
+	assert.True(t, ok)
+	assert.Equal(t, time.Hour, rel)
+	assert.True(t, got.Timeout.Remaining() > 59*time.Minute)
+	assert.Equal(t, "2030-06-01T00:00:00Z", got.Cutoff.String())
+
+	clock := NewManualClock(time.Date(2030, 5, 31, 23, 0, 0, 0, time.UTC))
+	WithClock(clock).FromEnv().To(&got)
+	assert.Equal(t, "2030-06-01T00:00:00Z", got.Timeout.String(), "relative deadlines count from the Builder's Clock")
+	assert.Equal(t, time.Hour, got.Cutoff.Remaining())
+	clock.Advance(time.Hour)
+	assert.True(t, got.Timeout.Expired())
+	assert.True(t, got.Cutoff.Expired())
+}
@@ -0,0 +1,64 @@
+// Package urfave registers config struct fields as github.com/urfave/cli/v2 flags,
+// so that command line flags and environment configuration share one struct definition.
+//
+// It lives in its own module, so that applications which do not use urfave/cli do not depend on it.
+package urfave
+
+import (
+	"github.com/imduffy15/config"
+	"github.com/urfave/cli/v2"
+)
+
+// Flags returns a cli.Flag for each field target is bound from, for an App's or Command's Flags.
+// See config.Flags for how flags are named. The flags only record their values; merge them into a Builder
+// with Bind or FlagSource in the action, typically last, so that flags override the environment and files:
+//
+//	var c Config
+//	app := &cli.App{
+//		Flags: configurfave.Flags(&c),
+//		Action: func(ctx *cli.Context) error {
+//			if err := configurfave.Bind(config.FromEnv(), ctx, &c); err != nil {
+//				return err
+//			}
+//			return run(c)
+//		},
+//	}
+func Flags(target interface{}) []cli.Flag {
+	var flags []cli.Flag
+	for _, f := range config.Flags(target) {
+		if f.Bool {
+			flags = append(flags, &cli.BoolFlag{Name: f.Name, Usage: f.Usage, DefaultText: f.Default})
+			continue
+		}
+		flags = append(flags, &cli.StringFlag{Name: f.Name, Usage: f.Usage, DefaultText: f.Default})
+	}
+	return flags
+}
+
+// FlagSource returns a config.Source of the flags for target's fields which were set in ctx.
+// Flags left unset are omitted, so they do not override values from other sources.
+func FlagSource(ctx *cli.Context, target interface{}) config.Source {
+	return config.SourceFunc(func() (map[string]string, error) {
+		values := make(map[string]string)
+		for _, f := range config.Flags(target) {
+			if !ctx.IsSet(f.Name) {
+				continue
+			}
+			if f.Bool {
+				values[f.Key] = "false"
+				if ctx.Bool(f.Name) {
+					values[f.Key] = "true"
+				}
+				continue
+			}
+			values[f.Key] = ctx.String(f.Name)
+		}
+		return values, nil
+	})
+}
+
+// Bind merges the flags for target's fields which were set in ctx into b, over the values from its other sources,
+// and binds target. See config.Builder.Bind for the errors returned.
+func Bind(b *config.Builder, ctx *cli.Context, target interface{}) error {
+	return b.FromSource(FlagSource(ctx, target)).Bind(target)
+}
@@ -0,0 +1,52 @@
+package urfave
+
+import (
+	"bytes"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/imduffy15/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v2"
+)
+
+func TestFlags(t *testing.T) {
+	type testConfig struct {
+		Port     int `help:"port to listen on"`
+		Timeout  time.Duration
+		Debug    bool
+		Database struct {
+			MaxConns int `config:"max_conns"`
+		}
+	}
+	os.Clearenv()
+	defer os.Clearenv()
+	require.NoError(t, os.Setenv("PORT", "80"))
+	require.NoError(t, os.Setenv("TIMEOUT", "5s"))
+
+	c := testConfig{Port: 8080}
+	var buf bytes.Buffer
+	app := &cli.App{
+		Name:   "server",
+		Writer: &buf,
+		Flags:  Flags(&c),
+		Action: func(ctx *cli.Context) error {
+			return Bind(config.FromEnv(), ctx, &c)
+		},
+	}
+	require.NoError(t, app.Run([]string{"server", "--port=9090", "--debug", "--database.max-conns", "3"}))
+
+	assert.Equal(t, 9090, c.Port, "flags override the environment")
+	assert.Equal(t, 5*time.Second, c.Timeout, "unset flags do not override the environment")
+	assert.True(t, c.Debug)
+	assert.Equal(t, 3, c.Database.MaxConns)
+
+	c = testConfig{Port: 8080}
+	require.NoError(t, app.Run([]string{"server", "--help"}))
+	assert.Contains(t, buf.String(), "port to listen on (default: 8080)")
+
+	err := app.Run([]string{"server", "--port=eighty"})
+	assert.Equal(t, config.CodeBadType, config.CodeOf(err))
+}
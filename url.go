@@ -0,0 +1,147 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+)
+
+// URLOption configures URLSource.
+type URLOption func(*urlOptions)
+
+type urlOptions struct {
+	client       *http.Client
+	header       http.Header
+	format       *Format
+	transformers []PayloadTransformer
+}
+
+// URLHeader adds a header to the request, e.g. URLHeader("X-Api-Key", key).
+func URLHeader(key, value string) URLOption {
+	return func(o *urlOptions) {
+		o.header.Add(key, value)
+	}
+}
+
+// URLBearerToken authorizes the request with an OAuth 2.0 bearer token.
+func URLBearerToken(token string) URLOption {
+	return URLHeader("Authorization", "Bearer "+token)
+}
+
+// URLBasicAuth authorizes the request with HTTP basic authentication.
+func URLBasicAuth(username, password string) URLOption {
+	return func(o *urlOptions) {
+		r := &http.Request{Header: o.header}
+		r.SetBasicAuth(username, password)
+	}
+}
+
+// URLClient sends the request with client, e.g. one with a timeout or TLS client certificates,
+// rather than http.DefaultClient.
+func URLClient(client *http.Client) URLOption {
+	return func(o *urlOptions) {
+		o.client = client
+	}
+}
+
+// URLFormat reads the response in format, whatever its Content-Type.
+func URLFormat(format Format) URLOption {
+	return func(o *urlOptions) {
+		o.format = &format
+	}
+}
+
+// URLTransform transforms the response with transformers, in order, before it is read,
+// e.g. URLTransform(GzipPayload()) for config stored compressed. See PayloadTransformer.
+func URLTransform(transformers ...PayloadTransformer) URLOption {
+	return func(o *urlOptions) {
+		o.transformers = append(o.transformers, transformers...)
+	}
+}
+
+// URLSource returns a Source of the config served at rawURL, fetched with a GET request using ctx.
+// The response is read in the format given by its Content-Type, or without one, by the extension of the URL's path,
+// see DetectFormat, after any transformers given with URLTransform. Any response other than 2xx is an error.
+func URLSource(ctx context.Context, rawURL string, opts ...URLOption) Source {
+	o := urlOptions{header: make(http.Header)}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	name := "url " + rawURL
+	if u, err := url.Parse(rawURL); err == nil {
+		name = "url " + u.Redacted()
+	}
+	return namedSource{name: name, Source: SourceFunc(func() (map[string]string, error) {
+		p, err := fetchURL(ctx, rawURL, o)
+		if err != nil {
+			return nil, err
+		}
+		p.Sibling = func(ctx context.Context, suffix string) ([]byte, error) {
+			u, err := url.Parse(rawURL)
+			if err != nil {
+				return nil, err
+			}
+			u.Path += suffix
+			sibling, err := fetchURL(ctx, u.String(), o)
+			return sibling.Data, err
+		}
+		if p, err = TransformPayload(ctx, p, o.transformers...); err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		format := DetectFormat(p.ContentType, p.Name)
+		if o.format != nil {
+			format = *o.format
+		}
+		values, err := ReaderSource(bytes.NewReader(p.Data), format).Load()
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		return values, nil
+	})}
+}
+
+func fetchURL(ctx context.Context, rawURL string, o urlOptions) (Payload, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return Payload{}, err
+	}
+	for k, vs := range o.header {
+		req.Header[k] = vs
+	}
+	client := o.client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return Payload{}, err
+	}
+	defer resp.Body.Close()
+	b, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return Payload{}, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return Payload{}, fmt.Errorf("%s: %s", req.URL.Redacted(), resp.Status)
+	}
+	return Payload{Name: req.URL.Path, ContentType: resp.Header.Get("Content-Type"), Data: b}, nil
+}
+
+// FromURL returns a new Builder, populated with the config served at rawURL. See URLSource.
+// It panics with an *Error if the config cannot be fetched or parsed.
+func FromURL(ctx context.Context, rawURL string, opts ...URLOption) *Builder {
+	return newBuilder().FromURL(ctx, rawURL, opts...)
+}
+
+// FromURL merges the config served at rawURL into the current config state, returning the Builder,
+// so that config served by an internal endpoint need not be downloaded to a file first:
+//
+//	config.FromURL(ctx, "https://config.internal/billing.env", config.URLBearerToken(token)).FromEnv().To(&c)
+//
+// See URLSource. It panics with an *Error if the config cannot be fetched or parsed.
+func (c *Builder) FromURL(ctx context.Context, rawURL string, opts ...URLOption) *Builder {
+	return c.FromSource(URLSource(ctx, rawURL, opts...))
+}
@@ -0,0 +1,82 @@
+package config
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFromURL(t *testing.T) {
+	os.Clearenv()
+	defer os.Clearenv()
+	require.NoError(t, os.Setenv("PORT", "9090"))
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		switch r.URL.Path {
+		case "/app.env":
+			w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+			_, _ = w.Write([]byte("PORT=8080\nDATABASE__HOST=db\n"))
+		case "/app":
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"database": {"host": "json"}}`))
+		case "/app.yaml":
+			w.Header().Set("Content-Type", "application/octet-stream")
+			_, _ = w.Write([]byte("database:\n  host: yaml\n"))
+		}
+	}))
+	defer srv.Close()
+
+	type testConfig struct {
+		Port     int
+		Database struct {
+			Host string
+		}
+	}
+	ctx := context.Background()
+	var c testConfig
+	FromURL(ctx, srv.URL+"/app.env", URLBearerToken("token")).FromEnv().To(&c)
+	assert.Equal(t, 9090, c.Port, "later sources override the URL")
+	assert.Equal(t, "db", c.Database.Host)
+
+	c = testConfig{}
+	FromURL(ctx, srv.URL+"/app", URLHeader("Authorization", "Bearer token")).To(&c)
+	assert.Equal(t, "json", c.Database.Host, "the format is chosen by content type")
+
+	c = testConfig{}
+	b := FromURL(ctx, srv.URL+"/app.yaml", URLBearerToken("token"), URLClient(srv.Client()))
+	b.To(&c)
+	assert.Equal(t, "yaml", c.Database.Host, "the format is chosen by extension for generic content types")
+
+	err := recoverError(func() { FromURL(ctx, srv.URL+"/app.env") })
+	assert.Equal(t, CodeSourceLoad, CodeOf(err))
+	assert.Contains(t, err.Error(), "401 Unauthorized")
+}
+
+func TestDetectFormat(t *testing.T) {
+	t.Parallel()
+	assert.Equal(t, FormatJSON, DetectFormat("application/json; charset=utf-8", "/app"))
+	assert.Equal(t, FormatYAML, DetectFormat("application/x-yaml", "/app"))
+	assert.Equal(t, FormatDotenv, DetectFormat("text/plain", "/app.json"))
+	assert.Equal(t, FormatJSON, DetectFormat("", "/app.JSON"))
+	assert.Equal(t, FormatDotenv, DetectFormat("", "/app"))
+}
+
+func TestURLBasicAuth(t *testing.T) {
+	t.Parallel()
+	o := urlOptions{header: make(http.Header)}
+	URLBasicAuth("user", "pass")(&o)
+	r := &http.Request{Header: o.header}
+	username, password, ok := r.BasicAuth()
+	assert.True(t, ok)
+	assert.Equal(t, "user", username)
+	assert.Equal(t, "pass", password)
+}
@@ -0,0 +1,160 @@
+// Package vault provides a config.ValuePreProcessor resolving values from HashiCorp Vault,
+// and a config.Source of whole secrets.
+//
+// It lives in its own module, so that applications which do not use Vault do not depend on its client.
+package vault
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/hashicorp/vault/api"
+	"github.com/imduffy15/config"
+)
+
+// Scheme prefixes values resolved from Vault, e.g. vault://secret/data/app#password.
+const Scheme = "vault"
+
+// Logical is implemented by Vault's *api.Logical, as returned by api.Client's Logical method.
+type Logical interface {
+	ReadWithContext(ctx context.Context, path string) (*api.Secret, error)
+}
+
+// VaultValuePreProcessor is a ValuePreProcessor for Vault.
+// Supports the KV secrets engine, versions 1 and 2, and any other engine whose secrets are read as key/value data.
+type VaultValuePreProcessor struct {
+	logical Logical
+	ctx     context.Context
+}
+
+// NewVaultValuePreProcessor creates a new VaultValuePreProcessor with the given context and Vault client.
+func NewVaultValuePreProcessor(ctx context.Context, client *api.Client) *VaultValuePreProcessor {
+	return &VaultValuePreProcessor{logical: client.Logical(), ctx: ctx}
+}
+
+// NewVaultValuePreProcessorFromEnv creates a new VaultValuePreProcessor with the given context,
+// with a client configured from the environment, e.g. VAULT_ADDR and VAULT_TOKEN, as the vault command is.
+func NewVaultValuePreProcessorFromEnv(ctx context.Context) (*VaultValuePreProcessor, error) {
+	client, err := api.NewClient(api.DefaultConfig())
+	if err != nil {
+		return nil, fmt.Errorf("config/vault: error creating vault client: %w", err)
+	}
+	return NewVaultValuePreProcessor(ctx, client), nil
+}
+
+// PreProcessValue pre-processes a config key/value pair.
+// It panics with a *config.Error with config.CodeSecretFetch if a value cannot be loaded.
+func (p *VaultValuePreProcessor) PreProcessValue(key, value string) string {
+	return p.PreProcessValueContext(p.ctx, key, value)
+}
+
+// PreProcessValueContext pre-processes a config key/value pair, using ctx rather than the context given at construction.
+func (p *VaultValuePreProcessor) PreProcessValueContext(ctx context.Context, key, value string) string {
+	return p.Register(config.NewResolvers(ctx)).PreProcessValueContext(ctx, key, value)
+}
+
+// Register registers the Vault resolver with r, returning r.
+// This allows Vault references to be resolved alongside those of other providers.
+func (p *VaultValuePreProcessor) Register(r *config.Resolvers) *config.Resolvers {
+	return r.Register(Scheme, p.Resolver())
+}
+
+// Resolver returns a config.Resolver for Vault references, which are the API path of a secret,
+// followed by '#' and the key of the value within it, e.g. vault://secret/data/app#password for the password
+// of the app secret in the KV version 2 engine mounted at secret. Without a key, the secret's data is returned as JSON.
+// Values which are not strings are returned as JSON, e.g. 3 or true.
+func (p *VaultValuePreProcessor) Resolver() config.Resolver {
+	return config.ResolverFunc(func(ctx context.Context, ref string) (string, error) {
+		path, key := ref, ""
+		if i := strings.LastIndex(ref, "#"); i >= 0 {
+			path, key = ref[:i], ref[i+1:]
+		}
+		data, err := p.read(ctx, path)
+		if err != nil {
+			return "", err
+		}
+		if key == "" {
+			b, err := json.Marshal(data)
+			return string(b), err
+		}
+		v, ok := data[key]
+		if !ok {
+			return "", fmt.Errorf("config/vault: secret %s has no key %s", path, key)
+		}
+		return stringValue(v)
+	})
+}
+
+// Source returns a config.Source of every key of the secret at path, e.g. secret/data/app,
+// so that a secret holding an application's settings can be layered like a file:
+//
+//	config.From("app.config").FromSource(p.Source("secret/data/app")).FromEnv().To(&c)
+//
+// Keys are bound as written in the secret, e.g. DATABASE__HOST, and values which are not strings are loaded as JSON.
+func (p *VaultValuePreProcessor) Source(path string) config.Source {
+	return config.SourceFunc(func() (map[string]string, error) {
+		data, err := p.read(p.ctx, path)
+		if err != nil {
+			return nil, err
+		}
+		values := make(map[string]string, len(data))
+		for k, v := range data {
+			s, err := stringValue(v)
+			if err != nil {
+				return nil, err
+			}
+			values[k] = s
+		}
+		return values, nil
+	})
+}
+
+// RegisterSourceScheme registers Scheme with config.RegisterSourceScheme, so that every key of a secret is loaded
+// by config.From, and by vault sources of source manifests, from locations such as vault://secret/data/app:
+//
+//	p.RegisterSourceScheme()
+//	config.From("vault://secret/data/app").FromEnv().To(&c)
+//
+// See Source. It panics if the scheme is already registered, so should be called once, e.g. from main.
+func (p *VaultValuePreProcessor) RegisterSourceScheme() {
+	config.RegisterSourceScheme(Scheme, func(u *url.URL) (config.Source, error) {
+		path := strings.TrimPrefix(u.Host+u.Path, "/")
+		if path == "" {
+			return nil, errors.New("config/vault: no secret path given, e.g. vault://secret/data/app")
+		}
+		return p.Source(path), nil
+	})
+}
+
+// read returns the data of the secret at path, unwrapped from the envelope of the KV version 2 engine.
+func (p *VaultValuePreProcessor) read(ctx context.Context, path string) (map[string]interface{}, error) {
+	secret, err := p.logical.ReadWithContext(ctx, path)
+	if err != nil {
+		return nil, fmt.Errorf("config/vault: error reading secret %s: %w", path, err)
+	}
+	if secret == nil || secret.Data == nil {
+		return nil, fmt.Errorf("config/vault: secret %s not found", path)
+	}
+	data := secret.Data
+	if inner, ok := data["data"].(map[string]interface{}); ok {
+		if _, ok := data["metadata"]; ok {
+			data = inner
+		}
+	}
+	return data, nil
+}
+
+func stringValue(v interface{}) (string, error) {
+	if s, ok := v.(string); ok {
+		return s, nil
+	}
+	b, err := json.Marshal(v)
+	return string(b), err
+}
+
+// compile time assertion
+var _ config.ContextValuePreProcessor = (*VaultValuePreProcessor)(nil)
@@ -0,0 +1,95 @@
+package vault
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hashicorp/vault/api"
+	"github.com/imduffy15/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// mockLogical is a Logical over a fixed set of secrets, by path.
+type mockLogical map[string]*api.Secret
+
+func (m mockLogical) ReadWithContext(ctx context.Context, path string) (*api.Secret, error) {
+	if path == "secret/data/broken" {
+		return nil, errors.New("permission denied")
+	}
+	return m[path], nil
+}
+
+func newTestPreProcessor() *VaultValuePreProcessor {
+	return &VaultValuePreProcessor{ctx: context.Background(), logical: mockLogical{
+		"secret/data/app": {Data: map[string]interface{}{
+			"data":     map[string]interface{}{"password": "s3cr3t", "DATABASE__HOST": "db", "PORT": 5432},
+			"metadata": map[string]interface{}{"version": 3},
+		}},
+		"kv/app": {Data: map[string]interface{}{"password": "v1"}},
+	}}
+}
+
+func TestVaultValuePreProcessor_PreProcessValue(t *testing.T) {
+	p := newTestPreProcessor()
+	assert.Equal(t, "s3cr3t", p.PreProcessValue("PASSWORD", "vault://secret/data/app#password"))
+	assert.Equal(t, "5432", p.PreProcessValue("PORT", "vault://secret/data/app#PORT"))
+	assert.Equal(t, "v1", p.PreProcessValue("PASSWORD", "vault://kv/app#password"), "KV version 1 secrets are not unwrapped")
+	assert.Equal(t, `{"password":"v1"}`, p.PreProcessValue("SECRET", "vault://kv/app"))
+	assert.Equal(t, "plain", p.PreProcessValue("HOST", "plain"))
+
+	for _, ref := range []string{"vault://secret/data/app#missing", "vault://secret/data/missing#key", "vault://secret/data/broken#key"} {
+		assert.Panics(t, func() { p.PreProcessValue("KEY", ref) }, ref)
+	}
+	defer func() {
+		assert.Equal(t, config.CodeSecretFetch, config.CodeOf(recover()))
+	}()
+	p.PreProcessValue("KEY", "vault://secret/data/broken#key")
+}
+
+func TestVaultValuePreProcessor_RegisterSourceScheme(t *testing.T) {
+	newTestPreProcessor().RegisterSourceScheme()
+
+	manifest := filepath.Join(t.TempDir(), "config.sources.yaml")
+	require.NoError(t, os.WriteFile(manifest, []byte("sources:\n  - vault: secret/data/app\n"), 0644))
+
+	var got struct {
+		Password string
+		Database struct{ Host string }
+	}
+	config.FromManifest(manifest).To(&got)
+	assert.Equal(t, "s3cr3t", got.Password)
+	assert.Equal(t, "db", got.Database.Host)
+
+	err := func() (err error) {
+		defer func() { err, _ = recover().(error) }()
+		config.From("vault://")
+		return nil
+	}()
+	assert.Equal(t, config.CodeSourceLoad, config.CodeOf(err))
+	assert.Contains(t, err.Error(), "config/vault: no secret path given")
+}
+
+func TestVaultValuePreProcessor_Source(t *testing.T) {
+	type testConfig struct {
+		Port     int
+		Database struct {
+			Host string
+		}
+	}
+	os.Clearenv()
+	defer os.Clearenv()
+	require.NoError(t, os.Setenv("PORT", "8080"))
+
+	p := newTestPreProcessor()
+	var c testConfig
+	config.FromSource(p.Source("secret/data/app")).FromEnv().To(&c)
+	assert.Equal(t, 8080, c.Port, "later sources override the secret")
+	assert.Equal(t, "db", c.Database.Host)
+
+	_, err := p.Source("secret/data/missing").Load()
+	assert.EqualError(t, err, "config/vault: secret secret/data/missing not found")
+}
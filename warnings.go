@@ -0,0 +1,165 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// WarningKind classifies a Warning.
+type WarningKind int
+
+const (
+	// WarningConversion means a value could not be converted to its field's type, leaving the field as its zero value.
+	// It is only raised by To, and only without a handler set by WithConversionErrorHandler, as otherwise
+	// the error is handled or reported.
+	WarningConversion WarningKind = iota
+	// WarningDeprecatedKey means a key declared with WithDeprecatedKey is set.
+	WarningDeprecatedKey
+	// WarningEmptyValue means a key bound to a field was set to an empty value, which was dropped,
+	// so the field keeps the value of an earlier source, if any.
+	WarningEmptyValue
+	// WarningUnknownKey means a key is not bound by any field and is probably a typo, as it is either
+	// similar to the key of a field without a value, e.g. DATABSE__HOST for DATABASE__HOST,
+	// or under a nested struct's prefix, e.g. DATABASE__TLS__CERTS for a Database.TLS struct.
+	// It is also raised, for the prefix of a field tagged `config:",remain"`, when the field receives keys.
+	WarningUnknownKey
+)
+
+var warningKindNames = map[WarningKind]string{
+	WarningConversion:    "conversion",
+	WarningDeprecatedKey: "deprecated key",
+	WarningEmptyValue:    "empty value",
+	WarningUnknownKey:    "unknown key",
+}
+
+func (k WarningKind) String() string {
+	if name, ok := warningKindNames[k]; ok {
+		return name
+	}
+	return fmt.Sprintf("WarningKind(%d)", int(k))
+}
+
+// Warning is a non fatal finding while binding, which applications may want to log even though binding succeeded.
+type Warning struct {
+	Kind WarningKind
+	// Key is the normalized key the warning relates to.
+	Key     string
+	Message string
+}
+
+// String returns the warning's key and message, e.g. "database__hots: not bound by any field under database__".
+func (w Warning) String() string {
+	return w.Key + ": " + w.Message
+}
+
+// Warnings returns the warnings raised by calls to To so far, in the order they were raised.
+// Each warning is raised once, however many times To is called:
+//
+//	b.To(&c)
+//	for _, w := range b.Warnings() {
+//		log.Printf("config: %s", w)
+//	}
+func (c *Builder) Warnings() []Warning {
+	return append([]Warning(nil), c.warnings...)
+}
+
+// WithDeprecatedKey creates a new builder with a deprecated key.
+func WithDeprecatedKey(key, replacement string) *Builder {
+	return newBuilder().WithDeprecatedKey(key, replacement)
+}
+
+// WithDeprecatedKey makes To raise a WarningDeprecatedKey if key is set, suggesting replacement instead,
+// so that keys can be renamed without breaking deployments which still set the old one.
+// The key is not bound to the replacement's field; it is only reported.
+func (c *Builder) WithDeprecatedKey(key, replacement string) *Builder {
+	if c.deprecated == nil {
+		c.deprecated = make(map[string]string)
+	}
+	c.deprecated[NormalizeKey(key)] = NormalizeKey(replacement)
+	return c
+}
+
+func (c *Builder) warn(kind WarningKind, key, message string) {
+	w := Warning{Kind: kind, Key: key, Message: message}
+	c.found = append(c.found, w)
+	if c.warned[w] {
+		return
+	}
+	if c.warned == nil {
+		c.warned = make(map[Warning]bool)
+	}
+	c.warned[w] = true
+	c.warnings = append(c.warnings, w)
+}
+
+// checkWarnings raises the warnings found once target is bound.
+func (c *Builder) checkWarnings(target interface{}) {
+	deprecated := make([]string, 0, len(c.deprecated))
+	for key := range c.deprecated {
+		deprecated = append(deprecated, key)
+	}
+	sort.Strings(deprecated)
+	for _, key := range deprecated {
+		if _, ok := c.lookup(key); ok {
+			c.warn(WarningDeprecatedKey, key, fmt.Sprintf("deprecated, use %s instead", c.deprecated[key]))
+		}
+	}
+
+	empty := make([]string, 0, len(c.empty))
+	for key := range c.empty {
+		empty = append(empty, key)
+	}
+	sort.Strings(empty)
+	for _, key := range empty {
+		if !c.bound[key] {
+			continue
+		}
+		if _, ok := c.lookup(key); ok {
+			c.warn(WarningEmptyValue, key, "empty value dropped, keeping the value from an earlier source")
+		} else {
+			c.warn(WarningEmptyValue, key, "empty value dropped, leaving the field unset")
+		}
+	}
+
+	fs := fields(structType(target))
+	typos := make(map[string]string)
+	for fieldKey, key := range c.similarKeys(fs) {
+		typos[key] = fieldKey
+	}
+	for _, f := range fs {
+		if f.remain && c.hasUnbound(f.key, siblingKeys(fs, f)) {
+			c.warn(WarningUnknownKey, f.key, fmt.Sprintf("received by %s, as no other field binds them, check them for typos",
+				strings.Join(f.path, ".")))
+		}
+	}
+	prefixes := nestedPrefixes(fs)
+	for _, key := range c.UnboundKeys("") {
+		if fieldKey, ok := typos[key]; ok {
+			c.warn(WarningUnknownKey, key, "not bound by any field, "+didYouMean(fieldKey))
+			continue
+		}
+		for _, prefix := range prefixes {
+			if strings.HasPrefix(key, prefix) {
+				c.warn(WarningUnknownKey, key, fmt.Sprintf("not bound by any field under %s, check it for typos", prefix))
+				break
+			}
+		}
+	}
+}
+
+// nestedPrefixes returns the prefixes of the nested structs fs belong to, longest first.
+func nestedPrefixes(fs []field) []string {
+	seen := make(map[string]bool)
+	var prefixes []string
+	for _, f := range fs {
+		for _, p := range f.prefixes[1:] {
+			if !seen[p] {
+				seen[p] = true
+				prefixes = append(prefixes, p)
+			}
+		}
+	}
+	sort.Slice(prefixes, func(i, j int) bool { return len(prefixes[i]) > len(prefixes[j]) })
+	return prefixes
+}
@@ -0,0 +1,75 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuilder_Warnings(t *testing.T) {
+	t.Parallel()
+	type testConfig struct {
+		Port     int
+		Host     string
+		User     string
+		Database struct {
+			Host string
+			TLS  struct{ Cert string }
+		}
+	}
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{"app.config": "HOST=\nUSER=\n"})
+
+	var got testConfig
+	b := WithDeprecatedKey("DB_HOST", "DATABASE__HOST").
+		FromSource(MapSource(map[string]string{
+			"PORT":                 "eighty",
+			"USER":                 "admin",
+			"DB_HOST":              "db",
+			"DATABASE__HOTS":       "db",
+			"DATABASE__TLS__CERTS": "x",
+			"DATABASE__TLS__CA":    "x",
+			"UNRELATED":            "y",
+		})).
+		From(filepath.Join(dir, "app.config"))
+	b.To(&got)
+	b.To(&got)
+
+	assert.Equal(t, []Warning{
+		{Kind: WarningConversion, Key: "port", Message: `cannot convert "eighty" to int: strconv.ParseInt: parsing "eighty": invalid syntax`},
+		{Kind: WarningDeprecatedKey, Key: "db_host", Message: "deprecated, use database__host instead"},
+		{Kind: WarningEmptyValue, Key: "host", Message: "empty value dropped, leaving the field unset"},
+		{Kind: WarningEmptyValue, Key: "user", Message: "empty value dropped, keeping the value from an earlier source"},
+		{Kind: WarningUnknownKey, Key: "database__hots", Message: "not bound by any field, did you mean DATABASE__HOST?"},
+		{Kind: WarningUnknownKey, Key: "database__tls__ca", Message: "not bound by any field under database__tls__, check it for typos"},
+		{Kind: WarningUnknownKey, Key: "database__tls__certs", Message: "not bound by any field, did you mean DATABASE__TLS__CERT?"},
+	}, b.Warnings(), "each warning is raised once")
+	assert.Equal(t, "db_host: deprecated, use database__host instead", b.Warnings()[1].String())
+	assert.Equal(t, "unknown key", WarningUnknownKey.String())
+}
+
+func TestBuilder_Warnings_reported(t *testing.T) {
+	t.Parallel()
+	var got struct{ Port int }
+	b := FromSource(MapSource(map[string]string{"PORT": "eighty"}))
+	assert.Error(t, b.Bind(&got))
+	assert.Empty(t, b.Warnings(), "conversion errors returned by Bind are not warnings")
+
+	b = WithConversionErrorHandler(func(key, value string, err error) {}).FromSource(MapSource(map[string]string{"PORT": "eighty"}))
+	b.To(&got)
+	assert.Empty(t, b.Warnings(), "conversion errors passed to a handler are not warnings")
+}
+
+func TestBuilder_Warnings_env(t *testing.T) {
+	os.Clearenv()
+	defer os.Clearenv()
+	assert.NoError(t, os.Setenv("HOST", ""))
+	assert.NoError(t, os.Setenv("PORT", "80"))
+
+	var got struct{ Host, Port string }
+	b := FromEnv()
+	b.To(&got)
+	assert.Equal(t, []Warning{{Kind: WarningEmptyValue, Key: "host", Message: "empty value dropped, leaving the field unset"}}, b.Warnings())
+}
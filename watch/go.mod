@@ -0,0 +1,19 @@
+module github.com/imduffy15/config/watch
+
+go 1.18
+
+replace github.com/imduffy15/config => ../
+
+require (
+	github.com/fsnotify/fsnotify v1.6.0
+	github.com/imduffy15/config v0.1.0
+	github.com/stretchr/testify v1.7.0
+)
+
+require (
+	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	golang.org/x/sys v0.0.0-20220908164124-27713097b956 // indirect
+	golang.org/x/time v0.3.0 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
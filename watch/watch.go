@@ -0,0 +1,161 @@
+// Package watch rebinds config when the files it is loaded from change, so services pick up changes without restart.
+//
+// It lives in its own module, so that applications which do not watch files do not depend on fsnotify.
+package watch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/imduffy15/config"
+)
+
+// defaultDebounce is how long changes must settle before config is rebuilt, as editors and
+// Kubernetes write files in several steps.
+const defaultDebounce = 100 * time.Millisecond
+
+// Option configures Watch.
+type Option func(*options)
+
+type options struct {
+	files    []string
+	debounce time.Duration
+	clock    config.Clock
+	onError  func(error)
+}
+
+// Files watches paths, the files and directories config is loaded from. Files are watched through their directory,
+// so that changes made by replacing them, as editors and Kubernetes ConfigMap volumes do, are seen.
+func Files(paths ...string) Option {
+	return func(o *options) {
+		o.files = append(o.files, paths...)
+	}
+}
+
+// Debounce waits for changes to settle for d, rather than 100ms, before rebuilding config.
+func Debounce(d time.Duration) Option {
+	return func(o *options) {
+		o.debounce = d
+	}
+}
+
+// Clock times the debounce with clock, rather than config.SystemClock, so that tests can advance a
+// config.ManualClock rather than sleeping.
+func Clock(clock config.Clock) Option {
+	return func(o *options) {
+		o.clock = clock
+	}
+}
+
+// OnError calls onError with the errors of rebuilds which fail, e.g. as a file is malformed, and of the watcher.
+// The previous config is kept, and rebuilt again on the next change. By default, errors are ignored.
+func OnError(onError func(error)) Option {
+	return func(o *options) {
+		o.onError = onError
+	}
+}
+
+// Watch calls onChange whenever the files given with Files change, with target, a struct pointer, or the config
+// it last passed as new, and a fresh pointer of the same type, bound from the Builder returned by build:
+//
+//	var c Config
+//	build := func() *config.Builder { return config.From("config.yaml").FromEnv() }
+//	build().To(&c)
+//	store := config.NewStore(&c)
+//	err := watch.Watch(ctx, &c, build, store.Update, watch.Files("config.yaml"))
+//
+// The config is bound with Bind, so invalid config is an error rather than a panic. onChange is only called
+// if the config differs from the last, as config.Equal compares them, and is called from a single goroutine,
+// which runs until ctx is done.
+// target itself is never changed, so a config.Store can hand readers the latest config without data races.
+// Watch returns immediately, with an error if the files cannot be watched.
+func Watch(ctx context.Context, target interface{}, build func() *config.Builder, onChange func(old, new interface{}), opts ...Option) error {
+	o := options{debounce: defaultDebounce, clock: config.SystemClock, onError: func(error) {}}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	t := reflect.TypeOf(target)
+	if t == nil || t.Kind() != reflect.Ptr || t.Elem().Kind() != reflect.Struct || reflect.ValueOf(target).IsNil() {
+		return fmt.Errorf("config/watch: cannot watch %T, it must be a non nil struct pointer", target)
+	}
+	if len(o.files) == 0 {
+		return errors.New("config/watch: no files to watch, use Files")
+	}
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("config/watch: error creating watcher: %w", err)
+	}
+	watched := make(map[string]bool)
+	for _, file := range o.files {
+		dir := filepath.Dir(filepath.Clean(file))
+		if watched[dir] {
+			continue
+		}
+		if err := watcher.Add(dir); err != nil {
+			watcher.Close()
+			return fmt.Errorf("config/watch: error watching %s: %w", file, err)
+		}
+		watched[dir] = true
+	}
+
+	go func() {
+		defer watcher.Close()
+		old := target
+		// settled is nil, and so never ready, until a change starts the debounce.
+		var settled <-chan time.Time
+		stop := func() bool { return false }
+		defer func() { stop() }()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case _, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				// Every event in the watched directories rebuilds, as Kubernetes swaps a ConfigMap's files
+				// through a symlink whose name is not that of any file; unchanged config is not reported.
+				// The debounce restarts with a fresh timer, so a timer which fired meanwhile is not seen.
+				stop()
+				settled, stop = o.clock.NewTimer(o.debounce)
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				o.onError(fmt.Errorf("config/watch: %w", err))
+			case <-settled:
+				settled = nil
+				fresh := reflect.New(t.Elem()).Interface()
+				if err := bind(build, fresh); err != nil {
+					o.onError(err)
+					continue
+				}
+				if !config.Equal(old, fresh) && ctx.Err() == nil {
+					onChange(old, fresh)
+					old = fresh
+				}
+			}
+		}
+	}()
+	return nil
+}
+
+// bind binds target from the Builder returned by build, which panics if a source fails to load.
+func bind(build func() *config.Builder, target interface{}) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			if e, ok := r.(error); ok {
+				err = e
+				return
+			}
+			err = fmt.Errorf("config/watch: %v", r)
+		}
+	}()
+	return build().Bind(target)
+}
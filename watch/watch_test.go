@@ -0,0 +1,135 @@
+package watch
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/imduffy15/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWatch(t *testing.T) {
+	type testConfig struct {
+		Port int
+		Host string
+	}
+	dir, err := ioutil.TempDir("", "watch")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "app.env")
+	require.NoError(t, ioutil.WriteFile(file, []byte("PORT=8080\nHOST=a\n"), 0600))
+
+	build := func() *config.Builder { return config.From(file) }
+	var c testConfig
+	build().To(&c)
+
+	type change struct{ old, new *testConfig }
+	changes, errs := make(chan change, 10), make(chan error, 10)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	clock := config.NewManualClock(time.Now())
+	err = Watch(ctx, &c, build, func(old, new interface{}) {
+		changes <- change{old.(*testConfig), new.(*testConfig)}
+	}, Files(file), Debounce(time.Second), Clock(clock), OnError(func(err error) { errs <- err }))
+	require.NoError(t, err)
+
+	require.NoError(t, ioutil.WriteFile(file, []byte("PORT=9090\nHOST=a\n"), 0600))
+	clock.BlockUntil(1)
+	clock.Advance(time.Second - time.Nanosecond)
+	select {
+	case ch := <-changes:
+		t.Fatalf("config rebuilt before the changes settled: %v", ch.new)
+	case <-time.After(10 * time.Millisecond):
+	}
+	ch := awaitSettled(t, clock, changes)
+	assert.Equal(t, &testConfig{Port: 8080, Host: "a"}, ch.old)
+	assert.Equal(t, &testConfig{Port: 9090, Host: "a"}, ch.new)
+	assert.Equal(t, testConfig{Port: 8080, Host: "a"}, c, "target is never changed")
+
+	require.NoError(t, ioutil.WriteFile(file, []byte("PORT=not a number\n"), 0600))
+	assert.Equal(t, config.CodeBadType, config.CodeOf(awaitSettled(t, clock, errs)))
+
+	// Replacing the file, as editors and Kubernetes do, is seen too.
+	tmp := filepath.Join(dir, "app.env.tmp")
+	require.NoError(t, ioutil.WriteFile(tmp, []byte("PORT=9090\nHOST=b\n"), 0600))
+	require.NoError(t, os.Rename(tmp, file))
+	ch = awaitSettled(t, clock, changes)
+	assert.Equal(t, &testConfig{Port: 9090, Host: "a"}, ch.old)
+	assert.Equal(t, &testConfig{Port: 9090, Host: "b"}, ch.new)
+}
+
+// awaitSettled advances clock by the debounce until c receives, as a write may be seen as several events,
+// each restarting the debounce.
+func awaitSettled[T any](t *testing.T, clock *config.ManualClock, c <-chan T) T {
+	t.Helper()
+	for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); {
+		select {
+		case v := <-c:
+			return v
+		case <-time.After(time.Millisecond):
+			clock.Advance(time.Second)
+		}
+	}
+	t.Fatal("config was not rebuilt")
+	var zero T
+	return zero
+}
+
+func TestWatch_errors(t *testing.T) {
+	ctx := context.Background()
+	build := func() *config.Builder { return config.FromEnv() }
+	noop := func(old, new interface{}) {}
+	var c struct{ Port int }
+
+	assert.EqualError(t, Watch(ctx, c, build, noop, Files("app.env")), "config/watch: cannot watch struct { Port int }, it must be a non nil struct pointer")
+	assert.EqualError(t, Watch(ctx, &c, build, noop), "config/watch: no files to watch, use Files")
+	assert.Error(t, Watch(ctx, &c, build, noop, Files("/does/not/exist/app.env")))
+}
+
+func TestWatch_wrappers(t *testing.T) {
+	type testConfig struct {
+		Port     config.Meta[int]
+		Password config.Lazy[string]
+	}
+	dir, err := ioutil.TempDir("", "watch")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "app.env")
+	require.NoError(t, ioutil.WriteFile(file, []byte("PORT=secret://8080\nPASSWORD=secret://p\n"), 0600))
+
+	p := config.ValuePreProcessorFunc(func(key, value string) string {
+		return strings.TrimPrefix(value, "secret://")
+	})
+	builds := make(chan struct{}, 10)
+	build := func() *config.Builder {
+		select {
+		case builds <- struct{}{}:
+		default:
+		}
+		return config.WithValuePreProcessor(p).From(file)
+	}
+	var c testConfig
+	build().To(&c)
+	<-builds
+
+	changes := make(chan *testConfig, 10)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	clock := config.NewManualClock(time.Now())
+	err = Watch(ctx, &c, build, func(old, new interface{}) {
+		changes <- new.(*testConfig)
+	}, Files(file), Debounce(time.Second), Clock(clock))
+	require.NoError(t, err)
+
+	// Rewriting the same config re-resolves it, which is not a change.
+	require.NoError(t, ioutil.WriteFile(file, []byte("PORT=secret://8080\nPASSWORD=secret://p\n"), 0600))
+	awaitSettled(t, clock, builds)
+	require.NoError(t, ioutil.WriteFile(file, []byte("PORT=secret://9090\nPASSWORD=secret://p\n"), 0600))
+	assert.Equal(t, 9090, awaitSettled(t, clock, changes).Port.Value, "only the real change is reported")
+}
@@ -0,0 +1,142 @@
+package config
+
+import (
+	"fmt"
+	"io/ioutil"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// YAMLSource returns a Source of the YAML document in file, whose top level must be a mapping.
+// Nested values are flattened into keys:
+//   - mappings are nested with the struct delimiter, so database: {host: ...} is bound from DATABASE__HOST
+//   - sequences of scalars are joined with the slice delimiter, so they bind to slice fields
+//   - sequences holding mappings or sequences are indexed, e.g. SUBNETS__0__ID
+//
+// Scalars are taken as written, so 0640 stays octal and 1.50 keeps its zeros. Nulls are skipped.
+// Anchors, aliases and merge keys are followed.
+func YAMLSource(file string) Source {
+	return namedSource{name: "file " + file, Source: SourceFunc(func() (map[string]string, error) {
+		b, err := ioutil.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		values, err := flattenYAML(b)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", file, err)
+		}
+		return values, nil
+	})}
+}
+
+// FromYAML returns a new Builder, populated with the values in file. See YAMLSource.
+// It panics with an *Error if file cannot be read or parsed.
+func FromYAML(file string) *Builder {
+	return newBuilder().FromYAML(file)
+}
+
+// FromYAML merges the values in file into the current config state, returning the Builder.
+// See YAMLSource. It panics with an *Error if file cannot be read or parsed.
+func (c *Builder) FromYAML(file string) *Builder {
+	return c.FromSource(YAMLSource(file))
+}
+
+func flattenYAML(b []byte) (map[string]string, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(b, &doc); err != nil {
+		return nil, fmt.Errorf("invalid YAML: %w", err)
+	}
+	values := make(map[string]string)
+	if len(doc.Content) == 0 {
+		return values, nil
+	}
+	root := resolveYAMLAlias(doc.Content[0])
+	if root.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("invalid YAML: line %d: top level must be a mapping", root.Line)
+	}
+	if err := flattenYAMLMapping(values, "", root); err != nil {
+		return nil, err
+	}
+	return values, nil
+}
+
+// flattenYAMLMapping flattens the entries of mapping n under prefix.
+// Entries from merge keys are flattened first, so the mapping's own entries override them.
+func flattenYAMLMapping(values map[string]string, prefix string, n *yaml.Node) error {
+	for pass := 0; pass < 2; pass++ {
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			k, v := resolveYAMLAlias(n.Content[i]), n.Content[i+1]
+			if k.Kind != yaml.ScalarNode {
+				return fmt.Errorf("invalid YAML: line %d: keys must be scalars", k.Line)
+			}
+			isMerge := k.Tag == "!!merge"
+			switch {
+			case pass == 0 && isMerge:
+				if err := flattenYAMLMerge(values, prefix, resolveYAMLAlias(v)); err != nil {
+					return err
+				}
+			case pass == 1 && !isMerge:
+				if err := flattenYAMLValue(values, prefix+k.Value, v); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// flattenYAMLMerge flattens the value of a merge key, a mapping or a sequence of mappings,
+// of which earlier mappings take precedence.
+func flattenYAMLMerge(values map[string]string, prefix string, v *yaml.Node) error {
+	switch v.Kind {
+	case yaml.MappingNode:
+		return flattenYAMLMapping(values, prefix, v)
+	case yaml.SequenceNode:
+		for i := len(v.Content) - 1; i >= 0; i-- {
+			if err := flattenYAMLMerge(values, prefix, resolveYAMLAlias(v.Content[i])); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return fmt.Errorf("invalid YAML: line %d: merge key values must be mappings", v.Line)
+}
+
+func flattenYAMLValue(values map[string]string, key string, v *yaml.Node) error {
+	v = resolveYAMLAlias(v)
+	switch v.Kind {
+	case yaml.MappingNode:
+		return flattenYAMLMapping(values, key+structDelim, v)
+	case yaml.SequenceNode:
+		scalars := make([]string, 0, len(v.Content))
+		for _, item := range v.Content {
+			item = resolveYAMLAlias(item)
+			if item.Kind != yaml.ScalarNode {
+				for i, item := range v.Content {
+					if err := flattenYAMLValue(values, key+structDelim+strconv.Itoa(i), item); err != nil {
+						return err
+					}
+				}
+				return nil
+			}
+			if item.Tag != "!!null" {
+				scalars = append(scalars, item.Value)
+			}
+		}
+		values[key] = strings.Join(scalars, sliceDelim)
+	case yaml.ScalarNode:
+		if v.Tag != "!!null" {
+			values[key] = v.Value
+		}
+	}
+	return nil
+}
+
+func resolveYAMLAlias(n *yaml.Node) *yaml.Node {
+	for n.Kind == yaml.AliasNode {
+		n = n.Alias
+	}
+	return n
+}
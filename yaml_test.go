@@ -0,0 +1,103 @@
+package config
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const yamlConfig = `
+defaults: &defaults
+  timeout: 5s
+  retries: 3
+api:
+  <<: *defaults
+  retries: 5
+  endpoint: "https://api.example.com"
+database:
+  host: db.internal
+  port: 5432
+  umask: 0640
+  ratio: 1.50
+  password: ~
+zones: [eu-west-1a, eu-west-1b]
+subnets:
+  - id: subnet-1
+  - id: subnet-2
+motd: |
+  hello
+  world
+`
+
+func TestYAMLSource(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"app.yaml":     yamlConfig,
+		"empty.yaml":   "",
+		"list.yaml":    "- a\n- b\n",
+		"invalid.yaml": "a: [b",
+		"complex.yaml": "? [a]\n: b\n",
+	})
+
+	values, err := YAMLSource(filepath.Join(dir, "app.yaml")).Load()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"defaults__timeout": "5s",
+		"defaults__retries": "3",
+		"api__timeout":      "5s",
+		"api__retries":      "5",
+		"api__endpoint":     "https://api.example.com",
+		"database__host":    "db.internal",
+		"database__port":    "5432",
+		"database__umask":   "0640",
+		"database__ratio":   "1.50",
+		"zones":             "eu-west-1a eu-west-1b",
+		"subnets__0__id":    "subnet-1",
+		"subnets__1__id":    "subnet-2",
+		"motd":              "hello\nworld\n",
+	}, values)
+
+	values, err = YAMLSource(filepath.Join(dir, "empty.yaml")).Load()
+	require.NoError(t, err)
+	assert.Empty(t, values)
+
+	_, err = YAMLSource(filepath.Join(dir, "list.yaml")).Load()
+	assert.EqualError(t, err, filepath.Join(dir, "list.yaml")+": invalid YAML: line 1: top level must be a mapping")
+	_, err = YAMLSource(filepath.Join(dir, "complex.yaml")).Load()
+	assert.EqualError(t, err, filepath.Join(dir, "complex.yaml")+": invalid YAML: line 1: keys must be scalars")
+	_, err = YAMLSource(filepath.Join(dir, "invalid.yaml")).Load()
+	assert.Contains(t, err.Error(), "invalid YAML: yaml: line 1")
+
+	err = recoverError(func() { FromYAML(filepath.Join(dir, "missing.yaml")) })
+	assert.Equal(t, CodeSourceLoad, CodeOf(err))
+}
+
+func TestBuilder_FromYAML(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{"app.yaml": yamlConfig, "override.config": "DATABASE__HOST=localhost"})
+
+	var got struct {
+		API struct {
+			Timeout  string
+			Retries  int
+			Endpoint string
+		}
+		Database struct {
+			Host  string
+			Port  int
+			Umask int `config:",octal"`
+		}
+		Zones []string
+	}
+	FromYAML(filepath.Join(dir, "app.yaml")).From(filepath.Join(dir, "override.config")).To(&got)
+	assert.Equal(t, "5s", got.API.Timeout)
+	assert.Equal(t, 5, got.API.Retries)
+	assert.Equal(t, "localhost", got.Database.Host)
+	assert.Equal(t, 5432, got.Database.Port)
+	assert.Equal(t, 0o640, got.Database.Umask)
+	assert.Equal(t, []string{"eu-west-1a", "eu-west-1b"}, got.Zones)
+}